@@ -0,0 +1,356 @@
+package alert
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"wyw/logging"
+	"wyw/metric"
+)
+
+// State alert
+const (
+	StatePending  = "pending"
+	StateFiring   = "firing"
+	StateResolved = "resolved"
+)
+
+// ErrSilenceNotFound dikembalikan bila silence tidak ada atau sudah kedaluwarsa
+var ErrSilenceNotFound = errors.New("alert: silence not found")
+
+// Alert adalah satu instance rule untuk satu kombinasi label By
+type Alert struct {
+	Name        string            `json:"name" example:"HTTPErrorRatioHigh"`
+	State       string            `json:"state" example:"firing"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	Value       float64           `json:"value" example:"0.083"`
+	ActiveAt    time.Time         `json:"active_at"`
+	FiredAt     *time.Time        `json:"fired_at,omitempty"`
+	ResolvedAt  *time.Time        `json:"resolved_at,omitempty"`
+	// SilencedBy berisi id silence yang cocok, alert yang di-silence tidak dikirim
+	SilencedBy []string `json:"silenced_by,omitempty"`
+	// Inhibited berarti ada alert lain yang membungkam alert ini lewat inhibit rule
+	Inhibited   bool   `json:"inhibited"`
+	Fingerprint string `json:"fingerprint" example:"8c1f0d2a4b6e7f90"`
+}
+
+// Matcher mencocokkan satu label pada silence
+type Matcher struct {
+	Name    string `json:"name" binding:"required" example:"alertname"`
+	Value   string `json:"value" example:"HTTPLatencyP99High"`
+	IsRegex bool   `json:"is_regex"`
+
+	re *regexp.Regexp
+}
+
+// Silence membungkam notifikasi alert yang cocok dengan semua matcher selama aktif
+type Silence struct {
+	ID        string    `json:"id" example:"k2m9x4q7ta"`
+	Matchers  []Matcher `json:"matchers"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	CreatedBy string    `json:"created_by" example:"oncall"`
+	Comment   string    `json:"comment" example:"deploying a fix"`
+}
+
+func (s Silence) active(now time.Time) bool {
+	return !now.Before(s.StartsAt) && now.Before(s.EndsAt)
+}
+
+func (s Silence) matches(labels map[string]string) bool {
+	for _, m := range s.Matchers {
+		if m.re != nil {
+			if !m.re.MatchString(labels[m.Name]) {
+				return false
+			}
+		} else if labels[m.Name] != m.Value {
+			return false
+		}
+	}
+	return true
+}
+
+// Options mengatur Engine
+type Options struct {
+	Interval     time.Duration
+	InhibitRules []InhibitRule
+	Notifier     NotifierOptions
+}
+
+// Engine mengevaluasi rule terhadap metrik exporter sendiri secara berkala, menjalankan
+// state machine pending/firing seperti Prometheus, lalu mengirim notifikasi ke webhook
+// dengan grouping, inhibition dan silence seperti Alertmanager
+type Engine struct {
+	metrics  *metric.AppMetricsExporter
+	rules    []*compiledRule
+	store    *store
+	notifier *notifier
+	opts     Options
+
+	mu       sync.RWMutex
+	alerts   map[string]*Alert
+	silences map[string]*Silence
+}
+
+// NewEngine membuat Engine. Rule yang tidak valid dikembalikan sebagai error.
+func NewEngine(rules []Rule, metrics *metric.AppMetricsExporter, opts Options) (*Engine, error) {
+	compiled, err := compileRules(rules)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = 15 * time.Second
+	}
+	e := &Engine{
+		metrics:  metrics,
+		rules:    compiled,
+		store:    newStore(compiled),
+		notifier: newNotifier(opts.Notifier, metrics),
+		opts:     opts,
+		alerts:   make(map[string]*Alert),
+		silences: make(map[string]*Silence),
+	}
+	metrics.SetAlertSource(e.samples)
+	return e, nil
+}
+
+// Run mengevaluasi rule setiap interval sampai ctx selesai
+func (e *Engine) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.opts.Interval)
+	defer ticker.Stop()
+	for {
+		e.Evaluate(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Evaluate menjalankan satu putaran evaluasi pada waktu now
+func (e *Engine) Evaluate(ctx context.Context, now time.Time) {
+	families, err := e.metrics.Gather()
+	if err != nil {
+		logging.Logger(logging.ModuleMetric).Warn("failed to gather metrics for alerting", slog.Any("error", err))
+	}
+
+	e.mu.Lock()
+	e.store.ingest(now, families)
+	var resolved []*Alert
+	seen := make(map[string]bool)
+	for _, r := range e.rules {
+		for _, s := range e.store.eval(r, now) {
+			if !compare(r.Op, s.value, r.Threshold) {
+				continue
+			}
+			labels := maps.Clone(s.labels)
+			maps.Copy(labels, r.Labels)
+			labels["alertname"] = r.Name
+			fp := fingerprint(labels)
+			seen[fp] = true
+
+			a, ok := e.alerts[fp]
+			if !ok {
+				a = &Alert{Name: r.Name, State: StatePending, Labels: labels, ActiveAt: now, Fingerprint: fp}
+				e.alerts[fp] = a
+			}
+			a.Value = s.value
+			a.Annotations = expand(r.Annotations, labels, s.value)
+			if a.State == StatePending && now.Sub(a.ActiveAt) >= r.forDuration {
+				a.State = StateFiring
+				a.FiredAt = &now
+				logging.Logger(logging.ModuleMetric).Warn("alert firing",
+					slog.String("alert", r.Name), slog.Any("labels", labels), slog.Float64("value", s.value))
+			}
+		}
+	}
+	for fp, a := range e.alerts {
+		if seen[fp] {
+			continue
+		}
+		delete(e.alerts, fp)
+		if a.State == StateFiring {
+			a.State = StateResolved
+			a.ResolvedAt = &now
+			resolved = append(resolved, a)
+			logging.Logger(logging.ModuleMetric).Info("alert resolved", slog.String("alert", a.Name), slog.Any("labels", a.Labels))
+		}
+	}
+
+	for id, s := range e.silences {
+		if !now.Before(s.EndsAt) {
+			delete(e.silences, id)
+		}
+	}
+	var firing []*Alert
+	for _, a := range e.alerts {
+		a.SilencedBy = nil
+		for _, s := range e.silences {
+			if s.active(now) && s.matches(a.Labels) {
+				a.SilencedBy = append(a.SilencedBy, s.ID)
+			}
+		}
+		slices.Sort(a.SilencedBy)
+		a.Inhibited = e.inhibited(a)
+		if a.State == StateFiring && len(a.SilencedBy) == 0 && !a.Inhibited {
+			firing = append(firing, cloneAlert(a))
+		}
+	}
+	for i, a := range resolved {
+		resolved[i] = cloneAlert(a)
+	}
+	e.mu.Unlock()
+
+	e.notifier.update(ctx, now, firing, resolved)
+}
+
+// inhibited melaporkan apakah ada alert firing lain yang membungkam a
+func (e *Engine) inhibited(a *Alert) bool {
+	for _, rule := range e.opts.InhibitRules {
+		if !matchLabels(a.Labels, rule.TargetMatch) {
+			continue
+		}
+		for _, source := range e.alerts {
+			if source == a || source.State != StateFiring || !matchLabels(source.Labels, rule.SourceMatch) {
+				continue
+			}
+			if !slices.ContainsFunc(rule.Equal, func(name string) bool { return source.Labels[name] != a.Labels[name] }) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Alerts mengembalikan alert pending dan firing, yang paling lama aktif lebih dulu
+func (e *Engine) Alerts() []Alert {
+	e.mu.RLock()
+	alerts := make([]Alert, 0, len(e.alerts))
+	for _, a := range e.alerts {
+		alerts = append(alerts, *cloneAlert(a))
+	}
+	e.mu.RUnlock()
+	slices.SortFunc(alerts, func(a, b Alert) int {
+		if c := a.ActiveAt.Compare(b.ActiveAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Fingerprint, b.Fingerprint)
+	})
+	return alerts
+}
+
+// Rules mengembalikan definisi rule yang dievaluasi
+func (e *Engine) Rules() []Rule {
+	rules := make([]Rule, 0, len(e.rules))
+	for _, r := range e.rules {
+		rules = append(rules, r.Rule)
+	}
+	return rules
+}
+
+// AddSilence mengaktifkan silence. StartsAt kosong berarti mulai sekarang.
+func (e *Engine) AddSilence(s Silence) (Silence, error) {
+	if len(s.Matchers) == 0 {
+		return Silence{}, errors.New("silence needs at least one matcher")
+	}
+	for i, m := range s.Matchers {
+		if m.IsRegex {
+			re, err := regexp.Compile("^(?:" + m.Value + ")$")
+			if err != nil {
+				return Silence{}, fmt.Errorf("matcher %s: %w", m.Name, err)
+			}
+			s.Matchers[i].re = re
+		}
+	}
+	if s.StartsAt.IsZero() {
+		s.StartsAt = time.Now().UTC()
+	}
+	if !s.EndsAt.After(s.StartsAt) || !s.EndsAt.After(time.Now()) {
+		return Silence{}, errors.New("silence must end after it starts and in the future")
+	}
+	s.ID = strings.ToLower(rand.Text()[:10])
+
+	e.mu.Lock()
+	e.silences[s.ID] = &s
+	e.mu.Unlock()
+	logging.Logger(logging.ModuleMetric).Info("alert silence created",
+		slog.String("id", s.ID), slog.String("created_by", s.CreatedBy), slog.Time("ends_at", s.EndsAt))
+	return s, nil
+}
+
+// Silences mengembalikan silence yang aktif atau akan aktif, yang paling cepat berakhir lebih dulu
+func (e *Engine) Silences() []Silence {
+	e.mu.RLock()
+	silences := make([]Silence, 0, len(e.silences))
+	now := time.Now()
+	for _, s := range e.silences {
+		if now.Before(s.EndsAt) {
+			silences = append(silences, *s)
+		}
+	}
+	e.mu.RUnlock()
+	slices.SortFunc(silences, func(a, b Silence) int { return a.EndsAt.Compare(b.EndsAt) })
+	return silences
+}
+
+// RemoveSilence mengakhiri silence sebelum waktunya
+func (e *Engine) RemoveSilence(id string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.silences[id]; !ok {
+		return ErrSilenceNotFound
+	}
+	delete(e.silences, id)
+	return nil
+}
+
+// samples mengembalikan alert aktif untuk metrik ALERTS
+func (e *Engine) samples() []metric.AlertSample {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	samples := make([]metric.AlertSample, 0, len(e.alerts))
+	for _, a := range e.alerts {
+		samples = append(samples, metric.AlertSample{Labels: a.Labels, State: a.State, ActiveAt: a.ActiveAt})
+	}
+	return samples
+}
+
+// expand mengganti {{ $value }} dan {{ $labels.nama }} di annotation
+func expand(annotations, labels map[string]string, value float64) map[string]string {
+	out := make(map[string]string, len(annotations))
+	for k, v := range annotations {
+		v = strings.ReplaceAll(v, "{{ $value }}", strconv.FormatFloat(value, 'g', 4, 64))
+		for name, lv := range labels {
+			v = strings.ReplaceAll(v, "{{ $labels."+name+" }}", lv)
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// fingerprint adalah identitas alert dari labelnya, stabil di antara evaluasi
+func fingerprint(labels map[string]string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(seriesKey("", labels)))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+func cloneAlert(a *Alert) *Alert {
+	c := *a
+	c.Labels = maps.Clone(a.Labels)
+	c.Annotations = maps.Clone(a.Annotations)
+	c.SilencedBy = slices.Clone(a.SilencedBy)
+	return &c
+}
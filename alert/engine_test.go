@@ -0,0 +1,174 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+	"testing"
+	"time"
+	"wyw/metric"
+	"wyw/testutil"
+)
+
+// newWebhookReceiver mencatat payload webhook Alertmanager yang diterima
+func newWebhookReceiver(t *testing.T) *testutil.Receiver[WebhookMessage] {
+	t.Helper()
+	return testutil.NewReceiver(t, func(r *http.Request) (WebhookMessage, error) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			return WebhookMessage{}, errors.New("want a JSON POST")
+		}
+		return testutil.DecodeJSON[WebhookMessage](r)
+	})
+}
+
+var sessionsHigh = Rule{
+	Name:        "SessionsHigh",
+	Kind:        KindThreshold,
+	Selector:    Selector{Metric: "app_active_sessions"},
+	Op:          ">",
+	Threshold:   10,
+	For:         "1m",
+	Labels:      map[string]string{"severity": "critical"},
+	Annotations: map[string]string{"summary": "{{ $value }} active sessions"},
+}
+
+func TestWebhookPayload(t *testing.T) {
+	wr := newWebhookReceiver(t)
+	metrics := metric.NewAppMetricsExporter()
+	engine, err := NewEngine([]Rule{sessionsHigh}, metrics, Options{
+		Notifier: NotifierOptions{
+			WebhookURLs:    []string{wr.URL},
+			RepeatInterval: time.Hour,
+			ExternalURL:    "https://wyw.example.com/",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	metrics.SetActiveSessions(20)
+	engine.Evaluate(ctx, start)
+	if got := wr.Received(); len(got) != 0 {
+		t.Fatalf("pending alert was notified: %+v", got)
+	}
+
+	engine.Evaluate(ctx, start.Add(time.Minute))
+	got := wr.Received()
+	if len(got) != 1 {
+		t.Fatalf("received %d notifications after the alert fired, want 1", len(got))
+	}
+	firing := got[0]
+	wantLabels := map[string]string{"alertname": "SessionsHigh", "severity": "critical"}
+	if firing.Version != "4" || firing.Status != StateFiring || firing.Receiver != "webhook" {
+		t.Fatalf("version=%q status=%q receiver=%q", firing.Version, firing.Status, firing.Receiver)
+	}
+	if firing.GroupKey != `{}:{alertname="SessionsHigh"}` {
+		t.Fatalf("groupKey = %s", firing.GroupKey)
+	}
+	if !maps.Equal(firing.GroupLabels, map[string]string{"alertname": "SessionsHigh"}) {
+		t.Fatalf("groupLabels = %v", firing.GroupLabels)
+	}
+	if !maps.Equal(firing.CommonLabels, wantLabels) {
+		t.Fatalf("commonLabels = %v, want %v", firing.CommonLabels, wantLabels)
+	}
+	if firing.CommonAnnotations["summary"] != "20 active sessions" {
+		t.Fatalf("commonAnnotations = %v", firing.CommonAnnotations)
+	}
+	if firing.ExternalURL != "https://wyw.example.com/" || len(firing.Alerts) != 1 {
+		t.Fatalf("externalURL=%q alerts=%d", firing.ExternalURL, len(firing.Alerts))
+	}
+	a := firing.Alerts[0]
+	if a.Status != StateFiring || !maps.Equal(a.Labels, wantLabels) || a.Fingerprint == "" {
+		t.Fatalf("alert = %+v", a)
+	}
+	if !a.StartsAt.Equal(start) || !a.EndsAt.IsZero() {
+		t.Fatalf("startsAt=%s endsAt=%s, want the pending time and no end", a.StartsAt, a.EndsAt)
+	}
+	if a.GeneratorURL != "https://wyw.example.com/api/v1/admin/alerts" {
+		t.Fatalf("generatorURL = %s", a.GeneratorURL)
+	}
+
+	// Grup yang tidak berubah tidak dikirim ulang sebelum RepeatInterval
+	engine.Evaluate(ctx, start.Add(2*time.Minute))
+	if got := wr.Received(); len(got) != 1 {
+		t.Fatalf("unchanged group was sent again: %d notifications", len(got))
+	}
+
+	metrics.SetActiveSessions(0)
+	engine.Evaluate(ctx, start.Add(3*time.Minute))
+	got = wr.Received()
+	if len(got) != 2 {
+		t.Fatalf("received %d notifications after the alert resolved, want 2", len(got))
+	}
+	resolved := got[1]
+	if resolved.Status != StateResolved || resolved.GroupKey != firing.GroupKey || len(resolved.Alerts) != 1 {
+		t.Fatalf("resolved message status=%q groupKey=%q alerts=%d", resolved.Status, resolved.GroupKey, len(resolved.Alerts))
+	}
+	r := resolved.Alerts[0]
+	if r.Status != StateResolved || r.Fingerprint != a.Fingerprint || !r.EndsAt.Equal(start.Add(3*time.Minute)) {
+		t.Fatalf("resolved alert = %+v", r)
+	}
+}
+
+func TestCompileRulesRejectsLabelNames(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		labels map[string]string
+		by     []string
+	}{
+		{name: "alertstate label", labels: map[string]string{"alertstate": "firing"}},
+		{name: "alertname label", labels: map[string]string{"alertname": "Other"}},
+		{name: "internal label", labels: map[string]string{"__name__": "x"}},
+		{name: "invalid label", labels: map[string]string{"team-name": "core"}},
+		{name: "invalid by", by: []string{"1st"}},
+		{name: "reserved by", by: []string{"alertstate"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := sessionsHigh
+			r.Labels, r.By = tc.labels, tc.by
+			if _, err := compileRules([]Rule{r}); err == nil {
+				t.Fatal("rule was accepted")
+			}
+		})
+	}
+	if _, err := compileRules([]Rule{sessionsHigh}); err != nil {
+		t.Fatalf("valid rule rejected: %v", err)
+	}
+}
+
+func TestAlertsMetricSkipsInvalidLabels(t *testing.T) {
+	metrics := metric.NewAppMetricsExporter()
+	activeAt := time.Unix(1700000000, 0)
+	metrics.SetAlertSource(func() []metric.AlertSample {
+		return []metric.AlertSample{
+			{Labels: map[string]string{"alertname": "Good"}, State: StateFiring, ActiveAt: activeAt},
+			// Label series bisa saja bentrok dengan alertstate atau tidak valid
+			{Labels: map[string]string{"alertname": "Clash", "alertstate": "x"}, State: StateFiring, ActiveAt: activeAt},
+			{Labels: map[string]string{"alertname": "Invalid", "__internal": "x"}, State: StatePending, ActiveAt: activeAt},
+		}
+	})
+
+	families, err := metrics.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var alerts []string
+	for _, f := range families {
+		if f.GetName() != "ALERTS" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "alertname" {
+					alerts = append(alerts, l.GetValue())
+				}
+			}
+		}
+	}
+	if len(alerts) != 1 || alerts[0] != "Good" {
+		t.Fatalf("ALERTS series = %v, want only the valid alert", alerts)
+	}
+}
@@ -0,0 +1,285 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"wyw/logging"
+	"wyw/metric"
+)
+
+// NotifierOptions mengatur pengiriman notifikasi, semantiknya sama dengan route Alertmanager
+type NotifierOptions struct {
+	// WebhookURLs menerima payload webhook Alertmanager, kosong berarti notifikasi dimatikan
+	WebhookURLs []string
+	Timeout     time.Duration
+	// GroupBy menentukan label yang menggabungkan alert ke satu notifikasi
+	GroupBy []string
+	// GroupWait adalah jeda sebelum notifikasi pertama sebuah grup, supaya alert yang
+	// muncul bersamaan terkirim sekaligus
+	GroupWait time.Duration
+	// GroupInterval adalah jeda minimum antar notifikasi grup yang isinya berubah
+	GroupInterval time.Duration
+	// RepeatInterval adalah jeda pengiriman ulang grup yang isinya tidak berubah
+	RepeatInterval time.Duration
+	// ExternalURL dipakai untuk generatorURL dan externalURL di payload
+	ExternalURL string
+}
+
+// WebhookMessage adalah payload webhook Alertmanager versi 4
+type WebhookMessage struct {
+	Version           string            `json:"version"`
+	GroupKey          string            `json:"groupKey"`
+	TruncatedAlerts   int               `json:"truncatedAlerts"`
+	Status            string            `json:"status"`
+	Receiver          string            `json:"receiver"`
+	GroupLabels       map[string]string `json:"groupLabels"`
+	CommonLabels      map[string]string `json:"commonLabels"`
+	CommonAnnotations map[string]string `json:"commonAnnotations"`
+	ExternalURL       string            `json:"externalURL"`
+	Alerts            []WebhookAlert    `json:"alerts"`
+}
+
+// WebhookAlert adalah satu alert di WebhookMessage
+type WebhookAlert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// receiverName dipakai sebagai field receiver di payload dan label metrik
+const receiverName = "webhook"
+
+// group adalah kumpulan alert dengan nilai label GroupBy yang sama
+type group struct {
+	key       string
+	labels    map[string]string
+	firing    map[string]*Alert
+	resolved  map[string]*Alert
+	notified  map[string]bool
+	firstSeen time.Time
+	lastSent  time.Time
+	// sentFiring adalah fingerprint alert firing pada notifikasi terakhir
+	sentFiring []string
+}
+
+// notifier menggabungkan alert per grup lalu mengirimnya ke webhook. Hanya dipanggil dari
+// goroutine evaluasi sehingga tidak perlu lock.
+type notifier struct {
+	opts    NotifierOptions
+	metrics *metric.AppMetricsExporter
+	client  *http.Client
+	groups  map[string]*group
+}
+
+func newNotifier(opts NotifierOptions, metrics *metric.AppMetricsExporter) *notifier {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.GroupBy == nil {
+		opts.GroupBy = []string{"alertname"}
+	}
+	return &notifier{opts: opts, metrics: metrics, client: &http.Client{Timeout: opts.Timeout}, groups: make(map[string]*group)}
+}
+
+// update menerima alert firing yang boleh dikirim (tidak di-silence atau di-inhibit) dan
+// alert yang baru resolved, lalu mengirim notifikasi grup yang sudah waktunya
+func (n *notifier) update(ctx context.Context, now time.Time, firing, resolved []*Alert) {
+	current := make(map[string]map[string]*Alert)
+	for _, a := range firing {
+		g := n.group(a, now)
+		if current[g.key] == nil {
+			current[g.key] = make(map[string]*Alert)
+		}
+		current[g.key][a.Fingerprint] = a
+	}
+	resolvedByFP := make(map[string]*Alert, len(resolved))
+	for _, a := range resolved {
+		resolvedByFP[a.Fingerprint] = a
+	}
+
+	for key, g := range n.groups {
+		// Alert yang hilang dari firing tanpa resolved berarti baru di-silence atau
+		// di-inhibit; yang sudah pernah dikirim diberitahukan sebagai resolved
+		for fp, a := range g.firing {
+			if _, ok := current[key][fp]; ok {
+				continue
+			}
+			if g.notified[fp] {
+				r, ok := resolvedByFP[fp]
+				if !ok {
+					r = cloneAlert(a)
+					r.State = StateResolved
+					r.ResolvedAt = &now
+				}
+				g.resolved[fp] = r
+			}
+			delete(g.firing, fp)
+			delete(g.notified, fp)
+		}
+		maps.Copy(g.firing, current[key])
+
+		if n.due(g, now) {
+			if err := n.send(ctx, g); err != nil {
+				logging.Logger(logging.ModuleMetric).Warn("failed to send alert notification",
+					slog.String("group", g.key), slog.Any("error", err))
+				continue
+			}
+			g.lastSent = now
+			g.sentFiring = sortedKeys(g.firing)
+			for fp := range g.firing {
+				g.notified[fp] = true
+			}
+			clear(g.resolved)
+		}
+		if len(g.firing) == 0 && len(g.resolved) == 0 {
+			delete(n.groups, key)
+		}
+	}
+}
+
+// group mengembalikan grup alert a, membuatnya bila belum ada
+func (n *notifier) group(a *Alert, now time.Time) *group {
+	labels := make(map[string]string, len(n.opts.GroupBy))
+	for _, name := range n.opts.GroupBy {
+		if v, ok := a.Labels[name]; ok {
+			labels[name] = v
+		}
+	}
+	pairs := make([]string, 0, len(labels))
+	for _, name := range sortedKeys(labels) {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", name, labels[name]))
+	}
+	key := "{}:{" + strings.Join(pairs, ",") + "}"
+
+	g, ok := n.groups[key]
+	if !ok {
+		g = &group{
+			key:       key,
+			labels:    labels,
+			firing:    make(map[string]*Alert),
+			resolved:  make(map[string]*Alert),
+			notified:  make(map[string]bool),
+			firstSeen: now,
+		}
+		n.groups[key] = g
+	}
+	return g
+}
+
+// due menerapkan group_wait, group_interval dan repeat_interval
+func (n *notifier) due(g *group, now time.Time) bool {
+	if len(n.opts.WebhookURLs) == 0 {
+		return false
+	}
+	if g.lastSent.IsZero() {
+		return len(g.firing) > 0 && now.Sub(g.firstSeen) >= n.opts.GroupWait
+	}
+	changed := len(g.resolved) > 0 || !slices.Equal(g.sentFiring, sortedKeys(g.firing))
+	if changed {
+		return now.Sub(g.lastSent) >= n.opts.GroupInterval
+	}
+	return len(g.firing) > 0 && now.Sub(g.lastSent) >= n.opts.RepeatInterval
+}
+
+// send mengirim grup ke semua webhook, error bila salah satu gagal supaya dicoba lagi
+// pada evaluasi berikutnya
+func (n *notifier) send(ctx context.Context, g *group) error {
+	msg := n.message(g)
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	var failed error
+	for _, url := range n.opts.WebhookURLs {
+		if err := n.post(ctx, url, body); err != nil {
+			n.metrics.RecordAlertNotification(receiverName, "failure")
+			failed = err
+			continue
+		}
+		n.metrics.RecordAlertNotification(receiverName, "success")
+	}
+	if failed == nil {
+		logging.Logger(logging.ModuleMetric).Info("alert notification sent",
+			slog.String("group", g.key), slog.String("status", msg.Status), slog.Int("alerts", len(msg.Alerts)))
+	}
+	return failed
+}
+
+func (n *notifier) post(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook %s answered %s", url, resp.Status)
+	}
+	return nil
+}
+
+// message membuat payload webhook Alertmanager dari isi grup
+func (n *notifier) message(g *group) WebhookMessage {
+	msg := WebhookMessage{
+		Version:     "4",
+		GroupKey:    g.key,
+		Status:      StateResolved,
+		Receiver:    receiverName,
+		GroupLabels: g.labels,
+		ExternalURL: n.opts.ExternalURL,
+	}
+	var alerts []*Alert
+	for _, fp := range sortedKeys(g.firing) {
+		alerts = append(alerts, g.firing[fp])
+		msg.Status = StateFiring
+	}
+	for _, fp := range sortedKeys(g.resolved) {
+		alerts = append(alerts, g.resolved[fp])
+	}
+
+	for i, a := range alerts {
+		wa := WebhookAlert{
+			Status:       StateFiring,
+			Labels:       a.Labels,
+			Annotations:  a.Annotations,
+			StartsAt:     a.ActiveAt,
+			GeneratorURL: strings.TrimRight(n.opts.ExternalURL, "/") + "/api/v1/admin/alerts",
+			Fingerprint:  a.Fingerprint,
+		}
+		if a.ResolvedAt != nil {
+			wa.Status = StateResolved
+			wa.EndsAt = *a.ResolvedAt
+		}
+		msg.Alerts = append(msg.Alerts, wa)
+
+		if i == 0 {
+			msg.CommonLabels = maps.Clone(a.Labels)
+			msg.CommonAnnotations = maps.Clone(a.Annotations)
+			continue
+		}
+		maps.DeleteFunc(msg.CommonLabels, func(k, v string) bool { return a.Labels[k] != v })
+		maps.DeleteFunc(msg.CommonAnnotations, func(k, v string) bool { return a.Annotations[k] != v })
+	}
+	return msg
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	return slices.Sorted(maps.Keys(m))
+}
@@ -0,0 +1,276 @@
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Jenis rule
+const (
+	// KindThreshold membandingkan nilai metrik saat ini, cocok untuk gauge
+	KindThreshold = "threshold"
+	// KindRate membandingkan kenaikan counter per detik dalam window
+	KindRate = "rate"
+	// KindRatio membandingkan metrik dengan Denominator. Counter dihitung kenaikannya dalam
+	// window, gauge memakai nilai saat ini.
+	KindRatio = "ratio"
+	// KindQuantile membandingkan persentil histogram dalam window
+	KindQuantile = "quantile"
+)
+
+// Kinds adalah semua jenis rule yang dikenal
+var Kinds = []string{KindThreshold, KindRate, KindRatio, KindQuantile}
+
+// Ops adalah operator pembanding yang dikenal
+var Ops = []string{">", ">=", "<", "<="}
+
+// Selector memilih series dari registry
+type Selector struct {
+	Metric string `json:"metric" example:"app_http_requests_total"`
+	// Match berisi regex per label yang harus cocok penuh, seperti matcher =~ PromQL
+	Match map[string]string `json:"match,omitempty"`
+}
+
+// Rule adalah definisi satu alert, dibaca dari konfigurasi sebagai JSON
+type Rule struct {
+	Name string `json:"name" example:"HTTPErrorRatioHigh"`
+	Kind string `json:"kind" example:"ratio"`
+	Selector
+	// Denominator wajib untuk rule ratio
+	Denominator *Selector `json:"denominator,omitempty"`
+	// Quantile wajib untuk rule quantile, misalnya 0.99
+	Quantile float64 `json:"quantile,omitempty"`
+	// Window adalah rentang kenaikan counter, default 5m
+	Window string `json:"window,omitempty" example:"5m"`
+	// By memecah rule menjadi satu alert per kombinasi label ini
+	By        []string `json:"by,omitempty"`
+	Op        string   `json:"op" example:">"`
+	Threshold float64  `json:"threshold" example:"0.05"`
+	// For adalah lama kondisi harus terpenuhi sebelum alert firing
+	For         string            `json:"for,omitempty" example:"5m"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// InhibitRule membungkam alert target selama ada alert source yang firing dengan nilai
+// label Equal yang sama, seperti inhibit_rules Alertmanager
+type InhibitRule struct {
+	SourceMatch map[string]string `json:"source_match"`
+	TargetMatch map[string]string `json:"target_match"`
+	Equal       []string          `json:"equal,omitempty"`
+}
+
+// DefaultRules dipakai bila konfigurasi tidak mendeklarasikan rule
+var DefaultRules = []Rule{
+	{
+		Name:        "HTTPErrorRatioHigh",
+		Kind:        KindRatio,
+		Selector:    Selector{Metric: "app_http_requests_total", Match: map[string]string{"status": "5.."}},
+		Denominator: &Selector{Metric: "app_http_requests_total"},
+		Op:          ">",
+		Threshold:   0.05,
+		For:         "5m",
+		Labels:      map[string]string{"severity": "critical", "component": "http"},
+		Annotations: map[string]string{"summary": "More than 5% of HTTP requests fail", "description": "5xx ratio over 5m is {{ $value }}"},
+	},
+	{
+		Name:        "HTTPErrorRatioElevated",
+		Kind:        KindRatio,
+		Selector:    Selector{Metric: "app_http_requests_total", Match: map[string]string{"status": "5.."}},
+		Denominator: &Selector{Metric: "app_http_requests_total"},
+		Op:          ">",
+		Threshold:   0.01,
+		For:         "10m",
+		Labels:      map[string]string{"severity": "warning", "component": "http"},
+		Annotations: map[string]string{"summary": "More than 1% of HTTP requests fail", "description": "5xx ratio over 5m is {{ $value }}"},
+	},
+	{
+		Name:      "HTTPLatencyP99High",
+		Kind:      KindQuantile,
+		Selector:  Selector{Metric: "app_http_request_duration_seconds"},
+		Quantile:  0.99,
+		By:        []string{"endpoint"},
+		Op:        ">",
+		Threshold: 1,
+		For:       "5m",
+		Labels:    map[string]string{"severity": "warning", "component": "http"},
+		Annotations: map[string]string{
+			"summary":     "p99 latency of {{ $labels.endpoint }} is above 1s",
+			"description": "p99 over 5m is {{ $value }}s",
+		},
+	},
+	{
+		Name:        "DBPoolSaturated",
+		Kind:        KindRatio,
+		Selector:    Selector{Metric: "go_sql_in_use_connections"},
+		Denominator: &Selector{Metric: "go_sql_max_open_connections"},
+		By:          []string{"db_name"},
+		Op:          ">=",
+		Threshold:   0.9,
+		For:         "2m",
+		Labels:      map[string]string{"severity": "warning", "component": "db"},
+		Annotations: map[string]string{"summary": "Database pool {{ $labels.db_name }} is nearly exhausted", "description": "{{ $value }} of the connections are in use"},
+	},
+	{
+		Name:      "FailedLoginsHigh",
+		Kind:      KindRate,
+		Selector:  Selector{Metric: "app_http_requests_total", Match: map[string]string{"endpoint": "/api/v1/login", "code": "invalid_credentials"}},
+		Op:        ">",
+		Threshold: 1,
+		For:       "5m",
+		Labels:    map[string]string{"severity": "warning", "component": "auth"},
+		Annotations: map[string]string{
+			"summary":     "Unusual number of failed logins",
+			"description": "{{ $value }} failed logins per second over 5m, possibly credential stuffing",
+		},
+	},
+}
+
+// DefaultInhibitRules membungkam warning selama critical di komponen yang sama firing
+var DefaultInhibitRules = []InhibitRule{
+	{SourceMatch: map[string]string{"severity": "critical"}, TargetMatch: map[string]string{"severity": "warning"}, Equal: []string{"component"}},
+}
+
+// matcher adalah Selector yang sudah dikompilasi
+type matcher struct {
+	metric string
+	match  map[string]*regexp.Regexp
+}
+
+func (m matcher) matches(labels map[string]string) bool {
+	for name, re := range m.match {
+		if !re.MatchString(labels[name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// compiledRule adalah Rule yang sudah divalidasi
+type compiledRule struct {
+	Rule
+	selector    matcher
+	denominator matcher
+	window      time.Duration
+	forDuration time.Duration
+}
+
+func compileRules(rules []Rule) ([]*compiledRule, error) {
+	compiled := make([]*compiledRule, 0, len(rules))
+	seen := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		if r.Name == "" || r.Metric == "" {
+			return nil, errors.New("alert rule needs a name and metric")
+		}
+		if seen[r.Name] {
+			return nil, fmt.Errorf("alert rule %s: duplicate name", r.Name)
+		}
+		seen[r.Name] = true
+		if !slices.Contains(Kinds, r.Kind) {
+			return nil, fmt.Errorf("alert rule %s: kind must be one of %v", r.Name, Kinds)
+		}
+		if !slices.Contains(Ops, r.Op) {
+			return nil, fmt.Errorf("alert rule %s: op must be one of %v", r.Name, Ops)
+		}
+
+		c := &compiledRule{Rule: r, window: 5 * time.Minute}
+		var err error
+		if r.Window != "" {
+			if c.window, err = time.ParseDuration(r.Window); err != nil || c.window <= 0 {
+				return nil, fmt.Errorf("alert rule %s: invalid window %q", r.Name, r.Window)
+			}
+		}
+		if r.For != "" {
+			if c.forDuration, err = time.ParseDuration(r.For); err != nil || c.forDuration < 0 {
+				return nil, fmt.Errorf("alert rule %s: invalid for %q", r.Name, r.For)
+			}
+		}
+		for name := range r.Labels {
+			if err := checkLabelName(name); err != nil {
+				return nil, fmt.Errorf("alert rule %s: labels: %w", r.Name, err)
+			}
+		}
+		for _, name := range r.By {
+			if err := checkLabelName(name); err != nil {
+				return nil, fmt.Errorf("alert rule %s: by: %w", r.Name, err)
+			}
+		}
+		if c.selector, err = compileSelector(r.Selector); err != nil {
+			return nil, fmt.Errorf("alert rule %s: %w", r.Name, err)
+		}
+		switch r.Kind {
+		case KindRatio:
+			if r.Denominator == nil || r.Denominator.Metric == "" {
+				return nil, fmt.Errorf("alert rule %s: ratio needs a denominator", r.Name)
+			}
+			if c.denominator, err = compileSelector(*r.Denominator); err != nil {
+				return nil, fmt.Errorf("alert rule %s: denominator: %w", r.Name, err)
+			}
+		case KindQuantile:
+			if r.Quantile <= 0 || r.Quantile >= 1 {
+				return nil, fmt.Errorf("alert rule %s: quantile must be between 0 and 1", r.Name)
+			}
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// labelName adalah format nama label Prometheus
+var labelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// reservedLabels diisi sendiri oleh engine dan metrik ALERTS, rule tidak boleh memakainya
+var reservedLabels = []string{"alertname", "alertstate"}
+
+// checkLabelName menolak nama label yang tidak valid atau dicadangkan, karena label
+// tersebut akan membuat metrik ALERTS tidak bisa dibuat
+func checkLabelName(name string) error {
+	if !labelName.MatchString(name) || strings.HasPrefix(name, "__") {
+		return fmt.Errorf("invalid label name %q", name)
+	}
+	if slices.Contains(reservedLabels, name) {
+		return fmt.Errorf("label %s is reserved", name)
+	}
+	return nil
+}
+
+func compileSelector(s Selector) (matcher, error) {
+	m := matcher{metric: s.Metric, match: make(map[string]*regexp.Regexp, len(s.Match))}
+	for name, expr := range s.Match {
+		re, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return matcher{}, fmt.Errorf("label %s: %w", name, err)
+		}
+		m.match[name] = re
+	}
+	return m, nil
+}
+
+// compare menerapkan operator rule
+func compare(op string, value, threshold float64) bool {
+	switch op {
+	case ">":
+		return value > threshold
+	case ">=":
+		return value >= threshold
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	}
+	return false
+}
+
+// matchLabels melaporkan apakah labels berisi semua pasangan di want
+func matchLabels(labels, want map[string]string) bool {
+	for name, value := range want {
+		if labels[name] != value {
+			return false
+		}
+	}
+	return true
+}
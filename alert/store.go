@@ -0,0 +1,297 @@
+package alert
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// point adalah satu nilai series pada waktu evaluasi
+type point struct {
+	t time.Time
+	v float64
+}
+
+// series adalah riwayat nilai satu series dari hasil Gather
+type series struct {
+	name    string
+	labels  map[string]string
+	counter bool
+	// born berarti series muncul setelah store mulai, sehingga nilai sebelumnya dianggap 0.
+	// Tanpa ini request 5xx pertama tidak terhitung karena series-nya baru muncul.
+	born   bool
+	points []point
+}
+
+// store menyimpan riwayat series yang dipakai rule selama window terpanjang
+type store struct {
+	names     map[string]bool
+	retention time.Duration
+	series    map[string]*series
+	started   bool
+}
+
+func newStore(rules []*compiledRule) *store {
+	s := &store{names: make(map[string]bool), series: make(map[string]*series)}
+	for _, r := range rules {
+		s.names[r.selector.metric] = true
+		if r.Kind == KindRatio {
+			s.names[r.denominator.metric] = true
+		}
+		s.retention = max(s.retention, r.window)
+	}
+	return s
+}
+
+// ingest menambahkan hasil Gather sebagai satu titik waktu. Histogram dipecah menjadi series
+// _bucket (dengan label le), _count dan _sum seperti format eksposisi Prometheus.
+func (s *store) ingest(now time.Time, families []*dto.MetricFamily) {
+	for _, mf := range families {
+		if !s.names[mf.GetName()] {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			labels := make(map[string]string, len(m.GetLabel())+1)
+			for _, lp := range m.GetLabel() {
+				labels[lp.GetName()] = lp.GetValue()
+			}
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				s.add(now, mf.GetName(), labels, true, m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				s.add(now, mf.GetName(), labels, false, m.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				s.add(now, mf.GetName(), labels, false, m.GetUntyped().GetValue())
+			case dto.MetricType_HISTOGRAM:
+				h := m.GetHistogram()
+				s.add(now, mf.GetName()+"_count", labels, true, float64(h.GetSampleCount()))
+				s.add(now, mf.GetName()+"_sum", labels, true, h.GetSampleSum())
+				for _, b := range h.GetBucket() {
+					s.add(now, mf.GetName()+"_bucket", withLabel(labels, "le", formatLe(b.GetUpperBound())), true, float64(b.GetCumulativeCount()))
+				}
+				s.add(now, mf.GetName()+"_bucket", withLabel(labels, "le", "+Inf"), true, float64(h.GetSampleCount()))
+			}
+		}
+	}
+
+	// Buang titik yang sudah keluar dari window terpanjang, sisakan satu sebagai titik awal
+	cutoff := now.Add(-s.retention)
+	for key, sr := range s.series {
+		i := 0
+		for i < len(sr.points)-1 && sr.points[i+1].t.Before(cutoff) {
+			i++
+		}
+		sr.points = sr.points[i:]
+		if sr.points[len(sr.points)-1].t.Before(cutoff) {
+			delete(s.series, key)
+		}
+	}
+	s.started = true
+}
+
+func (s *store) add(now time.Time, name string, labels map[string]string, counter bool, v float64) {
+	key := seriesKey(name, labels)
+	sr, ok := s.series[key]
+	if !ok {
+		sr = &series{name: name, labels: labels, counter: counter, born: s.started}
+		s.series[key] = sr
+	}
+	sr.points = append(sr.points, point{t: now, v: v})
+}
+
+// value mengembalikan nilai terakhir series, dipakai untuk gauge
+func (sr *series) value() float64 {
+	return sr.points[len(sr.points)-1].v
+}
+
+// increase menghitung kenaikan counter dalam window dengan memperhitungkan reset, serta
+// rentang waktu yang benar-benar tercakup titik-titiknya
+func (sr *series) increase(now time.Time, window time.Duration) (float64, time.Duration) {
+	start := now.Add(-window)
+	i := 0
+	for i < len(sr.points)-1 && sr.points[i+1].t.Before(start) {
+		i++
+	}
+	pts := sr.points[i:]
+
+	var inc float64
+	if sr.born && i == 0 && !pts[0].t.Before(start) {
+		inc = pts[0].v
+	}
+	for j := 1; j < len(pts); j++ {
+		if d := pts[j].v - pts[j-1].v; d >= 0 {
+			inc += d
+		} else {
+			inc += pts[j].v
+		}
+	}
+	return inc, pts[len(pts)-1].t.Sub(pts[0].t)
+}
+
+// sample adalah nilai satu grup hasil evaluasi rule
+type sample struct {
+	labels map[string]string
+	value  float64
+}
+
+// eval menghitung nilai rule per grup label By
+func (s *store) eval(r *compiledRule, now time.Time) []sample {
+	switch r.Kind {
+	case KindThreshold:
+		return s.aggregate(r.selector, r.selector.metric, r.By, func(sr *series) float64 { return sr.value() })
+	case KindRate:
+		return s.aggregate(r.selector, r.selector.metric, r.By, func(sr *series) float64 {
+			inc, span := sr.increase(now, r.window)
+			if span <= 0 {
+				span = r.window
+			}
+			return inc / span.Seconds()
+		})
+	case KindRatio:
+		valueOf := func(sr *series) float64 {
+			if sr.counter {
+				inc, _ := sr.increase(now, r.window)
+				return inc
+			}
+			return sr.value()
+		}
+		numerators := make(map[string]float64)
+		for _, n := range s.aggregate(r.selector, r.selector.metric, r.By, valueOf) {
+			numerators[seriesKey("", n.labels)] = n.value
+		}
+		// Grup tanpa numerator (misalnya belum ada 5xx sama sekali) bernilai 0
+		var result []sample
+		for _, d := range s.aggregate(r.denominator, r.denominator.metric, r.By, valueOf) {
+			if d.value > 0 {
+				result = append(result, sample{labels: d.labels, value: numerators[seriesKey("", d.labels)] / d.value})
+			}
+		}
+		return result
+	case KindQuantile:
+		return s.quantile(r, now)
+	}
+	return nil
+}
+
+// aggregate menjumlahkan nilai series yang cocok dengan selector per grup label by
+func (s *store) aggregate(m matcher, name string, by []string, valueOf func(*series) float64) []sample {
+	groups := make(map[string]*sample)
+	for _, sr := range s.series {
+		if sr.name != name || !m.matches(sr.labels) {
+			continue
+		}
+		labels := pick(sr.labels, by)
+		key := seriesKey("", labels)
+		g, ok := groups[key]
+		if !ok {
+			g = &sample{labels: labels}
+			groups[key] = g
+		}
+		g.value += valueOf(sr)
+	}
+	result := make([]sample, 0, len(groups))
+	for _, g := range groups {
+		result = append(result, *g)
+	}
+	return result
+}
+
+// quantile menghitung persentil histogram dengan interpolasi linear di dalam bucket,
+// sama seperti histogram_quantile PromQL
+func (s *store) quantile(r *compiledRule, now time.Time) []sample {
+	type bucket struct {
+		le    float64
+		count float64
+	}
+	groups := make(map[string][]bucket)
+	labelsOf := make(map[string]map[string]string)
+	for _, sr := range s.series {
+		if sr.name != r.selector.metric+"_bucket" || !r.selector.matches(sr.labels) {
+			continue
+		}
+		le, err := strconv.ParseFloat(sr.labels["le"], 64)
+		if err != nil {
+			continue
+		}
+		labels := pick(sr.labels, r.By)
+		key := seriesKey("", labels)
+		labelsOf[key] = labels
+		inc, _ := sr.increase(now, r.window)
+		i := slices.IndexFunc(groups[key], func(b bucket) bool { return b.le == le })
+		if i < 0 {
+			groups[key] = append(groups[key], bucket{le: le, count: inc})
+		} else {
+			groups[key][i].count += inc
+		}
+	}
+
+	var result []sample
+	for key, buckets := range groups {
+		slices.SortFunc(buckets, func(a, b bucket) int { return cmp.Compare(a.le, b.le) })
+		total := buckets[len(buckets)-1].count
+		if total == 0 || !math.IsInf(buckets[len(buckets)-1].le, 1) {
+			continue
+		}
+		rank := r.Quantile * total
+		i := slices.IndexFunc(buckets, func(b bucket) bool { return b.count >= rank })
+		var v float64
+		switch {
+		case i == len(buckets)-1:
+			// Jatuh di bucket +Inf, pakai batas atas bucket sebelumnya
+			if len(buckets) > 1 {
+				v = buckets[len(buckets)-2].le
+			}
+		case i == 0:
+			v = buckets[0].le * rank / buckets[0].count
+		default:
+			lower, upper := buckets[i-1], buckets[i]
+			v = lower.le + (upper.le-lower.le)*(rank-lower.count)/(upper.count-lower.count)
+		}
+		result = append(result, sample{labels: labelsOf[key], value: v})
+	}
+	return result
+}
+
+func pick(labels map[string]string, names []string) map[string]string {
+	picked := make(map[string]string, len(names))
+	for _, name := range names {
+		picked[name] = labels[name]
+	}
+	return picked
+}
+
+func withLabel(labels map[string]string, name, value string) map[string]string {
+	out := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		out[k] = v
+	}
+	out[name] = value
+	return out
+}
+
+// seriesKey menyusun identitas series yang stabil dari nama dan label terurut
+func seriesKey(name string, labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for n := range labels {
+		names = append(names, n)
+	}
+	slices.Sort(names)
+	var b strings.Builder
+	b.WriteString(name)
+	for _, n := range names {
+		b.WriteString("\xff")
+		b.WriteString(n)
+		b.WriteString("\xfe")
+		b.WriteString(labels[n])
+	}
+	return b.String()
+}
+
+func formatLe(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
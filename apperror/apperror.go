@@ -0,0 +1,152 @@
+package apperror
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Code adalah kode error domain yang stabil dan aman ditampilkan ke client
+type Code string
+
+const (
+	CodeInvalidJSON        Code = "invalid_json"
+	CodeValidation         Code = "validation_failed"
+	CodeInvalidCredentials Code = "invalid_credentials"
+	CodeUsernameTaken      Code = "username_taken"
+	CodeEmailTaken         Code = "email_taken"
+	CodeEmailNotVerified   Code = "email_not_verified"
+	CodeInvalidToken       Code = "invalid_token"
+	CodeInvalidMFACode     Code = "invalid_mfa_code"
+	CodeMFANotEnrolled     Code = "mfa_not_enrolled"
+	CodeMFAAlreadyEnabled  Code = "mfa_already_enabled"
+	CodeSSOFailed          Code = "sso_failed"
+	CodeSSONotLinked       Code = "sso_account_not_linked"
+	CodeSSOUnavailable     Code = "sso_unavailable"
+	CodeUnauthorized       Code = "unauthorized"
+	CodeForbidden          Code = "forbidden"
+	CodeNotFound           Code = "not_found"
+	CodeBodyTooLarge       Code = "body_too_large"
+	CodeIdempotencyReused  Code = "idempotency_key_reused"
+	CodeIdempotencyBusy    Code = "idempotency_key_in_progress"
+	CodeChaosFault         Code = "chaos_fault"
+	CodeTimeout            Code = "request_timeout"
+	CodeInternal           Code = "internal_error"
+)
+
+type codeInfo struct {
+	status int
+	title  string
+}
+
+var codes = map[Code]codeInfo{
+	CodeInvalidJSON:        {http.StatusBadRequest, "Malformed request body"},
+	CodeValidation:         {http.StatusUnprocessableEntity, "Validation failed"},
+	CodeInvalidCredentials: {http.StatusUnauthorized, "Invalid username or password"},
+	CodeUsernameTaken:      {http.StatusConflict, "Username already taken"},
+	CodeEmailTaken:         {http.StatusConflict, "Email already registered"},
+	CodeEmailNotVerified:   {http.StatusForbidden, "Email address not verified"},
+	CodeInvalidToken:       {http.StatusBadRequest, "Invalid or expired token"},
+	CodeInvalidMFACode:     {http.StatusUnauthorized, "Invalid authentication code"},
+	CodeMFANotEnrolled:     {http.StatusConflict, "Two-factor authentication is not enrolled"},
+	CodeMFAAlreadyEnabled:  {http.StatusConflict, "Two-factor authentication is already enabled"},
+	CodeSSOFailed:          {http.StatusUnauthorized, "Single sign-on failed"},
+	CodeSSONotLinked:       {http.StatusForbidden, "No account is linked to this identity"},
+	CodeSSOUnavailable:     {http.StatusBadGateway, "Identity provider unavailable"},
+	CodeUnauthorized:       {http.StatusUnauthorized, "Authentication required"},
+	CodeForbidden:          {http.StatusForbidden, "Access denied"},
+	CodeNotFound:           {http.StatusNotFound, "Resource not found"},
+	CodeBodyTooLarge:       {http.StatusRequestEntityTooLarge, "Request body too large"},
+	CodeIdempotencyReused:  {http.StatusUnprocessableEntity, "Idempotency key reused with a different payload"},
+	CodeIdempotencyBusy:    {http.StatusConflict, "Request with this idempotency key is still in progress"},
+	CodeChaosFault:         {http.StatusServiceUnavailable, "Injected fault"},
+	CodeTimeout:            {http.StatusServiceUnavailable, "Request timed out"},
+	CodeInternal:           {http.StatusInternalServerError, "Internal server error"},
+}
+
+// Status mengembalikan HTTP status untuk kode error
+func (c Code) Status() int {
+	if info, ok := codes[c]; ok {
+		return info.status
+	}
+	return http.StatusInternalServerError
+}
+
+// Title mengembalikan ringkasan singkat yang sama untuk setiap kemunculan kode error
+func (c Code) Title() string {
+	if info, ok := codes[c]; ok {
+		return info.title
+	}
+	return codes[CodeInternal].title
+}
+
+// FieldError menjelaskan kesalahan pada satu field payload
+type FieldError struct {
+	Field   string `json:"field" example:"username"`
+	Rule    string `json:"rule" example:"required"`
+	Message string `json:"message" example:"username is required"`
+}
+
+// Error adalah error aplikasi yang membawa kode domain dan detail untuk client.
+// Err menyimpan penyebab asli yang hanya dicatat di log, tidak dikirim ke client.
+type Error struct {
+	Code   Code
+	Detail string
+	Fields []FieldError
+	Err    error
+	// status mengganti HTTP status bawaan kode error, dipakai oleh fault injection
+	status int
+}
+
+// New membuat Error baru dengan detail untuk client
+func New(code Code, detail string) *Error {
+	return &Error{Code: code, Detail: detail}
+}
+
+// Wrap membuat Error baru yang membungkus penyebab asli
+func Wrap(code Code, err error, detail string) *Error {
+	return &Error{Code: code, Detail: detail, Err: err}
+}
+
+// WithFields menambahkan daftar kesalahan per field
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	e.Fields = append(e.Fields, fields...)
+	return e
+}
+
+// WithStatus mengganti HTTP status response untuk error ini
+func (e *Error) WithStatus(status int) *Error {
+	e.status = status
+	return e
+}
+
+// Status mengembalikan HTTP status response untuk error ini
+func (e *Error) Status() int {
+	if e.status != 0 {
+		return e.status
+	}
+	return e.Code.Status()
+}
+
+func (e *Error) Error() string {
+	msg := string(e.Code)
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// From mengubah error apa pun menjadi *Error, error yang tidak dikenal menjadi internal_error
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Wrap(CodeInternal, err, "")
+}
@@ -0,0 +1,37 @@
+package apperror
+
+// ContentType adalah media type untuk response error sesuai RFC 7807
+const ContentType = "application/problem+json"
+
+// TypeBase adalah prefix URI untuk field "type" pada problem detail
+const TypeBase = "urn:problem-type:wyw:"
+
+// Problem adalah representasi RFC 7807 problem detail
+type Problem struct {
+	Type      string       `json:"type" example:"urn:problem-type:wyw:invalid_credentials"`
+	Title     string       `json:"title" example:"Invalid username or password"`
+	Status    int          `json:"status" example:"401"`
+	Detail    string       `json:"detail,omitempty" example:"username or password is incorrect"`
+	Instance  string       `json:"instance,omitempty" example:"/api/v1/login"`
+	Code      Code         `json:"code" example:"invalid_credentials"`
+	RequestID string       `json:"request_id,omitempty" example:"3f1c0e6a9b7d4e2f8a1b2c3d4e5f6a7b"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// Problem mengubah Error menjadi problem detail untuk request tertentu
+func (e *Error) Problem(instance, requestID string) Problem {
+	return Problem{
+		Type:      TypeBase + string(e.Code),
+		Title:     e.Code.Title(),
+		Status:    e.Status(),
+		Detail:    e.Detail,
+		Instance:  instance,
+		Code:      e.Code,
+		RequestID: requestID,
+		Errors:    e.Fields,
+	}
+}
+
+// ContextKey adalah key di gin.Context tempat kode error request disimpan,
+// dipakai sebagai label metrik HTTP
+const ContextKey = "error_code"
@@ -0,0 +1,317 @@
+package audit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"wyw/logging"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Outcome hasil sebuah aksi yang diaudit
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeDenied  = "denied"
+)
+
+// Event adalah satu entri audit log. Hash dihitung dari PrevHash dan seluruh field lain,
+// sehingga mengubah atau menghapus satu baris memutus rantai di baris berikutnya.
+type Event struct {
+	ID        uint64    `json:"id" gorm:"primaryKey;autoIncrement"`
+	CreatedAt time.Time `json:"created_at" gorm:"index;precision:6"`
+	Actor     string    `json:"actor" gorm:"index;size:191"`
+	Action    string    `json:"action" gorm:"index;size:64"`
+	Target    string    `json:"target" gorm:"index;size:191"`
+	IP        string    `json:"ip" gorm:"size:64"`
+	RequestID string    `json:"request_id" gorm:"size:128"`
+	Outcome   string    `json:"outcome" gorm:"index;size:16"`
+	Detail    string    `json:"detail,omitempty" gorm:"size:512"`
+	PrevHash  string    `json:"prev_hash" gorm:"size:64"`
+	Hash      string    `json:"hash" gorm:"uniqueIndex;size:64"`
+}
+
+func (Event) TableName() string {
+	return "audit_events"
+}
+
+// ComputeHash menghitung hash entri dari PrevHash dan field-fieldnya. Waktu dinormalisasi
+// ke UTC mikrodetik supaya hasilnya sama setelah disimpan dan dibaca ulang dari MySQL.
+// Dengan key hash berupa HMAC-SHA256, sehingga pihak yang hanya punya akses tulis ke
+// database tidak bisa menghitung ulang rantai setelah mengubah entri.
+func (e Event) ComputeHash(key []byte) string {
+	canonical, _ := json.Marshal([]any{
+		e.PrevHash,
+		e.ID,
+		e.CreatedAt.UTC().Format("2006-01-02T15:04:05.000000Z"),
+		e.Actor,
+		e.Action,
+		e.Target,
+		e.IP,
+		e.RequestID,
+		e.Outcome,
+		e.Detail,
+	})
+	if len(key) == 0 {
+		sum := sha256.Sum256(canonical)
+		return hex.EncodeToString(sum[:])
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(canonical)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Options mengatur Recorder
+type Options struct {
+	// Key adalah kunci HMAC rantai hash, kosong berarti SHA-256 tanpa kunci.
+	// Key tidak boleh diganti setelah audit log berisi entri.
+	Key []byte
+	// AnchorPath adalah file di luar database yang menyimpan ID dan hash entri terakhir,
+	// supaya Verify bisa mendeteksi entri di ujung rantai yang dihapus. Kosong berarti
+	// tanpa anchor.
+	AnchorPath string
+}
+
+// Anchor adalah ujung rantai yang dicatat di luar database
+type Anchor struct {
+	ID   uint64 `json:"id"`
+	Hash string `json:"hash"`
+}
+
+// Recorder menulis event ke tabel audit_events secara append-only
+type Recorder struct {
+	db   *gorm.DB
+	opts Options
+	// mu menserialkan append di instance ini, antar instance dijaga oleh lock baris terakhir
+	mu sync.Mutex
+}
+
+// NewRecorder membuat Recorder dan memastikan tabelnya ada
+func NewRecorder(db *gorm.DB, opts Options) (*Recorder, error) {
+	if err := db.AutoMigrate(&Event{}); err != nil {
+		return nil, err
+	}
+	return &Recorder{db: db, opts: opts}, nil
+}
+
+// Record menambahkan event ke ujung rantai. ID, CreatedAt, PrevHash dan Hash diisi otomatis.
+func (r *Recorder) Record(ctx context.Context, e Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var last Event
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Order("id DESC").Limit(1).Find(&last).Error
+		if err != nil {
+			return err
+		}
+
+		// ID ditentukan di sini, bukan oleh auto increment, karena ikut dihitung dalam hash
+		e.ID = last.ID + 1
+		e.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		e.PrevHash = last.Hash
+		e.Hash = e.ComputeHash(r.opts.Key)
+		return tx.Create(&e).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	// Event sudah tersimpan, anchor yang gagal ditulis hanya membuat Verify tertinggal
+	// sampai Record berikutnya
+	if err := r.writeAnchor(Anchor{ID: e.ID, Hash: e.Hash}); err != nil {
+		logging.Logger(logging.ModuleApp).WarnContext(ctx, "failed to write audit anchor", slog.Any("error", err))
+	}
+	return nil
+}
+
+// writeAnchor mengganti file anchor secara atomik lewat file sementara dan rename.
+// Setiap instance menulis ujung rantai yang ia tambahkan sendiri.
+func (r *Recorder) writeAnchor(a Anchor) error {
+	if r.opts.AnchorPath == "" {
+		return nil
+	}
+	raw, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(r.opts.AnchorPath), filepath.Base(r.opts.AnchorPath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(append(raw, '\n'))
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), r.opts.AnchorPath)
+}
+
+// readAnchor membaca file anchor, nil bila anchor tidak dipakai atau belum pernah ditulis
+func (r *Recorder) readAnchor() (*Anchor, error) {
+	if r.opts.AnchorPath == "" {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(r.opts.AnchorPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("audit: read anchor: %w", err)
+	}
+	var a Anchor
+	if err := json.Unmarshal(raw, &a); err != nil || a.ID == 0 || a.Hash == "" {
+		return nil, fmt.Errorf("audit: anchor file %s is invalid", r.opts.AnchorPath)
+	}
+	return &a, nil
+}
+
+// Filter membatasi event yang dikembalikan Query dan Export
+type Filter struct {
+	Actor   string
+	Action  string
+	Target  string
+	Outcome string
+	Since   time.Time
+	Until   time.Time
+	// BeforeID dipakai sebagai cursor halaman berikutnya pada Query
+	BeforeID uint64
+	Limit    int
+}
+
+func (f Filter) apply(tx *gorm.DB) *gorm.DB {
+	if f.Actor != "" {
+		tx = tx.Where("actor = ?", f.Actor)
+	}
+	if f.Action != "" {
+		tx = tx.Where("action = ?", f.Action)
+	}
+	if f.Target != "" {
+		tx = tx.Where("target = ?", f.Target)
+	}
+	if f.Outcome != "" {
+		tx = tx.Where("outcome = ?", f.Outcome)
+	}
+	if !f.Since.IsZero() {
+		tx = tx.Where("created_at >= ?", f.Since)
+	}
+	if !f.Until.IsZero() {
+		tx = tx.Where("created_at < ?", f.Until)
+	}
+	return tx
+}
+
+// Query mengembalikan event terbaru lebih dulu sesuai filter
+func (r *Recorder) Query(ctx context.Context, f Filter) ([]Event, error) {
+	tx := f.apply(r.db.WithContext(ctx))
+	if f.BeforeID > 0 {
+		tx = tx.Where("id < ?", f.BeforeID)
+	}
+	out := []Event{}
+	err := tx.Order("id DESC").Limit(f.Limit).Find(&out).Error
+	return out, err
+}
+
+// Export memanggil fn untuk setiap event sesuai filter dari yang paling lama,
+// dibaca per batch supaya audit log besar tidak dimuat ke memori sekaligus
+func (r *Recorder) Export(ctx context.Context, f Filter, fn func(Event) error) error {
+	var afterID uint64
+	for {
+		var batch []Event
+		if err := f.apply(r.db.WithContext(ctx)).Where("id > ?", afterID).
+			Order("id ASC").Limit(500).Find(&batch).Error; err != nil {
+			return err
+		}
+		for _, e := range batch {
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+		if len(batch) < 500 {
+			return nil
+		}
+		afterID = batch[len(batch)-1].ID
+	}
+}
+
+// ErrChainBroken dikembalikan Verify bila ada entri yang diubah, dihapus atau disisipkan
+var ErrChainBroken = errors.New("audit: hash chain broken")
+
+// VerifyResult adalah hasil pemeriksaan rantai hash
+type VerifyResult struct {
+	Checked int64 `json:"checked"`
+	// AnchoredID adalah ID ujung rantai menurut file anchor, 0 bila tidak ada anchor
+	AnchoredID uint64 `json:"anchored_id,omitempty"`
+	// BrokenAt adalah ID entri pertama yang tidak cocok, 0 bila rantai utuh
+	BrokenAt uint64 `json:"broken_at,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Verify menelusuri seluruh rantai dari entri pertama dan berhenti di entri pertama yang rusak.
+// Bila ada anchor, rantai juga harus mencapai entri anchor dengan hash yang sama, sehingga
+// entri terakhir yang dihapus ikut terdeteksi.
+func (r *Recorder) Verify(ctx context.Context) (VerifyResult, error) {
+	var res VerifyResult
+	anchor, err := r.readAnchor()
+	if err != nil {
+		return res, err
+	}
+	if anchor != nil {
+		res.AnchoredID = anchor.ID
+	}
+
+	var prev Event
+	err = r.Export(ctx, Filter{}, func(e Event) error {
+		switch {
+		case e.ID != prev.ID+1:
+			res.Reason = "missing or reordered entry"
+		case e.PrevHash != prev.Hash:
+			res.Reason = "prev_hash does not match previous entry"
+		case e.Hash != e.ComputeHash(r.opts.Key):
+			res.Reason = "entry content does not match its hash"
+		case anchor != nil && e.ID == anchor.ID && e.Hash != anchor.Hash:
+			res.Reason = "entry does not match the anchored head"
+		}
+		if res.Reason != "" {
+			res.BrokenAt = e.ID
+			return ErrChainBroken
+		}
+		res.Checked++
+		prev = e
+		return nil
+	})
+	if err != nil {
+		return res, err
+	}
+	if anchor != nil && prev.ID < anchor.ID {
+		res.BrokenAt = prev.ID + 1
+		res.Reason = fmt.Sprintf("chain ends before the anchored head %d", anchor.ID)
+		return res, ErrChainBroken
+	}
+	return res, nil
+}
+
+// ErrAppendOnly dikembalikan bila ada kode yang mencoba mengubah atau menghapus event lewat GORM
+var ErrAppendOnly = errors.New("audit: events are append-only")
+
+func (Event) BeforeUpdate(*gorm.DB) error { return ErrAppendOnly }
+
+func (Event) BeforeDelete(*gorm.DB) error { return ErrAppendOnly }
@@ -0,0 +1,128 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"wyw/testutil"
+
+	"gorm.io/gorm"
+)
+
+var testKey = []byte("audit-test-key")
+
+// newTestRecorder membuat Recorder dengan kunci dan anchor berisi tiga event
+func newTestRecorder(t *testing.T) (*Recorder, *gorm.DB) {
+	t.Helper()
+	db := testutil.NewDB(t)
+	r, err := NewRecorder(db, Options{Key: testKey, AnchorPath: filepath.Join(t.TempDir(), "audit.anchor")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, action := range []string{"login", "password_reset", "logout"} {
+		if err := r.Record(context.Background(), Event{Actor: "alice", Action: action, Outcome: OutcomeSuccess}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return r, db
+}
+
+// rehash menghitung ulang hash mulai dari entri id seperti yang dilakukan penyerang
+func rehash(t *testing.T, db *gorm.DB, from uint64, key []byte) {
+	t.Helper()
+	var events []Event
+	if err := db.Order("id ASC").Find(&events).Error; err != nil {
+		t.Fatal(err)
+	}
+	prev := ""
+	for _, e := range events {
+		if e.ID >= from {
+			e.PrevHash = prev
+			e.Hash = e.ComputeHash(key)
+			if err := db.Exec("UPDATE audit_events SET prev_hash = ?, hash = ? WHERE id = ?", e.PrevHash, e.Hash, e.ID).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+		prev = e.Hash
+	}
+}
+
+func TestVerifyIntactChain(t *testing.T) {
+	r, _ := newTestRecorder(t)
+	res, err := r.Verify(context.Background())
+	if err != nil {
+		t.Fatalf("Verify = %v (%+v)", err, res)
+	}
+	if res.Checked != 3 || res.AnchoredID != 3 || res.BrokenAt != 0 {
+		t.Fatalf("result = %+v, want 3 entries checked up to the anchor", res)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		tamper   func(t *testing.T, db *gorm.DB)
+		brokenAt uint64
+	}{
+		{
+			name: "edited row",
+			tamper: func(t *testing.T, db *gorm.DB) {
+				if err := db.Exec("UPDATE audit_events SET actor = ? WHERE id = 2", "mallory").Error; err != nil {
+					t.Fatal(err)
+				}
+			},
+			brokenAt: 2,
+		},
+		{
+			name: "deleted middle row",
+			tamper: func(t *testing.T, db *gorm.DB) {
+				if err := db.Exec("DELETE FROM audit_events WHERE id = 2").Error; err != nil {
+					t.Fatal(err)
+				}
+			},
+			brokenAt: 3,
+		},
+		{
+			name: "truncated tail",
+			tamper: func(t *testing.T, db *gorm.DB) {
+				if err := db.Exec("DELETE FROM audit_events WHERE id = 3").Error; err != nil {
+					t.Fatal(err)
+				}
+			},
+			brokenAt: 3,
+		},
+		{
+			name: "chain rewritten without the key",
+			tamper: func(t *testing.T, db *gorm.DB) {
+				if err := db.Exec("UPDATE audit_events SET actor = ? WHERE id = 2", "mallory").Error; err != nil {
+					t.Fatal(err)
+				}
+				rehash(t, db, 2, nil)
+			},
+			brokenAt: 2,
+		},
+		{
+			name: "chain rewritten with the key",
+			tamper: func(t *testing.T, db *gorm.DB) {
+				if err := db.Exec("UPDATE audit_events SET actor = ? WHERE id = 2", "mallory").Error; err != nil {
+					t.Fatal(err)
+				}
+				rehash(t, db, 2, testKey)
+			},
+			brokenAt: 3,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, db := newTestRecorder(t)
+			tc.tamper(t, db)
+			res, err := r.Verify(context.Background())
+			if !errors.Is(err, ErrChainBroken) {
+				t.Fatalf("Verify = %v (%+v), want ErrChainBroken", err, res)
+			}
+			if res.BrokenAt != tc.brokenAt || res.Reason == "" {
+				t.Fatalf("result = %+v, want broken at %d", res, tc.brokenAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,363 @@
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"wyw/logging"
+	"wyw/metric"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Redacted menggantikan field rahasia yang nilainya bukan string, serta seluruh body yang
+// tidak bisa disamarkan per field (terpotong atau bukan JSON) tetapi memuat nama field rahasia.
+// Nilai string disamarkan oleh mask dengan fingerprint.
+const Redacted = "[REDACTED]"
+
+// Hasil penulisan record yang dicatat ke metrik
+const (
+	OutcomeWritten = "written"
+	OutcomeDropped = "dropped"
+)
+
+// secretHeaders tidak pernah ditulis ke file capture
+var secretHeaders = map[string]struct{}{
+	"authorization":       {},
+	"proxy-authorization": {},
+	"cookie":              {},
+	"x-api-key":           {},
+}
+
+// hopHeaders diatur ulang oleh client saat replay sehingga tidak perlu disimpan
+var hopHeaders = map[string]struct{}{
+	"connection":        {},
+	"content-length":    {},
+	"keep-alive":        {},
+	"te":                {},
+	"transfer-encoding": {},
+	"upgrade":           {},
+}
+
+// Record adalah satu request yang direkam, ditulis sebagai satu baris JSON
+type Record struct {
+	Time      time.Time         `json:"time"`
+	RequestID string            `json:"request_id,omitempty"`
+	Method    string            `json:"method"`
+	Route     string            `json:"route"`
+	Path      string            `json:"path"`
+	Query     string            `json:"query,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	// Body disimpan sebagai JSON bila payload-nya JSON, selain itu sebagai string
+	Body          json.RawMessage `json:"body,omitempty"`
+	BodyTruncated bool            `json:"body_truncated,omitempty"`
+	Status        int             `json:"status"`
+	LatencyMs     float64         `json:"latency_ms"`
+}
+
+// Options mengatur Recorder
+type Options struct {
+	Path string
+	// SampleRate adalah rasio request yang direkam (0.0 - 1.0)
+	SampleRate float64
+	// MaxBodyBytes membatasi body yang disimpan, sisanya dibuang dan record ditandai truncated
+	MaxBodyBytes int64
+	// Redact berisi nama field JSON dan query parameter yang nilainya disamarkan
+	Redact []string
+	// Skip mengecualikan request tertentu, misalnya route streaming
+	Skip func(*http.Request) bool
+}
+
+// Recorder menulis request yang tersampel ke file JSONL. Penulisan dilakukan di goroutine
+// terpisah; bila antrean penuh record dibuang supaya request tidak ikut melambat.
+type Recorder struct {
+	opts    Options
+	metrics *metric.AppMetricsExporter
+	redact  map[string]struct{}
+	queue   chan Record
+	// key adalah kunci HMAC untuk fingerprint nilai rahasia, dibaca dari file kunci
+	key  []byte
+	file *os.File
+	done chan struct{}
+
+	// mu menjaga queue supaya request yang selesai setelah Close tidak mengirim ke channel tertutup
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewRecorder membuka (atau membuat) file capture dalam mode append beserta file kuncinya
+func NewRecorder(opts Options, metrics *metric.AppMetricsExporter) (*Recorder, error) {
+	key, err := loadKey(opts.Path + ".key")
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(opts.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = 64 << 10
+	}
+	r := &Recorder{
+		opts:    opts,
+		metrics: metrics,
+		redact:  make(map[string]struct{}, len(opts.Redact)),
+		queue:   make(chan Record, 1024),
+		file:    f,
+		done:    make(chan struct{}),
+		key:     key,
+	}
+	for _, field := range opts.Redact {
+		r.redact[strings.ToLower(field)] = struct{}{}
+	}
+	go r.write()
+	return r, nil
+}
+
+// loadKey membaca kunci HMAC dari path, atau membuat kunci acak baru bila file belum ada.
+// Kunci yang sama dipakai setiap kali file capture yang sama ditambah, sehingga penanda
+// nilai yang sama tetap sama setelah restart. File kunci tidak diperlukan untuk replay
+// dan jangan ikut dibagikan bersama file capture.
+func loadKey(path string) ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := crand.Read(key); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err == nil {
+		_, err = f.WriteString(hex.EncodeToString(key) + "\n")
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("capture: write key file: %w", err)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, fs.ErrExist) {
+		return nil, fmt.Errorf("capture: create key file: %w", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("capture: read key file: %w", err)
+	}
+	key, err = hex.DecodeString(strings.TrimSpace(string(raw)))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("capture: key file %s must contain 32 hex-encoded bytes", path)
+	}
+	return key, nil
+}
+
+// Close menulis sisa antrean lalu menutup file
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mu.Unlock()
+	<-r.done
+	return r.file.Close()
+}
+
+func (r *Recorder) write() {
+	defer close(r.done)
+	w := bufio.NewWriter(r.file)
+	enc := json.NewEncoder(w)
+	flush := time.NewTicker(time.Second)
+	defer flush.Stop()
+	for {
+		select {
+		case rec, ok := <-r.queue:
+			if !ok {
+				_ = w.Flush()
+				return
+			}
+			if err := enc.Encode(rec); err != nil {
+				logging.Logger(logging.ModuleApp).Error("failed to write capture record", slog.Any("error", err))
+			}
+		case <-flush.C:
+			_ = w.Flush()
+		}
+	}
+}
+
+// Middleware merekam request yang tersampel. Keputusan sampling diambil sebelum handler
+// berjalan karena body harus disalin sebelum dibaca handler.
+func (r *Recorder) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if (r.opts.Skip != nil && r.opts.Skip(c.Request)) || rand.Float64() >= r.opts.SampleRate {
+			c.Next()
+			return
+		}
+		start := time.Now()
+
+		var body []byte
+		truncated := false
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			body, _ = io.ReadAll(io.LimitReader(c.Request.Body, r.opts.MaxBodyBytes+1))
+			// Handler tetap menerima body utuh: bagian yang sudah dibaca disambung dengan sisanya
+			c.Request.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), c.Request.Body), c.Request.Body}
+			if int64(len(body)) > r.opts.MaxBodyBytes {
+				body, truncated = body[:r.opts.MaxBodyBytes], true
+			}
+		}
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unknown"
+		}
+		rec := Record{
+			Time:          start.UTC(),
+			RequestID:     c.GetString(logging.RequestIDKey),
+			Method:        c.Request.Method,
+			Route:         route,
+			Path:          c.Request.URL.Path,
+			Query:         r.redactQuery(c.Request.URL.Query()),
+			Headers:       r.headers(c.Request.Header),
+			Body:          r.redactBody(body, truncated),
+			BodyTruncated: truncated,
+			Status:        c.Writer.Status(),
+			LatencyMs:     float64(time.Since(start).Microseconds()) / 1000,
+		}
+
+		r.enqueue(rec)
+	}
+}
+
+func (r *Recorder) enqueue(rec Record) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return
+	}
+	select {
+	case r.queue <- rec:
+		r.metrics.RecordCapture(OutcomeWritten)
+	default:
+		r.metrics.RecordCapture(OutcomeDropped)
+	}
+}
+
+func (r *Recorder) isRedacted(key string) bool {
+	_, ok := r.redact[strings.ToLower(key)]
+	return ok
+}
+
+func (r *Recorder) headers(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for name, values := range h {
+		lower := strings.ToLower(name)
+		if _, ok := secretHeaders[lower]; ok {
+			continue
+		}
+		if _, ok := hopHeaders[lower]; ok {
+			continue
+		}
+		out[name] = strings.Join(values, ", ")
+	}
+	return out
+}
+
+func (r *Recorder) redactQuery(q url.Values) string {
+	if len(q) == 0 {
+		return ""
+	}
+	for key := range q {
+		if r.isRedacted(key) {
+			for i, value := range q[key] {
+				q[key][i] = r.mask(value)
+			}
+		}
+	}
+	return q.Encode()
+}
+
+// redactBody menyamarkan field rahasia di body JSON. Body yang terpotong atau bukan JSON
+// tidak bisa diperiksa per field, jadi hanya disimpan bila tidak mengandung nama field rahasia.
+func (r *Recorder) redactBody(body []byte, truncated bool) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+	var v any
+	if !truncated && decodeJSON(body, &v) == nil {
+		out, err := json.Marshal(r.redactValue(v))
+		if err == nil {
+			return out
+		}
+	}
+	lower := strings.ToLower(string(body))
+	for field := range r.redact {
+		if strings.Contains(lower, field) {
+			raw, _ := json.Marshal(Redacted)
+			return raw
+		}
+	}
+	raw, _ := json.Marshal(string(body))
+	return raw
+}
+
+func (r *Recorder) redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if r.isRedacted(key) {
+				if str, ok := item.(string); ok {
+					v[key] = r.mask(str)
+				} else {
+					v[key] = Redacted
+				}
+				continue
+			}
+			v[key] = r.redactValue(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = r.redactValue(item)
+		}
+	}
+	return v
+}
+
+// decodeJSON seperti json.Unmarshal tetapi angka disimpan sebagai json.Number, supaya
+// integer besar seperti ID 64-bit tidak berubah saat body di-encode ulang
+func decodeJSON(data []byte, v *any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("capture: trailing data after JSON value")
+	}
+	return nil
+}
+
+// mask mengganti nilai rahasia dengan penanda yang berisi fingerprint nilainya
+func (r *Recorder) mask(value string) string {
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(value))
+	return fmt.Sprintf("[REDACTED:%x]", mac.Sum(nil)[:6])
+}
@@ -0,0 +1,119 @@
+package capture
+
+import (
+	"maps"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func newTestRecorder() *Recorder {
+	r := &Recorder{redact: map[string]struct{}{}, key: []byte("capture-test-key")}
+	for _, field := range []string{"password", "token", "code", "secret"} {
+		r.redact[field] = struct{}{}
+	}
+	return r
+}
+
+func TestRedactBody(t *testing.T) {
+	r := newTestRecorder()
+	hunter, abc := r.mask("hunter2"), r.mask("abc")
+	for _, tc := range []struct {
+		name      string
+		body      string
+		truncated bool
+		want      string
+	}{
+		{name: "empty", body: "", want: ""},
+		{name: "no secrets", body: `{"username":"alice"}`, want: `{"username":"alice"}`},
+		{name: "string secret", body: `{"username":"alice","password":"hunter2"}`, want: `{"password":"` + hunter + `","username":"alice"}`},
+		{name: "field name case", body: `{"Password":"hunter2"}`, want: `{"Password":"` + hunter + `"}`},
+		{name: "nested object", body: `{"user":{"name":"alice","password":"hunter2"}}`, want: `{"user":{"name":"alice","password":"` + hunter + `"}}`},
+		{name: "array of objects", body: `{"items":[{"token":"abc"},{"token":"abc","n":1}]}`, want: `{"items":[{"token":"` + abc + `"},{"n":1,"token":"` + abc + `"}]}`},
+		{name: "top level array", body: `[{"secret":"abc"},"password"]`, want: `[{"secret":"` + abc + `"},"password"]`},
+		{name: "number secret", body: `{"code":123456}`, want: `{"code":"[REDACTED]"}`},
+		{name: "object secret", body: `{"secret":{"value":"abc"}}`, want: `{"secret":"[REDACTED]"}`},
+		{name: "array secret", body: `{"token":["abc"]}`, want: `{"token":"[REDACTED]"}`},
+		{name: "null secret", body: `{"password":null}`, want: `{"password":"[REDACTED]"}`},
+		{name: "large integer", body: `{"id":12345678901234567890,"password":"hunter2"}`, want: `{"id":12345678901234567890,"password":"` + hunter + `"}`},
+		{name: "truncated with secret", body: `{"username":"alice","password":"hunt`, truncated: true, want: `"[REDACTED]"`},
+		{name: "truncated complete JSON", body: `{"password":"hunter2"}`, truncated: true, want: `"[REDACTED]"`},
+		{name: "truncated without secret", body: `{"username":"ali`, truncated: true, want: `"{\"username\":\"ali"`},
+		{name: "form with secret", body: `username=alice&password=hunter2`, want: `"[REDACTED]"`},
+		{name: "text with secret", body: `TOKEN abc`, want: `"[REDACTED]"`},
+		{name: "text without secret", body: `hello`, want: `"hello"`},
+		{name: "trailing data", body: `{"a":1} {"password":"hunter2"}`, want: `"[REDACTED]"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := string(r.redactBody([]byte(tc.body), tc.truncated)); got != tc.want {
+				t.Fatalf("redactBody(%s) = %s, want %s", tc.body, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMaskIsKeyedAndStable(t *testing.T) {
+	r, other := newTestRecorder(), newTestRecorder()
+	other.key = []byte("another-key")
+	if r.mask("hunter2") != r.mask("hunter2") {
+		t.Fatal("mask is not stable for the same value")
+	}
+	if r.mask("hunter2") == r.mask("hunter3") || r.mask("hunter2") == other.mask("hunter2") {
+		t.Fatal("mask does not depend on the value and the key")
+	}
+}
+
+func TestRedactQuery(t *testing.T) {
+	r := newTestRecorder()
+	for _, tc := range []struct {
+		name  string
+		query string
+		want  url.Values
+	}{
+		{name: "empty", query: "", want: nil},
+		{name: "no secrets", query: "page=2&sort=name", want: url.Values{"page": {"2"}, "sort": {"name"}}},
+		{name: "secret", query: "token=abc&page=2", want: url.Values{"token": {r.mask("abc")}, "page": {"2"}}},
+		{name: "repeated secret", query: "code=abc&code=hunter2", want: url.Values{"code": {r.mask("abc"), r.mask("hunter2")}}},
+		{name: "param name case", query: "Token=abc", want: url.Values{"Token": {r.mask("abc")}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := url.ParseQuery(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := r.redactQuery(q)
+			if tc.want == nil {
+				if got != "" {
+					t.Fatalf("redactQuery(%q) = %q, want empty", tc.query, got)
+				}
+				return
+			}
+			if got != tc.want.Encode() {
+				t.Fatalf("redactQuery(%q) = %q, want %q", tc.query, got, tc.want.Encode())
+			}
+		})
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	r := newTestRecorder()
+	h := http.Header{}
+	h.Set("Authorization", "Bearer abc")
+	h.Set("Proxy-Authorization", "Basic abc")
+	h.Set("Cookie", "session=abc")
+	h.Set("X-API-Key", "abc")
+	h.Set("Connection", "keep-alive")
+	h.Set("Content-Length", "42")
+	h.Set("Transfer-Encoding", "chunked")
+	h.Set("Content-Type", "application/json")
+	h.Add("Accept", "application/json")
+	h.Add("Accept", "text/plain")
+
+	want := map[string]string{
+		"Content-Type": "application/json",
+		"Accept":       "application/json, text/plain",
+	}
+	if got := r.headers(h); !maps.Equal(got, want) {
+		t.Fatalf("headers = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,398 @@
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+// ReadRecords membaca file JSONL hasil capture dan mengurutkannya berdasarkan waktu
+func ReadRecords(r io.Reader) ([]Record, error) {
+	var records []Record
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64<<10), 16<<20)
+	for line := 1; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, rec)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(records, func(a, b Record) int { return a.Time.Compare(b.Time) })
+	return records, nil
+}
+
+// ReplayOptions mengatur pengiriman ulang request
+type ReplayOptions struct {
+	// Target adalah base URL service tujuan, misalnya http://localhost:8080
+	Target string
+	// Speed mengatur jeda antar request relatif terhadap rekaman: 1 sama dengan aslinya,
+	// 2 dua kali lebih cepat, 0 berarti secepat mungkin dengan urutan tetap
+	Speed       float64
+	Concurrency int
+	Timeout     time.Duration
+	// Password menggantikan nilai yang disamarkan saat capture
+	Password string
+	// Headers ditambahkan ke setiap request, misalnya X-API-Key untuk endpoint admin
+	Headers map[string]string
+}
+
+// LatencySummary adalah persentil latency dalam milidetik
+type LatencySummary struct {
+	P50 float64 `json:"p50_ms"`
+	P90 float64 `json:"p90_ms"`
+	P99 float64 `json:"p99_ms"`
+	Max float64 `json:"max_ms"`
+}
+
+// RouteComparison membandingkan latency rekaman dan replay untuk satu route
+type RouteComparison struct {
+	Method   string         `json:"method"`
+	Route    string         `json:"route"`
+	Count    int64          `json:"count"`
+	Recorded LatencySummary `json:"recorded"`
+	Replayed LatencySummary `json:"replayed"`
+}
+
+// StatusMismatch menghitung request dengan status replay yang berbeda dari rekaman
+type StatusMismatch struct {
+	Method   string `json:"method"`
+	Route    string `json:"route"`
+	Recorded int    `json:"recorded"`
+	// Replayed bernilai 0 bila request gagal di level transport
+	Replayed int   `json:"replayed"`
+	Count    int64 `json:"count"`
+}
+
+// ReplayReport adalah hasil perbandingan rekaman dan replay
+type ReplayReport struct {
+	Records  int     `json:"records"`
+	Replayed int64   `json:"replayed"`
+	Elapsed  float64 `json:"elapsed_seconds"`
+	// Skipped berisi alasan request tidak dikirim, misalnya body terpotong
+	Skipped       map[string]int64  `json:"skipped,omitempty"`
+	StatusMatched int64             `json:"status_matched"`
+	Mismatches    []StatusMismatch  `json:"mismatches,omitempty"`
+	Errors        map[string]int64  `json:"errors,omitempty"`
+	Routes        []RouteComparison `json:"routes"`
+}
+
+type routeKey struct{ method, route string }
+
+type mismatchKey struct {
+	routeKey
+	recorded, replayed int
+}
+
+type routeStats struct {
+	count              int64
+	recorded, replayed *hdrhistogram.Histogram
+}
+
+// Replay mengirim ulang records ke target dan membandingkan status dan latency-nya
+func Replay(ctx context.Context, records []Record, opts ReplayOptions) (ReplayReport, error) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 32
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 30 * time.Second
+	}
+	target := strings.TrimRight(opts.Target, "/")
+	client := &http.Client{
+		Timeout: opts.Timeout,
+		Transport: &http.Transport{
+			MaxIdleConnsPerHost: opts.Concurrency,
+		},
+		// Redirect (misalnya login OIDC) dibandingkan apa adanya, tidak diikuti
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+
+	var (
+		mu         sync.Mutex
+		report     = ReplayReport{Records: len(records), Skipped: map[string]int64{}, Errors: map[string]int64{}}
+		routes     = map[routeKey]*routeStats{}
+		mismatches = map[mismatchKey]int64{}
+		wg         sync.WaitGroup
+		sem        = make(chan struct{}, opts.Concurrency)
+	)
+	observe := func(rec Record, status int, latency time.Duration, errLabel string) {
+		mu.Lock()
+		defer mu.Unlock()
+		key := routeKey{rec.Method, rec.Route}
+		if errLabel != "" {
+			report.Errors[errLabel]++
+		} else {
+			report.Replayed++
+			s, ok := routes[key]
+			if !ok {
+				s = &routeStats{recorded: newHistogram(), replayed: newHistogram()}
+				routes[key] = s
+			}
+			s.count++
+			_ = s.recorded.RecordValue(clampLatency(time.Duration(rec.LatencyMs * float64(time.Millisecond))))
+			_ = s.replayed.RecordValue(clampLatency(latency))
+		}
+		if status == rec.Status {
+			report.StatusMatched++
+		} else {
+			mismatches[mismatchKey{key, rec.Status, status}]++
+		}
+	}
+
+	started := time.Now()
+	var first time.Time
+	if len(records) > 0 {
+		first = records[0].Time
+	}
+	var err error
+dispatch:
+	for _, rec := range records {
+		req, reason := buildRequest(ctx, target, rec, opts)
+		if reason != "" {
+			mu.Lock()
+			report.Skipped[reason]++
+			mu.Unlock()
+			continue
+		}
+
+		if opts.Speed > 0 {
+			due := started.Add(time.Duration(float64(rec.Time.Sub(first)) / opts.Speed))
+			if wait := time.Until(due); wait > 0 {
+				select {
+				case <-ctx.Done():
+					err = ctx.Err()
+					break dispatch
+				case <-time.After(wait):
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			break dispatch
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			start := time.Now()
+			resp, err := client.Do(req)
+			if err != nil {
+				observe(rec, 0, 0, classifyError(err))
+				return
+			}
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+			observe(rec, resp.StatusCode, time.Since(start), "")
+		}()
+	}
+	wg.Wait()
+	report.Elapsed = time.Since(started).Seconds()
+
+	for _, key := range slices.SortedFunc(maps.Keys(routes), compareRouteKey) {
+		s := routes[key]
+		report.Routes = append(report.Routes, RouteComparison{
+			Method:   key.method,
+			Route:    key.route,
+			Count:    s.count,
+			Recorded: summarize(s.recorded),
+			Replayed: summarize(s.replayed),
+		})
+	}
+	for _, key := range slices.SortedFunc(maps.Keys(mismatches), func(a, b mismatchKey) int {
+		if c := compareRouteKey(a.routeKey, b.routeKey); c != 0 {
+			return c
+		}
+		return (a.recorded*1000 + a.replayed) - (b.recorded*1000 + b.replayed)
+	}) {
+		report.Mismatches = append(report.Mismatches, StatusMismatch{
+			Method:   key.method,
+			Route:    key.route,
+			Recorded: key.recorded,
+			Replayed: key.replayed,
+			Count:    mismatches[key],
+		})
+	}
+	return report, err
+}
+
+// buildRequest menyusun ulang request dari record, atau mengembalikan alasan bila tidak bisa dikirim
+func buildRequest(ctx context.Context, target string, rec Record, opts ReplayOptions) (*http.Request, string) {
+	if rec.BodyTruncated {
+		return nil, "body_truncated"
+	}
+
+	var body io.Reader
+	if len(rec.Body) > 0 {
+		var v any
+		if err := decodeJSON(rec.Body, &v); err != nil {
+			return nil, "invalid_body"
+		}
+		switch v := v.(type) {
+		case string:
+			// Body bukan JSON disimpan sebagai string apa adanya
+			if v == Redacted {
+				return nil, "body_redacted"
+			}
+			body = strings.NewReader(v)
+		default:
+			raw, _ := json.Marshal(restoreSecrets(v, opts.Password))
+			body = bytes.NewReader(raw)
+		}
+	}
+
+	u := target + rec.Path
+	if rec.Query != "" {
+		q, err := url.ParseQuery(rec.Query)
+		if err != nil {
+			return nil, "invalid_query"
+		}
+		for key, values := range q {
+			for i, value := range values {
+				values[i] = restoreSecret(value, opts.Password)
+			}
+			q[key] = values
+		}
+		u += "?" + q.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, rec.Method, u, body)
+	if err != nil {
+		return nil, "invalid_request"
+	}
+	for name, value := range rec.Headers {
+		req.Header.Set(name, value)
+	}
+	for name, value := range opts.Headers {
+		req.Header.Set(name, value)
+	}
+	return req, ""
+}
+
+// restoreSecret mengganti penanda rahasia dengan password pengganti. Penanda dengan
+// fingerprint berbeda menghasilkan password berbeda.
+func restoreSecret(value, password string) string {
+	if value == Redacted {
+		return password
+	}
+	if fp, ok := strings.CutPrefix(value, "[REDACTED:"); ok && strings.HasSuffix(fp, "]") {
+		return password + "-" + strings.TrimSuffix(fp, "]")
+	}
+	return value
+}
+
+func restoreSecrets(v any, password string) any {
+	switch v := v.(type) {
+	case string:
+		return restoreSecret(v, password)
+	case map[string]any:
+		for key, item := range v {
+			v[key] = restoreSecrets(item, password)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = restoreSecrets(item, password)
+		}
+	}
+	return v
+}
+
+func compareRouteKey(a, b routeKey) int {
+	if c := strings.Compare(a.route, b.route); c != 0 {
+		return c
+	}
+	return strings.Compare(a.method, b.method)
+}
+
+func classifyError(err error) string {
+	var netErr interface{ Timeout() bool }
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	default:
+		return "transport"
+	}
+}
+
+func newHistogram() *hdrhistogram.Histogram {
+	return hdrhistogram.New(int64(time.Microsecond), int64(time.Minute), 3)
+}
+
+func clampLatency(d time.Duration) int64 {
+	return min(max(int64(d), int64(time.Microsecond)), int64(time.Minute))
+}
+
+func summarize(h *hdrhistogram.Histogram) LatencySummary {
+	ms := func(v int64) float64 { return float64(v) / float64(time.Millisecond) }
+	return LatencySummary{
+		P50: ms(h.ValueAtQuantile(50)),
+		P90: ms(h.ValueAtQuantile(90)),
+		P99: ms(h.ValueAtQuantile(99)),
+		Max: ms(h.Max()),
+	}
+}
+
+// WriteText mencetak laporan replay sebagai tabel untuk terminal
+func (r ReplayReport) WriteText(w io.Writer) {
+	skipped := int64(0)
+	for _, n := range r.Skipped {
+		skipped += n
+	}
+	fmt.Fprintf(w, "%d records, %d replayed in %.1fs, %d skipped, %d status matched, %d mismatched\n\n",
+		r.Records, r.Replayed, r.Elapsed, skipped, r.StatusMatched, int64(r.Records)-skipped-r.StatusMatched)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "route\tcount\trecorded p50/p99 ms\treplayed p50/p99 ms\tp99 change")
+	for _, route := range r.Routes {
+		change := "n/a"
+		if route.Recorded.P99 > 0 {
+			change = fmt.Sprintf("%+.0f%%", (route.Replayed.P99/route.Recorded.P99-1)*100)
+		}
+		fmt.Fprintf(tw, "%s %s\t%d\t%.2f / %.2f\t%.2f / %.2f\t%s\n", route.Method, route.Route, route.Count,
+			route.Recorded.P50, route.Recorded.P99, route.Replayed.P50, route.Replayed.P99, change)
+	}
+	_ = tw.Flush()
+
+	if len(r.Mismatches) > 0 {
+		fmt.Fprintln(w, "\nstatus mismatches (recorded -> replayed):")
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, m := range r.Mismatches {
+			replayed := fmt.Sprint(m.Replayed)
+			if m.Replayed == 0 {
+				replayed = "error"
+			}
+			fmt.Fprintf(tw, "  %s %s\t%d -> %s\t%d\n", m.Method, m.Route, m.Recorded, replayed, m.Count)
+		}
+		_ = tw.Flush()
+	}
+	for _, reason := range slices.Sorted(maps.Keys(r.Errors)) {
+		fmt.Fprintf(w, "error %s: %d\n", reason, r.Errors[reason])
+	}
+	for _, reason := range slices.Sorted(maps.Keys(r.Skipped)) {
+		fmt.Fprintf(w, "skipped %s: %d\n", reason, r.Skipped[reason])
+	}
+}
@@ -0,0 +1,171 @@
+package chaos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Jenis fault yang bisa diinjeksi
+const (
+	TypeLatency = "latency"
+	TypeError   = "error"
+	TypeAbort   = "abort"
+	TypeDBError = "db_error"
+	TypeDBDelay = "db_delay"
+)
+
+// Distribusi latency yang didukung
+const (
+	DistFixed       = "fixed"
+	DistUniform     = "uniform"
+	DistNormal      = "normal"
+	DistExponential = "exponential"
+)
+
+// maxDelay membatasi satu sampel latency supaya fault tidak menahan request selamanya
+const maxDelay = time.Minute
+
+// ErrInjected dikembalikan ke query GORM yang terkena fault db_error
+var ErrInjected = errors.New("chaos: injected database failure")
+
+// Latency menjelaskan distribusi jeda yang diinjeksi, semua nilai dalam milidetik
+type Latency struct {
+	Distribution string  `json:"distribution" example:"normal"`
+	MeanMs       float64 `json:"mean_ms,omitempty" example:"300"`
+	StddevMs     float64 `json:"stddev_ms,omitempty" example:"100"`
+	MinMs        float64 `json:"min_ms,omitempty"`
+	MaxMs        float64 `json:"max_ms,omitempty"`
+}
+
+// Sample mengambil satu nilai jeda dari distribusi
+func (l Latency) Sample() time.Duration {
+	var ms float64
+	switch l.Distribution {
+	case DistUniform:
+		ms = l.MinMs + rand.Float64()*(l.MaxMs-l.MinMs)
+	case DistNormal:
+		ms = rand.NormFloat64()*l.StddevMs + l.MeanMs
+	case DistExponential:
+		ms = rand.ExpFloat64() * l.MeanMs
+	default:
+		ms = l.MeanMs
+	}
+	d := time.Duration(math.Max(ms, 0) * float64(time.Millisecond))
+	return min(d, maxDelay)
+}
+
+func (l Latency) validate() error {
+	switch l.Distribution {
+	case DistFixed, DistExponential:
+		if l.MeanMs <= 0 {
+			return fmt.Errorf("%s latency needs mean_ms", l.Distribution)
+		}
+	case DistNormal:
+		if l.MeanMs <= 0 || l.StddevMs < 0 {
+			return errors.New("normal latency needs mean_ms and a non-negative stddev_ms")
+		}
+	case DistUniform:
+		if l.MinMs < 0 || l.MaxMs <= l.MinMs {
+			return errors.New("uniform latency needs 0 <= min_ms < max_ms")
+		}
+	default:
+		return fmt.Errorf("unknown latency distribution %q", l.Distribution)
+	}
+	return nil
+}
+
+// Scope membatasi request yang terkena fault. Field kosong berarti semua.
+type Scope struct {
+	// Route adalah pola route gin, misalnya /api/v1/login; akhiran "*" berarti prefix
+	Route  string `json:"route,omitempty" example:"/api/v1/login"`
+	Method string `json:"method,omitempty" example:"POST"`
+	// Percentage adalah peluang request (atau query, untuk fault DB) terkena fault
+	Percentage float64 `json:"percentage" example:"50"`
+	// Header dan HeaderValue mencocokkan header request; HeaderValue kosong cukup header ada
+	Header      string `json:"header,omitempty" example:"X-Chaos"`
+	HeaderValue string `json:"header_value,omitempty"`
+	// User hanya berlaku di route yang memerlukan login
+	User string `json:"user,omitempty"`
+}
+
+// matchRoute mencocokkan route gin request dengan pola scope
+func (s Scope) matchRoute(route string) bool {
+	if s.Route == "" {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(s.Route, "*"); ok {
+		return strings.HasPrefix(route, prefix)
+	}
+	return route == s.Route
+}
+
+// roll menentukan secara acak apakah fault diterapkan sesuai Percentage
+func (s Scope) roll() bool {
+	return s.Percentage >= 100 || rand.Float64()*100 < s.Percentage
+}
+
+// Fault adalah satu gangguan aktif beserta scope dan masa berlakunya
+type Fault struct {
+	ID    string `json:"id" example:"f1c9a2"`
+	Type  string `json:"type" example:"latency"`
+	Scope Scope  `json:"scope"`
+	// Latency dipakai oleh fault latency dan db_delay
+	Latency *Latency `json:"latency,omitempty"`
+	// Status dipakai oleh fault error
+	Status     int       `json:"status,omitempty" example:"503"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Injections uint64    `json:"injections"`
+}
+
+// Validate memeriksa kombinasi jenis fault dan parameternya
+func (f Fault) Validate() error {
+	switch f.Type {
+	case TypeLatency, TypeDBDelay:
+		if f.Latency == nil {
+			return fmt.Errorf("%s fault needs latency", f.Type)
+		}
+		if err := f.Latency.validate(); err != nil {
+			return err
+		}
+	case TypeError:
+		if f.Status < 400 || f.Status > 599 {
+			return errors.New("error fault needs a 4xx or 5xx status")
+		}
+	case TypeAbort, TypeDBError:
+	default:
+		return fmt.Errorf("unknown fault type %q", f.Type)
+	}
+	if f.Scope.Percentage < 0 || f.Scope.Percentage > 100 {
+		return errors.New("percentage must be between 0 and 100")
+	}
+	return nil
+}
+
+// Active melaporkan apakah fault belum kedaluwarsa
+func (f Fault) Active(now time.Time) bool {
+	return now.Before(f.ExpiresAt)
+}
+
+// isDB melaporkan apakah fault diterapkan di callback GORM, bukan di middleware
+func (f Fault) isDB() bool {
+	return f.Type == TypeDBError || f.Type == TypeDBDelay
+}
+
+// active adalah fault yang tersimpan di Injector beserta penghitung injeksinya
+type active struct {
+	Fault
+	hits atomic.Uint64
+}
+
+// snapshot menyalin fault untuk response API beserta jumlah injeksinya
+func (a *active) snapshot() Fault {
+	f := a.Fault
+	f.Injections = a.hits.Load()
+	return f
+}
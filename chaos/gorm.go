@@ -0,0 +1,56 @@
+package chaos
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RegisterCallbacks memasang callback GORM yang menerapkan fault db_error dan db_delay.
+// Fault hanya berlaku untuk query yang memakai context request (DB.WithContext), sehingga
+// job background seperti relay outbox tidak ikut terganggu.
+func (i *Injector) RegisterCallbacks(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, register := range []func() error{
+		func() error { return cb.Create().Before("gorm:create").Register("chaos:create", i.apply) },
+		func() error { return cb.Query().Before("gorm:query").Register("chaos:query", i.apply) },
+		func() error { return cb.Update().Before("gorm:update").Register("chaos:update", i.apply) },
+		func() error { return cb.Delete().Before("gorm:delete").Register("chaos:delete", i.apply) },
+		func() error { return cb.Row().Before("gorm:row").Register("chaos:row", i.apply) },
+		func() error { return cb.Raw().Before("gorm:raw").Register("chaos:raw", i.apply) },
+	} {
+		if err := register(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *Injector) apply(db *gorm.DB) {
+	if db.Statement == nil || db.Statement.Context == nil || db.Error != nil {
+		return
+	}
+	ctx := db.Statement.Context
+	faults := faultsFrom(ctx)
+	if len(faults) == 0 {
+		return
+	}
+
+	now := time.Now()
+	for _, f := range faults {
+		if !f.Active(now) || !f.Scope.roll() {
+			continue
+		}
+		i.injected(f)
+		switch f.Type {
+		case TypeDBDelay:
+			if !sleep(ctx, f.Latency.Sample()) {
+				_ = db.AddError(ctx.Err())
+				return
+			}
+		case TypeDBError:
+			_ = db.AddError(ErrInjected)
+			return
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package chaos
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"wyw/apperror"
+	"wyw/logging"
+	"wyw/metric"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrTTL dikembalikan Add bila masa berlaku fault di luar batas
+var ErrTTL = errors.New("chaos: ttl out of range")
+
+// Injector menyimpan fault aktif di memori dan menerapkannya ke request dan query.
+// Setiap fault wajib punya masa berlaku supaya eksperimen tidak tertinggal aktif.
+type Injector struct {
+	metrics *metric.AppMetricsExporter
+	opts    Options
+
+	mu     sync.RWMutex
+	faults map[string]*active
+}
+
+// Options mengatur Injector
+type Options struct {
+	// MaxTTL adalah batas masa berlaku satu fault
+	MaxTTL time.Duration
+	// Exempt berisi prefix route yang tidak pernah terkena fault, misalnya API chaos itu
+	// sendiri supaya fault selalu bisa dihapus
+	Exempt []string
+}
+
+// NewInjector membuat Injector
+func NewInjector(metrics *metric.AppMetricsExporter, opts Options) *Injector {
+	if opts.MaxTTL <= 0 {
+		opts.MaxTTL = time.Hour
+	}
+	return &Injector{metrics: metrics, opts: opts, faults: map[string]*active{}}
+}
+
+// Add mengaktifkan fault selama ttl
+func (i *Injector) Add(f Fault, ttl time.Duration) (Fault, error) {
+	if ttl < time.Second || ttl > i.opts.MaxTTL {
+		return Fault{}, fmt.Errorf("%w: must be between 1s and %s", ErrTTL, i.opts.MaxTTL)
+	}
+	if f.Scope.Percentage == 0 {
+		f.Scope.Percentage = 100
+	}
+	f.Scope.Method = strings.ToUpper(f.Scope.Method)
+	if err := f.Validate(); err != nil {
+		return Fault{}, err
+	}
+	f.ID = strings.ToLower(rand.Text()[:10])
+	f.CreatedAt = time.Now().UTC()
+	f.ExpiresAt = f.CreatedAt.Add(ttl)
+	f.Injections = 0
+
+	i.mu.Lock()
+	i.faults[f.ID] = &active{Fault: f}
+	i.mu.Unlock()
+	i.metrics.SetChaosFault(f.ID, f.Type, f.Scope.Route, true)
+	logging.Logger(logging.ModuleApp).Warn("chaos fault activated",
+		slog.String("id", f.ID), slog.String("type", f.Type), slog.String("route", f.Scope.Route), slog.Time("expires_at", f.ExpiresAt))
+	return f, nil
+}
+
+// List mengembalikan fault yang masih aktif, yang paling cepat kedaluwarsa lebih dulu
+func (i *Injector) List() []Fault {
+	now := time.Now()
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	faults := make([]Fault, 0, len(i.faults))
+	for _, f := range i.faults {
+		if f.Active(now) {
+			faults = append(faults, f.snapshot())
+		}
+	}
+	slices.SortFunc(faults, func(a, b Fault) int { return a.ExpiresAt.Compare(b.ExpiresAt) })
+	return faults
+}
+
+// Remove menonaktifkan satu fault, false bila tidak ditemukan
+func (i *Injector) Remove(id string) bool {
+	i.mu.Lock()
+	f, ok := i.faults[id]
+	delete(i.faults, id)
+	i.mu.Unlock()
+	if ok {
+		i.deactivated(f, "removed")
+	}
+	return ok
+}
+
+// Clear menonaktifkan semua fault dan mengembalikan jumlahnya
+func (i *Injector) Clear() int {
+	i.mu.Lock()
+	faults := i.faults
+	i.faults = map[string]*active{}
+	i.mu.Unlock()
+	for _, f := range faults {
+		i.deactivated(f, "removed")
+	}
+	return len(faults)
+}
+
+// Run menghapus fault yang kedaluwarsa sampai ctx selesai
+func (i *Injector) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			var expired []*active
+			i.mu.Lock()
+			for id, f := range i.faults {
+				if !f.Active(now) {
+					expired = append(expired, f)
+					delete(i.faults, id)
+				}
+			}
+			i.mu.Unlock()
+			for _, f := range expired {
+				i.deactivated(f, "expired")
+			}
+		}
+	}
+}
+
+func (i *Injector) deactivated(f *active, reason string) {
+	i.metrics.SetChaosFault(f.ID, f.Type, f.Scope.Route, false)
+	logging.Logger(logging.ModuleApp).Info("chaos fault "+reason,
+		slog.String("id", f.ID), slog.String("type", f.Type), slog.Uint64("injections", f.hits.Load()))
+}
+
+// matching mengembalikan fault aktif yang cocok dengan request. Fault dengan scope user
+// hanya dicocokkan bila userScoped true, yaitu setelah middleware Auth.
+func (i *Injector) matching(c *gin.Context, userScoped bool) []*active {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	if len(i.faults) == 0 {
+		return nil
+	}
+
+	route := c.FullPath()
+	for _, prefix := range i.opts.Exempt {
+		if strings.HasPrefix(route, prefix) {
+			return nil
+		}
+	}
+
+	now := time.Now()
+	var matched []*active
+	for _, f := range i.faults {
+		s := f.Scope
+		switch {
+		case !f.Active(now),
+			(s.User != "") != userScoped,
+			s.User != "" && s.User != c.GetString(logging.UserKey),
+			!s.matchRoute(route),
+			s.Method != "" && s.Method != c.Request.Method,
+			s.Header != "" && !matchHeader(c.Request.Header, s.Header, s.HeaderValue):
+			continue
+		}
+		matched = append(matched, f)
+	}
+	// Urutan tetap supaya fault yang dibuat lebih dulu diterapkan lebih dulu
+	slices.SortFunc(matched, func(a, b *active) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return matched
+}
+
+func matchHeader(h http.Header, name, value string) bool {
+	values, ok := h[http.CanonicalHeaderKey(name)]
+	if !ok {
+		return false
+	}
+	return value == "" || slices.Contains(values, value)
+}
+
+// Middleware menerapkan fault HTTP (latency, error, abort) dan meneruskan fault DB ke
+// context request supaya callback GORM bisa menerapkannya. Fault dengan scope user
+// diabaikan di sini dan diterapkan oleh UserMiddleware.
+func (i *Injector) Middleware() gin.HandlerFunc {
+	return i.middleware(false)
+}
+
+// UserMiddleware sama dengan Middleware untuk fault dengan scope user, dipasang setelah Auth
+func (i *Injector) UserMiddleware() gin.HandlerFunc {
+	return i.middleware(true)
+}
+
+func (i *Injector) middleware(userScoped bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		faults := i.matching(c, userScoped)
+		if len(faults) == 0 {
+			c.Next()
+			return
+		}
+
+		var dbFaults []*active
+		for _, f := range faults {
+			if f.isDB() {
+				// Peluang fault DB dihitung per query di callback GORM
+				dbFaults = append(dbFaults, f)
+				continue
+			}
+			if !f.Scope.roll() {
+				continue
+			}
+			i.injected(f)
+			switch f.Type {
+			case TypeLatency:
+				if !sleep(c.Request.Context(), f.Latency.Sample()) {
+					c.Abort()
+					return
+				}
+			case TypeError:
+				_ = c.Error(apperror.New(apperror.CodeChaosFault, "fault "+f.ID+" injected this error").WithStatus(f.Status))
+				c.Abort()
+				return
+			case TypeAbort:
+				// Diteruskan oleh Recovery ke net/http yang menutup koneksi tanpa response
+				panic(http.ErrAbortHandler)
+			}
+		}
+		if len(dbFaults) > 0 {
+			c.Request = c.Request.WithContext(withFaults(c.Request.Context(), dbFaults))
+		}
+		c.Next()
+	}
+}
+
+func (i *Injector) injected(f *active) {
+	f.hits.Add(1)
+	i.metrics.RecordChaosInjection(f.Type)
+}
+
+// sleep menunggu d atau sampai ctx selesai, false bila ctx selesai lebih dulu
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+type faultsKey struct{}
+
+// withFaults menambahkan fault DB ke context, fault dari middleware sebelumnya tetap ada
+func withFaults(ctx context.Context, faults []*active) context.Context {
+	existing, _ := ctx.Value(faultsKey{}).([]*active)
+	return context.WithValue(ctx, faultsKey{}, append(slices.Clone(existing), faults...))
+}
+
+func faultsFrom(ctx context.Context) []*active {
+	faults, _ := ctx.Value(faultsKey{}).([]*active)
+	return faults
+}
@@ -0,0 +1,609 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+	"wyw/alert"
+	"wyw/audit"
+	"wyw/capture"
+	"wyw/config"
+	"wyw/dashboard"
+	"wyw/loadgen"
+	"wyw/metric"
+	"wyw/middleware"
+	"wyw/otlp"
+	"wyw/push"
+	"wyw/slo"
+
+	"github.com/klauspost/compress/snappy"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"google.golang.org/grpc"
+)
+
+// usage ditampilkan bila subcommand tidak dikenal
+const usage = `usage: wyw [command]
+
+Without a command the HTTP server is started.
+
+Commands:
+  audit verify   walk the audit log hash chain and report the first broken entry
+  loadgen        drive /register, /login and /users with a load scenario,
+                 run "wyw loadgen -h" for the options
+  replay FILE    re-issue requests captured with CAPTURE_ENABLED against a target
+                 and compare status codes and latencies, see "wyw replay -h"
+  dashboards generate
+                 write Grafana dashboards and provisioning built from the metric
+                 registry, see "wyw dashboards generate -h"
+  slo rules      print Prometheus recording and alerting rules for the SLOs in
+                 SLO_DEFINITIONS, "-o FILE" writes them to a file
+  push receive   stand-in Pushgateway and remote-write receiver that prints what
+                 PUSHGATEWAY_URL and REMOTE_WRITE_URL send, see "wyw push receive -h"
+  alerts receive listen for Alertmanager webhook notifications and print them,
+                 point ALERT_WEBHOOK_URLS at it to try alert rules locally
+  otlp receive   stand-in OpenTelemetry Collector that prints the metrics sent with
+                 OTLP_ENABLED, see "wyw otlp receive -h"
+  statsd receive print the StatsD or DogStatsD lines sent with METRICS_BACKENDS=statsd,
+                 see "wyw statsd receive -h"
+`
+
+// runCommand menjalankan subcommand CLI dan mengembalikan exit code
+func runCommand(cfg config.Config, args []string) int {
+	switch {
+	case len(args) == 2 && args[0] == "audit" && args[1] == "verify":
+		return auditVerify(cfg)
+	case len(args) > 0 && args[0] == "loadgen":
+		return runLoadgen(args[1:])
+	case len(args) > 0 && args[0] == "replay":
+		return runReplay(args[1:])
+	case len(args) > 1 && args[0] == "dashboards" && args[1] == "generate":
+		return generateDashboards(cfg, args[2:])
+	case len(args) > 1 && args[0] == "slo" && args[1] == "rules":
+		return sloRules(cfg, args[2:])
+	case len(args) > 1 && args[0] == "push" && args[1] == "receive":
+		return receivePush(args[2:])
+	case len(args) > 1 && args[0] == "alerts" && args[1] == "receive":
+		return receiveAlerts(args[2:])
+	case len(args) > 1 && args[0] == "otlp" && args[1] == "receive":
+		return receiveOTLP(args[2:])
+	case len(args) > 1 && args[0] == "statsd" && args[1] == "receive":
+		return receiveStatsD(args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+}
+
+// auditVerify memeriksa rantai hash audit log dan mencetak hasilnya sebagai JSON.
+// Exit code 1 berarti ada entri yang diubah, dihapus atau disisipkan.
+func auditVerify(cfg config.Config) int {
+	recorder, err := audit.NewRecorder(getInstance(cfg.DB), auditOptions(cfg.Audit))
+	if err != nil {
+		fatal("failed to open audit log", err)
+	}
+
+	res, err := recorder.Verify(context.Background())
+	_ = json.NewEncoder(os.Stdout).Encode(res)
+	switch {
+	case errors.Is(err, audit.ErrChainBroken):
+		fmt.Fprintf(os.Stderr, "audit chain broken at entry %d: %s\n", res.BrokenAt, res.Reason)
+		return 1
+	case err != nil:
+		fatal("failed to verify audit log", err)
+	}
+	fmt.Fprintf(os.Stderr, "audit chain intact, %d entries checked\n", res.Checked)
+	return 0
+}
+
+// runLoadgen menjalankan load generator terhadap service yang sudah berjalan. Laporan dicetak
+// sebagai tabel ke stderr dan, bila diminta, sebagai JSON ke stdout atau file.
+// Exit code 1 berarti ada response yang tidak sesuai harapan.
+func runLoadgen(args []string) int {
+	fs := flag.NewFlagSet("loadgen", flag.ContinueOnError)
+	target := fs.String("target", "http://localhost:8080", "base URL of the service")
+	scenario := fs.String("scenario", loadgen.ScenarioConstant, "constant, ramp, spike or soak")
+	rate := fs.Float64("rate", 20, "arrival rate in requests per second (final rate for ramp, base rate for spike)")
+	startRate := fs.Float64("start-rate", 1, "initial arrival rate for ramp")
+	duration := fs.Duration("duration", 0, "test duration (default 1m, 30m for soak)")
+	spikeRate := fs.Float64("spike-rate", 0, "arrival rate during the spike (default 10x rate)")
+	spikeAt := fs.Duration("spike-at", 0, "spike start offset (default a third of the duration)")
+	spikeDuration := fs.Duration("spike-duration", 0, "spike length (default a sixth of the duration)")
+	mix := fs.String("mix", "register=20,login=60,users=20", "operation weights")
+	failureRatio := fs.Float64("failure-ratio", 0.1, "share of requests sent with an invalid payload")
+	maxInFlight := fs.Int("max-in-flight", 1000, "concurrent request limit; arrivals beyond it are skipped")
+	timeout := fs.Duration("timeout", 10*time.Second, "per request timeout")
+	seedUsers := fs.Int("seed-users", 10, "accounts registered before measuring so logins have data")
+	progress := fs.Duration("progress", 0, "print an interim summary at this interval (default 1m for soak)")
+	format := fs.String("format", "text", "report on stdout: text or json")
+	jsonPath := fs.String("json", "", "also write the JSON report to this file")
+	pushgatewayURL := fs.String("pushgateway", "", "push the report as loadgen_* metrics to this Pushgateway")
+	pushJob := fs.String("push-job", "wyw_loadgen", "Pushgateway job name, the scenario is added as a grouping key")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	s := loadgen.Scenario{
+		Name:          *scenario,
+		Duration:      *duration,
+		Rate:          *rate,
+		StartRate:     *startRate,
+		SpikeRate:     *spikeRate,
+		SpikeAt:       *spikeAt,
+		SpikeDuration: *spikeDuration,
+	}
+	if s.Duration == 0 {
+		s.Duration = time.Minute
+		if s.Name == loadgen.ScenarioSoak {
+			s.Duration = 30 * time.Minute
+		}
+	}
+	if s.Name == loadgen.ScenarioSpike {
+		if s.SpikeRate == 0 {
+			s.SpikeRate = 10 * s.Rate
+		}
+		if s.SpikeDuration == 0 {
+			s.SpikeAt, s.SpikeDuration = s.Duration/3, s.Duration/6
+		}
+	}
+	if *progress == 0 && s.Name == loadgen.ScenarioSoak {
+		*progress = time.Minute
+	}
+	weights, err := loadgen.ParseMix(*mix)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "loadgen:", err)
+		return 2
+	}
+
+	runner, err := loadgen.NewRunner(loadgen.Options{
+		Target:           *target,
+		Scenario:         s,
+		Mix:              weights,
+		FailureRatio:     *failureRatio,
+		MaxInFlight:      *maxInFlight,
+		Timeout:          *timeout,
+		SeedUsers:        *seedUsers,
+		ProgressInterval: *progress,
+		Progress: func(r loadgen.Report) {
+			fmt.Fprintf(os.Stderr, "[%6.0fs] %d requests, %.1f req/s, p99 %.1f ms, %d unexpected, %d skipped\n",
+				r.Elapsed, r.Requests, r.Throughput, r.Latency.P99, r.Unexpected, r.Skipped)
+		},
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "loadgen:", err)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Fprintf(os.Stderr, "running %s scenario against %s for %s (~%d requests), press Ctrl+C to stop early\n",
+		s.Name, *target, s.Duration, s.ExpectedRequests())
+	report, err := runner.Run(ctx)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		fmt.Fprintln(os.Stderr, "loadgen:", err)
+		return 1
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+	} else {
+		report.WriteText(os.Stdout)
+	}
+	if *jsonPath != "" {
+		raw, _ := json.MarshalIndent(report, "", "  ")
+		if err := os.WriteFile(*jsonPath, raw, 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, "loadgen:", err)
+			return 1
+		}
+	}
+	if *pushgatewayURL != "" {
+		pusher := push.NewPushgateway(report.Gatherer(), nil, push.PushgatewayOptions{
+			URL:      *pushgatewayURL,
+			Job:      *pushJob,
+			Grouping: map[string]string{"scenario": s.Name},
+		})
+		if err := pusher.Push(context.Background()); err != nil {
+			fmt.Fprintln(os.Stderr, "loadgen:", err)
+			return 1
+		}
+		fmt.Fprintln(os.Stderr, "pushed report to", *pushgatewayURL)
+	}
+	if report.Unexpected > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runReplay mengirim ulang request hasil capture lalu membandingkan status dan latency-nya
+// dengan rekaman. Exit code 1 berarti ada status yang berbeda.
+func runReplay(args []string) int {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: wyw replay [flags] FILE")
+		fs.PrintDefaults()
+	}
+	target := fs.String("target", "http://localhost:8080", "base URL of the service")
+	speed := fs.Float64("speed", 1, "timing scale: 1 replays with the recorded gaps, 2 twice as fast, 0 as fast as possible")
+	concurrency := fs.Int("concurrency", 32, "maximum requests in flight")
+	timeout := fs.Duration("timeout", 30*time.Second, "per request timeout")
+	password := fs.String("password", "Replay-Passw0rd", "base value substituted for redacted fields; each distinct recorded secret gets its own suffix")
+	apiKey := fs.String("api-key", "", "X-API-Key sent with every request, needed to replay admin endpoints")
+	format := fs.String("format", "text", "report on stdout: text or json")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "replay:", err)
+		return 1
+	}
+	records, err := capture.ReadRecords(f)
+	_ = f.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "replay:", err)
+		return 1
+	}
+
+	opts := capture.ReplayOptions{
+		Target:      *target,
+		Speed:       *speed,
+		Concurrency: *concurrency,
+		Timeout:     *timeout,
+		Password:    *password,
+	}
+	if *apiKey != "" {
+		opts.Headers = map[string]string{middleware.HeaderAPIKey: *apiKey}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Fprintf(os.Stderr, "replaying %d requests against %s at speed %g\n", len(records), *target, *speed)
+	report, err := capture.Replay(ctx, records, opts)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		fmt.Fprintln(os.Stderr, "replay:", err)
+		return 1
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+	} else {
+		report.WriteText(os.Stdout)
+	}
+	if len(report.Mismatches) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// sloRules mencetak rule Prometheus untuk SLO yang dikonfigurasi, file hasilnya bisa
+// langsung dimasukkan ke rule_files
+func sloRules(cfg config.Config, args []string) int {
+	fs := flag.NewFlagSet("slo rules", flag.ContinueOnError)
+	out := fs.String("o", "", "write the rules to this file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	slos, err := newSLOs(cfg.SLO)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "slo rules:", err)
+		return 1
+	}
+	rules, err := slo.RulesYAML(slos)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "slo rules:", err)
+		return 1
+	}
+	if *out == "" {
+		_, _ = os.Stdout.Write(rules)
+		return 0
+	}
+	if err := os.WriteFile(*out, rules, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "slo rules:", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "wrote rules for %d SLOs to %s\n", len(slos), *out)
+	return 0
+}
+
+// generateDashboards membuat dashboard Grafana dari registry AppMetricsExporter yang sama
+// dengan server, termasuk metrik connection pool database, lalu menulis file provisioning
+// yang di-mount service grafana di docker-compose
+func generateDashboards(cfg config.Config, args []string) int {
+	fs := flag.NewFlagSet("dashboards generate", flag.ContinueOnError)
+	out := fs.String("out", "grafana", "output directory")
+	prometheusURL := fs.String("prometheus-url", "http://prometheus:9090", "Prometheus URL as seen from Grafana")
+	dashboardsPath := fs.String("dashboards-path", "/var/lib/grafana/dashboards", "directory the dashboards are mounted at inside Grafana")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	// sql.Open tidak membuka koneksi, cukup supaya collector go_sql_* terdaftar
+	sqlDB, err := sql.Open("mysql", cfg.DB.DSN)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "dashboards generate:", err)
+		return 1
+	}
+	defer sqlDB.Close()
+	metrics := metric.NewAppMetricsExporter()
+	metrics.RegisterDBStats(sqlDB, dbStatsName)
+
+	infos, err := metrics.Describe()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "dashboards generate:", err)
+		return 1
+	}
+	files, err := dashboard.Write(*out, dashboard.Generate(infos), dashboard.ProvisioningOptions{
+		PrometheusURL:  *prometheusURL,
+		DashboardsPath: *dashboardsPath,
+	})
+	for _, f := range files {
+		fmt.Fprintln(os.Stderr, "wrote", f)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "dashboards generate:", err)
+		return 1
+	}
+	return 0
+}
+
+// receivePush menjalankan pengganti Pushgateway (/metrics/job/...) dan receiver remote write
+// (/api/v1/write) yang mencetak setiap kiriman. -fail-ratio menolak sebagian remote write
+// dengan 503 untuk mencoba retry dan WAL.
+func receivePush(args []string) int {
+	fs := flag.NewFlagSet("push receive", flag.ContinueOnError)
+	addr := fs.String("addr", ":9091", "listen address")
+	verbose := fs.Bool("v", false, "print every metric family or series")
+	failRatio := fs.Float64("fail-ratio", 0, "share of remote-write requests answered with 503")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics/job/", func(w http.ResponseWriter, r *http.Request) {
+		group := strings.TrimPrefix(r.URL.Path, "/metrics/")
+		if r.Method == http.MethodDelete {
+			fmt.Printf("%s pushgateway DELETE %s\n", time.Now().Format(time.TimeOnly), group)
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		dec := expfmt.NewDecoder(r.Body, expfmt.ResponseFormat(r.Header))
+		var families []string
+		for {
+			var mf dto.MetricFamily
+			if err := dec.Decode(&mf); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			families = append(families, fmt.Sprintf("%s (%d)", mf.GetName(), len(mf.GetMetric())))
+		}
+		fmt.Printf("%s pushgateway %s %s: %d metric families\n", time.Now().Format(time.TimeOnly), r.Method, group, len(families))
+		if *verbose {
+			for _, f := range families {
+				fmt.Println("  " + f)
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("POST /api/v1/write", func(w http.ResponseWriter, r *http.Request) {
+		if rand.Float64() < *failRatio {
+			http.Error(w, "simulated failure", http.StatusServiceUnavailable)
+			return
+		}
+		compressed, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		raw, err := snappy.Decode(nil, compressed)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		series, err := push.DecodeWriteRequest(raw)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fmt.Printf("%s remote write: %d series, %d bytes compressed\n", time.Now().Format(time.TimeOnly), len(series), len(compressed))
+		if *verbose {
+			for _, ts := range series {
+				for _, smp := range ts.Samples {
+					fmt.Printf("  %v %g @%d\n", ts.Labels, smp.Value, smp.Timestamp)
+				}
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	srv := &http.Server{Addr: *addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		_ = srv.Shutdown(context.Background())
+	}()
+	fmt.Fprintf(os.Stderr, "listening on %s: Pushgateway at /metrics/job/..., remote write at /api/v1/write\n", *addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintln(os.Stderr, "push receive:", err)
+		return 1
+	}
+	return 0
+}
+
+// receiveAlerts menjalankan receiver webhook lokal yang mencetak setiap notifikasi alert,
+// pengganti Alertmanager saat mencoba rule di mesin developer
+func receiveAlerts(args []string) int {
+	fs := flag.NewFlagSet("alerts receive", flag.ContinueOnError)
+	addr := fs.String("addr", ":9095", "listen address")
+	raw := fs.Bool("json", false, "print the raw payload instead of a summary")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /", func(w http.ResponseWriter, r *http.Request) {
+		var msg alert.WebhookMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if *raw {
+			_ = json.NewEncoder(os.Stdout).Encode(msg)
+			return
+		}
+		fmt.Printf("%s [%s] %s (%d alerts)\n", time.Now().Format(time.TimeOnly), strings.ToUpper(msg.Status), msg.GroupKey, len(msg.Alerts))
+		for _, a := range msg.Alerts {
+			fmt.Printf("  %-8s %s %s\n", a.Status, a.Labels["alertname"], a.Annotations["summary"])
+		}
+	})
+	srv := &http.Server{Addr: *addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		_ = srv.Shutdown(context.Background())
+	}()
+	fmt.Fprintln(os.Stderr, "listening for alert notifications on", *addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintln(os.Stderr, "alerts receive:", err)
+		return 1
+	}
+	return 0
+}
+
+// receiveOTLP menjalankan pengganti OpenTelemetry Collector yang menerima OTLP metrics lewat
+// gRPC dan HTTP lalu mencetak ringkasannya
+func receiveOTLP(args []string) int {
+	fs := flag.NewFlagSet("otlp receive", flag.ContinueOnError)
+	grpcAddr := fs.String("grpc-addr", ":4317", "gRPC listen address")
+	httpAddr := fs.String("http-addr", ":4318", "HTTP listen address, metrics are accepted at /v1/metrics")
+	verbose := fs.Bool("v", false, "print every metric with its type and temporality")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	receiver := otlp.NewReceiver(os.Stdout, *verbose)
+	grpcServer := grpc.NewServer()
+	receiver.Register(grpcServer)
+	lis, err := net.Listen("tcp", *grpcAddr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "otlp receive:", err)
+		return 1
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/v1/metrics", receiver)
+	srv := &http.Server{Addr: *httpAddr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		grpcServer.GracefulStop()
+		_ = srv.Shutdown(context.Background())
+	}()
+	go func() {
+		if err := grpcServer.Serve(lis); err != nil {
+			fmt.Fprintln(os.Stderr, "otlp receive:", err)
+			stop()
+		}
+	}()
+	fmt.Fprintf(os.Stderr, "listening for OTLP metrics: gRPC on %s, HTTP on %s/v1/metrics\n", *grpcAddr, *httpAddr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintln(os.Stderr, "otlp receive:", err)
+		return 1
+	}
+	return 0
+}
+
+// receiveStatsD menjalankan pengganti agent StatsD/DogStatsD yang mencetak setiap baris dari
+// datagram UDP atau Unix socket
+func receiveStatsD(args []string) int {
+	fs := flag.NewFlagSet("statsd receive", flag.ContinueOnError)
+	addr := fs.String("addr", ":8125", "UDP listen address, empty to disable")
+	socket := fs.String("unix", "", "also listen on this Unix datagram socket")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	var conns []net.PacketConn
+	if *addr != "" {
+		conn, err := net.ListenPacket("udp", *addr)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "statsd receive:", err)
+			return 1
+		}
+		conns = append(conns, conn)
+	}
+	if *socket != "" {
+		_ = os.Remove(*socket)
+		conn, err := net.ListenPacket("unixgram", *socket)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "statsd receive:", err)
+			return 1
+		}
+		defer os.Remove(*socket)
+		conns = append(conns, conn)
+	}
+	if len(conns) == 0 {
+		fmt.Fprintln(os.Stderr, "statsd receive: nothing to listen on, set -addr or -unix")
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	lines := make(chan string)
+	for _, conn := range conns {
+		go func() {
+			buf := make([]byte, 65535)
+			for {
+				n, _, err := conn.ReadFrom(buf)
+				if err != nil {
+					return
+				}
+				for line := range strings.SplitSeq(string(buf[:n]), "\n") {
+					if line != "" {
+						lines <- line
+					}
+				}
+			}
+		}()
+	}
+	fmt.Fprintf(os.Stderr, "listening for StatsD packets on udp %q, unix %q\n", *addr, *socket)
+	for {
+		select {
+		case <-ctx.Done():
+			for _, conn := range conns {
+				_ = conn.Close()
+			}
+			return 0
+		case line := <-lines:
+			fmt.Printf("%s %s\n", time.Now().Format(time.TimeOnly), line)
+		}
+	}
+}
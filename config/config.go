@@ -0,0 +1,609 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config menampung seluruh konfigurasi aplikasi yang dibaca dari environment variable
+type Config struct {
+	// AdminToken dipakai untuk mengakses endpoint /admin lewat header X-API-Key
+	AdminToken  string
+	Log         LogConfig
+	AccessLog   AccessLogConfig
+	DB          DBConfig
+	CORS        CORSConfig
+	Server      ServerConfig
+	Password    PasswordConfig
+	Idempotency IdempotencyConfig
+	Mail        MailConfig
+	Account     AccountConfig
+	Auth        AuthConfig
+	OIDC        OIDCConfig
+	Outbox      OutboxConfig
+	Webhook     WebhookConfig
+	Live        LiveConfig
+	Capture     CaptureConfig
+	Chaos       ChaosConfig
+	Probe       ProbeConfig
+	SLO         SLOConfig
+	Alert       AlertConfig
+	Push        PushConfig
+	OTLP        OTLPConfig
+	Metrics     MetricsConfig
+	Audit       AuditConfig
+}
+
+// AuditConfig mengatur rantai hash audit log
+type AuditConfig struct {
+	// Key adalah kunci HMAC rantai hash. Isi dengan nilai yang sama di semua instance dan
+	// jangan diganti setelah audit log berisi entri, karena entri lama tidak bisa diverifikasi.
+	Key string
+	// AnchorPath menyimpan ID dan hash entri terakhir di luar database, sebaiknya di volume
+	// yang tidak bisa ditulis oleh pihak yang punya akses ke database. Kosong berarti tanpa anchor.
+	AnchorPath string
+}
+
+// MetricsConfig mengatur ke mana metrik HTTP dan bisnis dikirim
+type MetricsConfig struct {
+	// Backends berisi prometheus, statsd atau keduanya. Tanpa prometheus endpoint /metrics
+	// dan server :8081 tidak dibuka, registry tetap dipakai alert, SLO dan OTLP.
+	Backends []string
+
+	// StatsDAddress adalah udp://host:port atau unix:///path/ke/dsd.socket
+	StatsDAddress string
+	// StatsDFlavor adalah statsd (tag dijadikan segmen nama) atau dogstatsd
+	StatsDFlavor string
+	StatsDPrefix string
+	// StatsDTags hanya didukung flavor dogstatsd
+	StatsDTags map[string]string
+	// StatsDSampleRate berlaku untuk timing durasi request, counter selalu dikirim utuh
+	StatsDSampleRate float64
+	// StatsDMaxTimings membatasi sampel timing per series per flush interval
+	StatsDMaxTimings    int
+	StatsDFlushInterval time.Duration
+	StatsDQueueSize     int
+	// StatsDMaxPacketSize 0 berarti 1432 untuk UDP dan 8192 untuk UDS
+	StatsDMaxPacketSize int
+}
+
+// OTLPConfig mengatur export metrik ke OpenTelemetry Collector berdampingan dengan /metrics
+type OTLPConfig struct {
+	Enabled bool
+	// Protocol adalah grpc atau http
+	Protocol string
+	// Endpoint adalah URL collector, kosong berarti default protokol atau OTEL_EXPORTER_OTLP_ENDPOINT
+	Endpoint string
+	Headers  map[string]string
+	Interval time.Duration
+	Timeout  time.Duration
+	// Temporality adalah cumulative atau delta
+	Temporality string
+	ServiceName string
+	Environment string
+	// ResourceAttributes ditambahkan ke resource selain build info dan host
+	ResourceAttributes map[string]string
+}
+
+// PushConfig mengatur pengiriman metrik secara push, untuk deployment yang tidak bisa di-scrape.
+// Masing-masing aktif bila URL-nya diisi.
+type PushConfig struct {
+	PushgatewayURL string
+	Job            string
+	// Grouping adalah grouping key Pushgateway selain job, instance diisi hostname bila kosong
+	Grouping         map[string]string
+	Interval         time.Duration
+	DeleteOnShutdown bool
+
+	RemoteWriteURL     string
+	RemoteWriteTimeout time.Duration
+	Shards             int
+	MaxSamplesPerSend  int
+	// WALDir menyimpan batch remote write yang belum terkirim supaya selamat dari restart
+	WALDir     string
+	MaxPending int
+	// ExternalLabels ditambahkan ke setiap series remote write, job dan instance diisi bila kosong
+	ExternalLabels map[string]string
+}
+
+// AlertConfig mengatur evaluasi alert in-process dan notifikasi webhook ala Alertmanager
+type AlertConfig struct {
+	Enabled  bool
+	Interval time.Duration
+	// Rules berisi daftar rule dalam format JSON, kosong berarti pakai rule bawaan
+	Rules string
+	// Inhibit berisi daftar inhibit rule dalam format JSON, kosong berarti pakai inhibit bawaan
+	Inhibit string
+	// WebhookURLs menerima payload webhook Alertmanager, kosong berarti alert hanya bisa dilihat
+	// lewat /admin/alerts dan metrik ALERTS
+	WebhookURLs    []string
+	WebhookTimeout time.Duration
+	GroupBy        []string
+	GroupWait      time.Duration
+	GroupInterval  time.Duration
+	RepeatInterval time.Duration
+	// ExternalURL adalah alamat publik service untuk tautan di notifikasi
+	ExternalURL string
+}
+
+// SLOConfig mengatur SLO yang dilacak dan dijadikan rule Prometheus
+type SLOConfig struct {
+	Enabled bool
+	// Definitions berisi daftar SLO dalam format JSON, kosong berarti pakai SLO login bawaan
+	Definitions string
+}
+
+// ProbeConfig mengatur probe sintetis terhadap endpoint service sendiri
+type ProbeConfig struct {
+	Enabled bool
+	// BaseURL sebaiknya alamat yang dipakai user supaya probe melewati jalur yang sama
+	BaseURL   string
+	Interval  time.Duration
+	Timeout   time.Duration
+	Retention time.Duration
+	// Checks berisi daftar probe HTTP dalam format JSON, kosong berarti pakai daftar bawaan
+	Checks string
+	// DB menambahkan probe round-trip database
+	DB bool
+	// CanaryUsername dan CanaryPassword mengaktifkan probe login dengan akun canary
+	CanaryUsername string
+	CanaryPassword string
+}
+
+// ChaosConfig mengatur fault injection untuk eksperimen chaos, nonaktif secara default
+type ChaosConfig struct {
+	Enabled bool
+	// MaxTTL adalah batas masa berlaku satu fault
+	MaxTTL time.Duration
+}
+
+// CaptureConfig mengatur perekaman request ke file JSONL untuk di-replay
+type CaptureConfig struct {
+	Enabled bool
+	// Path adalah file JSONL tujuan. Kunci fingerprint nilai rahasia disimpan di <Path>.key.
+	Path       string
+	SampleRate float64
+	// MaxBodyBytes membatasi body yang disimpan per request
+	MaxBodyBytes int64
+	// Redact berisi nama field JSON dan query parameter yang nilainya disamarkan
+	Redact []string
+}
+
+// LiveConfig mengatur stream live (SSE dan WebSocket) untuk dashboard real time
+type LiveConfig struct {
+	Enabled bool
+	// History adalah jumlah event terakhir yang bisa di-replay lewat Last-Event-ID
+	History int
+	// ClientBuffer adalah jumlah event tertunda per client sebelum client dianggap lambat dan diputus
+	ClientBuffer  int
+	StatsInterval time.Duration
+	StatsWindow   time.Duration
+	Heartbeat     time.Duration
+}
+
+// WebhookConfig mengatur pengiriman webhook ke subscription
+type WebhookConfig struct {
+	Timeout     time.Duration
+	Concurrency int
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+	// MaxAttempts adalah batas percobaan sebelum delivery masuk dead letter
+	MaxAttempts int
+}
+
+// OutboxConfig mengatur relay yang mengirim event bisnis dari tabel outbox
+type OutboxConfig struct {
+	// RelayEnabled boleh true di beberapa instance, message di-klaim sebelum dikirim
+	RelayEnabled bool
+	// Sinks berisi tujuan pengiriman: "webhook", "nats", "kafka" dan/atau "file"
+	Sinks        []string
+	PollInterval time.Duration
+	BatchSize    int
+	// MaxAttempts adalah batas percobaan sebelum message ditandai gagal permanen
+	MaxAttempts    int
+	WebhookURL     string
+	WebhookTimeout time.Duration
+	NATSURL        string
+	NATSSubject    string
+	KafkaBrokers   []string
+	KafkaTopic     string
+	FilePath       string
+}
+
+// OIDCConfig mengatur login lewat OpenID Connect provider perusahaan
+type OIDCConfig struct {
+	Enabled      bool
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL harus sama persis dengan yang didaftarkan di IdP
+	RedirectURL string
+	Scopes      []string
+	GroupsClaim string
+	// RoleMapping berisi "grup=role" yang dicek berurutan, contoh OIDC_ROLE_MAPPING="sre=admin,dev=user"
+	RoleMapping []string
+	DefaultRole string
+	// AutoProvision membuat akun baru untuk identitas yang emailnya belum terdaftar
+	AutoProvision bool
+	// StateSecret menandatangani cookie state login. Bila kosong dibuat acak saat start,
+	// isi dengan nilai yang sama di semua instance.
+	StateSecret string
+}
+
+// AuthConfig mengatur session login dan two-factor authentication
+type AuthConfig struct {
+	SessionTTL time.Duration
+	// SessionStore: "gorm" (default, dibagi antar instance) atau "memory"
+	SessionStore string
+	// SessionCacheTTL adalah lama session dari database di-cache di memori, 0 berarti tanpa cache
+	SessionCacheTTL time.Duration
+	MFAIssuer       string
+	MFAChallengeTTL time.Duration
+}
+
+// MailConfig mengatur pengiriman email
+type MailConfig struct {
+	// Driver: "smtp", "file" atau "stdout"
+	Driver       string
+	From         string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	// Dir adalah folder tujuan file .eml untuk driver "file"
+	Dir string
+}
+
+// AccountConfig mengatur verifikasi email dan reset password
+type AccountConfig struct {
+	// TokenSecret dipakai untuk menandatangani token, minimal 32 byte.
+	// Bila kosong dibuat acak saat start sehingga token lama tidak valid setelah restart.
+	TokenSecret          string
+	BaseURL              string
+	ResetURL             string
+	VerifyTokenTTL       time.Duration
+	ResetTokenTTL        time.Duration
+	RequireVerifiedEmail bool
+}
+
+// IdempotencyConfig mengatur penyimpanan Idempotency-Key
+type IdempotencyConfig struct {
+	// Store: "gorm" (default, dibagi antar instance) atau "memory"
+	Store string
+	TTL   time.Duration
+}
+
+// PasswordConfig mengatur policy kekuatan password saat registrasi
+type PasswordConfig struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// ServerConfig mengatur timeout http.Server dan batas ukuran request
+type ServerConfig struct {
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// HandlerTimeout adalah batas waktu pemrosesan satu request oleh handler
+	HandlerTimeout time.Duration
+	MaxHeaderBytes int
+	// MaxBodyBytes adalah batas ukuran body request, <= 0 berarti tanpa batas
+	MaxBodyBytes int64
+	// HSTSMaxAge dipakai untuk header Strict-Transport-Security, 0 berarti tidak dikirim
+	HSTSMaxAge time.Duration
+	// ShutdownTimeout adalah batas menunggu worker background selesai saat shutdown,
+	// termasuk push terakhir ke Pushgateway dan export OTLP terakhir
+	ShutdownTimeout time.Duration
+}
+
+// CORSConfig mengatur policy CORS default dan override untuk endpoint admin
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+	// AdminAllowedOrigins berlaku untuk /api/v1/admin, kosong berarti tidak bisa diakses cross-origin
+	AdminAllowedOrigins []string
+}
+
+// LogConfig mengatur logger per modul
+type LogConfig struct {
+	// Format output: "json" atau "logfmt"
+	Format string
+	// Level default untuk semua modul
+	Level string
+	// Levels berisi override per modul, contoh LOG_LEVELS="db=debug,http=warn"
+	Levels map[string]string
+}
+
+// DBConfig mengatur koneksi database
+type DBConfig struct {
+	DSN string
+	// SlowThreshold adalah batas durasi query yang dicatat sebagai slow query
+	SlowThreshold time.Duration
+}
+
+// AccessLogConfig mengatur perilaku access log per request
+type AccessLogConfig struct {
+	// Format output: "json" atau "logfmt"
+	Format string
+	// SampleRate adalah rasio request sukses yang dicatat (0.0 - 1.0)
+	SampleRate float64
+	// AlwaysLogErrors mencatat semua response >= 400 tanpa memperhatikan sampling
+	AlwaysLogErrors bool
+	// Redact berisi nama field/query/header yang nilainya disamarkan
+	Redact []string
+}
+
+// Load membaca konfigurasi dari environment variable dengan nilai default
+func Load() Config {
+	return Config{
+		AdminToken: getString("ADMIN_TOKEN", ""),
+		Log: LogConfig{
+			Format: getString("LOG_FORMAT", "json"),
+			Level:  getString("LOG_LEVEL", "info"),
+			Levels: getMap("LOG_LEVELS"),
+		},
+		DB: DBConfig{
+			DSN:           getString("DB_DSN", "root:korie123@tcp(localhost:3306)/hehey?charset=utf8mb4&parseTime=True&loc=Local"),
+			SlowThreshold: getDuration("DB_SLOW_THRESHOLD", 200*time.Millisecond),
+		},
+		Mail: MailConfig{
+			Driver:       getString("MAIL_DRIVER", "stdout"),
+			From:         getString("MAIL_FROM", "no-reply@localhost"),
+			SMTPHost:     getString("SMTP_HOST", "localhost"),
+			SMTPPort:     getInt("SMTP_PORT", 1025),
+			SMTPUsername: getString("SMTP_USERNAME", ""),
+			SMTPPassword: getString("SMTP_PASSWORD", ""),
+			Dir:          getString("MAIL_DIR", "mails"),
+		},
+		Account: AccountConfig{
+			TokenSecret:          getString("TOKEN_SECRET", ""),
+			BaseURL:              getString("APP_BASE_URL", "http://localhost:8080"),
+			ResetURL:             getString("PASSWORD_RESET_URL", "http://localhost:3001/reset-password"),
+			VerifyTokenTTL:       getDuration("VERIFY_TOKEN_TTL", 48*time.Hour),
+			ResetTokenTTL:        getDuration("RESET_TOKEN_TTL", 30*time.Minute),
+			RequireVerifiedEmail: getBool("REQUIRE_VERIFIED_EMAIL", false),
+		},
+		Auth: AuthConfig{
+			SessionTTL:      getDuration("SESSION_TTL", 24*time.Hour),
+			SessionStore:    getString("SESSION_STORE", "gorm"),
+			SessionCacheTTL: getDuration("SESSION_CACHE_TTL", 30*time.Second),
+			MFAIssuer:       getString("MFA_ISSUER", "example-monitoring"),
+			MFAChallengeTTL: getDuration("MFA_CHALLENGE_TTL", 5*time.Minute),
+		},
+		OIDC: OIDCConfig{
+			Enabled:       getBool("OIDC_ENABLED", false),
+			IssuerURL:     getString("OIDC_ISSUER_URL", ""),
+			ClientID:      getString("OIDC_CLIENT_ID", ""),
+			ClientSecret:  getString("OIDC_CLIENT_SECRET", ""),
+			RedirectURL:   getString("OIDC_REDIRECT_URL", "http://localhost:8080/api/v1/auth/oidc/callback"),
+			Scopes:        getList("OIDC_SCOPES", []string{"openid", "profile", "email"}),
+			GroupsClaim:   getString("OIDC_GROUPS_CLAIM", "groups"),
+			RoleMapping:   getList("OIDC_ROLE_MAPPING", nil),
+			DefaultRole:   getString("OIDC_DEFAULT_ROLE", "user"),
+			AutoProvision: getBool("OIDC_AUTO_PROVISION", true),
+			StateSecret:   getString("OIDC_STATE_SECRET", ""),
+		},
+		Outbox: OutboxConfig{
+			RelayEnabled:   getBool("OUTBOX_RELAY_ENABLED", true),
+			Sinks:          getList("OUTBOX_SINKS", []string{"file"}),
+			PollInterval:   getDuration("OUTBOX_POLL_INTERVAL", time.Second),
+			BatchSize:      getInt("OUTBOX_BATCH_SIZE", 100),
+			MaxAttempts:    getInt("OUTBOX_MAX_ATTEMPTS", 20),
+			WebhookURL:     getString("OUTBOX_WEBHOOK_URL", ""),
+			WebhookTimeout: getDuration("OUTBOX_WEBHOOK_TIMEOUT", 10*time.Second),
+			NATSURL:        getString("OUTBOX_NATS_URL", "nats://localhost:4222"),
+			NATSSubject:    getString("OUTBOX_NATS_SUBJECT", "wyw.events"),
+			KafkaBrokers:   getList("OUTBOX_KAFKA_BROKERS", []string{"localhost:9092"}),
+			KafkaTopic:     getString("OUTBOX_KAFKA_TOPIC", "wyw.events"),
+			FilePath:       getString("OUTBOX_FILE_PATH", "outbox.ndjson"),
+		},
+		Webhook: WebhookConfig{
+			Timeout:     getDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+			Concurrency: getInt("WEBHOOK_CONCURRENCY", 4),
+			MinBackoff:  getDuration("WEBHOOK_MIN_BACKOFF", 10*time.Second),
+			MaxBackoff:  getDuration("WEBHOOK_MAX_BACKOFF", time.Hour),
+			MaxAttempts: getInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		},
+		SLO: SLOConfig{
+			Enabled:     getBool("SLO_ENABLED", true),
+			Definitions: getString("SLO_DEFINITIONS", ""),
+		},
+		Alert: AlertConfig{
+			Enabled:        getBool("ALERT_ENABLED", false),
+			Interval:       getDuration("ALERT_INTERVAL", 15*time.Second),
+			Rules:          getString("ALERT_RULES", ""),
+			Inhibit:        getString("ALERT_INHIBIT", ""),
+			WebhookURLs:    getList("ALERT_WEBHOOK_URLS", nil),
+			WebhookTimeout: getDuration("ALERT_WEBHOOK_TIMEOUT", 10*time.Second),
+			GroupBy:        getList("ALERT_GROUP_BY", []string{"alertname"}),
+			GroupWait:      getDuration("ALERT_GROUP_WAIT", 30*time.Second),
+			GroupInterval:  getDuration("ALERT_GROUP_INTERVAL", 5*time.Minute),
+			RepeatInterval: getDuration("ALERT_REPEAT_INTERVAL", 4*time.Hour),
+			ExternalURL:    getString("ALERT_EXTERNAL_URL", "http://localhost:8080"),
+		},
+		Push: PushConfig{
+			PushgatewayURL:     getString("PUSHGATEWAY_URL", ""),
+			Job:                getString("PUSH_JOB", "wyw"),
+			Grouping:           getMap("PUSHGATEWAY_GROUPING"),
+			Interval:           getDuration("PUSH_INTERVAL", 15*time.Second),
+			DeleteOnShutdown:   getBool("PUSHGATEWAY_DELETE_ON_SHUTDOWN", true),
+			RemoteWriteURL:     getString("REMOTE_WRITE_URL", ""),
+			RemoteWriteTimeout: getDuration("REMOTE_WRITE_TIMEOUT", 30*time.Second),
+			Shards:             getInt("REMOTE_WRITE_SHARDS", 4),
+			MaxSamplesPerSend:  getInt("REMOTE_WRITE_MAX_SAMPLES_PER_SEND", 2000),
+			WALDir:             getString("REMOTE_WRITE_WAL_DIR", "data/remote-write"),
+			MaxPending:         getInt("REMOTE_WRITE_MAX_PENDING", 1000),
+			ExternalLabels:     getMap("REMOTE_WRITE_EXTERNAL_LABELS"),
+		},
+		OTLP: OTLPConfig{
+			Enabled:            getBool("OTLP_ENABLED", false),
+			Protocol:           getString("OTLP_PROTOCOL", "grpc"),
+			Endpoint:           getString("OTLP_ENDPOINT", ""),
+			Headers:            getMap("OTLP_HEADERS"),
+			Interval:           getDuration("OTLP_INTERVAL", 30*time.Second),
+			Timeout:            getDuration("OTLP_TIMEOUT", 10*time.Second),
+			Temporality:        getString("OTLP_TEMPORALITY", "cumulative"),
+			ServiceName:        getString("OTLP_SERVICE_NAME", "wyw"),
+			Environment:        getString("OTLP_ENVIRONMENT", ""),
+			ResourceAttributes: getMap("OTLP_RESOURCE_ATTRIBUTES"),
+		},
+		Metrics: MetricsConfig{
+			Backends:            getList("METRICS_BACKENDS", []string{"prometheus"}),
+			StatsDAddress:       getString("STATSD_ADDRESS", "udp://127.0.0.1:8125"),
+			StatsDFlavor:        getString("STATSD_FLAVOR", "dogstatsd"),
+			StatsDPrefix:        getString("STATSD_PREFIX", "wyw."),
+			StatsDTags:          getMap("STATSD_TAGS"),
+			StatsDSampleRate:    getFloat("STATSD_SAMPLE_RATE", 1),
+			StatsDMaxTimings:    getInt("STATSD_MAX_TIMINGS", 1000),
+			StatsDFlushInterval: getDuration("STATSD_FLUSH_INTERVAL", time.Second),
+			StatsDQueueSize:     getInt("STATSD_QUEUE_SIZE", 4096),
+			StatsDMaxPacketSize: getInt("STATSD_MAX_PACKET_SIZE", 0),
+		},
+		Audit: AuditConfig{
+			Key:        getString("AUDIT_KEY", ""),
+			AnchorPath: getString("AUDIT_ANCHOR_PATH", "audit.anchor"),
+		},
+		Probe: ProbeConfig{
+			Enabled:        getBool("PROBE_ENABLED", false),
+			BaseURL:        getString("PROBE_BASE_URL", "http://localhost:8080"),
+			Interval:       getDuration("PROBE_INTERVAL", 30*time.Second),
+			Timeout:        getDuration("PROBE_TIMEOUT", 5*time.Second),
+			Retention:      getDuration("PROBE_RETENTION", 24*time.Hour),
+			Checks:         getString("PROBE_CHECKS", ""),
+			DB:             getBool("PROBE_DB", true),
+			CanaryUsername: getString("PROBE_CANARY_USERNAME", ""),
+			CanaryPassword: getString("PROBE_CANARY_PASSWORD", ""),
+		},
+		Chaos: ChaosConfig{
+			Enabled: getBool("CHAOS_ENABLED", false),
+			MaxTTL:  getDuration("CHAOS_MAX_TTL", time.Hour),
+		},
+		Capture: CaptureConfig{
+			Enabled:      getBool("CAPTURE_ENABLED", false),
+			Path:         getString("CAPTURE_PATH", "capture.jsonl"),
+			SampleRate:   getFloat("CAPTURE_SAMPLE_RATE", 0.1),
+			MaxBodyBytes: int64(getInt("CAPTURE_MAX_BODY_BYTES", 64<<10)),
+			Redact:       getList("CAPTURE_REDACT", []string{"password", "new_password", "token", "code", "secret", "client_secret"}),
+		},
+		Live: LiveConfig{
+			Enabled:       getBool("LIVE_ENABLED", false),
+			History:       getInt("LIVE_HISTORY", 1024),
+			ClientBuffer:  getInt("LIVE_CLIENT_BUFFER", 256),
+			StatsInterval: getDuration("LIVE_STATS_INTERVAL", time.Second),
+			StatsWindow:   getDuration("LIVE_STATS_WINDOW", 10*time.Second),
+			Heartbeat:     getDuration("LIVE_HEARTBEAT", 15*time.Second),
+		},
+		Idempotency: IdempotencyConfig{
+			Store: getString("IDEMPOTENCY_STORE", "gorm"),
+			TTL:   getDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+		},
+		Password: PasswordConfig{
+			MinLength:     getInt("PASSWORD_MIN_LENGTH", 8),
+			MaxLength:     getInt("PASSWORD_MAX_LENGTH", 72),
+			RequireUpper:  getBool("PASSWORD_REQUIRE_UPPER", true),
+			RequireLower:  getBool("PASSWORD_REQUIRE_LOWER", true),
+			RequireDigit:  getBool("PASSWORD_REQUIRE_DIGIT", true),
+			RequireSymbol: getBool("PASSWORD_REQUIRE_SYMBOL", false),
+		},
+		Server: ServerConfig{
+			ReadHeaderTimeout: getDuration("SERVER_READ_HEADER_TIMEOUT", 5*time.Second),
+			ReadTimeout:       getDuration("SERVER_READ_TIMEOUT", 15*time.Second),
+			WriteTimeout:      getDuration("SERVER_WRITE_TIMEOUT", 30*time.Second),
+			IdleTimeout:       getDuration("SERVER_IDLE_TIMEOUT", 120*time.Second),
+			HandlerTimeout:    getDuration("SERVER_HANDLER_TIMEOUT", 20*time.Second),
+			MaxHeaderBytes:    getInt("SERVER_MAX_HEADER_BYTES", 1<<20),
+			MaxBodyBytes:      int64(getInt("SERVER_MAX_BODY_BYTES", 1<<20)),
+			HSTSMaxAge:        getDuration("SERVER_HSTS_MAX_AGE", 365*24*time.Hour),
+			ShutdownTimeout:   getDuration("SERVER_SHUTDOWN_TIMEOUT", 15*time.Second),
+		},
+		CORS: CORSConfig{
+			AllowedOrigins:      getList("CORS_ALLOWED_ORIGINS", []string{"http://localhost:3000", "http://localhost:8080"}),
+			AllowedHeaders:      getList("CORS_ALLOWED_HEADERS", []string{"Origin", "Content-Type", "Accept", "Authorization", "X-API-Key", "X-Request-ID", "Idempotency-Key"}),
+			ExposedHeaders:      getList("CORS_EXPOSED_HEADERS", []string{"X-Request-ID", "Idempotent-Replayed"}),
+			AllowCredentials:    getBool("CORS_ALLOW_CREDENTIALS", false),
+			MaxAge:              getDuration("CORS_MAX_AGE", 10*time.Minute),
+			AdminAllowedOrigins: getList("CORS_ADMIN_ALLOWED_ORIGINS", nil),
+		},
+		AccessLog: AccessLogConfig{
+			Format:          getString("ACCESS_LOG_FORMAT", "json"),
+			SampleRate:      getFloat("ACCESS_LOG_SAMPLE_RATE", 1),
+			AlwaysLogErrors: getBool("ACCESS_LOG_ALWAYS_ERRORS", true),
+			Redact:          getList("ACCESS_LOG_REDACT", []string{"password", "token", "authorization", "cookie"}),
+		},
+	}
+}
+
+func getString(key, def string) string {
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		return v
+	}
+	return def
+}
+
+func getFloat(key string, def float64) float64 {
+	v, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+func getInt(key string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+func getBool(key string, def bool) bool {
+	v, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+func getDuration(key string, def time.Duration) time.Duration {
+	v, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+func getList(key string, def []string) []string {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// getMap membaca daftar "key=value" yang dipisah koma
+func getMap(key string) map[string]string {
+	out := map[string]string{}
+	for _, item := range getList(key, nil) {
+		k, v, ok := strings.Cut(item, "=")
+		if ok {
+			out[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,407 @@
+package dashboard
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"wyw/metric"
+)
+
+// Tag dipasang di semua dashboard hasil generator, dipakai untuk link antar dashboard
+const Tag = "wyw"
+
+// selector membatasi semua query ke job dan instance yang dipilih di variabel dashboard
+const selector = `job=~"$job",instance=~"$instance"`
+
+// datasource menunjuk ke variabel datasource sehingga dashboard tidak terikat ke satu uid
+var datasource = &Datasource{Type: "prometheus", UID: "${datasource}"}
+
+// highCardinality adalah label yang tidak di-group di panel generik
+var highCardinality = []string{"user_id", "le", "quantile"}
+
+// Generate membuat dashboard HTTP (RED), bisnis, connection pool database dan runtime dari
+// metadata registry. Metrik yang tidak punya panel khusus tetap mendapat panel generik
+// sesuai tipenya, jadi metrik baru otomatis muncul saat generator dijalankan ulang.
+func Generate(infos []metric.MetricInfo) []*Dashboard {
+	byName := make(map[string]metric.MetricInfo, len(infos))
+	for _, info := range infos {
+		byName[info.Name] = info
+	}
+	g := &generator{infos: infos, byName: byName}
+
+	var dashboards []*Dashboard
+	for _, d := range []*Dashboard{g.http(), g.business(), g.database(), g.runtime()} {
+		if len(d.Panels) > 0 {
+			dashboards = append(dashboards, d)
+		}
+	}
+	return dashboards
+}
+
+type generator struct {
+	infos  []metric.MetricInfo
+	byName map[string]metric.MetricInfo
+}
+
+// target adalah query panel khusus, dilewati bila metriknya tidak ada di registry
+type target struct {
+	metric string
+	expr   string
+	legend string
+}
+
+func (g *generator) http() *Dashboard {
+	b := newBuilder("wyw-http", "wyw / HTTP (RED)", "Request rate, errors and duration per endpoint.")
+	b.d.Templating.List = append(b.d.Templating.List, queryVariable("endpoint", "Endpoint",
+		`label_values(app_http_requests_total{job=~"$job"}, endpoint)`))
+
+	sel := selector + `,endpoint=~"$endpoint"`
+	requests := "app_http_requests_total"
+	duration := "app_http_request_duration_seconds"
+	buckets := duration + "_bucket"
+
+	b.row("Rate, errors, duration", false)
+	b.add(g.panel("Request rate", "Requests per second by endpoint.", "reqps",
+		target{requests, sumBy([]string{"endpoint"}, rate(requests, sel)), "{{endpoint}}"}), 12)
+	b.add(g.panel("Error ratio (5xx)", "Share of requests answered with a 5xx status.", "percentunit",
+		target{requests, fmt.Sprintf("%s\n/\n%s",
+			sumBy([]string{"endpoint"}, rate(requests, sel+`,status=~"5.."`)),
+			sumBy([]string{"endpoint"}, rate(requests, sel))), "{{endpoint}}"}), 12)
+	b.add(g.panel("Requests by status", "Requests per second by HTTP status.", "reqps",
+		target{requests, sumBy([]string{"status"}, rate(requests, sel)), "{{status}}"}), 12)
+	b.add(g.panel("Errors by code", "Requests per second that ended with a domain error code.", "reqps",
+		target{requests, sumBy([]string{"code"}, rate(requests, sel+`,code!="none"`)), "{{code}}"}), 12)
+	b.add(g.panel("Latency percentiles", "Request duration across the selected endpoints.", "s",
+		target{duration, quantile(0.5, nil, buckets, sel), "p50"},
+		target{duration, quantile(0.9, nil, buckets, sel), "p90"},
+		target{duration, quantile(0.99, nil, buckets, sel), "p99"}), 12)
+	b.add(g.panel("p99 latency by endpoint", "99th percentile request duration per endpoint.", "s",
+		target{duration, quantile(0.99, []string{"endpoint"}, buckets, sel), "{{endpoint}}"}), 12)
+
+	b.row("Other HTTP metrics", false)
+	for _, info := range g.matching(func(name string) bool {
+		return strings.HasPrefix(name, "app_http_") && name != requests && name != duration
+	}) {
+		b.add(genericPanel(info), 12)
+	}
+	return b.build()
+}
+
+func (g *generator) business() *Dashboard {
+	b := newBuilder("wyw-business", "wyw / Business", "Business events and background work: mail, sessions, outbox, webhooks and more.")
+	events := "app_business_events_total"
+
+	b.row("Business events", false)
+	b.add(g.panel("Business events", "Business events per second by type.", "ops",
+		target{events, sumBy([]string{"event_type"}, rate(events, selector)), "{{event_type}}"}), 24)
+
+	// Metrik lain dikelompokkan per subsystem, yaitu kata kedua nama metrik
+	groups := make(map[string][]metric.MetricInfo)
+	var order []string
+	for _, info := range g.matching(func(name string) bool {
+		return strings.HasPrefix(name, "app_") && name != events && name != "app_build_info" &&
+			!strings.HasPrefix(name, "app_http_") && !strings.HasPrefix(name, "app_system_")
+	}) {
+		subsystem := strings.SplitN(info.Name, "_", 3)[1]
+		if _, ok := groups[subsystem]; !ok {
+			order = append(order, subsystem)
+		}
+		groups[subsystem] = append(groups[subsystem], info)
+	}
+	var other []metric.MetricInfo
+	for _, subsystem := range order {
+		if len(groups[subsystem]) == 1 {
+			other = append(other, groups[subsystem]...)
+			continue
+		}
+		b.row(strings.ToUpper(subsystem[:1])+subsystem[1:], false)
+		for _, info := range groups[subsystem] {
+			b.add(genericPanel(info), 12)
+		}
+	}
+	if len(other) > 0 {
+		b.row("Other", false)
+		for _, info := range other {
+			b.add(genericPanel(info), 12)
+		}
+	}
+	return b.build()
+}
+
+func (g *generator) database() *Dashboard {
+	b := newBuilder("wyw-db", "wyw / Database pool", "database/sql connection pool of the service.")
+	db := []string{"db_name"}
+
+	b.row("Connections", false)
+	b.add(g.panel("Connections", "Open connections split by state, against the configured maximum.", "short",
+		target{"go_sql_max_open_connections", sumBy(db, gauge("go_sql_max_open_connections", selector)), "{{db_name}} max open"},
+		target{"go_sql_open_connections", sumBy(db, gauge("go_sql_open_connections", selector)), "{{db_name}} open"},
+		target{"go_sql_in_use_connections", sumBy(db, gauge("go_sql_in_use_connections", selector)), "{{db_name}} in use"},
+		target{"go_sql_idle_connections", sumBy(db, gauge("go_sql_idle_connections", selector)), "{{db_name}} idle"}), 12)
+	b.add(g.panel("Pool saturation", "In-use connections divided by the maximum; waits start when this reaches 100%.", "percentunit",
+		target{"go_sql_in_use_connections", fmt.Sprintf("%s\n/\n%s",
+			sumBy(db, gauge("go_sql_in_use_connections", selector)),
+			sumBy(db, gauge("go_sql_max_open_connections", selector))), "{{db_name}}"}), 12)
+	b.add(g.panel("Waits", "Connections per second that had to wait for a free slot.", "ops",
+		target{"go_sql_wait_count_total", sumBy(db, rate("go_sql_wait_count_total", selector)), "{{db_name}}"}), 12)
+	b.add(g.panel("Wait time", "Seconds spent waiting for a connection, per second.", "s",
+		target{"go_sql_wait_duration_seconds_total", sumBy(db, rate("go_sql_wait_duration_seconds_total", selector)), "{{db_name}}"}), 12)
+	b.add(g.panel("Closed connections", "Connections per second closed by the pool limits.", "ops",
+		target{"go_sql_max_idle_closed_total", sumBy(db, rate("go_sql_max_idle_closed_total", selector)), "{{db_name}} max idle"},
+		target{"go_sql_max_idle_time_closed_total", sumBy(db, rate("go_sql_max_idle_time_closed_total", selector)), "{{db_name}} idle time"},
+		target{"go_sql_max_lifetime_closed_total", sumBy(db, rate("go_sql_max_lifetime_closed_total", selector)), "{{db_name}} lifetime"}), 12)
+	return b.build()
+}
+
+func (g *generator) runtime() *Dashboard {
+	b := newBuilder("wyw-runtime", "wyw / Go runtime", "Go runtime and process metrics of the service.")
+
+	b.row("Overview", false)
+	b.add(g.panel("Goroutines", "", "short",
+		target{"go_goroutines", gauge("go_goroutines", selector), "{{instance}}"}), 8)
+	b.add(g.panel("Heap", "", "bytes",
+		target{"go_memstats_heap_alloc_bytes", gauge("go_memstats_heap_alloc_bytes", selector), "{{instance}} alloc"},
+		target{"go_memstats_heap_inuse_bytes", gauge("go_memstats_heap_inuse_bytes", selector), "{{instance}} in use"},
+		target{"go_memstats_sys_bytes", gauge("go_memstats_sys_bytes", selector), "{{instance}} from OS"}), 8)
+	b.add(g.panel("GC pause", "", "s",
+		target{"go_gc_duration_seconds", gauge("go_gc_duration_seconds", selector), "{{instance}} q{{quantile}}"}), 8)
+	b.add(g.panel("CPU", "CPU cores used by the process.", "short",
+		target{"process_cpu_seconds_total", rate("process_cpu_seconds_total", selector), "{{instance}}"}), 8)
+	b.add(g.panel("Resident memory", "", "bytes",
+		target{"process_resident_memory_bytes", gauge("process_resident_memory_bytes", selector), "{{instance}}"}), 8)
+	b.add(g.panel("File descriptors", "", "short",
+		target{"process_open_fds", gauge("process_open_fds", selector), "{{instance}} open"},
+		target{"process_max_fds", gauge("process_max_fds", selector), "{{instance}} max"}), 8)
+
+	b.row("All runtime metrics", true)
+	for _, info := range g.matching(func(name string) bool {
+		return (strings.HasPrefix(name, "go_") && !strings.HasPrefix(name, "go_sql_")) ||
+			strings.HasPrefix(name, "process_") || strings.HasPrefix(name, "app_system_") || name == "app_build_info"
+	}) {
+		b.add(genericPanel(info), 12)
+	}
+	return b.build()
+}
+
+// panel membuat panel timeseries dari target yang metriknya ada, nil bila tidak ada sama sekali
+func (g *generator) panel(title, description, unit string, targets ...target) *Panel {
+	p := timeseries(title, description, unit)
+	for _, t := range targets {
+		if _, ok := g.byName[t.metric]; ok {
+			p.Targets = append(p.Targets, newTarget(len(p.Targets), t.expr, t.legend))
+		}
+	}
+	if len(p.Targets) == 0 {
+		return nil
+	}
+	return p
+}
+
+func (g *generator) matching(match func(name string) bool) []metric.MetricInfo {
+	var infos []metric.MetricInfo
+	for _, info := range g.infos {
+		if match(info.Name) {
+			infos = append(infos, info)
+		}
+	}
+	return infos
+}
+
+// genericPanel membuat panel sesuai tipe metrik: rate untuk counter, persentil untuk
+// histogram, nilai quantile untuk summary dan nilai mentah untuk gauge
+func genericPanel(info metric.MetricInfo) *Panel {
+	labels := slices.DeleteFunc(slices.Clone(info.Labels), func(l string) bool { return slices.Contains(highCardinality, l) })
+	legend := legendFor(labels, info.Name)
+	p := timeseries(info.Name, info.Help, unitFor(info))
+
+	switch info.Type {
+	case "counter":
+		p.Targets = []Target{newTarget(0, sumBy(labels, rate(info.Name, selector)), legend)}
+	case "histogram":
+		name := info.Name + "_bucket"
+		if len(labels) == 0 {
+			for i, q := range []float64{0.5, 0.9, 0.99} {
+				p.Targets = append(p.Targets, newTarget(i, quantile(q, nil, name, selector), fmt.Sprintf("p%g", q*100)))
+			}
+		} else {
+			p.Targets = []Target{newTarget(0, quantile(0.95, labels, name, selector), "p95 "+legend)}
+		}
+	case "summary":
+		p.Targets = []Target{newTarget(0, gauge(info.Name, selector), "{{instance}} q{{quantile}}")}
+	default:
+		p.Targets = []Target{newTarget(0, sumBy(labels, gauge(info.Name, selector)), legend)}
+	}
+	return p
+}
+
+func unitFor(info metric.MetricInfo) string {
+	name := strings.TrimSuffix(info.Name, "_total")
+	switch {
+	case info.Type == "counter" && strings.HasSuffix(name, "_seconds"):
+		return "s"
+	case info.Type == "counter" && strings.HasSuffix(name, "_bytes"):
+		return "Bps"
+	case info.Type == "counter":
+		return "ops"
+	case strings.HasSuffix(name, "_seconds"):
+		return "s"
+	case strings.HasSuffix(name, "_bytes"):
+		return "bytes"
+	default:
+		return "short"
+	}
+}
+
+func legendFor(labels []string, name string) string {
+	if len(labels) == 0 {
+		return name
+	}
+	parts := make([]string, 0, len(labels))
+	for _, l := range labels {
+		parts = append(parts, "{{"+l+"}}")
+	}
+	return strings.Join(parts, " ")
+}
+
+func rate(name, sel string) string {
+	return fmt.Sprintf("rate(%s{%s}[$__rate_interval])", name, sel)
+}
+
+func gauge(name, sel string) string {
+	return fmt.Sprintf("%s{%s}", name, sel)
+}
+
+func sumBy(labels []string, expr string) string {
+	if len(labels) == 0 {
+		return fmt.Sprintf("sum(%s)", expr)
+	}
+	return fmt.Sprintf("sum by (%s) (%s)", strings.Join(labels, ", "), expr)
+}
+
+func quantile(q float64, labels []string, bucket, sel string) string {
+	return fmt.Sprintf("histogram_quantile(%g, %s)", q, sumBy(append([]string{"le"}, labels...), rate(bucket, sel)))
+}
+
+func timeseries(title, description, unit string) *Panel {
+	return &Panel{
+		Type:        "timeseries",
+		Title:       title,
+		Description: description,
+		Datasource:  datasource,
+		FieldConfig: &FieldConfig{Defaults: FieldDefaults{Unit: unit}, Overrides: []any{}},
+		Options: map[string]any{
+			"legend":  map[string]any{"displayMode": "table", "placement": "bottom", "calcs": []string{"mean", "max", "lastNotNull"}},
+			"tooltip": map[string]any{"mode": "multi", "sort": "desc"},
+		},
+	}
+}
+
+func newTarget(i int, expr, legend string) Target {
+	return Target{RefID: string(rune('A' + i)), Datasource: datasource, Expr: expr, LegendFormat: legend, Range: true}
+}
+
+func queryVariable(name, label, query string) Variable {
+	return Variable{
+		Name:       name,
+		Label:      label,
+		Type:       "query",
+		Query:      query,
+		Datasource: datasource,
+		Refresh:    2,
+		Multi:      true,
+		IncludeAll: true,
+		AllValue:   ".*",
+		Sort:       1,
+		Current:    map[string]any{"text": "All", "value": "$__all"},
+	}
+}
+
+// panelHeight adalah tinggi semua panel timeseries
+const panelHeight = 8
+
+// section adalah satu row dashboard beserta panelnya
+type section struct {
+	title     string
+	collapsed bool
+	panels    []*Panel
+}
+
+// builder mengumpulkan panel per row lalu menyusunnya di grid 24 kolom saat build
+type builder struct {
+	d        *Dashboard
+	sections []*section
+}
+
+func newBuilder(uid, title, description string) *builder {
+	return &builder{d: &Dashboard{
+		UID:           uid,
+		Title:         title,
+		Description:   description,
+		Tags:          []string{Tag},
+		Timezone:      "browser",
+		Editable:      true,
+		GraphTooltip:  1,
+		Refresh:       "30s",
+		SchemaVersion: 39,
+		Version:       1,
+		Time:          TimeRange{From: "now-1h", To: "now"},
+		Templating: Templating{List: []Variable{
+			{Name: "datasource", Label: "Data source", Type: "datasource", Query: "prometheus", Current: map[string]any{}},
+			queryVariable("job", "Job", "label_values(app_build_info, job)"),
+			queryVariable("instance", "Instance", `label_values(app_build_info{job=~"$job"}, instance)`),
+		}},
+		Links:  []Link{{Title: "wyw dashboards", Type: "dashboards", Tags: []string{Tag}, AsDropdown: true, IncludeVars: true, KeepTime: true}},
+		Panels: []*Panel{},
+	}}
+}
+
+// row memulai row baru, panel berikutnya masuk ke row ini
+func (b *builder) row(title string, collapsed bool) {
+	b.sections = append(b.sections, &section{title: title, collapsed: collapsed})
+}
+
+// add menambahkan panel selebar width kolom ke row terakhir, panel nil dilewati
+func (b *builder) add(p *Panel, width int) {
+	if p == nil {
+		return
+	}
+	p.GridPos = GridPos{H: panelHeight, W: width}
+	sec := b.sections[len(b.sections)-1]
+	sec.panels = append(sec.panels, p)
+}
+
+// build menghitung id dan posisi panel. Row tanpa panel dibuang; panel di row yang
+// collapsed disimpan di row itu sendiri, sesuai cara Grafana menyimpan row tertutup.
+func (b *builder) build() *Dashboard {
+	id, y := 0, 0
+	for _, sec := range b.sections {
+		if len(sec.panels) == 0 {
+			continue
+		}
+		id++
+		collapsed := sec.collapsed
+		row := &Panel{ID: id, Type: "row", Title: sec.title, GridPos: GridPos{H: 1, W: 24, Y: y}, Collapsed: &collapsed, Panels: []*Panel{}}
+		b.d.Panels = append(b.d.Panels, row)
+		y++
+
+		x, py := 0, y
+		for _, p := range sec.panels {
+			if x+p.GridPos.W > 24 {
+				x, py = 0, py+panelHeight
+			}
+			id++
+			p.ID = id
+			p.GridPos.X, p.GridPos.Y = x, py
+			x += p.GridPos.W
+			if collapsed {
+				row.Panels = append(row.Panels, p)
+			} else {
+				b.d.Panels = append(b.d.Panels, p)
+			}
+		}
+		if !collapsed {
+			y = py + panelHeight
+		}
+	}
+	return b.d
+}
@@ -0,0 +1,102 @@
+package dashboard
+
+// Struct di file ini adalah subset model JSON dashboard Grafana yang dipakai generator
+
+// Dashboard adalah satu dashboard Grafana
+type Dashboard struct {
+	UID           string     `json:"uid"`
+	Title         string     `json:"title"`
+	Description   string     `json:"description,omitempty"`
+	Tags          []string   `json:"tags"`
+	Timezone      string     `json:"timezone"`
+	Editable      bool       `json:"editable"`
+	GraphTooltip  int        `json:"graphTooltip"`
+	Refresh       string     `json:"refresh"`
+	SchemaVersion int        `json:"schemaVersion"`
+	Version       int        `json:"version"`
+	Time          TimeRange  `json:"time"`
+	Templating    Templating `json:"templating"`
+	Links         []Link     `json:"links"`
+	Panels        []*Panel   `json:"panels"`
+}
+
+type TimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type Templating struct {
+	List []Variable `json:"list"`
+}
+
+// Variable adalah variabel template dashboard
+type Variable struct {
+	Name       string      `json:"name"`
+	Label      string      `json:"label,omitempty"`
+	Type       string      `json:"type"`
+	Query      string      `json:"query"`
+	Datasource *Datasource `json:"datasource,omitempty"`
+	Refresh    int         `json:"refresh,omitempty"`
+	Multi      bool        `json:"multi"`
+	IncludeAll bool        `json:"includeAll"`
+	AllValue   string      `json:"allValue,omitempty"`
+	Sort       int         `json:"sort,omitempty"`
+	Current    any         `json:"current"`
+	Hide       int         `json:"hide"`
+}
+
+// Link menghubungkan dashboard yang punya tag sama
+type Link struct {
+	Title       string   `json:"title"`
+	Type        string   `json:"type"`
+	Tags        []string `json:"tags"`
+	AsDropdown  bool     `json:"asDropdown"`
+	IncludeVars bool     `json:"includeVars"`
+	KeepTime    bool     `json:"keepTime"`
+}
+
+type Datasource struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+type GridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Panel adalah panel timeseries atau row. Row yang collapsed menyimpan panelnya
+// sendiri di Panels.
+type Panel struct {
+	ID          int          `json:"id"`
+	Type        string       `json:"type"`
+	Title       string       `json:"title"`
+	Description string       `json:"description,omitempty"`
+	Datasource  *Datasource  `json:"datasource,omitempty"`
+	GridPos     GridPos      `json:"gridPos"`
+	Targets     []Target     `json:"targets,omitempty"`
+	FieldConfig *FieldConfig `json:"fieldConfig,omitempty"`
+	Options     any          `json:"options,omitempty"`
+	Collapsed   *bool        `json:"collapsed,omitempty"`
+	Panels      []*Panel     `json:"panels,omitempty"`
+}
+
+// Target adalah satu query PromQL panel
+type Target struct {
+	RefID        string      `json:"refId"`
+	Datasource   *Datasource `json:"datasource"`
+	Expr         string      `json:"expr"`
+	LegendFormat string      `json:"legendFormat,omitempty"`
+	Range        bool        `json:"range"`
+}
+
+type FieldConfig struct {
+	Defaults  FieldDefaults `json:"defaults"`
+	Overrides []any         `json:"overrides"`
+}
+
+type FieldDefaults struct {
+	Unit string `json:"unit,omitempty"`
+}
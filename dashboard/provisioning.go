@@ -0,0 +1,117 @@
+package dashboard
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DatasourceUID adalah uid data source Prometheus hasil provisioning
+const DatasourceUID = "prometheus"
+
+// ProvisioningOptions mengatur file provisioning Grafana
+type ProvisioningOptions struct {
+	// PrometheusURL adalah alamat Prometheus dilihat dari container Grafana
+	PrometheusURL string
+	// DashboardsPath adalah lokasi file dashboard di dalam container Grafana
+	DashboardsPath string
+}
+
+type datasourceFile struct {
+	APIVersion  int               `yaml:"apiVersion"`
+	Datasources []datasourceEntry `yaml:"datasources"`
+}
+
+type datasourceEntry struct {
+	Name      string `yaml:"name"`
+	Type      string `yaml:"type"`
+	UID       string `yaml:"uid"`
+	Access    string `yaml:"access"`
+	URL       string `yaml:"url"`
+	IsDefault bool   `yaml:"isDefault"`
+	Editable  bool   `yaml:"editable"`
+}
+
+type providerFile struct {
+	APIVersion int        `yaml:"apiVersion"`
+	Providers  []provider `yaml:"providers"`
+}
+
+type provider struct {
+	Name                  string            `yaml:"name"`
+	Folder                string            `yaml:"folder"`
+	Type                  string            `yaml:"type"`
+	DisableDeletion       bool              `yaml:"disableDeletion"`
+	AllowUIUpdates        bool              `yaml:"allowUiUpdates"`
+	UpdateIntervalSeconds int               `yaml:"updateIntervalSeconds"`
+	Options               map[string]string `yaml:"options"`
+}
+
+// Write menulis dashboard ke dir/dashboards dan file provisioning ke dir/provisioning,
+// siap di-mount ke /etc/grafana/provisioning. Path file yang ditulis dikembalikan.
+func Write(dir string, dashboards []*Dashboard, opts ProvisioningOptions) ([]string, error) {
+	files := make(map[string][]byte)
+	for _, d := range dashboards {
+		b, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		files[filepath.Join(dir, "dashboards", d.UID+".json")] = append(b, '\n')
+	}
+
+	datasources, err := marshalYAML(datasourceFile{APIVersion: 1, Datasources: []datasourceEntry{{
+		Name:      "Prometheus",
+		Type:      "prometheus",
+		UID:       DatasourceUID,
+		Access:    "proxy",
+		URL:       opts.PrometheusURL,
+		IsDefault: true,
+	}}})
+	if err != nil {
+		return nil, err
+	}
+	files[filepath.Join(dir, "provisioning", "datasources", "prometheus.yaml")] = datasources
+
+	providers, err := marshalYAML(providerFile{APIVersion: 1, Providers: []provider{{
+		Name:                  Tag,
+		Folder:                Tag,
+		Type:                  "file",
+		AllowUIUpdates:        true,
+		UpdateIntervalSeconds: 30,
+		Options:               map[string]string{"path": opts.DashboardsPath},
+	}}})
+	if err != nil {
+		return nil, err
+	}
+	files[filepath.Join(dir, "provisioning", "dashboards", Tag+".yaml")] = providers
+
+	written := make([]string, 0, len(files))
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return written, err
+		}
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			return written, err
+		}
+		written = append(written, path)
+	}
+	slices.Sort(written)
+	return written, nil
+}
+
+func marshalYAML(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
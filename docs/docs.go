@@ -15,157 +15,3115 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/login": {
-            "post": {
-                "description": "Validates user credentials and logs the user in if the credentials are correct.",
+        "/admin/access-log": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the active access log format and sample rate.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "admin"
                 ],
-                "summary": "User Login",
+                "summary": "Get Access Log Settings",
+                "responses": {
+                    "200": {
+                        "description": "Access log settings",
+                        "schema": {
+                            "$ref": "#/definitions/handler.AccessLogSettings"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Switches the access log between json and logfmt and changes the sample rate.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Set Access Log Settings",
                 "parameters": [
                     {
-                        "description": "User credentials (username and password)",
-                        "name": "credentials",
+                        "description": "Fields to change",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.User"
+                            "$ref": "#/definitions/handler.AccessLogSettings"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success message indicating user login",
+                        "description": "Updated settings",
                         "schema": {
-                            "$ref": "#/definitions/entity.MsgResponse"
+                            "$ref": "#/definitions/handler.AccessLogSettings"
                         }
                     },
                     "400": {
-                        "description": "Error message indicating invalid credentials",
+                        "description": "Problem detail indicating invalid JSON format",
                         "schema": {
-                            "$ref": "#/definitions/entity.ErrorResponse"
+                            "$ref": "#/definitions/apperror.Problem"
                         }
                     },
                     "422": {
-                        "description": "Error message indicating invalid JSON format",
+                        "description": "Problem detail indicating invalid format or sample rate",
                         "schema": {
-                            "$ref": "#/definitions/entity.ErrorResponse"
+                            "$ref": "#/definitions/apperror.Problem"
                         }
                     }
                 }
             }
         },
-        "/register": {
+        "/admin/alerts": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the alerts of the embedded alert engine that are pending (condition met, waiting for the rule's for duration) or firing, with the silences and inhibitions currently muting their notifications.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "List Alerts",
+                "responses": {
+                    "200": {
+                        "description": "Active alerts, longest active first",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/alert.Alert"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/alerts/rules": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the rules evaluated by the embedded alert engine.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "List Alert Rules",
+                "responses": {
+                    "200": {
+                        "description": "Alert rules",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/alert.Rule"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/alerts/silences": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns every silence that has not ended yet.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "List Silences",
+                "responses": {
+                    "200": {
+                        "description": "Silences, soonest end first",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/alert.Silence"
+                            }
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Registers a new user by saving the provided user credentials to the database.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Mutes notifications for alerts whose labels match every matcher until the silence ends. Silenced alerts are still evaluated and listed.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "alerts"
                 ],
-                "summary": "User Registration",
+                "summary": "Create Silence",
                 "parameters": [
                     {
-                        "description": "User credentials (username, password, etc.)",
-                        "name": "credentials",
+                        "description": "Silence",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.User"
+                            "$ref": "#/definitions/handler.SilenceRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key that makes retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Success message indicating successful registration",
+                    "201": {
+                        "description": "Active silence",
                         "schema": {
-                            "$ref": "#/definitions/entity.MsgResponse"
+                            "$ref": "#/definitions/alert.Silence"
                         }
                     },
-                    "400": {
-                        "description": "Error message indicating invalid username or password",
+                    "409": {
+                        "description": "Problem detail indicating the idempotency key is in use",
                         "schema": {
-                            "$ref": "#/definitions/entity.ErrorResponse"
+                            "$ref": "#/definitions/apperror.Problem"
                         }
                     },
                     "422": {
-                        "description": "Error message indicating invalid JSON format",
+                        "description": "Problem detail with field-level validation errors or a reused idempotency key",
                         "schema": {
-                            "$ref": "#/definitions/entity.ErrorResponse"
+                            "$ref": "#/definitions/apperror.Problem"
                         }
                     }
                 }
             }
         },
-        "/users": {
+        "/admin/alerts/silences/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Ends a silence before its end time, notifications for the alerts it muted resume on the next evaluation.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Expire Silence",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Silence ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "$ref": "#/definitions/entity.MsgResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Problem detail indicating the silence does not exist or already ended",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/audit": {
             "get": {
-                "description": "Retrieves all users from the database.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns audit events newest first. Use next_cursor as the cursor parameter to fetch the next page.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "admin"
+                ],
+                "summary": "Query Audit Log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Who performed the action",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Action, e.g. login, register, password_reset",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Affected user or resource",
+                        "name": "target",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "success, failure or denied",
+                        "name": "outcome",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 start time (inclusive)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 end time (exclusive)",
+                        "name": "until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1-1000 (default 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get Users",
                 "responses": {
                     "200": {
-                        "description": "List of users in the database",
+                        "description": "Audit events and next_cursor",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/entity.User"
+                                "$ref": "#/definitions/audit.Event"
                             }
                         }
                     },
                     "422": {
-                        "description": "Error message indicating no users found",
+                        "description": "Problem detail indicating an invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/audit/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Streams every matching audit event oldest first as newline-delimited JSON, including the hash chain fields.",
+                "produces": [
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Export Audit Log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Who performed the action",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Action, e.g. login, register, password_reset",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Affected user or resource",
+                        "name": "target",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "success, failure or denied",
+                        "name": "outcome",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 start time (inclusive)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 end time (exclusive)",
+                        "name": "until",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "One audit event per line",
                         "schema": {
-                            "$ref": "#/definitions/entity.ErrorResponse"
+                            "$ref": "#/definitions/audit.Event"
                         }
                     },
-                    "500": {
-                        "description": "Error message indicating internal server error",
+                    "422": {
+                        "description": "Problem detail indicating an invalid filter",
                         "schema": {
-                            "$ref": "#/definitions/entity.ErrorResponse"
+                            "$ref": "#/definitions/apperror.Problem"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "entity.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string",
-                    "example": "Invalid Username and Password"
-                }
-            }
         },
-        "entity.MsgResponse": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string",
-                    "example": "user123 login successfully"
+        "/admin/chaos/faults": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns every active fault with its expiry and the number of requests or queries it affected.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chaos"
+                ],
+                "summary": "List Faults",
+                "responses": {
+                    "200": {
+                        "description": "Active faults, soonest expiry first",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/chaos.Fault"
+                            }
+                        }
+                    }
                 }
-            }
-        },
-        "entity.User": {
-            "type": "object",
-            "properties": {
-                "password": {
-                    "type": "string"
-                },
-                "username": {
-                    "type": "string"
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Activates a fault until its TTL expires. latency delays matching requests, error answers them with the given status, abort closes the connection without a response, db_error fails and db_delay slows down their database queries. Faults are scoped by route (a trailing * matches a prefix), method, header, user (authenticated routes only) and percentage.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chaos"
+                ],
+                "summary": "Inject Fault",
+                "parameters": [
+                    {
+                        "description": "Fault",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ChaosFaultRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key that makes retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Active fault",
+                        "schema": {
+                            "$ref": "#/definitions/chaos.Fault"
+                        }
+                    },
+                    "409": {
+                        "description": "Problem detail indicating the idempotency key is in use",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Problem detail with field-level validation errors or a reused idempotency key",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
                 }
-            }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deactivates every fault, ending all running chaos experiments.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chaos"
+                ],
+                "summary": "Remove All Faults",
+                "responses": {
+                    "200": {
+                        "description": "Success message with the number of removed faults",
+                        "schema": {
+                            "$ref": "#/definitions/entity.MsgResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/chaos/faults/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deactivates a fault before its TTL expires.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chaos"
+                ],
+                "summary": "Remove Fault",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Fault ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "$ref": "#/definitions/entity.MsgResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Problem detail indicating the fault does not exist or already expired",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/log-levels": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the current and base log level of every logger module.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get Log Levels",
+                "responses": {
+                    "200": {
+                        "description": "Log level of each module",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/logging.LevelStatus"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/log-levels/{module}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Changes the level of a logger module, optionally reverting after a TTL.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Set Log Level",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Logger module (app, handler, metric, db, http)",
+                        "name": "module",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New level and optional TTL",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.SetLevelRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated level",
+                        "schema": {
+                            "$ref": "#/definitions/logging.LevelStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Problem detail indicating invalid JSON format",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Problem detail indicating invalid level, ttl or module",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/probes": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the last result, consecutive failures and rolling availability (5m, 1h, 24h) of every synthetic probe, measured the way users reach the service.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "probe"
+                ],
+                "summary": "List Probes",
+                "responses": {
+                    "200": {
+                        "description": "Probe statuses",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/probe.Status"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/probes/{probe}/history": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the results of a probe, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "probe"
+                ],
+                "summary": "Probe History",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Probe name",
+                        "name": "probe",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only results after this RFC 3339 time (default 1 hour ago)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum results (default 500, max 10000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Probe results",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/probe.Result"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Problem detail indicating the probe does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Problem detail indicating an invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/slo": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns SLI, remaining error budget, burn rates and multi-window burn-rate alert state of every SLO, computed from the service's own request counters. Counters reset on restart; coverage tells how much of the compliance window has been observed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "slo"
+                ],
+                "summary": "List SLOs",
+                "responses": {
+                    "200": {
+                        "description": "SLO statuses",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/slo.Status"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/slo/rules": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns recording rules for the SLO error ratios and multi-window, multi-burn-rate alerting rules as a Prometheus rule file.",
+                "produces": [
+                    "application/yaml"
+                ],
+                "tags": [
+                    "slo"
+                ],
+                "summary": "SLO Prometheus Rules",
+                "responses": {
+                    "200": {
+                        "description": "Prometheus rule file",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{username}/sessions": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revokes all active sessions of a user, forcing them to log in again on every device.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Revoke User Sessions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Number of revoked sessions",
+                        "schema": {
+                            "$ref": "#/definitions/entity.RevokedSessionsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Problem detail indicating a missing or invalid API key",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhook-deliveries/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Get Webhook Delivery",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Delivery and attempt history",
+                        "schema": {
+                            "$ref": "#/definitions/handler.WebhookDeliveryDetail"
+                        }
+                    },
+                    "404": {
+                        "description": "Problem detail indicating the delivery does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhook-deliveries/{id}/redeliver": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Resets the attempt counter and sends the delivery again as soon as possible, including dead or already succeeded deliveries.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Redeliver Webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Rescheduled delivery",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Delivery"
+                        }
+                    },
+                    "404": {
+                        "description": "Problem detail indicating the delivery does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns all webhook subscriptions without their secrets.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "List Webhook Subscriptions",
+                "responses": {
+                    "200": {
+                        "description": "Subscriptions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/webhook.Subscription"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Registers a URL that receives signed user lifecycle events. The secret is returned only in this response.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Create Webhook Subscription",
+                "parameters": [
+                    {
+                        "description": "Subscription",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.WebhookSubscriptionRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key that makes retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created subscription including its secret",
+                        "schema": {
+                            "$ref": "#/definitions/handler.WebhookSubscriptionCreated"
+                        }
+                    },
+                    "409": {
+                        "description": "Problem detail indicating the idempotency key is in use",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Problem detail with field-level validation errors or a reused idempotency key",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Get Webhook Subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Subscription",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Subscription"
+                        }
+                    },
+                    "404": {
+                        "description": "Problem detail indicating the subscription does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replaces the URL and event filter. The secret is only rotated when a new one is sent.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Update Webhook Subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Subscription",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.WebhookSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated subscription",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Subscription"
+                        }
+                    },
+                    "404": {
+                        "description": "Problem detail indicating the subscription does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Problem detail with field-level validation errors",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes the subscription. Pending deliveries for it are moved to the dead state.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Delete Webhook Subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "$ref": "#/definitions/entity.MsgResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Problem detail indicating the subscription does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the newest deliveries of a subscription, optionally filtered by status.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "List Webhook Deliveries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pending, retrying, succeeded or dead",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1-1000 (default 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deliveries, newest first",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/webhook.Delivery"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Problem detail indicating the subscription does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/callback": {
+            "get": {
+                "description": "Exchanges the authorization code, validates the ID token and returns a session token. The identity is linked to an existing account by verified email, or a new account is provisioned when enabled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sso"
+                ],
+                "summary": "OIDC Callback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State returned by the identity provider, must match the state cookie set by the login endpoint",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Session token",
+                        "schema": {
+                            "$ref": "#/definitions/entity.LoginResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Problem detail indicating the login was rejected or the ID token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Problem detail indicating no account is linked to the identity",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "502": {
+                        "description": "Problem detail indicating the identity provider could not be reached",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/login": {
+            "get": {
+                "description": "Redirects to the identity provider's authorization endpoint using the authorization code flow with PKCE.",
+                "tags": [
+                    "sso"
+                ],
+                "summary": "Start OIDC Login",
+                "responses": {
+                    "302": {
+                        "description": "Redirect to the identity provider"
+                    },
+                    "502": {
+                        "description": "Problem detail indicating the identity provider could not be reached",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/live/events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Streams HTTP request observations, business events and rolling request statistics (rate, error ratio, p50/p90/p99 latency) as Server-Sent Events. Reconnecting clients send Last-Event-ID to replay missed events from the in-memory buffer. Clients that cannot keep up are disconnected with a \"dropped\" event.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "live"
+                ],
+                "summary": "Live Event Stream",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma separated event types to receive: http, business, stats",
+                        "name": "types",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated business event types, e.g. register,login",
+                        "name": "event",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only HTTP events whose route starts with this prefix",
+                        "name": "endpoint",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only HTTP events with at least this status code",
+                        "name": "min_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sequence number of the last event received",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream of events",
+                        "schema": {
+                            "$ref": "#/definitions/live.Event"
+                        }
+                    },
+                    "401": {
+                        "description": "Problem detail indicating a missing or invalid API key",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Problem detail indicating an invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/live/ws": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Same stream as /live/events delivered as JSON text messages over a WebSocket. Use the last_event_id query parameter to replay missed events. Slow clients are closed with status 1013 and all clients are closed with status 1001 when the server shuts down.",
+                "tags": [
+                    "live"
+                ],
+                "summary": "Live Event WebSocket",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma separated event types to receive: http, business, stats",
+                        "name": "types",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated business event types, e.g. register,login",
+                        "name": "event",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only HTTP events whose route starts with this prefix",
+                        "name": "endpoint",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only HTTP events with at least this status code",
+                        "name": "min_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Sequence number of the last event received",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching protocols"
+                    },
+                    "401": {
+                        "description": "Problem detail indicating a missing or invalid API key",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Problem detail indicating an invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Validates user credentials and returns a session token. Accounts with TOTP enabled get an mfa_required challenge that must be completed at /login/mfa.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "User Login",
+                "parameters": [
+                    {
+                        "description": "User credentials (username and password)",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Session token, or an MFA challenge token",
+                        "schema": {
+                            "$ref": "#/definitions/entity.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Problem detail indicating invalid JSON format",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Problem detail indicating invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Problem detail indicating the email address is not verified yet",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Problem detail with field-level validation errors",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/login/mfa": {
+            "post": {
+                "description": "Exchanges the challenge token returned by /login together with a TOTP or recovery code for a session token.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "Complete MFA Login",
+                "parameters": [
+                    {
+                        "description": "Challenge token and code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.MFALoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Session token",
+                        "schema": {
+                            "$ref": "#/definitions/entity.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Problem detail indicating an invalid or expired challenge",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Problem detail indicating an invalid code",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Problem detail with field-level validation errors",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/mfa/totp": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Disables two-factor login after verifying a current TOTP code or a recovery code.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "Disable TOTP",
+                "parameters": [
+                    {
+                        "description": "Current TOTP code or recovery code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.MFACodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "$ref": "#/definitions/entity.MsgResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Problem detail indicating an invalid session or code",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Problem detail indicating two-factor authentication is not enabled",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/mfa/totp/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Verifies the first code from the authenticator app, enables two-factor login and returns one-time recovery codes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "Confirm TOTP",
+                "parameters": [
+                    {
+                        "description": "Current TOTP code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.MFACodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Recovery codes, shown only once",
+                        "schema": {
+                            "$ref": "#/definitions/entity.RecoveryCodesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Problem detail indicating an invalid session or code",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Problem detail indicating no enrollment was started or it is already enabled",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/mfa/totp/enroll": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Generates a new TOTP secret, otpauth:// URI and QR code. Two-factor login is only enabled after the first code is confirmed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "Enroll TOTP",
+                "responses": {
+                    "200": {
+                        "description": "Secret, otpauth URI and base64 PNG QR code",
+                        "schema": {
+                            "$ref": "#/definitions/entity.TOTPEnrollResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Problem detail indicating a missing or invalid session",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Problem detail indicating two-factor authentication is already enabled",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/mfa/totp/qr.png": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the otpauth:// URI of the pending or active enrollment as a PNG QR code.",
+                "produces": [
+                    "image/png"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "TOTP QR Code",
+                "responses": {
+                    "200": {
+                        "description": "QR code PNG",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "401": {
+                        "description": "Problem detail indicating a missing or invalid session",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Problem detail indicating no enrollment was started",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns every active session of the current user with device, IP, user agent and activity times.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "List My Sessions",
+                "responses": {
+                    "200": {
+                        "description": "Active sessions, newest first",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.SessionInfo"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Problem detail indicating a missing or invalid session",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revokes a session of the current user, e.g. a lost device. Revoking the current session logs the caller out.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Revoke My Session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "$ref": "#/definitions/entity.MsgResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Problem detail indicating a missing or invalid session",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Problem detail indicating the session does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/password-reset/confirm": {
+            "post": {
+                "description": "Redeems a single-use reset token, replaces the user's password and revokes all of the user's sessions.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Confirm Password Reset",
+                "parameters": [
+                    {
+                        "description": "Reset token and new password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.PasswordResetConfirm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message indicating the password was changed",
+                        "schema": {
+                            "$ref": "#/definitions/entity.MsgResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Problem detail indicating an invalid, expired or used token",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Problem detail with field-level validation errors",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/password-reset/request": {
+            "post": {
+                "description": "Sends a single-use reset token to the email address if it belongs to a user. Always returns 202 so registered emails cannot be discovered.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Request Password Reset",
+                "parameters": [
+                    {
+                        "description": "Email address of the account",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.PasswordResetRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key that makes retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Message indicating the request was accepted",
+                        "schema": {
+                            "$ref": "#/definitions/entity.MsgResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Problem detail indicating the idempotency key is in use",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Problem detail with field-level validation errors or a reused idempotency key",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "Registers a new user by saving the provided user credentials to the database and sends a verification email.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "User Registration",
+                "parameters": [
+                    {
+                        "description": "User credentials (username, password, etc.)",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.RegisterRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key that makes retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message indicating successful registration",
+                        "schema": {
+                            "$ref": "#/definitions/entity.MsgResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Problem detail indicating invalid JSON format",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Problem detail indicating the username or email is taken, or the idempotency key is in use",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Problem detail with field-level validation errors or a reused idempotency key",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "description": "Retrieves all users from the database.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get Users",
+                "responses": {
+                    "200": {
+                        "description": "List of users in the database, empty when none are registered",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.User"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Problem detail indicating internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/verify-email": {
+            "get": {
+                "description": "Redeems a single-use email verification token sent after registration.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Verify Email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Verification token from the email",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message indicating the email is verified",
+                        "schema": {
+                            "$ref": "#/definitions/entity.MsgResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Problem detail indicating an invalid, expired or used token",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "alert.Alert": {
+            "type": "object",
+            "properties": {
+                "active_at": {
+                    "type": "string"
+                },
+                "annotations": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "fingerprint": {
+                    "type": "string",
+                    "example": "8c1f0d2a4b6e7f90"
+                },
+                "fired_at": {
+                    "type": "string"
+                },
+                "inhibited": {
+                    "description": "Inhibited berarti ada alert lain yang membungkam alert ini lewat inhibit rule",
+                    "type": "boolean"
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "HTTPErrorRatioHigh"
+                },
+                "resolved_at": {
+                    "type": "string"
+                },
+                "silenced_by": {
+                    "description": "SilencedBy berisi id silence yang cocok, alert yang di-silence tidak dikirim",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "state": {
+                    "type": "string",
+                    "example": "firing"
+                },
+                "value": {
+                    "type": "number",
+                    "example": 0.083
+                }
+            }
+        },
+        "alert.Matcher": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "is_regex": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "alertname"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "HTTPLatencyP99High"
+                }
+            }
+        },
+        "alert.Rule": {
+            "type": "object",
+            "properties": {
+                "annotations": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "by": {
+                    "description": "By memecah rule menjadi satu alert per kombinasi label ini",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "denominator": {
+                    "description": "Denominator wajib untuk rule ratio",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/alert.Selector"
+                        }
+                    ]
+                },
+                "for": {
+                    "description": "For adalah lama kondisi harus terpenuhi sebelum alert firing",
+                    "type": "string",
+                    "example": "5m"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "ratio"
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "match": {
+                    "description": "Match berisi regex per label yang harus cocok penuh, seperti matcher =~ PromQL",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "metric": {
+                    "type": "string",
+                    "example": "app_http_requests_total"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "HTTPErrorRatioHigh"
+                },
+                "op": {
+                    "type": "string",
+                    "example": "\u003e"
+                },
+                "quantile": {
+                    "description": "Quantile wajib untuk rule quantile, misalnya 0.99",
+                    "type": "number"
+                },
+                "threshold": {
+                    "type": "number",
+                    "example": 0.05
+                },
+                "window": {
+                    "description": "Window adalah rentang kenaikan counter, default 5m",
+                    "type": "string",
+                    "example": "5m"
+                }
+            }
+        },
+        "alert.Selector": {
+            "type": "object",
+            "properties": {
+                "match": {
+                    "description": "Match berisi regex per label yang harus cocok penuh, seperti matcher =~ PromQL",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "metric": {
+                    "type": "string",
+                    "example": "app_http_requests_total"
+                }
+            }
+        },
+        "alert.Silence": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "example": "deploying a fix"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "oncall"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "k2m9x4q7ta"
+                },
+                "matchers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/alert.Matcher"
+                    }
+                },
+                "starts_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "apperror.Code": {
+            "type": "string",
+            "enum": [
+                "invalid_json",
+                "validation_failed",
+                "invalid_credentials",
+                "username_taken",
+                "email_taken",
+                "email_not_verified",
+                "invalid_token",
+                "invalid_mfa_code",
+                "mfa_not_enrolled",
+                "mfa_already_enabled",
+                "sso_failed",
+                "sso_account_not_linked",
+                "sso_unavailable",
+                "unauthorized",
+                "forbidden",
+                "not_found",
+                "body_too_large",
+                "idempotency_key_reused",
+                "idempotency_key_in_progress",
+                "chaos_fault",
+                "request_timeout",
+                "internal_error"
+            ],
+            "x-enum-varnames": [
+                "CodeInvalidJSON",
+                "CodeValidation",
+                "CodeInvalidCredentials",
+                "CodeUsernameTaken",
+                "CodeEmailTaken",
+                "CodeEmailNotVerified",
+                "CodeInvalidToken",
+                "CodeInvalidMFACode",
+                "CodeMFANotEnrolled",
+                "CodeMFAAlreadyEnabled",
+                "CodeSSOFailed",
+                "CodeSSONotLinked",
+                "CodeSSOUnavailable",
+                "CodeUnauthorized",
+                "CodeForbidden",
+                "CodeNotFound",
+                "CodeBodyTooLarge",
+                "CodeIdempotencyReused",
+                "CodeIdempotencyBusy",
+                "CodeChaosFault",
+                "CodeTimeout",
+                "CodeInternal"
+            ]
+        },
+        "apperror.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "username"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "username is required"
+                },
+                "rule": {
+                    "type": "string",
+                    "example": "required"
+                }
+            }
+        },
+        "apperror.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/apperror.Code"
+                        }
+                    ],
+                    "example": "invalid_credentials"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "username or password is incorrect"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apperror.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/api/v1/login"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "3f1c0e6a9b7d4e2f8a1b2c3d4e5f6a7b"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 401
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Invalid username or password"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "urn:problem-type:wyw:invalid_credentials"
+                }
+            }
+        },
+        "audit.Event": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "hash": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "outcome": {
+                    "type": "string"
+                },
+                "prev_hash": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "target": {
+                    "type": "string"
+                }
+            }
+        },
+        "chaos.Fault": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "f1c9a2"
+                },
+                "injections": {
+                    "type": "integer"
+                },
+                "latency": {
+                    "description": "Latency dipakai oleh fault latency dan db_delay",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/chaos.Latency"
+                        }
+                    ]
+                },
+                "scope": {
+                    "$ref": "#/definitions/chaos.Scope"
+                },
+                "status": {
+                    "description": "Status dipakai oleh fault error",
+                    "type": "integer",
+                    "example": 503
+                },
+                "type": {
+                    "type": "string",
+                    "example": "latency"
+                }
+            }
+        },
+        "chaos.Latency": {
+            "type": "object",
+            "properties": {
+                "distribution": {
+                    "type": "string",
+                    "example": "normal"
+                },
+                "max_ms": {
+                    "type": "number"
+                },
+                "mean_ms": {
+                    "type": "number",
+                    "example": 300
+                },
+                "min_ms": {
+                    "type": "number"
+                },
+                "stddev_ms": {
+                    "type": "number",
+                    "example": 100
+                }
+            }
+        },
+        "chaos.Scope": {
+            "type": "object",
+            "properties": {
+                "header": {
+                    "description": "Header dan HeaderValue mencocokkan header request; HeaderValue kosong cukup header ada",
+                    "type": "string",
+                    "example": "X-Chaos"
+                },
+                "header_value": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string",
+                    "example": "POST"
+                },
+                "percentage": {
+                    "description": "Percentage adalah peluang request (atau query, untuk fault DB) terkena fault",
+                    "type": "number",
+                    "example": 50
+                },
+                "route": {
+                    "description": "Route adalah pola route gin, misalnya /api/v1/login; akhiran \"*\" berarti prefix",
+                    "type": "string",
+                    "example": "/api/v1/login"
+                },
+                "user": {
+                    "description": "User hanya berlaku di route yang memerlukan login",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.LoginRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "S3cretPassw0rd"
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "user123"
+                }
+            }
+        },
+        "entity.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "challenge_token": {
+                    "type": "string",
+                    "example": "eyJqdGkiOi...Zm9v.c2lnbmF0dXJl"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "user123 login successfully"
+                },
+                "mfa_required": {
+                    "type": "boolean"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "q8Z1k3V9x0..."
+                }
+            }
+        },
+        "entity.MFACodeRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "example": "123456"
+                }
+            }
+        },
+        "entity.MFALoginRequest": {
+            "type": "object",
+            "required": [
+                "challenge_token",
+                "code"
+            ],
+            "properties": {
+                "challenge_token": {
+                    "type": "string",
+                    "maxLength": 512
+                },
+                "code": {
+                    "description": "Code berisi kode TOTP 6 digit atau kode pemulihan xxxxx-xxxxx",
+                    "type": "string",
+                    "maxLength": 32,
+                    "example": "123456"
+                }
+            }
+        },
+        "entity.MsgResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "user123 login successfully"
+                }
+            }
+        },
+        "entity.PasswordResetConfirm": {
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "N3wPassw0rd"
+                },
+                "token": {
+                    "type": "string",
+                    "maxLength": 512,
+                    "example": "eyJqdGkiOi...Zm9v.c2lnbmF0dXJl"
+                }
+            }
+        },
+        "entity.PasswordResetRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 254,
+                    "example": "user123@example.com"
+                }
+            }
+        },
+        "entity.RecoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "abcde-fghij"
+                    ]
+                }
+            }
+        },
+        "entity.RegisterRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password",
+                "username"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 254,
+                    "example": "user123@example.com"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "S3cretPassw0rd"
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 3,
+                    "example": "user123"
+                }
+            }
+        },
+        "entity.RevokedSessionsResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "3 sessions revoked for user123"
+                },
+                "revoked": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "entity.SessionInfo": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "description": "Current bernilai true untuk session yang dipakai request ini",
+                    "type": "boolean"
+                },
+                "device": {
+                    "type": "string",
+                    "example": "Firefox on Linux"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015"
+                },
+                "ip": {
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string",
+                    "example": "Mozilla/5.0 (X11; Linux x86_64; rv:131.0) Gecko/20100101 Firefox/131.0"
+                }
+            }
+        },
+        "entity.TOTPEnrollResponse": {
+            "type": "object",
+            "properties": {
+                "otpauth_url": {
+                    "type": "string",
+                    "example": "otpauth://totp/example-monitoring:user123?secret=JBSWY3DPEHPK3PXP\u0026issuer=example-monitoring"
+                },
+                "qr_code_png": {
+                    "description": "QRCodePNG adalah gambar PNG ter-encode base64",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "JBSWY3DPEHPK3PXP"
+                }
+            }
+        },
+        "entity.User": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "Email nullable supaya akun lama tanpa email tidak bentrok di unique index",
+                    "type": "string"
+                },
+                "email_verified_at": {
+                    "type": "string"
+                },
+                "role": {
+                    "description": "Role diisi dari mapping grup IdP untuk akun OIDC, akun lokal memakai default",
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.AccessLogSettings": {
+            "type": "object",
+            "properties": {
+                "format": {
+                    "type": "string",
+                    "example": "json"
+                },
+                "sample_rate": {
+                    "type": "number",
+                    "example": 0.5
+                }
+            }
+        },
+        "handler.ChaosFaultRequest": {
+            "type": "object",
+            "required": [
+                "ttl",
+                "type"
+            ],
+            "properties": {
+                "latency": {
+                    "description": "Latency distribution for latency and db_delay faults.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/chaos.Latency"
+                        }
+                    ]
+                },
+                "scope": {
+                    "$ref": "#/definitions/chaos.Scope"
+                },
+                "status": {
+                    "description": "Status returned by error faults.",
+                    "type": "integer",
+                    "maximum": 599,
+                    "minimum": 400,
+                    "example": 503
+                },
+                "ttl": {
+                    "description": "TTL is how long the fault stays active, as a Go duration.",
+                    "type": "string",
+                    "example": "5m"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "latency",
+                        "error",
+                        "abort",
+                        "db_error",
+                        "db_delay"
+                    ],
+                    "example": "latency"
+                }
+            }
+        },
+        "handler.SetLevelRequest": {
+            "type": "object",
+            "properties": {
+                "level": {
+                    "type": "string",
+                    "example": "debug"
+                },
+                "ttl": {
+                    "description": "TTL is optional, e.g. \"15m\". When set the level reverts after it expires.",
+                    "type": "string",
+                    "example": "15m"
+                }
+            }
+        },
+        "handler.SilenceRequest": {
+            "type": "object",
+            "required": [
+                "created_by",
+                "matchers"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "example": "deploying a fix"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "oncall"
+                },
+                "duration": {
+                    "description": "Duration is how long the silence lasts, as a Go duration. Ignored when ends_at is set.",
+                    "type": "string",
+                    "example": "2h"
+                },
+                "ends_at": {
+                    "description": "EndsAt is when the silence ends.",
+                    "type": "string"
+                },
+                "matchers": {
+                    "description": "Matchers must all match an alert's labels for it to be silenced.",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/alert.Matcher"
+                    }
+                }
+            }
+        },
+        "handler.WebhookDeliveryDetail": {
+            "type": "object",
+            "properties": {
+                "attempt_history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/webhook.Attempt"
+                    }
+                },
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "description": "EventID adalah ID message outbox, unik per subscription supaya redelivery dari\noutbox tidak membuat delivery ganda",
+                    "type": "integer"
+                },
+                "event_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_status_code": {
+                    "type": "integer"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.WebhookSubscriptionCreated": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "description": "Events berisi daftar event yang dipisah koma, atau \"*\" untuk semua event",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "whsec_3q2+7w..."
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.WebhookSubscriptionRequest": {
+            "type": "object",
+            "required": [
+                "events",
+                "url"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "events": {
+                    "description": "Events to deliver, \"*\" subscribes to every event.",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "register",
+                        "login"
+                    ]
+                },
+                "secret": {
+                    "description": "Secret signs the deliveries. A random secret is generated when empty.",
+                    "type": "string",
+                    "maxLength": 128,
+                    "minLength": 16
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 2048,
+                    "example": "https://hooks.example.com/users"
+                }
+            }
+        },
+        "live.Event": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "seq": {
+                    "type": "integer"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "logging.LevelStatus": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "level": {
+                    "type": "string"
+                },
+                "module": {
+                    "type": "string"
+                }
+            }
+        },
+        "probe.Availability": {
+            "type": "object",
+            "properties": {
+                "ratio": {
+                    "type": "number",
+                    "example": 0.9917
+                },
+                "samples": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "window": {
+                    "type": "string",
+                    "example": "1h0m0s"
+                }
+            }
+        },
+        "probe.Result": {
+            "type": "object",
+            "properties": {
+                "duration_ms": {
+                    "type": "number"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "probe.Status": {
+            "type": "object",
+            "properties": {
+                "availability": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/probe.Availability"
+                    }
+                },
+                "consecutive_failures": {
+                    "description": "ConsecutiveFailures menghitung kegagalan berturut-turut sampai hasil terakhir",
+                    "type": "integer"
+                },
+                "last": {
+                    "$ref": "#/definitions/probe.Result"
+                },
+                "probe": {
+                    "type": "string",
+                    "example": "login_canary"
+                }
+            }
+        },
+        "slo.Alert": {
+            "type": "object",
+            "properties": {
+                "firing": {
+                    "type": "boolean"
+                },
+                "long_window": {
+                    "type": "string",
+                    "example": "1h"
+                },
+                "severity": {
+                    "type": "string",
+                    "example": "page"
+                },
+                "short_window": {
+                    "type": "string",
+                    "example": "5m"
+                },
+                "threshold": {
+                    "type": "number",
+                    "example": 14.4
+                }
+            }
+        },
+        "slo.BurnRate": {
+            "type": "object",
+            "properties": {
+                "burn_rate": {
+                    "description": "BurnRate 1 berarti budget habis tepat di akhir periode compliance",
+                    "type": "number",
+                    "example": 0.4
+                },
+                "error_ratio": {
+                    "type": "number",
+                    "example": 0.0004
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 5230
+                },
+                "window": {
+                    "type": "string",
+                    "example": "1h"
+                }
+            }
+        },
+        "slo.Status": {
+            "type": "object",
+            "properties": {
+                "alerts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/slo.Alert"
+                    }
+                },
+                "budget_remaining": {
+                    "description": "BudgetRemaining adalah sisa error budget, negatif bila SLO sudah dilanggar",
+                    "type": "number",
+                    "example": 0.583
+                },
+                "burn_rates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/slo.BurnRate"
+                    }
+                },
+                "coverage": {
+                    "description": "Coverage adalah porsi periode compliance yang teramati sejak proses mulai, counter\nin-process hilang saat restart sehingga nilai di bawah 1 berarti data belum lengkap",
+                    "type": "number",
+                    "example": 0.25
+                },
+                "endpoint": {
+                    "type": "string",
+                    "example": "/api/v1/login"
+                },
+                "good": {
+                    "type": "integer",
+                    "example": 119950
+                },
+                "latency_ms": {
+                    "type": "number",
+                    "example": 300
+                },
+                "method": {
+                    "type": "string",
+                    "example": "POST"
+                },
+                "objective": {
+                    "type": "number",
+                    "example": 0.999
+                },
+                "sli": {
+                    "description": "SLI adalah rasio request baik, 1 bila belum ada request",
+                    "type": "number",
+                    "example": 0.99958
+                },
+                "slo": {
+                    "type": "string",
+                    "example": "login"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 120000
+                },
+                "window": {
+                    "type": "string",
+                    "example": "30d"
+                }
+            }
+        },
+        "webhook.Attempt": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "delivery_id": {
+                    "type": "integer"
+                },
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "status_code": {
+                    "type": "integer"
+                }
+            }
+        },
+        "webhook.Delivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "description": "EventID adalah ID message outbox, unik per subscription supaya redelivery dari\noutbox tidak membuat delivery ganda",
+                    "type": "integer"
+                },
+                "event_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_status_code": {
+                    "type": "integer"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "webhook.Subscription": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "description": "Events berisi daftar event yang dipisah koma, atau \"*\" untuk semua event",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "Session token from /login, sent as \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
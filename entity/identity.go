@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+// UserIdentity menghubungkan akun lokal dengan identitas dari OIDC provider.
+// Pasangan issuer + subject unik karena subject hanya unik per issuer.
+type UserIdentity struct {
+	ID          uint   `gorm:"primaryKey"`
+	Issuer      string `gorm:"uniqueIndex:idx_identity_issuer_subject;size:191"`
+	Subject     string `gorm:"uniqueIndex:idx_identity_issuer_subject;size:191"`
+	Username    string `gorm:"index;size:191"`
+	Email       string `gorm:"size:254"`
+	CreatedAt   time.Time
+	LastLoginAt time.Time
+}
@@ -0,0 +1,71 @@
+package entity
+
+import "time"
+
+// UserMFA menyimpan secret TOTP milik user. Enabled baru true setelah user
+// mengonfirmasi enrollment dengan kode pertama.
+type UserMFA struct {
+	Username string `gorm:"primaryKey;size:191"`
+	Secret   string `gorm:"size:64"`
+	Enabled  bool
+	// LastStep adalah time step TOTP terakhir yang diterima, mencegah kode dipakai ulang
+	LastStep    int64
+	ConfirmedAt *time.Time
+	CreatedAt   time.Time
+}
+
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// RecoveryCode adalah kode pemulihan sekali pakai, hanya hash-nya yang disimpan
+type RecoveryCode struct {
+	ID       uint   `gorm:"primaryKey"`
+	Username string `gorm:"index;size:191"`
+	CodeHash string `gorm:"uniqueIndex;size:64"`
+	UsedAt   *time.Time
+}
+
+// MFAChallengeAttempt menghitung kode salah per challenge token di database supaya batas
+// percobaan berlaku di semua instance dan tidak hilang saat restart
+type MFAChallengeAttempt struct {
+	ChallengeID string `gorm:"primaryKey;size:32"`
+	Username    string `gorm:"size:191"`
+	Failures    int
+	ExpiresAt   time.Time `gorm:"index"`
+}
+
+// LoginResponse dikembalikan oleh login. Bila MFARequired true, ChallengeToken harus
+// ditukar lewat /login/mfa untuk mendapatkan session.
+type LoginResponse struct {
+	Message        string     `json:"message" example:"user123 login successfully"`
+	Token          string     `json:"token,omitempty" example:"q8Z1k3V9x0..."`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	MFARequired    bool       `json:"mfa_required,omitempty"`
+	ChallengeToken string     `json:"challenge_token,omitempty" example:"eyJqdGkiOi...Zm9v.c2lnbmF0dXJl"`
+}
+
+// MFALoginRequest adalah payload untuk menukar challenge token dengan session
+type MFALoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required,max=512"`
+	// Code berisi kode TOTP 6 digit atau kode pemulihan xxxxx-xxxxx
+	Code string `json:"code" binding:"required,max=32" example:"123456"`
+}
+
+// MFACodeRequest adalah payload yang hanya berisi kode TOTP atau kode pemulihan
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required,max=32" example:"123456"`
+}
+
+// TOTPEnrollResponse berisi data untuk mendaftarkan akun di aplikasi authenticator
+type TOTPEnrollResponse struct {
+	Secret     string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	OTPAuthURL string `json:"otpauth_url" example:"otpauth://totp/example-monitoring:user123?secret=JBSWY3DPEHPK3PXP&issuer=example-monitoring"`
+	// QRCodePNG adalah gambar PNG ter-encode base64
+	QRCodePNG []byte `json:"qr_code_png"`
+}
+
+// RecoveryCodesResponse berisi kode pemulihan yang hanya ditampilkan sekali
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"abcde-fghij"`
+}
@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// SessionInfo adalah session login yang ditampilkan ke pemiliknya
+type SessionInfo struct {
+	ID         string    `json:"id" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	Device     string    `json:"device" example:"Firefox on Linux"`
+	IP         string    `json:"ip" example:"203.0.113.7"`
+	UserAgent  string    `json:"user_agent" example:"Mozilla/5.0 (X11; Linux x86_64; rv:131.0) Gecko/20100101 Firefox/131.0"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	// Current bernilai true untuk session yang dipakai request ini
+	Current bool `json:"current"`
+}
+
+// RevokedSessionsResponse berisi jumlah session yang dicabut
+type RevokedSessionsResponse struct {
+	Message string `json:"message" example:"3 sessions revoked for user123"`
+	Revoked int64  `json:"revoked" example:"3"`
+}
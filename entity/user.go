@@ -1,14 +1,50 @@
 package entity
 
+import "time"
+
 type User struct {
 	Username string `json:"username" gorm:"unique"`
-	Password string
+	Password string `json:"-"`
+	// Email nullable supaya akun lama tanpa email tidak bentrok di unique index
+	Email           *string    `json:"email,omitempty" gorm:"uniqueIndex;size:254"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	// Role diisi dari mapping grup IdP untuk akun OIDC, akun lokal memakai default
+	Role string `json:"role" gorm:"size:32;default:user"`
 }
 
 type MsgResponse struct {
 	Message string `json:"message" example:"user123 login successfully"`
 }
 
-type ErrorResponse struct {
-	Message string `json:"message" example:"Invalid Username and Password"`
+// LoginRequest adalah payload untuk endpoint login
+type LoginRequest struct {
+	Username string `json:"username" binding:"required,max=64" example:"user123"`
+	Password string `json:"password" binding:"required,max=128" example:"S3cretPassw0rd"`
+}
+
+// RegisterRequest adalah payload untuk endpoint register.
+// Aturan username dan password didaftarkan di package validation.
+type RegisterRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=32,username" example:"user123"`
+	Password string `json:"password" binding:"required,password" example:"S3cretPassw0rd"`
+	Email    string `json:"email" binding:"required,email,max=254" example:"user123@example.com"`
+}
+
+// PasswordResetRequest adalah payload untuk meminta email reset password
+type PasswordResetRequest struct {
+	Email string `json:"email" binding:"required,email,max=254" example:"user123@example.com"`
+}
+
+// PasswordResetConfirm adalah payload untuk mengganti password dengan token reset
+type PasswordResetConfirm struct {
+	Token    string `json:"token" binding:"required,max=512" example:"eyJqdGkiOi...Zm9v.c2lnbmF0dXJl"`
+	Password string `json:"password" binding:"required,password" example:"N3wPassw0rd"`
+}
+
+// UserEvent adalah payload event bisnis user yang dikirim lewat outbox
+type UserEvent struct {
+	Type       string    `json:"type" example:"register"`
+	Username   string    `json:"username" example:"user123"`
+	RequestID  string    `json:"request_id,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
 }
@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+	"wyw/apperror"
+	"wyw/audit"
+	"wyw/entity"
+	"wyw/logging"
+	"wyw/mail"
+	"wyw/metric"
+	"wyw/session"
+	"wyw/token"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AccountHandler interface {
+	VerifyEmail(c *gin.Context)
+	RequestPasswordReset(c *gin.Context)
+	ConfirmPasswordReset(c *gin.Context)
+}
+
+// AccountOptions mengatur link dan masa berlaku token di email akun
+type AccountOptions struct {
+	// VerifyURL adalah URL yang akan ditambah query ?token=... untuk verifikasi email
+	VerifyURL string
+	// ResetURL adalah URL halaman reset password yang akan ditambah query ?token=...
+	ResetURL       string
+	VerifyTokenTTL time.Duration
+	ResetTokenTTL  time.Duration
+}
+
+type AccountHandlerImpl struct {
+	*gorm.DB
+	*metric.AppMetricsExporter
+	Mailer   mail.Mailer
+	Tokens   *token.Manager
+	Sessions *session.Manager
+	Options  AccountOptions
+	Audit    *audit.Recorder
+}
+
+func NewAccountHandler(DB *gorm.DB, appMetricsExporter *metric.AppMetricsExporter, mailer mail.Mailer, tokens *token.Manager, sessions *session.Manager, auditor *audit.Recorder, options AccountOptions) *AccountHandlerImpl {
+	return &AccountHandlerImpl{DB: DB, AppMetricsExporter: appMetricsExporter, Mailer: mailer, Tokens: tokens, Sessions: sessions, Audit: auditor, Options: options}
+}
+
+// mailData adalah data yang dipakai template email akun
+type mailData struct {
+	Username  string
+	Token     string
+	Link      string
+	ExpiresIn string
+}
+
+// SendVerification mengirim email verifikasi ke user secara asynchronous
+func (a AccountHandlerImpl) SendVerification(user entity.User) error {
+	if user.Email == nil {
+		return nil
+	}
+	tok, err := a.Tokens.Issue(token.PurposeVerifyEmail, user.Username, a.Options.VerifyTokenTTL)
+	if err != nil {
+		return err
+	}
+	a.sendAsync(mail.TemplateVerifyEmail, *user.Email, mailData{
+		Username:  user.Username,
+		Token:     tok,
+		Link:      withToken(a.Options.VerifyURL, tok),
+		ExpiresIn: a.Options.VerifyTokenTTL.String(),
+	})
+	return nil
+}
+
+// sendAsync merender dan mengirim email di goroutine terpisah supaya latensi SMTP
+// tidak menahan response, hasilnya dicatat di log dan metrik
+func (a AccountHandlerImpl) sendAsync(template, to string, data mailData) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		msg, err := mail.Render(template, to, data)
+		if err == nil {
+			err = a.Mailer.Send(ctx, msg)
+		}
+		if err != nil {
+			a.AppMetricsExporter.RecordMailSent(template, "failed")
+			logging.Logger(logging.ModuleHandler).Error("failed to send email",
+				slog.String("template", template), slog.String("username", data.Username), slog.Any("error", err))
+			return
+		}
+		a.AppMetricsExporter.RecordMailSent(template, "sent")
+	}()
+}
+
+func withToken(base, tok string) string {
+	u, err := url.Parse(base)
+	if err != nil {
+		return base + "?token=" + url.QueryEscape(tok)
+	}
+	q := u.Query()
+	q.Set("token", tok)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// VerifyEmail marks the user's email address as verified.
+// @Summary      Verify Email
+// @Description  Redeems a single-use email verification token sent after registration.
+// @Param        token query string true "Verification token from the email"
+// @Produce      application/json
+// @Tags         account
+// @Success      200 {object} entity.MsgResponse "Success message indicating the email is verified"
+// @Failure      400 {object} apperror.Problem "Problem detail indicating an invalid, expired or used token"
+// @Router       /verify-email [get]
+func (a AccountHandlerImpl) VerifyEmail(c *gin.Context) {
+	raw := c.Query("token")
+	ctx := c.Request.Context()
+
+	var claims token.Claims
+	err := a.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if claims, err = a.Tokens.Redeem(ctx, tx, raw, token.PurposeVerifyEmail); err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.User{}).
+			Where("username = ?", claims.Subject).
+			Update("email_verified_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return publishEvent(c, tx, "email_verified", claims.Subject)
+	})
+	if err != nil {
+		recordAudit(c, a.Audit, "", "verify_email", "", audit.OutcomeFailure, err.Error())
+		a.tokenError(c, err)
+		return
+	}
+
+	a.AppMetricsExporter.RecordBusinessEvent("email_verified", claims.Subject)
+	recordAudit(c, a.Audit, claims.Subject, "verify_email", claims.Subject, audit.OutcomeSuccess, "")
+	c.JSON(http.StatusOK, gin.H{"message": "email verified successfully"})
+}
+
+// RequestPasswordReset sends a password reset email.
+// @Summary      Request Password Reset
+// @Description  Sends a single-use reset token to the email address if it belongs to a user. Always returns 202 so registered emails cannot be discovered.
+// @Param        request body entity.PasswordResetRequest true "Email address of the account"
+// @Param        Idempotency-Key header string false "Unique key that makes retries of this request safe"
+// @Produce      application/json
+// @Tags         account
+// @Success      202 {object} entity.MsgResponse "Message indicating the request was accepted"
+// @Failure      409 {object} apperror.Problem "Problem detail indicating the idempotency key is in use"
+// @Failure      422 {object} apperror.Problem "Problem detail with field-level validation errors or a reused idempotency key"
+// @Router       /password-reset/request [post]
+func (a AccountHandlerImpl) RequestPasswordReset(c *gin.Context) {
+	var request entity.PasswordResetRequest
+	if !bindJSON(c, a.AppMetricsExporter, &request) {
+		return
+	}
+
+	var user entity.User
+	err := a.DB.WithContext(c.Request.Context()).Where("email = ?", request.Email).First(&user).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		logging.Logger(logging.ModuleHandler).DebugContext(c.Request.Context(), "password reset for unknown email")
+	case err != nil:
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to look up user"))
+		return
+	default:
+		tok, err := a.Tokens.Issue(token.PurposePasswordReset, user.Username, a.Options.ResetTokenTTL)
+		if err != nil {
+			_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to issue reset token"))
+			return
+		}
+		a.sendAsync(mail.TemplatePasswordReset, request.Email, mailData{
+			Username:  user.Username,
+			Token:     tok,
+			Link:      withToken(a.Options.ResetURL, tok),
+			ExpiresIn: a.Options.ResetTokenTTL.String(),
+		})
+		a.AppMetricsExporter.RecordBusinessEvent("password_reset_requested", user.Username)
+		recordAudit(c, a.Audit, "", "password_reset_request", user.Username, audit.OutcomeSuccess, "")
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "if the email is registered, a reset link has been sent"})
+}
+
+// ConfirmPasswordReset sets a new password using a reset token.
+// @Summary      Confirm Password Reset
+// @Description  Redeems a single-use reset token, replaces the user's password and revokes all of the user's sessions.
+// @Param        request body entity.PasswordResetConfirm true "Reset token and new password"
+// @Produce      application/json
+// @Tags         account
+// @Success      200 {object} entity.MsgResponse "Success message indicating the password was changed"
+// @Failure      400 {object} apperror.Problem "Problem detail indicating an invalid, expired or used token"
+// @Failure      422 {object} apperror.Problem "Problem detail with field-level validation errors"
+// @Router       /password-reset/confirm [post]
+func (a AccountHandlerImpl) ConfirmPasswordReset(c *gin.Context) {
+	var request entity.PasswordResetConfirm
+	if !bindJSON(c, a.AppMetricsExporter, &request) {
+		return
+	}
+	ctx := c.Request.Context()
+
+	var claims token.Claims
+	err := a.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if claims, err = a.Tokens.Redeem(ctx, tx, request.Token, token.PurposePasswordReset); err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.User{}).
+			Where("username = ?", claims.Subject).
+			Update("password", request.Password).Error; err != nil {
+			return err
+		}
+		return publishEvent(c, tx, "password_reset", claims.Subject)
+	})
+	if err != nil {
+		recordAudit(c, a.Audit, "", "password_reset", "", audit.OutcomeFailure, err.Error())
+		a.tokenError(c, err)
+		return
+	}
+
+	// Session yang dibuat dengan password lama (mungkin oleh orang yang mencurinya) dicabut
+	revoked, err := a.Sessions.RevokeAll(ctx, claims.Subject)
+	if err != nil {
+		recordAudit(c, a.Audit, claims.Subject, "password_reset", claims.Subject, audit.OutcomeFailure, "revoke sessions: "+err.Error())
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "password changed but existing sessions could not be revoked"))
+		return
+	}
+
+	a.AppMetricsExporter.RecordBusinessEvent("password_reset", claims.Subject)
+	recordAudit(c, a.Audit, claims.Subject, "password_reset", claims.Subject, audit.OutcomeSuccess, fmt.Sprintf("%d sessions revoked", revoked))
+	c.JSON(http.StatusOK, gin.H{"message": "password changed successfully"})
+}
+
+func (a AccountHandlerImpl) tokenError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, token.ErrExpired):
+		_ = c.Error(apperror.Wrap(apperror.CodeInvalidToken, err, "token has expired"))
+	case errors.Is(err, token.ErrConsumed):
+		_ = c.Error(apperror.Wrap(apperror.CodeInvalidToken, err, "token has already been used"))
+	case errors.Is(err, token.ErrInvalid):
+		_ = c.Error(apperror.Wrap(apperror.CodeInvalidToken, err, "token is invalid"))
+	default:
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to redeem token"))
+	}
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"wyw/alert"
+	"wyw/apperror"
+	"wyw/audit"
+	"wyw/metric"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AlertHandler interface {
+	ListAlerts(c *gin.Context)
+	ListRules(c *gin.Context)
+	CreateSilence(c *gin.Context)
+	ListSilences(c *gin.Context)
+	DeleteSilence(c *gin.Context)
+}
+
+type AlertHandlerImpl struct {
+	*metric.AppMetricsExporter
+	Engine *alert.Engine
+	Audit  *audit.Recorder
+}
+
+func NewAlertHandler(appMetricsExporter *metric.AppMetricsExporter, engine *alert.Engine, auditor *audit.Recorder) *AlertHandlerImpl {
+	return &AlertHandlerImpl{AppMetricsExporter: appMetricsExporter, Engine: engine, Audit: auditor}
+}
+
+// SilenceRequest is the payload for silencing alerts.
+type SilenceRequest struct {
+	// Matchers must all match an alert's labels for it to be silenced.
+	Matchers []alert.Matcher `json:"matchers" binding:"required,min=1,dive"`
+	// Duration is how long the silence lasts, as a Go duration. Ignored when ends_at is set.
+	Duration string `json:"duration" example:"2h"`
+	// EndsAt is when the silence ends.
+	EndsAt    *time.Time `json:"ends_at"`
+	CreatedBy string     `json:"created_by" binding:"required" example:"oncall"`
+	Comment   string     `json:"comment" example:"deploying a fix"`
+}
+
+// ListAlerts lists the pending and firing alerts.
+// @Summary      List Alerts
+// @Description  Returns the alerts of the embedded alert engine that are pending (condition met, waiting for the rule's for duration) or firing, with the silences and inhibitions currently muting their notifications.
+// @Produce      application/json
+// @Tags         alerts
+// @Security     ApiKeyAuth
+// @Success      200 {object} []alert.Alert "Active alerts, longest active first"
+// @Router       /admin/alerts [get]
+func (h AlertHandlerImpl) ListAlerts(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"data": h.Engine.Alerts(),
+	})
+}
+
+// ListRules lists the alert rules.
+// @Summary      List Alert Rules
+// @Description  Returns the rules evaluated by the embedded alert engine.
+// @Produce      application/json
+// @Tags         alerts
+// @Security     ApiKeyAuth
+// @Success      200 {object} []alert.Rule "Alert rules"
+// @Router       /admin/alerts/rules [get]
+func (h AlertHandlerImpl) ListRules(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"data": h.Engine.Rules(),
+	})
+}
+
+// CreateSilence silences alerts.
+// @Summary      Create Silence
+// @Description  Mutes notifications for alerts whose labels match every matcher until the silence ends. Silenced alerts are still evaluated and listed.
+// @Param        request body SilenceRequest true "Silence"
+// @Param        Idempotency-Key header string false "Unique key that makes retries of this request safe"
+// @Produce      application/json
+// @Tags         alerts
+// @Security     ApiKeyAuth
+// @Success      201 {object} alert.Silence "Active silence"
+// @Failure      409 {object} apperror.Problem "Problem detail indicating the idempotency key is in use"
+// @Failure      422 {object} apperror.Problem "Problem detail with field-level validation errors or a reused idempotency key"
+// @Router       /admin/alerts/silences [post]
+func (h AlertHandlerImpl) CreateSilence(c *gin.Context) {
+	var request SilenceRequest
+	if !bindJSON(c, h.AppMetricsExporter, &request) {
+		return
+	}
+
+	silence := alert.Silence{Matchers: request.Matchers, CreatedBy: request.CreatedBy, Comment: request.Comment}
+	switch {
+	case request.EndsAt != nil:
+		silence.EndsAt = request.EndsAt.UTC()
+	default:
+		d, err := time.ParseDuration(request.Duration)
+		if err != nil || d <= 0 {
+			h.AppMetricsExporter.RecordValidationFailure(c.FullPath(), "duration", "duration")
+			_ = c.Error(apperror.New(apperror.CodeValidation, "one or more fields are invalid").WithFields(apperror.FieldError{
+				Field: "duration", Rule: "duration", Message: "duration must be a positive duration such as 30m or 2h, or ends_at must be set",
+			}))
+			return
+		}
+		silence.EndsAt = time.Now().UTC().Add(d)
+	}
+
+	silence, err := h.Engine.AddSilence(silence)
+	if err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeValidation, err, err.Error()))
+		return
+	}
+
+	matchers := make([]string, 0, len(silence.Matchers))
+	for _, m := range silence.Matchers {
+		op := "="
+		if m.IsRegex {
+			op = "=~"
+		}
+		matchers = append(matchers, fmt.Sprintf("%s%s%q", m.Name, op, m.Value))
+	}
+	recordAudit(c, h.Audit, "admin", "alert_silence_create", silence.ID, audit.OutcomeSuccess,
+		fmt.Sprintf("{%s} until %s by %s", strings.Join(matchers, ","), silence.EndsAt.Format(time.RFC3339), silence.CreatedBy))
+	c.JSON(http.StatusCreated, silence)
+}
+
+// ListSilences lists the silences.
+// @Summary      List Silences
+// @Description  Returns every silence that has not ended yet.
+// @Produce      application/json
+// @Tags         alerts
+// @Security     ApiKeyAuth
+// @Success      200 {object} []alert.Silence "Silences, soonest end first"
+// @Router       /admin/alerts/silences [get]
+func (h AlertHandlerImpl) ListSilences(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"data": h.Engine.Silences(),
+	})
+}
+
+// DeleteSilence ends a silence.
+// @Summary      Expire Silence
+// @Description  Ends a silence before its end time, notifications for the alerts it muted resume on the next evaluation.
+// @Param        id path string true "Silence ID"
+// @Produce      application/json
+// @Tags         alerts
+// @Security     ApiKeyAuth
+// @Success      200 {object} entity.MsgResponse "Success message"
+// @Failure      404 {object} apperror.Problem "Problem detail indicating the silence does not exist or already ended"
+// @Router       /admin/alerts/silences/{id} [delete]
+func (h AlertHandlerImpl) DeleteSilence(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.Engine.RemoveSilence(id); err != nil {
+		if errors.Is(err, alert.ErrSilenceNotFound) {
+			_ = c.Error(apperror.New(apperror.CodeNotFound, "silence not found"))
+			return
+		}
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to expire silence"))
+		return
+	}
+
+	recordAudit(c, h.Audit, "admin", "alert_silence_delete", id, audit.OutcomeSuccess, "")
+	c.JSON(http.StatusOK, gin.H{"message": "silence expired"})
+}
@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"wyw/apperror"
+	"wyw/audit"
+	"wyw/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxAuditPageSize membatasi jumlah event per halaman query audit
+const maxAuditPageSize = 1000
+
+type AuditHandler interface {
+	Query(c *gin.Context)
+	Export(c *gin.Context)
+}
+
+type AuditHandlerImpl struct {
+	Recorder *audit.Recorder
+}
+
+func NewAuditHandler(recorder *audit.Recorder) *AuditHandlerImpl {
+	return &AuditHandlerImpl{Recorder: recorder}
+}
+
+// recordAudit mencatat aksi yang relevan untuk keamanan. Actor kosong diambil dari user
+// yang sedang login. Kegagalan menulis audit log hanya dicatat di log supaya tidak
+// menggagalkan request user.
+func recordAudit(c *gin.Context, rec *audit.Recorder, actor, action, target, outcome, detail string) {
+	if rec == nil {
+		return
+	}
+	if actor == "" {
+		actor = c.GetString(logging.UserKey)
+	}
+	if actor == "" {
+		actor = "anonymous"
+	}
+	err := rec.Record(c.Request.Context(), audit.Event{
+		Actor:     actor,
+		Action:    action,
+		Target:    target,
+		IP:        c.ClientIP(),
+		RequestID: logging.RequestIDFromContext(c.Request.Context()),
+		Outcome:   outcome,
+		Detail:    detail,
+	})
+	if err != nil {
+		logging.Logger(logging.ModuleHandler).ErrorContext(c.Request.Context(), "failed to write audit event",
+			slog.String("action", action), slog.String("actor", actor), slog.Any("error", err))
+	}
+}
+
+// auditFilter membaca filter dari query string
+func auditFilter(c *gin.Context) (audit.Filter, bool) {
+	f := audit.Filter{
+		Actor:   c.Query("actor"),
+		Action:  c.Query("action"),
+		Target:  c.Query("target"),
+		Outcome: c.Query("outcome"),
+		Limit:   100,
+	}
+	for name, dst := range map[string]*time.Time{"since": &f.Since, "until": &f.Until} {
+		if v := c.Query(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				_ = c.Error(apperror.New(apperror.CodeValidation, name+" must be an RFC 3339 timestamp").
+					WithFields(apperror.FieldError{Field: name, Rule: "datetime", Message: name + " must be an RFC 3339 timestamp"}))
+				return f, false
+			}
+			*dst = t
+		}
+	}
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxAuditPageSize {
+			_ = c.Error(apperror.New(apperror.CodeValidation, "limit must be between 1 and 1000").
+				WithFields(apperror.FieldError{Field: "limit", Rule: "range", Message: "limit must be between 1 and 1000"}))
+			return f, false
+		}
+		f.Limit = n
+	}
+	if v := c.Query("cursor"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			_ = c.Error(apperror.New(apperror.CodeValidation, "cursor is invalid").
+				WithFields(apperror.FieldError{Field: "cursor", Rule: "numeric", Message: "cursor is invalid"}))
+			return f, false
+		}
+		f.BeforeID = n
+	}
+	return f, true
+}
+
+// Query lists audit events.
+// @Summary      Query Audit Log
+// @Description  Returns audit events newest first. Use next_cursor as the cursor parameter to fetch the next page.
+// @Param        actor   query string false "Who performed the action"
+// @Param        action  query string false "Action, e.g. login, register, password_reset"
+// @Param        target  query string false "Affected user or resource"
+// @Param        outcome query string false "success, failure or denied"
+// @Param        since   query string false "RFC 3339 start time (inclusive)"
+// @Param        until   query string false "RFC 3339 end time (exclusive)"
+// @Param        limit   query int    false "Page size, 1-1000 (default 100)"
+// @Param        cursor  query string false "Cursor from the previous page"
+// @Produce      application/json
+// @Tags         admin
+// @Security     ApiKeyAuth
+// @Success      200 {object} []audit.Event "Audit events and next_cursor"
+// @Failure      422 {object} apperror.Problem "Problem detail indicating an invalid filter"
+// @Router       /admin/audit [get]
+func (h AuditHandlerImpl) Query(c *gin.Context) {
+	f, ok := auditFilter(c)
+	if !ok {
+		return
+	}
+
+	events, err := h.Recorder.Query(c.Request.Context(), f)
+	if err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to query audit log"))
+		return
+	}
+
+	resp := gin.H{"data": events}
+	if len(events) == f.Limit {
+		resp["next_cursor"] = strconv.FormatUint(events[len(events)-1].ID, 10)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Export streams audit events as NDJSON.
+// @Summary      Export Audit Log
+// @Description  Streams every matching audit event oldest first as newline-delimited JSON, including the hash chain fields.
+// @Param        actor   query string false "Who performed the action"
+// @Param        action  query string false "Action, e.g. login, register, password_reset"
+// @Param        target  query string false "Affected user or resource"
+// @Param        outcome query string false "success, failure or denied"
+// @Param        since   query string false "RFC 3339 start time (inclusive)"
+// @Param        until   query string false "RFC 3339 end time (exclusive)"
+// @Produce      application/x-ndjson
+// @Tags         admin
+// @Security     ApiKeyAuth
+// @Success      200 {object} audit.Event "One audit event per line"
+// @Failure      422 {object} apperror.Problem "Problem detail indicating an invalid filter"
+// @Router       /admin/audit/export [get]
+func (h AuditHandlerImpl) Export(c *gin.Context) {
+	f, ok := auditFilter(c)
+	if !ok {
+		return
+	}
+
+	// Export audit log besar bisa melewati WriteTimeout server, jadi deadline-nya dilepas
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="audit.ndjson"`)
+	c.Status(http.StatusOK)
+
+	enc := json.NewEncoder(c.Writer)
+	written := 0
+	err := h.Recorder.Export(c.Request.Context(), f, func(e audit.Event) error {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+		if written++; written%100 == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	c.Writer.Flush()
+	if err != nil {
+		// Header sudah terkirim, jadi error hanya bisa dicatat di log
+		logging.Logger(logging.ModuleHandler).ErrorContext(c.Request.Context(), "audit export aborted", slog.Any("error", err))
+	}
+}
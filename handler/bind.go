@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"wyw/apperror"
+	"wyw/metric"
+	"wyw/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bindJSON mem-bind body JSON ke request dan mendaftarkan error yang sesuai ke c.Errors.
+// Error validasi dicatat per field di metrik, JSON rusak dilaporkan sebagai invalid_json.
+func bindJSON(c *gin.Context, metrics *metric.AppMetricsExporter, request any) bool {
+	err := c.ShouldBindJSON(request)
+	if err == nil {
+		return true
+	}
+
+	fields, ok := validation.FieldErrors(err, c.GetHeader("Accept-Language"))
+	if !ok {
+		_ = c.Error(apperror.Wrap(apperror.CodeInvalidJSON, err, "request body must be a valid JSON object"))
+		return false
+	}
+
+	for _, field := range fields {
+		metrics.RecordValidationFailure(c.FullPath(), field.Field, field.Rule)
+	}
+	_ = c.Error(apperror.Wrap(apperror.CodeValidation, err, "one or more fields are invalid").WithFields(fields...))
+	return false
+}
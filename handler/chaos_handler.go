@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+	"wyw/apperror"
+	"wyw/audit"
+	"wyw/chaos"
+	"wyw/metric"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ChaosHandler interface {
+	CreateFault(c *gin.Context)
+	ListFaults(c *gin.Context)
+	DeleteFault(c *gin.Context)
+	ClearFaults(c *gin.Context)
+}
+
+type ChaosHandlerImpl struct {
+	*metric.AppMetricsExporter
+	Injector *chaos.Injector
+	Audit    *audit.Recorder
+}
+
+func NewChaosHandler(appMetricsExporter *metric.AppMetricsExporter, injector *chaos.Injector, auditor *audit.Recorder) *ChaosHandlerImpl {
+	return &ChaosHandlerImpl{AppMetricsExporter: appMetricsExporter, Injector: injector, Audit: auditor}
+}
+
+// ChaosFaultRequest is the payload for injecting a fault.
+type ChaosFaultRequest struct {
+	Type  string      `json:"type" binding:"required,oneof=latency error abort db_error db_delay" example:"latency"`
+	Scope chaos.Scope `json:"scope"`
+	// Latency distribution for latency and db_delay faults.
+	Latency *chaos.Latency `json:"latency"`
+	// Status returned by error faults.
+	Status int `json:"status" binding:"omitempty,min=400,max=599" example:"503"`
+	// TTL is how long the fault stays active, as a Go duration.
+	TTL string `json:"ttl" binding:"required" example:"5m"`
+}
+
+// CreateFault injects a fault.
+// @Summary      Inject Fault
+// @Description  Activates a fault until its TTL expires. latency delays matching requests, error answers them with the given status, abort closes the connection without a response, db_error fails and db_delay slows down their database queries. Faults are scoped by route (a trailing * matches a prefix), method, header, user (authenticated routes only) and percentage.
+// @Param        request body ChaosFaultRequest true "Fault"
+// @Param        Idempotency-Key header string false "Unique key that makes retries of this request safe"
+// @Produce      application/json
+// @Tags         chaos
+// @Security     ApiKeyAuth
+// @Success      201 {object} chaos.Fault "Active fault"
+// @Failure      409 {object} apperror.Problem "Problem detail indicating the idempotency key is in use"
+// @Failure      422 {object} apperror.Problem "Problem detail with field-level validation errors or a reused idempotency key"
+// @Router       /admin/chaos/faults [post]
+func (h ChaosHandlerImpl) CreateFault(c *gin.Context) {
+	var request ChaosFaultRequest
+	if !bindJSON(c, h.AppMetricsExporter, &request) {
+		return
+	}
+
+	ttl, err := time.ParseDuration(request.TTL)
+	if err != nil {
+		h.AppMetricsExporter.RecordValidationFailure(c.FullPath(), "ttl", "duration")
+		_ = c.Error(apperror.New(apperror.CodeValidation, "one or more fields are invalid").WithFields(apperror.FieldError{
+			Field: "ttl", Rule: "duration", Message: "ttl must be a duration such as 30s or 5m",
+		}))
+		return
+	}
+
+	fault, err := h.Injector.Add(chaos.Fault{
+		Type:    request.Type,
+		Scope:   request.Scope,
+		Latency: request.Latency,
+		Status:  request.Status,
+	}, ttl)
+	if err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeValidation, err, err.Error()))
+		return
+	}
+
+	recordAudit(c, h.Audit, "admin", "chaos_fault_create", fault.ID, audit.OutcomeSuccess,
+		fmt.Sprintf("%s on %q for %s", fault.Type, fault.Scope.Route, ttl))
+	c.JSON(http.StatusCreated, fault)
+}
+
+// ListFaults lists the active faults.
+// @Summary      List Faults
+// @Description  Returns every active fault with its expiry and the number of requests or queries it affected.
+// @Produce      application/json
+// @Tags         chaos
+// @Security     ApiKeyAuth
+// @Success      200 {object} []chaos.Fault "Active faults, soonest expiry first"
+// @Router       /admin/chaos/faults [get]
+func (h ChaosHandlerImpl) ListFaults(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"data": h.Injector.List(),
+	})
+}
+
+// DeleteFault removes one fault.
+// @Summary      Remove Fault
+// @Description  Deactivates a fault before its TTL expires.
+// @Param        id path string true "Fault ID"
+// @Produce      application/json
+// @Tags         chaos
+// @Security     ApiKeyAuth
+// @Success      200 {object} entity.MsgResponse "Success message"
+// @Failure      404 {object} apperror.Problem "Problem detail indicating the fault does not exist or already expired"
+// @Router       /admin/chaos/faults/{id} [delete]
+func (h ChaosHandlerImpl) DeleteFault(c *gin.Context) {
+	id := c.Param("id")
+	if !h.Injector.Remove(id) {
+		_ = c.Error(apperror.New(apperror.CodeNotFound, "fault not found"))
+		return
+	}
+
+	recordAudit(c, h.Audit, "admin", "chaos_fault_delete", id, audit.OutcomeSuccess, "")
+	c.JSON(http.StatusOK, gin.H{"message": "fault removed"})
+}
+
+// ClearFaults removes every fault.
+// @Summary      Remove All Faults
+// @Description  Deactivates every fault, ending all running chaos experiments.
+// @Produce      application/json
+// @Tags         chaos
+// @Security     ApiKeyAuth
+// @Success      200 {object} entity.MsgResponse "Success message with the number of removed faults"
+// @Router       /admin/chaos/faults [delete]
+func (h ChaosHandlerImpl) ClearFaults(c *gin.Context) {
+	n := h.Injector.Clear()
+
+	recordAudit(c, h.Audit, "admin", "chaos_fault_clear", "", audit.OutcomeSuccess, fmt.Sprintf("%d faults", n))
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("%d faults removed", n)})
+}
@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"wyw/apperror"
+	"wyw/live"
+	"wyw/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+type LiveHandler interface {
+	Events(c *gin.Context)
+	WebSocket(c *gin.Context)
+}
+
+type LiveHandlerImpl struct {
+	Hub *live.Hub
+	// Heartbeat adalah jeda komentar/ping kosong supaya proxy tidak menutup koneksi idle
+	Heartbeat time.Duration
+	upgrader  websocket.Upgrader
+}
+
+// NewLiveHandler membuat handler stream live. Koneksi WebSocket hanya diterima dari host
+// yang sama atau dari salah satu allowedOrigins.
+func NewLiveHandler(hub *live.Hub, heartbeat time.Duration, allowedOrigins []string) *LiveHandlerImpl {
+	return &LiveHandlerImpl{
+		Hub:       hub,
+		Heartbeat: heartbeat,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				if origin == "" || slices.Contains(allowedOrigins, "*") || slices.Contains(allowedOrigins, origin) {
+					return true
+				}
+				u, err := url.Parse(origin)
+				return err == nil && strings.EqualFold(u.Host, r.Host)
+			},
+		},
+	}
+}
+
+// Events streams live events over Server-Sent Events.
+// @Summary      Live Event Stream
+// @Description  Streams HTTP request observations, business events and rolling request statistics (rate, error ratio, p50/p90/p99 latency) as Server-Sent Events. Reconnecting clients send Last-Event-ID to replay missed events from the in-memory buffer. Clients that cannot keep up are disconnected with a "dropped" event.
+// @Param        types      query  string false "Comma separated event types to receive: http, business, stats"
+// @Param        event      query  string false "Comma separated business event types, e.g. register,login"
+// @Param        endpoint   query  string false "Only HTTP events whose route starts with this prefix"
+// @Param        min_status query  int    false "Only HTTP events with at least this status code"
+// @Param        Last-Event-ID header string false "Sequence number of the last event received"
+// @Produce      text/event-stream
+// @Tags         live
+// @Security     ApiKeyAuth
+// @Success      200 {object} live.Event "Stream of events"
+// @Failure      401 {object} apperror.Problem "Problem detail indicating a missing or invalid API key"
+// @Failure      422 {object} apperror.Problem "Problem detail indicating an invalid filter"
+// @Router       /live/events [get]
+func (h LiveHandlerImpl) Events(c *gin.Context) {
+	filter, after, ok := liveFilter(c)
+	if !ok {
+		return
+	}
+
+	// Stream berjalan lama, lepas write deadline server supaya koneksi tidak diputus
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		logging.Logger(logging.ModuleHandler).DebugContext(c.Request.Context(), "cannot clear write deadline", slog.Any("error", err))
+	}
+
+	sub := h.Hub.Subscribe(filter, after)
+	defer h.Hub.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.Heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+		case e, open := <-sub.C:
+			if !open {
+				if sub.Dropped() {
+					fmt.Fprint(c.Writer, "event: dropped\ndata: {\"reason\":\"client too slow\"}\n\n")
+					c.Writer.Flush()
+				}
+				return
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", e.Seq, e.Type, data); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+// WebSocket streams live events over a WebSocket connection.
+// @Summary      Live Event WebSocket
+// @Description  Same stream as /live/events delivered as JSON text messages over a WebSocket. Use the last_event_id query parameter to replay missed events. Slow clients are closed with status 1013 and all clients are closed with status 1001 when the server shuts down.
+// @Param        types         query string false "Comma separated event types to receive: http, business, stats"
+// @Param        event         query string false "Comma separated business event types, e.g. register,login"
+// @Param        endpoint      query string false "Only HTTP events whose route starts with this prefix"
+// @Param        min_status    query int    false "Only HTTP events with at least this status code"
+// @Param        last_event_id query int    false "Sequence number of the last event received"
+// @Tags         live
+// @Security     ApiKeyAuth
+// @Success      101 "Switching protocols"
+// @Failure      401 {object} apperror.Problem "Problem detail indicating a missing or invalid API key"
+// @Failure      422 {object} apperror.Problem "Problem detail indicating an invalid filter"
+// @Router       /live/ws [get]
+func (h LiveHandlerImpl) WebSocket(c *gin.Context) {
+	filter, after, ok := liveFilter(c)
+	if !ok {
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrader sudah menulis response error ke client
+		return
+	}
+	defer conn.Close()
+
+	sub := h.Hub.Subscribe(filter, after)
+	defer h.Hub.Unsubscribe(sub)
+
+	// Pesan dari client tidak dipakai, tapi harus dibaca supaya close dan pong diproses
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(h.Heartbeat)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case <-closed:
+			return
+		case <-heartbeat.C:
+			err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second))
+		case e, open := <-sub.C:
+			if !open {
+				msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+				if sub.Dropped() {
+					msg = websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "client too slow")
+				}
+				_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+				return
+			}
+			_ = conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			err = conn.WriteJSON(e)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// liveFilter membaca filter dan posisi replay dari query string dan header
+func liveFilter(c *gin.Context) (live.Filter, uint64, bool) {
+	filter := live.Filter{
+		Types:    splitList(c.Query("types")),
+		Events:   splitList(c.Query("event")),
+		Endpoint: c.Query("endpoint"),
+	}
+	for _, t := range filter.Types {
+		if t != live.TypeHTTP && t != live.TypeBusiness && t != live.TypeStats {
+			msg := fmt.Sprintf("unknown event type %q", t)
+			_ = c.Error(apperror.New(apperror.CodeValidation, msg).
+				WithFields(apperror.FieldError{Field: "types", Rule: "oneof", Message: msg}))
+			return filter, 0, false
+		}
+	}
+	if raw := c.Query("min_status"); raw != "" {
+		status, err := strconv.Atoi(raw)
+		if err != nil || status < 100 || status > 599 {
+			_ = c.Error(apperror.New(apperror.CodeValidation, "min_status must be an HTTP status code").
+				WithFields(apperror.FieldError{Field: "min_status", Rule: "range", Message: "min_status must be an HTTP status code"}))
+			return filter, 0, false
+		}
+		filter.MinStatus = status
+	}
+
+	lastID := c.GetHeader("Last-Event-ID")
+	if lastID == "" {
+		lastID = c.Query("last_event_id")
+	}
+	var after uint64
+	if lastID != "" {
+		var err error
+		if after, err = strconv.ParseUint(lastID, 10, 64); err != nil {
+			_ = c.Error(apperror.New(apperror.CodeValidation, "last event id must be a sequence number").
+				WithFields(apperror.FieldError{Field: "last_event_id", Rule: "numeric", Message: "last event id must be a sequence number"}))
+			return filter, 0, false
+		}
+	}
+	return filter, after, true
+}
+
+func splitList(raw string) []string {
+	var items []string
+	for item := range strings.SplitSeq(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
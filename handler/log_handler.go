@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+	"wyw/apperror"
+	"wyw/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LogHandler interface {
+	GetLevels(c *gin.Context)
+	SetLevel(c *gin.Context)
+	GetAccessLog(c *gin.Context)
+	SetAccessLog(c *gin.Context)
+}
+
+type LogHandlerImpl struct {
+	*logging.AccessLogger
+}
+
+func NewLogHandler(accessLogger *logging.AccessLogger) *LogHandlerImpl {
+	return &LogHandlerImpl{AccessLogger: accessLogger}
+}
+
+// SetLevelRequest is the payload for changing a module log level.
+type SetLevelRequest struct {
+	Level string `json:"level" example:"debug"`
+	// TTL is optional, e.g. "15m". When set the level reverts after it expires.
+	TTL string `json:"ttl" example:"15m"`
+}
+
+// AccessLogSettings describes the runtime access log configuration.
+type AccessLogSettings struct {
+	Format     string   `json:"format" example:"json"`
+	SampleRate *float64 `json:"sample_rate" example:"0.5"`
+}
+
+// GetLevels lists the log level of every module.
+// @Summary      Get Log Levels
+// @Description  Returns the current and base log level of every logger module.
+// @Produce      application/json
+// @Tags         admin
+// @Security     ApiKeyAuth
+// @Success      200 {object} []logging.LevelStatus "Log level of each module"
+// @Router       /admin/log-levels [get]
+func (h LogHandlerImpl) GetLevels(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": logging.Levels()})
+}
+
+// SetLevel changes the log level of a single module.
+// @Summary      Set Log Level
+// @Description  Changes the level of a logger module, optionally reverting after a TTL.
+// @Param        module path string true "Logger module (app, handler, metric, db, http)"
+// @Param        request body SetLevelRequest true "New level and optional TTL"
+// @Produce      application/json
+// @Tags         admin
+// @Security     ApiKeyAuth
+// @Success      200 {object} logging.LevelStatus "Updated level"
+// @Failure      400 {object} apperror.Problem "Problem detail indicating invalid JSON format"
+// @Failure      422 {object} apperror.Problem "Problem detail indicating invalid level, ttl or module"
+// @Router       /admin/log-levels/{module} [put]
+func (h LogHandlerImpl) SetLevel(c *gin.Context) {
+	var request SetLevelRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInvalidJSON, err, "request body must be a valid JSON object"))
+		return
+	}
+
+	level, err := logging.ParseLevel(request.Level)
+	if err != nil {
+		_ = c.Error(apperror.New(apperror.CodeValidation, err.Error()))
+		return
+	}
+
+	var ttl time.Duration
+	if request.TTL != "" {
+		if ttl, err = time.ParseDuration(request.TTL); err != nil || ttl < 0 {
+			_ = c.Error(apperror.New(apperror.CodeValidation, "ttl must be a positive duration such as 15m"))
+			return
+		}
+	}
+
+	status, err := logging.SetLevel(c.Param("module"), level, ttl)
+	if err != nil {
+		_ = c.Error(apperror.New(apperror.CodeValidation, err.Error()))
+		return
+	}
+
+	logging.Logger(logging.ModuleApp).Info("log level changed",
+		"module", status.Module, "level", status.Level, "ttl", ttl.String())
+	c.JSON(http.StatusOK, status)
+}
+
+// GetAccessLog returns the access log settings.
+// @Summary      Get Access Log Settings
+// @Description  Returns the active access log format and sample rate.
+// @Produce      application/json
+// @Tags         admin
+// @Security     ApiKeyAuth
+// @Success      200 {object} AccessLogSettings "Access log settings"
+// @Router       /admin/access-log [get]
+func (h LogHandlerImpl) GetAccessLog(c *gin.Context) {
+	rate := h.AccessLogger.SampleRate()
+	c.JSON(http.StatusOK, AccessLogSettings{Format: h.AccessLogger.Format(), SampleRate: &rate})
+}
+
+// SetAccessLog changes the access log format and/or sample rate.
+// @Summary      Set Access Log Settings
+// @Description  Switches the access log between json and logfmt and changes the sample rate.
+// @Param        request body AccessLogSettings true "Fields to change"
+// @Produce      application/json
+// @Tags         admin
+// @Security     ApiKeyAuth
+// @Success      200 {object} AccessLogSettings "Updated settings"
+// @Failure      400 {object} apperror.Problem "Problem detail indicating invalid JSON format"
+// @Failure      422 {object} apperror.Problem "Problem detail indicating invalid format or sample rate"
+// @Router       /admin/access-log [put]
+func (h LogHandlerImpl) SetAccessLog(c *gin.Context) {
+	var request AccessLogSettings
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInvalidJSON, err, "request body must be a valid JSON object"))
+		return
+	}
+
+	if request.SampleRate != nil && (*request.SampleRate < 0 || *request.SampleRate > 1) {
+		_ = c.Error(apperror.New(apperror.CodeValidation, "sample_rate must be between 0 and 1"))
+		return
+	}
+	if request.Format != "" {
+		if err := h.AccessLogger.SetFormat(request.Format); err != nil {
+			_ = c.Error(apperror.New(apperror.CodeValidation, err.Error()))
+			return
+		}
+	}
+	if request.SampleRate != nil {
+		h.AccessLogger.SetSampleRate(*request.SampleRate)
+	}
+
+	h.GetAccessLog(c)
+}
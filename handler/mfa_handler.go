@@ -0,0 +1,425 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+	"wyw/apperror"
+	"wyw/audit"
+	"wyw/entity"
+	"wyw/logging"
+	"wyw/metric"
+	"wyw/mfa"
+	"wyw/middleware"
+	"wyw/session"
+	"wyw/token"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxChallengeAttempts membatasi tebakan kode per challenge token
+const maxChallengeAttempts = 5
+
+// recoveryCodeCount adalah jumlah kode pemulihan yang dibuat saat enrollment
+const recoveryCodeCount = 10
+
+type MFAHandler interface {
+	EnrollTOTP(c *gin.Context)
+	TOTPQRCode(c *gin.Context)
+	ConfirmTOTP(c *gin.Context)
+	DisableTOTP(c *gin.Context)
+	LoginMFA(c *gin.Context)
+}
+
+type MFAHandlerImpl struct {
+	*gorm.DB
+	*metric.AppMetricsExporter
+	Tokens   *token.Manager
+	Sessions *session.Manager
+	Audit    *audit.Recorder
+	Issuer   string
+}
+
+func NewMFAHandler(DB *gorm.DB, appMetricsExporter *metric.AppMetricsExporter, tokens *token.Manager, sessions *session.Manager, auditor *audit.Recorder, issuer string) *MFAHandlerImpl {
+	return &MFAHandlerImpl{
+		DB:                 DB,
+		AppMetricsExporter: appMetricsExporter,
+		Tokens:             tokens,
+		Sessions:           sessions,
+		Audit:              auditor,
+		Issuer:             issuer,
+	}
+}
+
+// EnrollTOTP starts TOTP enrollment for the logged in user.
+// @Summary      Enroll TOTP
+// @Description  Generates a new TOTP secret, otpauth:// URI and QR code. Two-factor login is only enabled after the first code is confirmed.
+// @Produce      application/json
+// @Tags         mfa
+// @Security     BearerAuth
+// @Success      200 {object} entity.TOTPEnrollResponse "Secret, otpauth URI and base64 PNG QR code"
+// @Failure      401 {object} apperror.Problem "Problem detail indicating a missing or invalid session"
+// @Failure      409 {object} apperror.Problem "Problem detail indicating two-factor authentication is already enabled"
+// @Router       /me/mfa/totp/enroll [post]
+func (h *MFAHandlerImpl) EnrollTOTP(c *gin.Context) {
+	username := middleware.CurrentSession(c).Username
+	ctx := c.Request.Context()
+
+	var existing entity.UserMFA
+	err := h.DB.WithContext(ctx).Where("username = ?", username).First(&existing).Error
+	if err == nil && existing.Enabled {
+		_ = c.Error(apperror.New(apperror.CodeMFAAlreadyEnabled, "disable two-factor authentication before enrolling again"))
+		return
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to load mfa settings"))
+		return
+	}
+
+	enrollment, err := mfa.Generate(h.Issuer, username, 256)
+	if err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to generate totp secret"))
+		return
+	}
+	if err := h.DB.WithContext(ctx).Save(&entity.UserMFA{
+		Username:  username,
+		Secret:    enrollment.Secret,
+		CreatedAt: time.Now(),
+	}).Error; err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to store totp secret"))
+		return
+	}
+
+	c.JSON(http.StatusOK, entity.TOTPEnrollResponse{
+		Secret:     enrollment.Secret,
+		OTPAuthURL: enrollment.URL,
+		QRCodePNG:  enrollment.QRCode,
+	})
+}
+
+// TOTPQRCode renders the pending enrollment as a QR code image.
+// @Summary      TOTP QR Code
+// @Description  Returns the otpauth:// URI of the pending or active enrollment as a PNG QR code.
+// @Produce      image/png
+// @Tags         mfa
+// @Security     BearerAuth
+// @Success      200 {file} binary "QR code PNG"
+// @Failure      401 {object} apperror.Problem "Problem detail indicating a missing or invalid session"
+// @Failure      409 {object} apperror.Problem "Problem detail indicating no enrollment was started"
+// @Router       /me/mfa/totp/qr.png [get]
+func (h *MFAHandlerImpl) TOTPQRCode(c *gin.Context) {
+	settings, ok := h.loadSettings(c)
+	if !ok {
+		return
+	}
+	png, err := mfa.QRCode(mfa.KeyURL(h.Issuer, settings.Username, settings.Secret), 256)
+	if err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to render qr code"))
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+// ConfirmTOTP enables TOTP after verifying the first code.
+// @Summary      Confirm TOTP
+// @Description  Verifies the first code from the authenticator app, enables two-factor login and returns one-time recovery codes.
+// @Param        request body entity.MFACodeRequest true "Current TOTP code"
+// @Produce      application/json
+// @Tags         mfa
+// @Security     BearerAuth
+// @Success      200 {object} entity.RecoveryCodesResponse "Recovery codes, shown only once"
+// @Failure      401 {object} apperror.Problem "Problem detail indicating an invalid session or code"
+// @Failure      409 {object} apperror.Problem "Problem detail indicating no enrollment was started or it is already enabled"
+// @Router       /me/mfa/totp/confirm [post]
+func (h *MFAHandlerImpl) ConfirmTOTP(c *gin.Context) {
+	var request entity.MFACodeRequest
+	if !bindJSON(c, h.AppMetricsExporter, &request) {
+		return
+	}
+	settings, ok := h.loadSettings(c)
+	if !ok {
+		return
+	}
+	if settings.Enabled {
+		_ = c.Error(apperror.New(apperror.CodeMFAAlreadyEnabled, "two-factor authentication is already enabled"))
+		return
+	}
+
+	step, valid := mfa.Validate(settings.Secret, request.Code, settings.LastStep, time.Now())
+	if !valid {
+		h.AppMetricsExporter.RecordBusinessEvent("mfa_enroll_failure", settings.Username)
+		recordAudit(c, h.Audit, "", "mfa_enable", settings.Username, audit.OutcomeFailure, "invalid code")
+		_ = c.Error(apperror.New(apperror.CodeInvalidMFACode, "the code does not match, check the time on your device"))
+		return
+	}
+
+	codes := mfa.GenerateRecoveryCodes(recoveryCodeCount)
+	err := h.DB.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&settings).Updates(map[string]any{
+			"enabled":      true,
+			"last_step":    step,
+			"confirmed_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(tx, settings.Username, codes)
+	})
+	if err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to enable two-factor authentication"))
+		return
+	}
+
+	h.AppMetricsExporter.RecordBusinessEvent("mfa_enrolled", settings.Username)
+	recordAudit(c, h.Audit, "", "mfa_enable", settings.Username, audit.OutcomeSuccess, "")
+	c.JSON(http.StatusOK, entity.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableTOTP turns off two-factor login.
+// @Summary      Disable TOTP
+// @Description  Disables two-factor login after verifying a current TOTP code or a recovery code.
+// @Param        request body entity.MFACodeRequest true "Current TOTP code or recovery code"
+// @Produce      application/json
+// @Tags         mfa
+// @Security     BearerAuth
+// @Success      200 {object} entity.MsgResponse "Success message"
+// @Failure      401 {object} apperror.Problem "Problem detail indicating an invalid session or code"
+// @Failure      409 {object} apperror.Problem "Problem detail indicating two-factor authentication is not enabled"
+// @Router       /me/mfa/totp [delete]
+func (h *MFAHandlerImpl) DisableTOTP(c *gin.Context) {
+	var request entity.MFACodeRequest
+	if !bindJSON(c, h.AppMetricsExporter, &request) {
+		return
+	}
+	settings, ok := h.loadSettings(c)
+	if !ok {
+		return
+	}
+	if !settings.Enabled {
+		_ = c.Error(apperror.New(apperror.CodeMFANotEnrolled, "two-factor authentication is not enabled"))
+		return
+	}
+
+	valid, err := h.verifyCode(c, settings, request.Code)
+	if err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to verify code"))
+		return
+	}
+	if !valid {
+		recordAudit(c, h.Audit, "", "mfa_disable", settings.Username, audit.OutcomeFailure, "invalid code")
+		_ = c.Error(apperror.New(apperror.CodeInvalidMFACode, "the code is not valid"))
+		return
+	}
+
+	err = h.DB.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("username = ?", settings.Username).Delete(&entity.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&settings).Error
+	})
+	if err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to disable two-factor authentication"))
+		return
+	}
+
+	h.AppMetricsExporter.RecordBusinessEvent("mfa_disabled", settings.Username)
+	recordAudit(c, h.Audit, "", "mfa_disable", settings.Username, audit.OutcomeSuccess, "")
+	c.JSON(http.StatusOK, gin.H{"message": "two-factor authentication disabled"})
+}
+
+// LoginMFA exchanges a login challenge and a code for a session.
+// @Summary      Complete MFA Login
+// @Description  Exchanges the challenge token returned by /login together with a TOTP or recovery code for a session token.
+// @Param        request body entity.MFALoginRequest true "Challenge token and code"
+// @Produce      application/json
+// @Tags         mfa
+// @Success      200 {object} entity.LoginResponse "Session token"
+// @Failure      400 {object} apperror.Problem "Problem detail indicating an invalid or expired challenge"
+// @Failure      401 {object} apperror.Problem "Problem detail indicating an invalid code"
+// @Failure      422 {object} apperror.Problem "Problem detail with field-level validation errors"
+// @Router       /login/mfa [post]
+func (h *MFAHandlerImpl) LoginMFA(c *gin.Context) {
+	var request entity.MFALoginRequest
+	if !bindJSON(c, h.AppMetricsExporter, &request) {
+		return
+	}
+	ctx := c.Request.Context()
+
+	claims, err := h.Tokens.Verify(request.ChallengeToken, token.PurposeMFAChallenge)
+	if err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInvalidToken, err, "challenge token is invalid or has expired"))
+		return
+	}
+
+	var settings entity.UserMFA
+	if err := h.DB.WithContext(ctx).Where("username = ? AND enabled = ?", claims.Subject, true).First(&settings).Error; err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInvalidToken, err, "challenge token is invalid or has expired"))
+		return
+	}
+
+	// Challenge yang sudah mencapai batas tidak boleh dipakai menebak lagi, termasuk lewat
+	// perbedaan pesan error antara kode salah dan challenge terpakai
+	var attempt entity.MFAChallengeAttempt
+	err = h.DB.WithContext(ctx).Where("challenge_id = ?", claims.ID).Limit(1).Find(&attempt).Error
+	if err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to load mfa attempts"))
+		return
+	}
+	if attempt.Failures >= maxChallengeAttempts {
+		_ = c.Error(apperror.New(apperror.CodeInvalidToken, "too many invalid codes, log in again"))
+		return
+	}
+
+	valid, err := h.verifyCode(c, settings, request.Code)
+	if err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to verify code"))
+		return
+	}
+	if !valid {
+		h.AppMetricsExporter.RecordBusinessEvent("mfa_failure", claims.Subject)
+		recordAudit(c, h.Audit, claims.Subject, "login_mfa", claims.Subject, audit.OutcomeFailure, "invalid code")
+		locked, err := h.recordFailure(c, claims)
+		if err != nil {
+			_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to record invalid code"))
+			return
+		}
+		if locked {
+			// Terlalu banyak tebakan, hanguskan challenge supaya user harus login ulang
+			_, _ = h.Tokens.Redeem(ctx, h.DB, request.ChallengeToken, token.PurposeMFAChallenge)
+			recordAudit(c, h.Audit, claims.Subject, "login_mfa", claims.Subject, audit.OutcomeDenied, "too many invalid codes")
+			if err := publishEvent(c, h.DB.WithContext(ctx), "locked", claims.Subject); err != nil {
+				logging.Logger(logging.ModuleHandler).ErrorContext(ctx, "failed to record locked event", slog.Any("error", err))
+			}
+			_ = c.Error(apperror.New(apperror.CodeInvalidToken, "too many invalid codes, log in again"))
+			return
+		}
+		_ = c.Error(apperror.New(apperror.CodeInvalidMFACode, "the code is not valid"))
+		return
+	}
+
+	if _, err := h.Tokens.Redeem(ctx, h.DB, request.ChallengeToken, token.PurposeMFAChallenge); err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInvalidToken, err, "challenge token has already been used"))
+		return
+	}
+	h.clearFailures(c, claims.ID)
+	if err := publishEvent(c, h.DB.WithContext(ctx), "login", claims.Subject); err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to record login event"))
+		return
+	}
+
+	h.AppMetricsExporter.RecordBusinessEvent("mfa_success", claims.Subject)
+	h.AppMetricsExporter.RecordBusinessEvent("login", claims.Subject)
+	recordAudit(c, h.Audit, claims.Subject, "login_mfa", claims.Subject, audit.OutcomeSuccess, "")
+	startSession(c, h.Sessions, claims.Subject)
+}
+
+// verifyCode memeriksa kode TOTP atau kode pemulihan dan menandainya terpakai
+func (h *MFAHandlerImpl) verifyCode(c *gin.Context, settings entity.UserMFA, code string) (bool, error) {
+	ctx := c.Request.Context()
+
+	if mfa.IsRecoveryCode(code) {
+		res := h.DB.WithContext(ctx).Model(&entity.RecoveryCode{}).
+			Where("username = ? AND code_hash = ? AND used_at IS NULL", settings.Username, mfa.HashRecoveryCode(code)).
+			Update("used_at", time.Now())
+		if res.Error != nil {
+			return false, res.Error
+		}
+		if res.RowsAffected == 1 {
+			h.AppMetricsExporter.RecordBusinessEvent("mfa_recovery_code_used", settings.Username)
+			return true, nil
+		}
+		return false, nil
+	}
+
+	step, valid := mfa.Validate(settings.Secret, code, settings.LastStep, time.Now())
+	if !valid {
+		return false, nil
+	}
+	// Update bersyarat supaya dua request paralel dengan kode yang sama tidak sama-sama lolos
+	res := h.DB.WithContext(ctx).Model(&entity.UserMFA{}).
+		Where("username = ? AND last_step < ?", settings.Username, step).
+		Update("last_step", step)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (h *MFAHandlerImpl) loadSettings(c *gin.Context) (entity.UserMFA, bool) {
+	var settings entity.UserMFA
+	err := h.DB.WithContext(c.Request.Context()).
+		Where("username = ?", middleware.CurrentSession(c).Username).
+		First(&settings).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		_ = c.Error(apperror.New(apperror.CodeMFANotEnrolled, "start enrollment first"))
+		return settings, false
+	}
+	if err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to load mfa settings"))
+		return settings, false
+	}
+	return settings, true
+}
+
+// recordFailure menambah hitungan kode salah untuk challenge secara atomik di database dan
+// mengembalikan true bila batas percobaan sudah tercapai
+func (h *MFAHandlerImpl) recordFailure(c *gin.Context, claims token.Claims) (bool, error) {
+	db := h.DB.WithContext(c.Request.Context())
+	if err := db.Where("expires_at < ?", time.Now()).Delete(&entity.MFAChallengeAttempt{}).Error; err != nil {
+		return false, err
+	}
+
+	err := db.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{"failures": gorm.Expr("failures + 1")}),
+	}).Create(&entity.MFAChallengeAttempt{
+		ChallengeID: claims.ID,
+		Username:    claims.Subject,
+		Failures:    1,
+		ExpiresAt:   claims.ExpiresAt,
+	}).Error
+	if err != nil {
+		return false, err
+	}
+
+	var attempt entity.MFAChallengeAttempt
+	if err := db.Where("challenge_id = ?", claims.ID).First(&attempt).Error; err != nil {
+		return false, err
+	}
+	return attempt.Failures >= maxChallengeAttempts, nil
+}
+
+func (h *MFAHandlerImpl) clearFailures(c *gin.Context, id string) {
+	err := h.DB.WithContext(c.Request.Context()).Where("challenge_id = ?", id).Delete(&entity.MFAChallengeAttempt{}).Error
+	if err != nil {
+		logging.Logger(logging.ModuleHandler).WarnContext(c.Request.Context(), "failed to clear mfa attempts", slog.Any("error", err))
+	}
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, username string, codes []string) error {
+	if err := tx.Where("username = ?", username).Delete(&entity.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	rows := make([]entity.RecoveryCode, len(codes))
+	for i, code := range codes {
+		rows[i] = entity.RecoveryCode{Username: username, CodeHash: mfa.HashRecoveryCode(code)}
+	}
+	return tx.Create(&rows).Error
+}
+
+// startSession membuat session baru untuk username dan menulis LoginResponse
+func startSession(c *gin.Context, sessions *session.Manager, username string) {
+	tok, sess, err := sessions.Create(c.Request.Context(), username, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to create session"))
+		return
+	}
+	logging.Logger(logging.ModuleHandler).DebugContext(c.Request.Context(), "session created",
+		slog.String("username", username), slog.String("session_id", sess.ID))
+
+	c.JSON(http.StatusOK, entity.LoginResponse{
+		Message:   username + " login successfully",
+		Token:     tok,
+		ExpiresAt: &sess.ExpiresAt,
+	})
+}
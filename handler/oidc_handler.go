@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"wyw/apperror"
+	"wyw/audit"
+	"wyw/entity"
+	"wyw/logging"
+	"wyw/metric"
+	"wyw/session"
+	"wyw/sso"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// usernameUnsafe membuang karakter yang tidak lolos aturan validator "username"
+var usernameUnsafe = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+type OIDCHandler interface {
+	Login(c *gin.Context)
+	Callback(c *gin.Context)
+}
+
+type OIDCHandlerImpl struct {
+	*gorm.DB
+	*metric.AppMetricsExporter
+	RelyingParty *sso.RelyingParty
+	Sessions     *session.Manager
+	Audit        *audit.Recorder
+	// AutoProvision membuat akun baru untuk identitas yang belum terhubung ke akun lokal
+	AutoProvision bool
+}
+
+func NewOIDCHandler(DB *gorm.DB, appMetricsExporter *metric.AppMetricsExporter, rp *sso.RelyingParty, sessions *session.Manager, auditor *audit.Recorder, autoProvision bool) *OIDCHandlerImpl {
+	return &OIDCHandlerImpl{
+		DB:                 DB,
+		AppMetricsExporter: appMetricsExporter,
+		RelyingParty:       rp,
+		Sessions:           sessions,
+		Audit:              auditor,
+		AutoProvision:      autoProvision,
+	}
+}
+
+// Login redirects the browser to the identity provider.
+// @Summary      Start OIDC Login
+// @Description  Redirects to the identity provider's authorization endpoint using the authorization code flow with PKCE.
+// @Tags         sso
+// @Success      302 "Redirect to the identity provider"
+// @Failure      502 {object} apperror.Problem "Problem detail indicating the identity provider could not be reached"
+// @Router       /auth/oidc/login [get]
+func (h OIDCHandlerImpl) Login(c *gin.Context) {
+	url, cookie, err := h.RelyingParty.AuthCodeURL(c.Request.Context())
+	if err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeSSOUnavailable, err, "identity provider discovery failed"))
+		return
+	}
+	http.SetCookie(c.Writer, cookie)
+	c.Redirect(http.StatusFound, url)
+}
+
+// Callback completes the OIDC login.
+// @Summary      OIDC Callback
+// @Description  Exchanges the authorization code, validates the ID token and returns a session token. The identity is linked to an existing account by verified email, or a new account is provisioned when enabled.
+// @Param        code  query string true  "Authorization code"
+// @Param        state query string true  "State returned by the identity provider, must match the state cookie set by the login endpoint"
+// @Produce      application/json
+// @Tags         sso
+// @Success      200 {object} entity.LoginResponse "Session token"
+// @Failure      401 {object} apperror.Problem "Problem detail indicating the login was rejected or the ID token is invalid"
+// @Failure      403 {object} apperror.Problem "Problem detail indicating no account is linked to the identity"
+// @Failure      502 {object} apperror.Problem "Problem detail indicating the identity provider could not be reached"
+// @Router       /auth/oidc/callback [get]
+func (h OIDCHandlerImpl) Callback(c *gin.Context) {
+	ctx := c.Request.Context()
+	// Cookie state hanya berlaku untuk satu callback, berhasil maupun gagal
+	stateCookie, _ := c.Cookie(sso.StateCookie)
+	http.SetCookie(c.Writer, h.RelyingParty.ClearCookie())
+
+	if idpErr := c.Query("error"); idpErr != "" {
+		h.AppMetricsExporter.RecordBusinessEvent("oidc_login_failure", "anonymous")
+		recordAudit(c, h.Audit, "", "login_oidc", "", audit.OutcomeFailure, "idp error: "+idpErr)
+		_ = c.Error(apperror.New(apperror.CodeSSOFailed, fmt.Sprintf("identity provider returned %q", idpErr)))
+		return
+	}
+
+	identity, err := h.RelyingParty.Exchange(ctx, c.Query("code"), c.Query("state"), stateCookie)
+	if err != nil {
+		logging.Logger(logging.ModuleHandler).WarnContext(ctx, "oidc callback rejected", slog.Any("error", err))
+		h.AppMetricsExporter.RecordBusinessEvent("oidc_login_failure", "anonymous")
+		recordAudit(c, h.Audit, "", "login_oidc", "", audit.OutcomeFailure, err.Error())
+		_ = c.Error(apperror.Wrap(apperror.CodeSSOFailed, err, "the sign-in could not be completed, start again"))
+		return
+	}
+
+	username, err := h.resolveUser(c, identity)
+	if err != nil {
+		var appErr *apperror.Error
+		if errors.As(err, &appErr) {
+			recordAudit(c, h.Audit, identity.Subject, "login_oidc", identity.Email, audit.OutcomeDenied, string(appErr.Code))
+			_ = c.Error(appErr)
+			return
+		}
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to resolve account"))
+		return
+	}
+
+	//RECORD METRICS
+	h.AppMetricsExporter.RecordBusinessEvent("oidc_login", username)
+	recordAudit(c, h.Audit, username, "login_oidc", username, audit.OutcomeSuccess, identity.Issuer)
+
+	startSession(c, h.Sessions, username)
+}
+
+// resolveUser mencari akun untuk identitas OIDC dengan urutan: identitas yang sudah
+// terhubung, akun dengan email terverifikasi yang sama, lalu auto-provision.
+// Role akun selalu diperbarui dari grup IdP terbaru.
+func (h OIDCHandlerImpl) resolveUser(c *gin.Context, identity sso.Identity) (string, error) {
+	var username string
+	err := h.DB.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		var link entity.UserIdentity
+		err := tx.Where("issuer = ? AND subject = ?", identity.Issuer, identity.Subject).First(&link).Error
+		switch {
+		case err == nil:
+			username = link.Username
+			if err := tx.Model(&link).Update("last_login_at", time.Now()).Error; err != nil {
+				return err
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			var user entity.User
+			var provisioned bool
+			username, provisioned, err = h.linkOrProvision(tx, identity, &user)
+			if err != nil {
+				return err
+			}
+			if provisioned {
+				if err := publishEvent(c, tx, "register", username); err != nil {
+					return err
+				}
+			}
+			link = entity.UserIdentity{
+				Issuer:      identity.Issuer,
+				Subject:     identity.Subject,
+				Username:    username,
+				Email:       identity.Email,
+				LastLoginAt: time.Now(),
+			}
+			if err := tx.Create(&link).Error; err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		if identity.Role != "" {
+			if err := tx.Model(&entity.User{}).Where("username = ?", username).Update("role", identity.Role).Error; err != nil {
+				return err
+			}
+		}
+		return publishEvent(c, tx, "login", username)
+	})
+	return username, err
+}
+
+// linkOrProvision mengembalikan username akun yang ditautkan, dan true bila akunnya baru dibuat
+func (h OIDCHandlerImpl) linkOrProvision(tx *gorm.DB, identity sso.Identity, user *entity.User) (string, bool, error) {
+	// Akun hanya ditautkan bila email sudah diverifikasi di kedua sisi. Tanpa verifikasi IdP
+	// siapa pun bisa mengisi email orang lain di IdP; tanpa verifikasi lokal, orang yang
+	// mendaftarkan email korban lebih dulu akan ikut masuk ke akun itu lewat SSO.
+	if identity.Email != "" && identity.EmailVerified {
+		err := tx.Where("email = ?", identity.Email).First(user).Error
+		if err == nil {
+			if user.EmailVerifiedAt == nil {
+				h.AppMetricsExporter.RecordBusinessEvent("oidc_login_failure", "anonymous")
+				return "", false, apperror.New(apperror.CodeSSONotLinked, "verify the email of your existing account before signing in with SSO")
+			}
+			h.AppMetricsExporter.RecordBusinessEvent("oidc_link", user.Username)
+			return user.Username, false, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", false, err
+		}
+	}
+
+	if !h.AutoProvision {
+		h.AppMetricsExporter.RecordBusinessEvent("oidc_login_failure", "anonymous")
+		return "", false, apperror.New(apperror.CodeSSONotLinked, "ask an administrator to link your identity to an account")
+	}
+
+	username, err := uniqueUsername(tx, identity)
+	if err != nil {
+		return "", false, err
+	}
+	*user = entity.User{Username: username, Password: unusablePassword(), Role: identity.Role}
+	if identity.Email != "" && identity.EmailVerified {
+		now := time.Now()
+		user.Email = &identity.Email
+		user.EmailVerifiedAt = &now
+	}
+	if err := tx.Create(user).Error; err != nil {
+		return "", false, err
+	}
+	h.AppMetricsExporter.RecordBusinessEvent("oidc_provision", username)
+	return username, true, nil
+}
+
+// uniqueUsername menurunkan username dari preferred_username atau email, lalu menambahkan
+// angka di belakang bila sudah dipakai
+func uniqueUsername(tx *gorm.DB, identity sso.Identity) (string, error) {
+	base := identity.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(identity.Email, "@")
+	}
+	base = strings.Trim(usernameUnsafe.ReplaceAllString(base, ""), "._-")
+	if len(base) < 3 {
+		base = "user"
+	}
+	if len(base) > 24 {
+		base = base[:24]
+	}
+
+	candidate := base
+	for i := 2; i < 100; i++ {
+		var count int64
+		if err := tx.Model(&entity.User{}).Where("username = ?", candidate).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s%d", base, i)
+	}
+	return "", fmt.Errorf("no free username for %q", base)
+}
+
+// unusablePassword membuat password acak yang tidak pernah diberikan ke siapa pun,
+// sehingga akun hasil provisioning hanya bisa login lewat SSO sampai password di-reset
+func unusablePassword() string {
+	return "!sso:" + rand.Text()
+}
@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"time"
+	"wyw/entity"
+	"wyw/logging"
+	"wyw/outbox"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// publishEvent menulis event bisnis user ke outbox memakai tx, sehingga event ikut
+// commit atau rollback bersama perubahan data di transaksi yang sama
+func publishEvent(c *gin.Context, tx *gorm.DB, eventType, username string) error {
+	return outbox.Enqueue(tx, "user:"+username, eventType, entity.UserEvent{
+		Type:       eventType,
+		Username:   username,
+		RequestID:  logging.RequestIDFromContext(c.Request.Context()),
+		OccurredAt: time.Now().UTC(),
+	})
+}
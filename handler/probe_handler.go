@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+	"wyw/apperror"
+	"wyw/probe"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProbeHandler interface {
+	ListProbes(c *gin.Context)
+	ProbeHistory(c *gin.Context)
+}
+
+type ProbeHandlerImpl struct {
+	Prober *probe.Prober
+}
+
+func NewProbeHandler(prober *probe.Prober) *ProbeHandlerImpl {
+	return &ProbeHandlerImpl{Prober: prober}
+}
+
+// ListProbes summarizes the synthetic probes.
+// @Summary      List Probes
+// @Description  Returns the last result, consecutive failures and rolling availability (5m, 1h, 24h) of every synthetic probe, measured the way users reach the service.
+// @Produce      application/json
+// @Tags         probe
+// @Security     ApiKeyAuth
+// @Success      200 {object} []probe.Status "Probe statuses"
+// @Router       /admin/probes [get]
+func (h ProbeHandlerImpl) ListProbes(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"data": h.Prober.Statuses(),
+	})
+}
+
+// ProbeHistory returns recent results of one probe.
+// @Summary      Probe History
+// @Description  Returns the results of a probe, newest first.
+// @Param        probe path  string true  "Probe name"
+// @Param        since query string false "Only results after this RFC 3339 time (default 1 hour ago)"
+// @Param        limit query int    false "Maximum results (default 500, max 10000)"
+// @Produce      application/json
+// @Tags         probe
+// @Security     ApiKeyAuth
+// @Success      200 {object} []probe.Result "Probe results"
+// @Failure      404 {object} apperror.Problem "Problem detail indicating the probe does not exist"
+// @Failure      422 {object} apperror.Problem "Problem detail indicating an invalid filter"
+// @Router       /admin/probes/{probe}/history [get]
+func (h ProbeHandlerImpl) ProbeHistory(c *gin.Context) {
+	since := time.Now().Add(-time.Hour)
+	if v := c.Query("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			_ = c.Error(apperror.New(apperror.CodeValidation, "since must be an RFC 3339 timestamp").
+				WithFields(apperror.FieldError{Field: "since", Rule: "datetime", Message: "since must be an RFC 3339 timestamp"}))
+			return
+		}
+		since = t
+	}
+	limit := 500
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 10000 {
+			_ = c.Error(apperror.New(apperror.CodeValidation, "limit must be between 1 and 10000").
+				WithFields(apperror.FieldError{Field: "limit", Rule: "range", Message: "limit must be between 1 and 10000"}))
+			return
+		}
+		limit = n
+	}
+
+	results, ok := h.Prober.History(c.Param("probe"), since, limit)
+	if !ok {
+		_ = c.Error(apperror.New(apperror.CodeNotFound, "probe not found"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": results,
+	})
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"wyw/apperror"
+	"wyw/audit"
+	"wyw/entity"
+	"wyw/logging"
+	"wyw/metric"
+	"wyw/middleware"
+	"wyw/session"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionHandler interface {
+	ListMine(c *gin.Context)
+	RevokeMine(c *gin.Context)
+	RevokeAllForUser(c *gin.Context)
+}
+
+type SessionHandlerImpl struct {
+	*metric.AppMetricsExporter
+	Sessions *session.Manager
+	Audit    *audit.Recorder
+}
+
+func NewSessionHandler(appMetricsExporter *metric.AppMetricsExporter, sessions *session.Manager, auditor *audit.Recorder) *SessionHandlerImpl {
+	return &SessionHandlerImpl{AppMetricsExporter: appMetricsExporter, Sessions: sessions, Audit: auditor}
+}
+
+// ListMine lists the active sessions of the logged in user.
+// @Summary      List My Sessions
+// @Description  Returns every active session of the current user with device, IP, user agent and activity times.
+// @Produce      application/json
+// @Tags         session
+// @Security     BearerAuth
+// @Success      200 {object} []entity.SessionInfo "Active sessions, newest first"
+// @Failure      401 {object} apperror.Problem "Problem detail indicating a missing or invalid session"
+// @Router       /me/sessions [get]
+func (h SessionHandlerImpl) ListMine(c *gin.Context) {
+	current := middleware.CurrentSession(c)
+
+	sessions, err := h.Sessions.List(c.Request.Context(), current.Username)
+	if err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to list sessions"))
+		return
+	}
+
+	results := make([]entity.SessionInfo, 0, len(sessions))
+	for _, s := range sessions {
+		results = append(results, entity.SessionInfo{
+			ID:         s.ID,
+			Device:     s.Device,
+			IP:         s.IP,
+			UserAgent:  s.UserAgent,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			ExpiresAt:  s.ExpiresAt,
+			Current:    s.ID == current.ID,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"data": results})
+}
+
+// RevokeMine revokes one session of the logged in user.
+// @Summary      Revoke My Session
+// @Description  Revokes a session of the current user, e.g. a lost device. Revoking the current session logs the caller out.
+// @Param        id path string true "Session ID"
+// @Produce      application/json
+// @Tags         session
+// @Security     BearerAuth
+// @Success      200 {object} entity.MsgResponse "Success message"
+// @Failure      401 {object} apperror.Problem "Problem detail indicating a missing or invalid session"
+// @Failure      404 {object} apperror.Problem "Problem detail indicating the session does not exist"
+// @Router       /me/sessions/{id} [delete]
+func (h SessionHandlerImpl) RevokeMine(c *gin.Context) {
+	username := middleware.CurrentSession(c).Username
+	id := c.Param("id")
+
+	if err := h.Sessions.Revoke(c.Request.Context(), username, id); err != nil {
+		if errors.Is(err, session.ErrNotFound) {
+			_ = c.Error(apperror.Wrap(apperror.CodeNotFound, err, "session not found"))
+			return
+		}
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to revoke session"))
+		return
+	}
+
+	h.AppMetricsExporter.RecordBusinessEvent("session_revoked", username)
+	recordAudit(c, h.Audit, username, "session_revoke", id, audit.OutcomeSuccess, "")
+	c.JSON(http.StatusOK, entity.MsgResponse{Message: "session revoked"})
+}
+
+// RevokeAllForUser revokes every session of a user.
+// @Summary      Revoke User Sessions
+// @Description  Revokes all active sessions of a user, forcing them to log in again on every device.
+// @Param        username path string true "Username"
+// @Produce      application/json
+// @Tags         admin
+// @Security     ApiKeyAuth
+// @Success      200 {object} entity.RevokedSessionsResponse "Number of revoked sessions"
+// @Failure      401 {object} apperror.Problem "Problem detail indicating a missing or invalid API key"
+// @Router       /admin/users/{username}/sessions [delete]
+func (h SessionHandlerImpl) RevokeAllForUser(c *gin.Context) {
+	username := c.Param("username")
+
+	n, err := h.Sessions.RevokeAll(c.Request.Context(), username)
+	if err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to revoke sessions"))
+		return
+	}
+
+	logging.Logger(logging.ModuleHandler).InfoContext(c.Request.Context(), "sessions revoked by admin",
+		slog.String("username", username), slog.Int64("revoked", n))
+	h.AppMetricsExporter.RecordBusinessEvent("sessions_revoked_by_admin", username)
+	recordAudit(c, h.Audit, "admin", "session_revoke_all", username, audit.OutcomeSuccess, fmt.Sprintf("%d sessions", n))
+	c.JSON(http.StatusOK, entity.RevokedSessionsResponse{
+		Message: fmt.Sprintf("%d sessions revoked for %s", n, username),
+		Revoked: n,
+	})
+}
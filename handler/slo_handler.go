@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+	"wyw/apperror"
+	"wyw/slo"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SLOHandler interface {
+	ListSLOs(c *gin.Context)
+	SLORules(c *gin.Context)
+}
+
+type SLOHandlerImpl struct {
+	Tracker *slo.Tracker
+}
+
+func NewSLOHandler(tracker *slo.Tracker) *SLOHandlerImpl {
+	return &SLOHandlerImpl{Tracker: tracker}
+}
+
+// ListSLOs reports the error budget of every SLO.
+// @Summary      List SLOs
+// @Description  Returns SLI, remaining error budget, burn rates and multi-window burn-rate alert state of every SLO, computed from the service's own request counters. Counters reset on restart; coverage tells how much of the compliance window has been observed.
+// @Produce      application/json
+// @Tags         slo
+// @Security     ApiKeyAuth
+// @Success      200 {object} []slo.Status "SLO statuses"
+// @Router       /admin/slo [get]
+func (h SLOHandlerImpl) ListSLOs(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"data": h.Tracker.Statuses(),
+	})
+}
+
+// SLORules returns the Prometheus rules of the SLOs.
+// @Summary      SLO Prometheus Rules
+// @Description  Returns recording rules for the SLO error ratios and multi-window, multi-burn-rate alerting rules as a Prometheus rule file.
+// @Produce      application/yaml
+// @Tags         slo
+// @Security     ApiKeyAuth
+// @Success      200 {string} string "Prometheus rule file"
+// @Router       /admin/slo/rules [get]
+func (h SLOHandlerImpl) SLORules(c *gin.Context) {
+	rules, err := slo.RulesYAML(h.Tracker.SLOs())
+	if err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to generate slo rules"))
+		return
+	}
+	c.Data(http.StatusOK, "application/yaml", rules)
+}
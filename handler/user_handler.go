@@ -1,12 +1,20 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"log/slog"
 	"net/http"
+	"time"
+	"wyw/apperror"
+	"wyw/audit"
 	"wyw/entity"
+	"wyw/logging"
 	"wyw/metric"
+	"wyw/session"
+	"wyw/token"
 )
 
 type UserHandler interface {
@@ -18,28 +26,42 @@ type UserHandler interface {
 type UserHandlerImpl struct {
 	*gorm.DB
 	*metric.AppMetricsExporter
+	Accounts *AccountHandlerImpl
+	Sessions *session.Manager
+	Audit    *audit.Recorder
+	// RequireVerifiedEmail menolak login dari akun yang emailnya belum diverifikasi
+	RequireVerifiedEmail bool
+	// MFAChallengeTTL adalah masa berlaku challenge token untuk akun dengan TOTP aktif
+	MFAChallengeTTL time.Duration
 }
 
-func NewUserHandler(DB *gorm.DB, appMetricsExporter *metric.AppMetricsExporter) *UserHandlerImpl {
-	return &UserHandlerImpl{DB: DB, AppMetricsExporter: appMetricsExporter}
+func NewUserHandler(DB *gorm.DB, appMetricsExporter *metric.AppMetricsExporter, accounts *AccountHandlerImpl, sessions *session.Manager, auditor *audit.Recorder, requireVerifiedEmail bool, mfaChallengeTTL time.Duration) *UserHandlerImpl {
+	return &UserHandlerImpl{
+		DB:                   DB,
+		AppMetricsExporter:   appMetricsExporter,
+		Accounts:             accounts,
+		Sessions:             sessions,
+		Audit:                auditor,
+		RequireVerifiedEmail: requireVerifiedEmail,
+		MFAChallengeTTL:      mfaChallengeTTL,
+	}
 }
 
 // Login handles user login requests.
 // @Summary      User Login
-// @Description  Validates user credentials and logs the user in if the credentials are correct.
-// @Param        credentials body entity.User true "User credentials (username and password)"
+// @Description  Validates user credentials and returns a session token. Accounts with TOTP enabled get an mfa_required challenge that must be completed at /login/mfa.
+// @Param        credentials body entity.LoginRequest true "User credentials (username and password)"
 // @Produce      application/json
 // @Tags         user
-// @Success      200 {object} entity.MsgResponse "Success message indicating user login"
-// @Failure      400 {object} entity.ErrorResponse "Error message indicating invalid credentials"
-// @Failure      422 {object} entity.ErrorResponse "Error message indicating invalid JSON format"
+// @Success      200 {object} entity.LoginResponse "Session token, or an MFA challenge token"
+// @Failure      400 {object} apperror.Problem "Problem detail indicating invalid JSON format"
+// @Failure      401 {object} apperror.Problem "Problem detail indicating invalid credentials"
+// @Failure      403 {object} apperror.Problem "Problem detail indicating the email address is not verified yet"
+// @Failure      422 {object} apperror.Problem "Problem detail with field-level validation errors"
 // @Router       /login [post]
 func (u UserHandlerImpl) Login(c *gin.Context) {
-	var request entity.User
-	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{
-			"message": "invalid format json ",
-		})
+	var request entity.LoginRequest
+	if !bindJSON(c, u.AppMetricsExporter, &request) {
 		return
 	}
 
@@ -47,73 +69,133 @@ func (u UserHandlerImpl) Login(c *gin.Context) {
 	if err := u.DB.WithContext(c.Request.Context()).
 		Where("username =? AND password =?", request.Username, request.Password).
 		First(&user).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message": "Invalid Username and Password",
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to look up user"))
+			return
+		}
+		logging.Logger(logging.ModuleHandler).DebugContext(c.Request.Context(), "login failed",
+			slog.String("username", request.Username))
+		recordAudit(c, u.Audit, request.Username, "login", request.Username, audit.OutcomeFailure, "invalid credentials")
+		_ = c.Error(apperror.New(apperror.CodeInvalidCredentials, "username or password is incorrect"))
+		return
+	}
+
+	if u.RequireVerifiedEmail && user.EmailVerifiedAt == nil {
+		u.AppMetricsExporter.RecordBusinessEvent("login_unverified", user.Username)
+		recordAudit(c, u.Audit, user.Username, "login", user.Username, audit.OutcomeDenied, "email not verified")
+		_ = c.Error(apperror.New(apperror.CodeEmailNotVerified, "verify your email address before logging in"))
+		return
+	}
+
+	var mfaCount int64
+	if err := u.DB.WithContext(c.Request.Context()).Model(&entity.UserMFA{}).
+		Where("username = ? AND enabled = ?", user.Username, true).
+		Count(&mfaCount).Error; err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to load mfa settings"))
+		return
+	}
+	if mfaCount > 0 {
+		challenge, err := u.Accounts.Tokens.Issue(token.PurposeMFAChallenge, user.Username, u.MFAChallengeTTL)
+		if err != nil {
+			_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to issue mfa challenge"))
+			return
+		}
+		u.AppMetricsExporter.RecordBusinessEvent("mfa_challenge", user.Username)
+		recordAudit(c, u.Audit, user.Username, "login", user.Username, audit.OutcomeSuccess, "mfa challenge issued")
+		c.JSON(http.StatusOK, entity.LoginResponse{
+			Message:        "two-factor authentication required",
+			MFARequired:    true,
+			ChallengeToken: challenge,
 		})
 		return
 	}
 
+	if err := publishEvent(c, u.DB.WithContext(c.Request.Context()), "login", user.Username); err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to record login event"))
+		return
+	}
+
 	//RECORD METRICS
 	u.AppMetricsExporter.RecordBusinessEvent("login", user.Username)
+	recordAudit(c, u.Audit, user.Username, "login", user.Username, audit.OutcomeSuccess, "")
 
-	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("%s login successfully", user.Username)})
+	startSession(c, u.Sessions, user.Username)
 }
 
 // Register handles user registration requests.
 // @Summary      User Registration
-// @Description  Registers a new user by saving the provided user credentials to the database.
-// @Param        credentials body entity.User true "User credentials (username, password, etc.)"
+// @Description  Registers a new user by saving the provided user credentials to the database and sends a verification email.
+// @Param        credentials body entity.RegisterRequest true "User credentials (username, password, etc.)"
+// @Param        Idempotency-Key header string false "Unique key that makes retries of this request safe"
 // @Produce      application/json
 // @Tags         user
 // @Success      200 {object} entity.MsgResponse "Success message indicating successful registration"
-// @Failure      400 {object} entity.ErrorResponse "Error message indicating invalid username or password"
-// @Failure      422 {object} entity.ErrorResponse "Error message indicating invalid JSON format"
+// @Failure      400 {object} apperror.Problem "Problem detail indicating invalid JSON format"
+// @Failure      409 {object} apperror.Problem "Problem detail indicating the username or email is taken, or the idempotency key is in use"
+// @Failure      422 {object} apperror.Problem "Problem detail with field-level validation errors or a reused idempotency key"
 // @Router       /register [post]
 func (u UserHandlerImpl) Register(c *gin.Context) {
-	var request entity.User
-	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{
-			"message": "invalid format json ",
-		})
+	var request entity.RegisterRequest
+	if !bindJSON(c, u.AppMetricsExporter, &request) {
 		return
 	}
 
-	if err := u.DB.WithContext(c.Request.Context()).Create(&request).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message": "Invalid Username and Password",
-		})
+	user := entity.User{Username: request.Username, Password: request.Password, Email: &request.Email}
+	err := u.DB.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return publishEvent(c, tx, "register", user.Username)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			logging.Logger(logging.ModuleHandler).DebugContext(c.Request.Context(), "register failed, duplicate user",
+				slog.String("username", request.Username))
+			appErr := u.duplicateError(c, err, request)
+			recordAudit(c, u.Audit, request.Username, "register", request.Username, audit.OutcomeFailure, string(appErr.Code))
+			_ = c.Error(appErr)
+			return
+		}
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to create user"))
 		return
 	}
 
+	if err := u.Accounts.SendVerification(user); err != nil {
+		logging.Logger(logging.ModuleHandler).ErrorContext(c.Request.Context(), "failed to issue verification token",
+			slog.String("username", user.Username), slog.Any("error", err))
+	}
+
 	//RECORD METRICS
 	u.AppMetricsExporter.RecordBusinessEvent("register", request.Username)
+	recordAudit(c, u.Audit, request.Username, "register", request.Username, audit.OutcomeSuccess, "")
 
 	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("%s register successfully", request.Username)})
 }
 
+// duplicateError menentukan unique key mana yang bentrok, karena ErrDuplicatedKey dari GORM
+// tidak membawa nama index
+func (u UserHandlerImpl) duplicateError(c *gin.Context, err error, request entity.RegisterRequest) *apperror.Error {
+	var count int64
+	u.DB.WithContext(c.Request.Context()).Model(&entity.User{}).Where("email = ?", request.Email).Count(&count)
+	if count > 0 {
+		return apperror.Wrap(apperror.CodeEmailTaken, err, "email is already registered")
+	}
+	return apperror.Wrap(apperror.CodeUsernameTaken, err, fmt.Sprintf("username %q is already registered", request.Username))
+}
+
 // GetUser handles fetching all users from the database.
 // @Summary      Get Users
 // @Description  Retrieves all users from the database.
 // @Produce      application/json
 // @Tags         user
-// @Success      200 {object} []entity.User{} "List of users in the database"
-// @Failure      500 {object} entity.ErrorResponse "Error message indicating internal server error"
-// @Failure      422 {object} entity.ErrorResponse "Error message indicating no users found"
+// @Success      200 {object} []entity.User{} "List of users in the database, empty when none are registered"
+// @Failure      500 {object} apperror.Problem "Problem detail indicating internal server error"
 // @Router       /users [get]
 func (u UserHandlerImpl) GetUser(c *gin.Context) {
 
-	var results []entity.User
+	results := []entity.User{}
 	if err := u.DB.WithContext(c.Request.Context()).Find(&results).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"message": "internal error try again later",
-		})
-		return
-	}
-
-	if len(results) == 0 {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"message": "data not insert yet ",
-		})
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to list users"))
 		return
 	}
 
@@ -0,0 +1,322 @@
+package handler
+
+import (
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"wyw/apperror"
+	"wyw/metric"
+	"wyw/webhook"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type WebhookHandler interface {
+	CreateSubscription(c *gin.Context)
+	ListSubscriptions(c *gin.Context)
+	GetSubscription(c *gin.Context)
+	UpdateSubscription(c *gin.Context)
+	DeleteSubscription(c *gin.Context)
+	ListDeliveries(c *gin.Context)
+	GetDelivery(c *gin.Context)
+	Redeliver(c *gin.Context)
+}
+
+type WebhookHandlerImpl struct {
+	*gorm.DB
+	*metric.AppMetricsExporter
+	Dispatcher *webhook.Dispatcher
+}
+
+func NewWebhookHandler(DB *gorm.DB, appMetricsExporter *metric.AppMetricsExporter, dispatcher *webhook.Dispatcher) *WebhookHandlerImpl {
+	return &WebhookHandlerImpl{DB: DB, AppMetricsExporter: appMetricsExporter, Dispatcher: dispatcher}
+}
+
+// WebhookSubscriptionRequest is the payload for creating or updating a subscription.
+type WebhookSubscriptionRequest struct {
+	URL string `json:"url" binding:"required,url,max=2048" example:"https://hooks.example.com/users"`
+	// Events to deliver, "*" subscribes to every event.
+	Events []string `json:"events" binding:"required,min=1,dive,oneof=* register login locked email_verified password_reset" example:"register,login"`
+	// Secret signs the deliveries. A random secret is generated when empty.
+	Secret string `json:"secret" binding:"omitempty,min=16,max=128"`
+	Active *bool  `json:"active"`
+}
+
+// WebhookSubscriptionCreated is returned once on creation and is the only response that carries the secret.
+type WebhookSubscriptionCreated struct {
+	webhook.Subscription
+	Secret string `json:"secret" example:"whsec_3q2+7w..."`
+}
+
+// WebhookDeliveryDetail is a delivery together with its attempt history.
+type WebhookDeliveryDetail struct {
+	webhook.Delivery
+	Attempts []webhook.Attempt `json:"attempt_history"`
+}
+
+// bindSubscription mem-bind payload subscription dan memastikan URL memakai http atau https
+func (h WebhookHandlerImpl) bindSubscription(c *gin.Context) (WebhookSubscriptionRequest, bool) {
+	var request WebhookSubscriptionRequest
+	if !bindJSON(c, h.AppMetricsExporter, &request) {
+		return request, false
+	}
+	if u, err := url.Parse(request.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		h.AppMetricsExporter.RecordValidationFailure(c.FullPath(), "url", "scheme")
+		_ = c.Error(apperror.New(apperror.CodeValidation, "one or more fields are invalid").WithFields(apperror.FieldError{
+			Field: "url", Rule: "scheme", Message: "url must use http or https",
+		}))
+		return request, false
+	}
+	return request, true
+}
+
+// loadSubscription mengambil subscription dari path parameter :id
+func (h WebhookHandlerImpl) loadSubscription(c *gin.Context) (webhook.Subscription, bool) {
+	var sub webhook.Subscription
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err == nil {
+		err = h.DB.WithContext(c.Request.Context()).First(&sub, id).Error
+	} else {
+		err = gorm.ErrRecordNotFound
+	}
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		_ = c.Error(apperror.New(apperror.CodeNotFound, "webhook subscription not found"))
+		return sub, false
+	case err != nil:
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to load webhook subscription"))
+		return sub, false
+	}
+	return sub, true
+}
+
+// CreateSubscription registers a webhook endpoint.
+// @Summary      Create Webhook Subscription
+// @Description  Registers a URL that receives signed user lifecycle events. The secret is returned only in this response.
+// @Param        request body WebhookSubscriptionRequest true "Subscription"
+// @Param        Idempotency-Key header string false "Unique key that makes retries of this request safe"
+// @Produce      application/json
+// @Tags         webhook
+// @Security     ApiKeyAuth
+// @Success      201 {object} WebhookSubscriptionCreated "Created subscription including its secret"
+// @Failure      409 {object} apperror.Problem "Problem detail indicating the idempotency key is in use"
+// @Failure      422 {object} apperror.Problem "Problem detail with field-level validation errors or a reused idempotency key"
+// @Router       /admin/webhooks [post]
+func (h WebhookHandlerImpl) CreateSubscription(c *gin.Context) {
+	request, ok := h.bindSubscription(c)
+	if !ok {
+		return
+	}
+
+	secret := request.Secret
+	if secret == "" {
+		secret = "whsec_" + rand.Text()
+	}
+	sub := webhook.Subscription{
+		URL:    request.URL,
+		Events: strings.Join(request.Events, ","),
+		Secret: secret,
+		Active: request.Active == nil || *request.Active,
+	}
+	if err := h.DB.WithContext(c.Request.Context()).Create(&sub).Error; err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to create webhook subscription"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, WebhookSubscriptionCreated{Subscription: sub, Secret: secret})
+}
+
+// ListSubscriptions lists every webhook subscription.
+// @Summary      List Webhook Subscriptions
+// @Description  Returns all webhook subscriptions without their secrets.
+// @Produce      application/json
+// @Tags         webhook
+// @Security     ApiKeyAuth
+// @Success      200 {object} []webhook.Subscription "Subscriptions"
+// @Router       /admin/webhooks [get]
+func (h WebhookHandlerImpl) ListSubscriptions(c *gin.Context) {
+	results := []webhook.Subscription{}
+	if err := h.DB.WithContext(c.Request.Context()).Order("id ASC").Find(&results).Error; err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to list webhook subscriptions"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": results})
+}
+
+// GetSubscription returns one webhook subscription.
+// @Summary      Get Webhook Subscription
+// @Param        id path int true "Subscription ID"
+// @Produce      application/json
+// @Tags         webhook
+// @Security     ApiKeyAuth
+// @Success      200 {object} webhook.Subscription "Subscription"
+// @Failure      404 {object} apperror.Problem "Problem detail indicating the subscription does not exist"
+// @Router       /admin/webhooks/{id} [get]
+func (h WebhookHandlerImpl) GetSubscription(c *gin.Context) {
+	sub, ok := h.loadSubscription(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+// UpdateSubscription replaces the URL, event filter and state of a subscription.
+// @Summary      Update Webhook Subscription
+// @Description  Replaces the URL and event filter. The secret is only rotated when a new one is sent.
+// @Param        id path int true "Subscription ID"
+// @Param        request body WebhookSubscriptionRequest true "Subscription"
+// @Produce      application/json
+// @Tags         webhook
+// @Security     ApiKeyAuth
+// @Success      200 {object} webhook.Subscription "Updated subscription"
+// @Failure      404 {object} apperror.Problem "Problem detail indicating the subscription does not exist"
+// @Failure      422 {object} apperror.Problem "Problem detail with field-level validation errors"
+// @Router       /admin/webhooks/{id} [put]
+func (h WebhookHandlerImpl) UpdateSubscription(c *gin.Context) {
+	sub, ok := h.loadSubscription(c)
+	if !ok {
+		return
+	}
+	request, ok := h.bindSubscription(c)
+	if !ok {
+		return
+	}
+
+	sub.URL = request.URL
+	sub.Events = strings.Join(request.Events, ",")
+	if request.Secret != "" {
+		sub.Secret = request.Secret
+	}
+	if request.Active != nil {
+		sub.Active = *request.Active
+	}
+	if err := h.DB.WithContext(c.Request.Context()).Save(&sub).Error; err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to update webhook subscription"))
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+// DeleteSubscription removes a webhook subscription.
+// @Summary      Delete Webhook Subscription
+// @Description  Deletes the subscription. Pending deliveries for it are moved to the dead state.
+// @Param        id path int true "Subscription ID"
+// @Produce      application/json
+// @Tags         webhook
+// @Security     ApiKeyAuth
+// @Success      200 {object} entity.MsgResponse "Success message"
+// @Failure      404 {object} apperror.Problem "Problem detail indicating the subscription does not exist"
+// @Router       /admin/webhooks/{id} [delete]
+func (h WebhookHandlerImpl) DeleteSubscription(c *gin.Context) {
+	sub, ok := h.loadSubscription(c)
+	if !ok {
+		return
+	}
+	if err := h.DB.WithContext(c.Request.Context()).Delete(&sub).Error; err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to delete webhook subscription"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "webhook subscription deleted"})
+}
+
+// ListDeliveries returns the delivery history of a subscription.
+// @Summary      List Webhook Deliveries
+// @Description  Returns the newest deliveries of a subscription, optionally filtered by status.
+// @Param        id     path  int    true  "Subscription ID"
+// @Param        status query string false "pending, retrying, succeeded or dead"
+// @Param        limit  query int    false "Page size, 1-1000 (default 100)"
+// @Produce      application/json
+// @Tags         webhook
+// @Security     ApiKeyAuth
+// @Success      200 {object} []webhook.Delivery "Deliveries, newest first"
+// @Failure      404 {object} apperror.Problem "Problem detail indicating the subscription does not exist"
+// @Router       /admin/webhooks/{id}/deliveries [get]
+func (h WebhookHandlerImpl) ListDeliveries(c *gin.Context) {
+	sub, ok := h.loadSubscription(c)
+	if !ok {
+		return
+	}
+
+	limit := 100
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 1000 {
+			_ = c.Error(apperror.New(apperror.CodeValidation, "limit must be between 1 and 1000").
+				WithFields(apperror.FieldError{Field: "limit", Rule: "range", Message: "limit must be between 1 and 1000"}))
+			return
+		}
+		limit = n
+	}
+
+	tx := h.DB.WithContext(c.Request.Context()).Where("subscription_id = ?", sub.ID)
+	if status := c.Query("status"); status != "" {
+		tx = tx.Where("status = ?", status)
+	}
+	results := []webhook.Delivery{}
+	if err := tx.Order("id DESC").Limit(limit).Find(&results).Error; err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to list webhook deliveries"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": results})
+}
+
+// GetDelivery returns one delivery with every attempt.
+// @Summary      Get Webhook Delivery
+// @Param        id path int true "Delivery ID"
+// @Produce      application/json
+// @Tags         webhook
+// @Security     ApiKeyAuth
+// @Success      200 {object} WebhookDeliveryDetail "Delivery and attempt history"
+// @Failure      404 {object} apperror.Problem "Problem detail indicating the delivery does not exist"
+// @Router       /admin/webhook-deliveries/{id} [get]
+func (h WebhookHandlerImpl) GetDelivery(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var detail WebhookDeliveryDetail
+	err := h.DB.WithContext(ctx).First(&detail.Delivery, "id = ?", c.Param("id")).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		_ = c.Error(apperror.New(apperror.CodeNotFound, "webhook delivery not found"))
+		return
+	}
+	if err == nil {
+		err = h.DB.WithContext(ctx).Where("delivery_id = ?", detail.ID).Order("id ASC").Find(&detail.Attempts).Error
+	}
+	if err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to load webhook delivery"))
+		return
+	}
+	c.JSON(http.StatusOK, detail)
+}
+
+// Redeliver schedules a delivery to be sent again.
+// @Summary      Redeliver Webhook
+// @Description  Resets the attempt counter and sends the delivery again as soon as possible, including dead or already succeeded deliveries.
+// @Param        id path int true "Delivery ID"
+// @Produce      application/json
+// @Tags         webhook
+// @Security     ApiKeyAuth
+// @Success      202 {object} webhook.Delivery "Rescheduled delivery"
+// @Failure      404 {object} apperror.Problem "Problem detail indicating the delivery does not exist"
+// @Router       /admin/webhook-deliveries/{id}/redeliver [post]
+func (h WebhookHandlerImpl) Redeliver(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		_ = c.Error(apperror.New(apperror.CodeNotFound, "webhook delivery not found"))
+		return
+	}
+
+	delivery, err := h.Dispatcher.Redeliver(c.Request.Context(), id)
+	if errors.Is(err, webhook.ErrNotFound) {
+		_ = c.Error(apperror.New(apperror.CodeNotFound, "webhook delivery not found"))
+		return
+	}
+	if err != nil {
+		_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to reschedule webhook delivery"))
+		return
+	}
+	c.JSON(http.StatusAccepted, delivery)
+}
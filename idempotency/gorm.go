@@ -0,0 +1,63 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GormStore menyimpan record di tabel idempotency_keys sehingga bisa dipakai bersama antar instance
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore membuat GormStore dan memastikan tabelnya ada
+func NewGormStore(db *gorm.DB) (*GormStore, error) {
+	if err := db.AutoMigrate(&Record{}); err != nil {
+		return nil, err
+	}
+	return &GormStore{db: db}, nil
+}
+
+func (s *GormStore) Get(ctx context.Context, key string) (*Record, error) {
+	var rec Record
+	err := s.db.WithContext(ctx).
+		Where("idempotency_key = ? AND expires_at > ?", key, time.Now()).
+		First(&rec).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func (s *GormStore) Reserve(ctx context.Context, rec *Record) (bool, error) {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Record kedaluwarsa yang belum dibersihkan janitor tidak boleh menghalangi key baru
+		if err := tx.Where("idempotency_key = ? AND expires_at <= ?", rec.Key, time.Now()).Delete(&Record{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(rec).Error
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *GormStore) Complete(ctx context.Context, rec *Record) error {
+	return s.db.WithContext(ctx).Save(rec).Error
+}
+
+func (s *GormStore) Delete(ctx context.Context, key string) error {
+	return s.db.WithContext(ctx).Where("idempotency_key = ?", key).Delete(&Record{}).Error
+}
+
+func (s *GormStore) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	res := s.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&Record{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,70 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore menyimpan record di memori proses, cocok untuk satu instance
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+// NewMemoryStore membuat MemoryStore kosong
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: map[string]Record{}}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.records[key]
+	if !ok || time.Now().After(rec.ExpiresAt) {
+		return nil, nil
+	}
+	return &rec, nil
+}
+
+func (s *MemoryStore) Reserve(_ context.Context, rec *Record) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.records[rec.Key]; ok && time.Now().Before(existing.ExpiresAt) {
+		return false, nil
+	}
+	s.records[rec.Key] = *rec
+	return true, nil
+}
+
+func (s *MemoryStore) Complete(_ context.Context, rec *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[rec.Key] = *rec
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+func (s *MemoryStore) DeleteExpired(_ context.Context, now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for key, rec := range s.records {
+		if now.After(rec.ExpiresAt) {
+			delete(s.records, key)
+			n++
+		}
+	}
+	return n, nil
+}
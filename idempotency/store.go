@@ -0,0 +1,63 @@
+package idempotency
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"wyw/logging"
+)
+
+// Record menyimpan fingerprint request dan response pertama untuk sebuah Idempotency-Key
+type Record struct {
+	Key         string `gorm:"column:idempotency_key;primaryKey;size:64"`
+	Fingerprint string `gorm:"size:64"`
+	Completed   bool
+	Status      int
+	ContentType string `gorm:"size:128"`
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time `gorm:"index"`
+}
+
+// TableName menentukan nama tabel GORM untuk Record
+func (Record) TableName() string {
+	return "idempotency_keys"
+}
+
+// Store adalah penyimpanan Record yang bisa diganti (memory, GORM, dll)
+type Store interface {
+	// Get mengembalikan record yang belum kedaluwarsa, nil bila tidak ada
+	Get(ctx context.Context, key string) (*Record, error)
+	// Reserve menyimpan record in-flight bila key belum dipakai, false bila sudah ada
+	Reserve(ctx context.Context, rec *Record) (bool, error)
+	// Complete menyimpan response akhir untuk record yang sudah di-reserve
+	Complete(ctx context.Context, rec *Record) error
+	// Delete menghapus record, dipakai saat request gagal supaya client bisa retry
+	Delete(ctx context.Context, key string) error
+	// DeleteExpired menghapus semua record yang kedaluwarsa sebelum now
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// StartJanitor menghapus record kedaluwarsa secara periodik sampai ctx selesai
+func StartJanitor(ctx context.Context, store Store, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				n, err := store.DeleteExpired(ctx, now)
+				if err != nil {
+					logging.Logger(logging.ModuleDB).Warn("failed to delete expired idempotency keys", slog.Any("error", err))
+					continue
+				}
+				if n > 0 {
+					logging.Logger(logging.ModuleDB).Debug("expired idempotency keys deleted", slog.Int64("count", n))
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,244 @@
+package live
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"wyw/metric"
+)
+
+// Tipe event yang dikirim ke client
+const (
+	TypeHTTP     = "http"
+	TypeBusiness = "business"
+	TypeStats    = "stats"
+)
+
+// Event adalah satu item di stream. Seq naik terus sehingga client bisa melanjutkan
+// dari event terakhir yang diterima lewat Last-Event-ID.
+type Event struct {
+	Seq  uint64    `json:"seq"`
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	Data any       `json:"data"`
+}
+
+// HTTPRequest adalah data event bertipe "http"
+type HTTPRequest struct {
+	Method     string  `json:"method"`
+	Endpoint   string  `json:"endpoint"`
+	Status     int     `json:"status"`
+	Code       string  `json:"code,omitempty"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+// BusinessEvent adalah data event bertipe "business"
+type BusinessEvent struct {
+	EventType string `json:"event_type"`
+	UserID    string `json:"user_id"`
+}
+
+// Filter memilih event yang dikirim ke satu client. Field kosong berarti semua.
+type Filter struct {
+	Types     []string
+	Events    []string
+	Endpoint  string
+	MinStatus int
+}
+
+// Match melaporkan apakah event lolos filter
+func (f Filter) Match(e Event) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, e.Type) {
+		return false
+	}
+	switch data := e.Data.(type) {
+	case HTTPRequest:
+		if f.Endpoint != "" && !strings.HasPrefix(data.Endpoint, f.Endpoint) {
+			return false
+		}
+		if data.Status < f.MinStatus {
+			return false
+		}
+	case BusinessEvent:
+		if len(f.Events) > 0 && !slices.Contains(f.Events, data.EventType) {
+			return false
+		}
+	}
+	return true
+}
+
+// Subscriber adalah satu client yang terhubung. C ditutup ketika client diputus oleh hub,
+// misalnya karena terlalu lambat membaca.
+type Subscriber struct {
+	C      <-chan Event
+	ch     chan Event
+	filter Filter
+	// Dropped bernilai true bila hub memutus client karena buffernya penuh
+	dropped bool
+}
+
+// Dropped melaporkan apakah subscriber diputus karena tidak mampu mengikuti stream
+func (s *Subscriber) Dropped() bool {
+	return s.dropped
+}
+
+// Options mengatur ukuran buffer hub
+type Options struct {
+	// History adalah jumlah event terakhir yang disimpan di ring buffer untuk replay
+	History int
+	// ClientBuffer adalah jumlah event yang boleh tertunda per client sebelum client diputus
+	ClientBuffer int
+	// StatsInterval adalah jeda pengiriman event "stats"
+	StatsInterval time.Duration
+	// StatsWindow adalah rentang waktu perhitungan rate dan persentil latency
+	StatsWindow time.Duration
+}
+
+// Hub menerima observasi dari AppMetricsExporter, menyimpannya di ring buffer,
+// dan meneruskannya ke semua subscriber tanpa pernah menahan goroutine request.
+type Hub struct {
+	metrics *metric.AppMetricsExporter
+	opts    Options
+
+	mu      sync.Mutex
+	seq     uint64
+	ring    []Event
+	next    int
+	full    bool
+	clients map[*Subscriber]struct{}
+	closed  bool
+
+	stats *statsWindow
+}
+
+// NewHub membuat Hub dan mendaftarkannya sebagai observer di metrics
+func NewHub(metrics *metric.AppMetricsExporter, opts Options) *Hub {
+	if opts.History <= 0 {
+		opts.History = 1024
+	}
+	if opts.ClientBuffer <= 0 {
+		opts.ClientBuffer = 256
+	}
+	if opts.StatsInterval <= 0 {
+		opts.StatsInterval = time.Second
+	}
+	if opts.StatsWindow <= 0 {
+		opts.StatsWindow = 10 * time.Second
+	}
+	h := &Hub{
+		metrics: metrics,
+		opts:    opts,
+		ring:    make([]Event, opts.History),
+		clients: map[*Subscriber]struct{}{},
+		stats:   newStatsWindow(opts.StatsWindow),
+	}
+	metrics.AddObserver(h)
+	return h
+}
+
+// ObserveHTTPRequest memenuhi metric.Observer
+func (h *Hub) ObserveHTTPRequest(status int, method, endpoint, code string, duration time.Duration) {
+	h.stats.add(time.Now(), duration, status)
+	h.publish(TypeHTTP, HTTPRequest{
+		Method:     method,
+		Endpoint:   endpoint,
+		Status:     status,
+		Code:       code,
+		DurationMs: float64(duration.Microseconds()) / 1000,
+	})
+}
+
+// RecordBusinessEvent memenuhi metric.Observer
+func (h *Hub) RecordBusinessEvent(eventType, userID string) {
+	h.publish(TypeBusiness, BusinessEvent{EventType: eventType, UserID: userID})
+}
+
+func (h *Hub) publish(typ string, data any) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	e := Event{Seq: h.seq, Type: typ, Time: time.Now().UTC(), Data: data}
+	h.ring[h.next] = e
+	h.next = (h.next + 1) % len(h.ring)
+	if h.next == 0 {
+		h.full = true
+	}
+
+	for sub := range h.clients {
+		if !sub.filter.Match(e) {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			// Client terlalu lambat, putus daripada menahan request atau memakan memori
+			sub.dropped = true
+			h.removeLocked(sub)
+			h.metrics.RecordLiveClientDropped()
+		}
+	}
+}
+
+// Subscribe mendaftarkan client baru. Bila afterSeq > 0, event di ring buffer dengan
+// Seq lebih besar dikirim lebih dulu supaya client yang reconnect tidak kehilangan event.
+func (h *Hub) Subscribe(filter Filter, afterSeq uint64) *Subscriber {
+	ch := make(chan Event, h.opts.ClientBuffer)
+	sub := &Subscriber{C: ch, ch: ch, filter: filter}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(ch)
+		return sub
+	}
+	if afterSeq > 0 {
+		for _, e := range h.historyLocked() {
+			if e.Seq > afterSeq && filter.Match(e) {
+				select {
+				case ch <- e:
+				default:
+				}
+			}
+		}
+	}
+	h.clients[sub] = struct{}{}
+	h.metrics.SetLiveClients(len(h.clients))
+	return sub
+}
+
+// Unsubscribe melepas client yang sudah selesai
+func (h *Hub) Unsubscribe(sub *Subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(sub)
+}
+
+// Close memutus semua subscriber dan menolak subscriber baru. Dipanggil saat server
+// shutdown, karena stream yang masih terbuka akan menahan http.Server.Shutdown sampai timeout.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for sub := range h.clients {
+		h.removeLocked(sub)
+	}
+}
+
+func (h *Hub) removeLocked(sub *Subscriber) {
+	if _, ok := h.clients[sub]; !ok {
+		return
+	}
+	delete(h.clients, sub)
+	close(sub.ch)
+	h.metrics.SetLiveClients(len(h.clients))
+}
+
+// historyLocked mengembalikan isi ring buffer dari yang paling lama
+func (h *Hub) historyLocked() []Event {
+	if !h.full {
+		return slices.Clone(h.ring[:h.next])
+	}
+	return append(slices.Clone(h.ring[h.next:]), h.ring[:h.next]...)
+}
@@ -0,0 +1,97 @@
+package live
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// maxStatsSamples membatasi memori jendela statistik saat trafik sangat tinggi
+const maxStatsSamples = 100_000
+
+// Stats adalah data event bertipe "stats" yang dihitung dari jendela waktu terakhir
+type Stats struct {
+	WindowSeconds float64 `json:"window_seconds"`
+	Requests      int     `json:"requests"`
+	RatePerSecond float64 `json:"rate_per_second"`
+	ErrorRatio    float64 `json:"error_ratio"`
+	P50Ms         float64 `json:"p50_ms"`
+	P90Ms         float64 `json:"p90_ms"`
+	P99Ms         float64 `json:"p99_ms"`
+}
+
+type sample struct {
+	at       time.Time
+	duration time.Duration
+	failed   bool
+}
+
+// statsWindow menyimpan sampel request selama rentang waktu tertentu
+type statsWindow struct {
+	mu      sync.Mutex
+	window  time.Duration
+	samples []sample
+}
+
+func newStatsWindow(window time.Duration) *statsWindow {
+	return &statsWindow{window: window}
+}
+
+func (w *statsWindow) add(at time.Time, duration time.Duration, status int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.samples) >= maxStatsSamples {
+		w.samples = w.samples[1:]
+	}
+	w.samples = append(w.samples, sample{at: at, duration: duration, failed: status >= 500})
+}
+
+func (w *statsWindow) snapshot(now time.Time) Stats {
+	w.mu.Lock()
+	cutoff := now.Add(-w.window)
+	i, _ := slices.BinarySearchFunc(w.samples, cutoff, func(s sample, t time.Time) int { return s.at.Compare(t) })
+	w.samples = slices.Delete(w.samples, 0, i)
+	durations := make([]time.Duration, len(w.samples))
+	failed := 0
+	for i, s := range w.samples {
+		durations[i] = s.duration
+		if s.failed {
+			failed++
+		}
+	}
+	w.mu.Unlock()
+
+	st := Stats{WindowSeconds: w.window.Seconds(), Requests: len(durations)}
+	if len(durations) == 0 {
+		return st
+	}
+	slices.Sort(durations)
+	st.RatePerSecond = float64(len(durations)) / w.window.Seconds()
+	st.ErrorRatio = float64(failed) / float64(len(durations))
+	st.P50Ms = percentile(durations, 0.50)
+	st.P90Ms = percentile(durations, 0.90)
+	st.P99Ms = percentile(durations, 0.99)
+	return st
+}
+
+// percentile memakai metode nearest-rank pada slice yang sudah terurut
+func percentile(sorted []time.Duration, q float64) float64 {
+	idx := int(q*float64(len(sorted))+0.5) - 1
+	idx = max(0, min(idx, len(sorted)-1))
+	return float64(sorted[idx].Microseconds()) / 1000
+}
+
+// Run mengirim event "stats" setiap StatsInterval sampai ctx selesai
+func (h *Hub) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.opts.StatsInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			h.publish(TypeStats, h.stats.snapshot(now))
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package loadgen
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+)
+
+// Operasi yang dijalankan load generator
+const (
+	OpRegister = "register"
+	OpLogin    = "login"
+	OpUsers    = "users"
+)
+
+// Mix adalah bobot relatif tiap operasi
+type Mix map[string]int
+
+// DefaultMix meniru pola trafik demo: kebanyakan login, sebagian register dan list user
+var DefaultMix = Mix{OpRegister: 20, OpLogin: 60, OpUsers: 20}
+
+// ParseMix membaca format "register=20,login=60,users=20"
+func ParseMix(raw string) (Mix, error) {
+	mix := Mix{}
+	for item := range strings.SplitSeq(raw, ",") {
+		name, weight, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid mix entry %q, want op=weight", item)
+		}
+		switch name {
+		case OpRegister, OpLogin, OpUsers:
+		default:
+			return nil, fmt.Errorf("unknown operation %q", name)
+		}
+		w, err := strconv.Atoi(weight)
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("invalid weight for %s", name)
+		}
+		mix[name] = w
+	}
+	if mix.total() == 0 {
+		return nil, fmt.Errorf("mix must have at least one positive weight")
+	}
+	return mix, nil
+}
+
+func (m Mix) total() int {
+	total := 0
+	for _, w := range m {
+		total += w
+	}
+	return total
+}
+
+// pick memilih operasi secara acak sesuai bobot
+func (m Mix) pick(rng *rand.Rand) string {
+	n := rng.IntN(m.total())
+	for _, op := range []string{OpRegister, OpLogin, OpUsers} {
+		if n < m[op] {
+			return op
+		}
+		n -= m[op]
+	}
+	return OpUsers
+}
@@ -0,0 +1,213 @@
+package loadgen
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Rentang histogram latency: 1µs sampai 60 detik dengan 3 digit presisi
+const (
+	minLatency = int64(time.Microsecond)
+	maxLatency = int64(time.Minute)
+)
+
+// Latency adalah ringkasan histogram dalam milidetik
+type Latency struct {
+	Min   float64 `json:"min_ms"`
+	Mean  float64 `json:"mean_ms"`
+	P50   float64 `json:"p50_ms"`
+	P90   float64 `json:"p90_ms"`
+	P99   float64 `json:"p99_ms"`
+	P999  float64 `json:"p99_9_ms"`
+	Max   float64 `json:"max_ms"`
+	Count int64   `json:"count"`
+}
+
+// OperationReport adalah hasil satu operasi
+type OperationReport struct {
+	Operation string `json:"operation"`
+	Requests  int64  `json:"requests"`
+	// Expected menghitung response yang sesuai niat payload: 2xx untuk payload valid
+	// dan 4xx untuk payload yang sengaja salah
+	Expected   int64            `json:"expected"`
+	Unexpected int64            `json:"unexpected"`
+	Outcomes   map[string]int64 `json:"outcomes"`
+	Latency    Latency          `json:"latency"`
+}
+
+// Report adalah hasil keseluruhan tes
+type Report struct {
+	Scenario   Scenario  `json:"scenario"`
+	StartedAt  time.Time `json:"started_at"`
+	Elapsed    float64   `json:"elapsed_seconds"`
+	Requests   int64     `json:"requests"`
+	Throughput float64   `json:"throughput_rps"`
+	// Skipped adalah kedatangan yang tidak dikirim karena batas in-flight tercapai
+	Skipped    int64             `json:"skipped"`
+	Unexpected int64             `json:"unexpected"`
+	Latency    Latency           `json:"latency"`
+	Operations []OperationReport `json:"operations"`
+}
+
+// collector mengumpulkan hasil request dari banyak goroutine
+type collector struct {
+	mu      sync.Mutex
+	ops     map[string]*opStats
+	overall *hdrhistogram.Histogram
+	skipped int64
+}
+
+type opStats struct {
+	requests   int64
+	expected   int64
+	unexpected int64
+	outcomes   map[string]int64
+	latency    *hdrhistogram.Histogram
+}
+
+func newCollector() *collector {
+	return &collector{ops: map[string]*opStats{}, overall: newHistogram()}
+}
+
+func newHistogram() *hdrhistogram.Histogram {
+	return hdrhistogram.New(minLatency, maxLatency, 3)
+}
+
+func (c *collector) record(op, outcome string, expected bool, latency time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.ops[op]
+	if !ok {
+		s = &opStats{outcomes: map[string]int64{}, latency: newHistogram()}
+		c.ops[op] = s
+	}
+	s.requests++
+	s.outcomes[outcome]++
+	if expected {
+		s.expected++
+	} else {
+		s.unexpected++
+	}
+	v := min(max(int64(latency), minLatency), maxLatency)
+	_ = s.latency.RecordValue(v)
+	_ = c.overall.RecordValue(v)
+}
+
+func (c *collector) skip() {
+	c.mu.Lock()
+	c.skipped++
+	c.mu.Unlock()
+}
+
+// report membuat ringkasan dari data yang terkumpul sampai saat ini
+func (c *collector) report(scenario Scenario, started time.Time, elapsed time.Duration) Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r := Report{
+		Scenario:  scenario,
+		StartedAt: started,
+		Elapsed:   elapsed.Seconds(),
+		Skipped:   c.skipped,
+		Latency:   summarize(c.overall),
+	}
+	for _, op := range slices.Sorted(maps.Keys(c.ops)) {
+		s := c.ops[op]
+		r.Requests += s.requests
+		r.Unexpected += s.unexpected
+		r.Operations = append(r.Operations, OperationReport{
+			Operation:  op,
+			Requests:   s.requests,
+			Expected:   s.expected,
+			Unexpected: s.unexpected,
+			Outcomes:   maps.Clone(s.outcomes),
+			Latency:    summarize(s.latency),
+		})
+	}
+	if elapsed > 0 {
+		r.Throughput = float64(r.Requests) / elapsed.Seconds()
+	}
+	return r
+}
+
+func summarize(h *hdrhistogram.Histogram) Latency {
+	if h.TotalCount() == 0 {
+		return Latency{}
+	}
+	ms := func(v int64) float64 { return float64(v) / float64(time.Millisecond) }
+	return Latency{
+		Min:   ms(h.Min()),
+		Mean:  h.Mean() / float64(time.Millisecond),
+		P50:   ms(h.ValueAtQuantile(50)),
+		P90:   ms(h.ValueAtQuantile(90)),
+		P99:   ms(h.ValueAtQuantile(99)),
+		P999:  ms(h.ValueAtQuantile(99.9)),
+		Max:   ms(h.Max()),
+		Count: h.TotalCount(),
+	}
+}
+
+// WriteText mencetak laporan sebagai tabel untuk terminal
+func (r Report) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "scenario %s: %d requests in %.1fs (%.1f req/s), %d unexpected, %d skipped\n\n",
+		r.Scenario.Name, r.Requests, r.Elapsed, r.Throughput, r.Unexpected, r.Skipped)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "operation\trequests\texpected\tunexpected\tp50 ms\tp90 ms\tp99 ms\tp99.9 ms\tmax ms\t")
+	for _, op := range r.Operations {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t\n", op.Operation, op.Requests, op.Expected, op.Unexpected,
+			op.Latency.P50, op.Latency.P90, op.Latency.P99, op.Latency.P999, op.Latency.Max)
+	}
+	fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t\n", "all", r.Requests, r.Requests-r.Unexpected, r.Unexpected,
+		r.Latency.P50, r.Latency.P90, r.Latency.P99, r.Latency.P999, r.Latency.Max)
+	_ = tw.Flush()
+
+	fmt.Fprintln(w, "\noutcomes:")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, op := range r.Operations {
+		for _, outcome := range slices.Sorted(maps.Keys(op.Outcomes)) {
+			fmt.Fprintf(tw, "  %s\t%s\t%d\n", op.Operation, outcome, op.Outcomes[outcome])
+		}
+	}
+	_ = tw.Flush()
+}
+
+// Gatherer mengubah laporan menjadi metrik loadgen_* untuk di-push ke Pushgateway, supaya
+// hasil load test bisa dibandingkan antar run di Grafana
+func (r Report) Gatherer() prometheus.Gatherer {
+	registry := prometheus.NewRegistry()
+	gauge := func(name, help string, labels ...string) *prometheus.GaugeVec {
+		g := prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: "loadgen", Name: name, Help: help}, labels)
+		registry.MustRegister(g)
+		return g
+	}
+	requests := gauge("requests", "Requests sent in the last run by operation", "operation")
+	unexpected := gauge("unexpected_requests", "Requests with an unexpected response in the last run by operation", "operation")
+	latency := gauge("latency_seconds", "Latency quantiles of the last run by operation", "operation", "quantile")
+	gauge("throughput_rps", "Throughput of the last run").WithLabelValues().Set(r.Throughput)
+	gauge("skipped_requests", "Arrivals skipped in the last run because of the in-flight limit").WithLabelValues().Set(float64(r.Skipped))
+	gauge("duration_seconds", "Duration of the last run").WithLabelValues().Set(r.Elapsed)
+	gauge("last_run_timestamp_seconds", "Unix time the last run started").WithLabelValues().Set(float64(r.StartedAt.Unix()))
+
+	set := func(op string, l Latency) {
+		for q, ms := range map[string]float64{"0.5": l.P50, "0.9": l.P90, "0.99": l.P99, "0.999": l.P999, "1": l.Max} {
+			latency.WithLabelValues(op, q).Set(ms / 1000)
+		}
+	}
+	for _, op := range r.Operations {
+		requests.WithLabelValues(op.Operation).Set(float64(op.Requests))
+		unexpected.WithLabelValues(op.Operation).Set(float64(op.Unexpected))
+		set(op.Operation, op.Latency)
+	}
+	requests.WithLabelValues("all").Set(float64(r.Requests))
+	unexpected.WithLabelValues("all").Set(float64(r.Unexpected))
+	set("all", r.Latency)
+	return registry
+}
@@ -0,0 +1,344 @@
+package loadgen
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	mrand "math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// maxPoolUsers membatasi jumlah akun hasil register yang disimpan untuk dipakai login
+const maxPoolUsers = 10_000
+
+// Options mengatur satu kali jalan load generator
+type Options struct {
+	// Target adalah base URL service, misalnya http://localhost:8080
+	Target   string
+	Scenario Scenario
+	Mix      Mix
+	// FailureRatio adalah proporsi request yang sengaja memakai payload salah (0..1)
+	FailureRatio float64
+	// MaxInFlight membatasi request yang berjalan bersamaan; kedatangan yang melewati batas
+	// tidak dikirim dan dihitung sebagai skipped
+	MaxInFlight int
+	Timeout     time.Duration
+	// SeedUsers adalah jumlah akun yang dibuat sebelum pengukuran supaya login punya data
+	SeedUsers int
+	// Progress dipanggil setiap ProgressInterval dengan laporan sementara, berguna untuk soak
+	Progress         func(Report)
+	ProgressInterval time.Duration
+}
+
+type credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Email    string `json:"email,omitempty"`
+}
+
+// Runner mengirim trafik dengan model terbuka: request dikirim sesuai jadwal kedatangan
+// tanpa menunggu response sebelumnya, sehingga service yang melambat tidak ikut menurunkan beban
+type Runner struct {
+	opts   Options
+	client *http.Client
+	runID  string
+	seq    atomic.Uint64
+
+	mu    sync.Mutex
+	users []credentials
+}
+
+// NewRunner memvalidasi opsi dan membuat Runner
+func NewRunner(opts Options) (*Runner, error) {
+	if err := opts.Scenario.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.FailureRatio < 0 || opts.FailureRatio > 1 {
+		return nil, errors.New("failure ratio must be between 0 and 1")
+	}
+	if opts.Mix == nil {
+		opts.Mix = DefaultMix
+	}
+	if opts.MaxInFlight <= 0 {
+		opts.MaxInFlight = 1000
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	opts.Target = strings.TrimRight(opts.Target, "/")
+
+	return &Runner{
+		opts: opts,
+		client: &http.Client{
+			Timeout: opts.Timeout,
+			Transport: &http.Transport{
+				MaxIdleConns:        opts.MaxInFlight,
+				MaxIdleConnsPerHost: opts.MaxInFlight,
+				IdleConnTimeout:     90 * time.Second,
+			},
+		},
+		// runID membuat username unik antar jalan supaya tidak bentrok dengan data lama
+		runID: strings.ToLower(rand.Text()[:6]),
+	}, nil
+}
+
+// Run menjalankan skenario sampai selesai atau ctx dibatalkan, lalu menunggu
+// request yang masih berjalan dan mengembalikan laporan akhir
+func (r *Runner) Run(ctx context.Context) (Report, error) {
+	for range r.opts.SeedUsers {
+		if _, err := r.register(ctx, true); err != nil {
+			return Report{}, fmt.Errorf("seed users: %w", err)
+		}
+	}
+
+	col := newCollector()
+	started := time.Now()
+	rng := mrand.New(mrand.NewPCG(uint64(started.UnixNano()), 0))
+	sem := make(chan struct{}, r.opts.MaxInFlight)
+	var wg sync.WaitGroup
+
+	progressDone := make(chan struct{})
+	if r.opts.Progress != nil && r.opts.ProgressInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(r.opts.ProgressInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-progressDone:
+					return
+				case <-ticker.C:
+					r.opts.Progress(col.report(r.opts.Scenario, started, time.Since(started)))
+				}
+			}
+		}()
+	}
+
+	next := started
+	for {
+		elapsed := next.Sub(started)
+		if elapsed >= r.opts.Scenario.Duration {
+			break
+		}
+		rate := r.opts.Scenario.RateAt(elapsed)
+		if rate <= 0 {
+			// Laju nol di awal ramp, cek lagi sebentar kemudian
+			next = next.Add(10 * time.Millisecond)
+			continue
+		}
+		// Jarak antar kedatangan berdistribusi eksponensial (proses Poisson)
+		next = next.Add(time.Duration(rng.ExpFloat64() / rate * float64(time.Second)))
+
+		if wait := time.Until(next); wait > 0 {
+			select {
+			case <-ctx.Done():
+				return r.finish(col, started, &wg, progressDone), ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+
+		select {
+		case sem <- struct{}{}:
+		default:
+			col.skip()
+			continue
+		}
+		op := r.opts.Mix.pick(rng)
+		valid := rng.Float64() >= r.opts.FailureRatio
+		intended := next
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			r.execute(ctx, col, op, valid, intended)
+		}()
+	}
+	return r.finish(col, started, &wg, progressDone), nil
+}
+
+func (r *Runner) finish(col *collector, started time.Time, wg *sync.WaitGroup, progressDone chan struct{}) Report {
+	wg.Wait()
+	close(progressDone)
+	return col.report(r.opts.Scenario, started, time.Since(started))
+}
+
+// execute mengirim satu request. Latency dihitung dari waktu kedatangan terjadwal, bukan dari
+// saat request benar-benar dikirim, supaya antrean di sisi client tidak menyembunyikan lambatnya
+// service (coordinated omission).
+func (r *Runner) execute(ctx context.Context, col *collector, op string, valid bool, intended time.Time) {
+	var (
+		outcome string
+		ok      bool
+	)
+	switch op {
+	case OpRegister:
+		outcome, ok = r.outcome(r.register(ctx, valid))
+	case OpLogin:
+		user, found := r.randomUser()
+		if !found {
+			// Belum ada akun untuk login, daftarkan satu dulu
+			op = OpRegister
+			outcome, ok = r.outcome(r.register(ctx, valid))
+			break
+		}
+		outcome, ok = r.outcome(r.login(ctx, user, valid))
+	default:
+		// List user tidak punya payload, selalu diharapkan berhasil
+		outcome, ok = r.outcome(r.do(ctx, http.MethodGet, "/api/v1/users", nil))
+	}
+	col.record(op, outcome, ok, time.Since(intended))
+}
+
+type result struct {
+	status int
+	code   string
+	valid  bool
+}
+
+// outcome mengubah hasil request menjadi label breakdown dan status sesuai harapan
+func (r *Runner) outcome(res result, err error) (string, bool) {
+	if err != nil {
+		return classifyError(err), false
+	}
+	label := fmt.Sprintf("%d", res.status)
+	if res.code != "" {
+		label += " " + res.code
+	}
+	if res.valid {
+		return label, res.status >= 200 && res.status < 300
+	}
+	return label, res.status >= 400 && res.status < 500
+}
+
+func classifyError(err error) string {
+	var netErr interface{ Timeout() bool }
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "error timeout"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "error connection_refused"
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return "error connection_reset"
+	case errors.Is(err, context.Canceled):
+		return "error canceled"
+	default:
+		return "error transport"
+	}
+}
+
+// register mendaftarkan akun baru. Payload salah dipilih acak dari password lemah,
+// email tidak valid, atau username yang sudah dipakai.
+func (r *Runner) register(ctx context.Context, valid bool) (result, error) {
+	n := r.seq.Add(1)
+	user := credentials{
+		Username: fmt.Sprintf("lg_%s_%d", r.runID, n),
+		Password: fmt.Sprintf("Loadgen%d%s", n, rand.Text()[:8]),
+		Email:    fmt.Sprintf("lg_%s_%d@example.com", r.runID, n),
+	}
+	if !valid {
+		switch mrand.IntN(3) {
+		case 0:
+			user.Password = "short"
+		case 1:
+			user.Email = "not-an-email"
+		default:
+			if existing, ok := r.randomUser(); ok {
+				user.Username = existing.Username
+			} else {
+				user.Password = "short"
+			}
+		}
+	}
+
+	res, err := r.do(ctx, http.MethodPost, "/api/v1/register", user)
+	res.valid = valid
+	if err == nil && valid && res.status >= 200 && res.status < 300 {
+		r.addUser(user)
+	}
+	return res, err
+}
+
+// login masuk dengan akun hasil register. Payload salah dipilih acak dari password salah,
+// user yang tidak ada, atau JSON rusak.
+func (r *Runner) login(ctx context.Context, user credentials, valid bool) (result, error) {
+	body := any(credentials{Username: user.Username, Password: user.Password})
+	if !valid {
+		switch mrand.IntN(3) {
+		case 0:
+			body = credentials{Username: user.Username, Password: "wrong-" + user.Password}
+		case 1:
+			body = credentials{Username: fmt.Sprintf("lg_%s_missing_%d", r.runID, r.seq.Add(1)), Password: user.Password}
+		default:
+			body = json.RawMessage(`{"username":`)
+		}
+	}
+	res, err := r.do(ctx, http.MethodPost, "/api/v1/login", body)
+	res.valid = valid
+	return res, err
+}
+
+// do mengirim request dan membaca code dari problem detail bila response gagal
+func (r *Runner) do(ctx context.Context, method, path string, body any) (result, error) {
+	var reader io.Reader
+	if body != nil {
+		raw, ok := body.(json.RawMessage)
+		if !ok {
+			var err error
+			if raw, err = json.Marshal(body); err != nil {
+				return result{}, err
+			}
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, r.opts.Target+path, reader)
+	if err != nil {
+		return result{}, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return result{}, err
+	}
+	defer resp.Body.Close()
+
+	res := result{status: resp.StatusCode, valid: true}
+	if resp.StatusCode >= 400 {
+		var problem struct {
+			Code string `json:"code"`
+		}
+		if json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&problem) == nil {
+			res.code = problem.Code
+		}
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return res, nil
+}
+
+func (r *Runner) addUser(user credentials) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.users) < maxPoolUsers {
+		r.users = append(r.users, user)
+		return
+	}
+	r.users[mrand.IntN(len(r.users))] = user
+}
+
+func (r *Runner) randomUser() (credentials, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.users) == 0 {
+		return credentials{}, false
+	}
+	return r.users[mrand.IntN(len(r.users))], true
+}
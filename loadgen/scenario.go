@@ -0,0 +1,83 @@
+package loadgen
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Nama skenario yang didukung
+const (
+	ScenarioConstant = "constant"
+	ScenarioRamp     = "ramp"
+	ScenarioSpike    = "spike"
+	ScenarioSoak     = "soak"
+)
+
+// Scenario menentukan laju kedatangan request (request per detik) pada setiap titik waktu
+type Scenario struct {
+	Name     string
+	Duration time.Duration
+	// Rate adalah laju utama: laju tetap untuk constant dan soak, laju akhir untuk ramp,
+	// dan laju dasar untuk spike
+	Rate float64
+	// StartRate adalah laju awal skenario ramp
+	StartRate float64
+	// SpikeRate, SpikeAt dan SpikeDuration mengatur lonjakan pada skenario spike
+	SpikeRate     float64
+	SpikeAt       time.Duration
+	SpikeDuration time.Duration
+}
+
+// Validate memeriksa parameter skenario
+func (s Scenario) Validate() error {
+	if s.Duration <= 0 {
+		return fmt.Errorf("duration must be positive")
+	}
+	if s.Rate <= 0 {
+		return fmt.Errorf("rate must be positive")
+	}
+	switch s.Name {
+	case ScenarioConstant, ScenarioSoak:
+	case ScenarioRamp:
+		if s.StartRate < 0 {
+			return fmt.Errorf("start rate must not be negative")
+		}
+	case ScenarioSpike:
+		if s.SpikeRate <= s.Rate {
+			return fmt.Errorf("spike rate must be greater than the base rate")
+		}
+		if s.SpikeAt < 0 || s.SpikeDuration <= 0 || s.SpikeAt+s.SpikeDuration > s.Duration {
+			return fmt.Errorf("spike window must lie inside the test duration")
+		}
+	default:
+		return fmt.Errorf("unknown scenario %q", s.Name)
+	}
+	return nil
+}
+
+// RateAt mengembalikan laju kedatangan pada waktu elapsed sejak tes dimulai
+func (s Scenario) RateAt(elapsed time.Duration) float64 {
+	switch s.Name {
+	case ScenarioRamp:
+		progress := min(float64(elapsed)/float64(s.Duration), 1)
+		return s.StartRate + (s.Rate-s.StartRate)*progress
+	case ScenarioSpike:
+		if elapsed >= s.SpikeAt && elapsed < s.SpikeAt+s.SpikeDuration {
+			return s.SpikeRate
+		}
+		return s.Rate
+	default:
+		return s.Rate
+	}
+}
+
+// ExpectedRequests memperkirakan jumlah kedatangan selama skenario
+func (s Scenario) ExpectedRequests() int64 {
+	const step = 100 * time.Millisecond
+	total := 0.0
+	for t := time.Duration(0); t < s.Duration; t += step {
+		total += s.RateAt(t) * step.Seconds()
+	}
+	return int64(math.Round(total))
+}
@@ -0,0 +1,195 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Format output log yang didukung
+const (
+	FormatJSON   = "json"
+	FormatLogfmt = "logfmt"
+)
+
+const redacted = "[REDACTED]"
+
+// AccessLogOptions mengatur perilaku AccessLogger
+type AccessLogOptions struct {
+	Format          string
+	SampleRate      float64
+	AlwaysLogErrors bool
+	Redact          []string
+	// Level menyaring record berdasarkan level, biasanya Leveler(ModuleHTTP)
+	Level slog.Leveler
+}
+
+// AccessLogger menulis satu record slog terstruktur untuk setiap request.
+// Format dan sample rate dapat diganti saat runtime tanpa restart.
+type AccessLogger struct {
+	out             io.Writer
+	redact          map[string]struct{}
+	alwaysLogErrors bool
+	level           slog.Leveler
+
+	logger     atomic.Pointer[slog.Logger]
+	format     atomic.Value
+	sampleRate atomic.Uint64 // rasio sampling dalam satuan per sejuta
+}
+
+// NewAccessLogger membuat AccessLogger yang menulis ke out
+func NewAccessLogger(out io.Writer, opts AccessLogOptions) *AccessLogger {
+	l := &AccessLogger{
+		out:             out,
+		redact:          make(map[string]struct{}, len(opts.Redact)),
+		alwaysLogErrors: opts.AlwaysLogErrors,
+		level:           opts.Level,
+	}
+	for _, field := range opts.Redact {
+		l.redact[strings.ToLower(field)] = struct{}{}
+	}
+	if err := l.SetFormat(opts.Format); err != nil {
+		_ = l.SetFormat(FormatJSON)
+	}
+	l.SetSampleRate(opts.SampleRate)
+	return l
+}
+
+// SetFormat mengganti format output ("json" atau "logfmt")
+func (l *AccessLogger) SetFormat(format string) error {
+	handlerOpts := &slog.HandlerOptions{Level: l.level, ReplaceAttr: l.replaceAttr}
+
+	var h slog.Handler
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		h = slog.NewJSONHandler(l.out, handlerOpts)
+	case FormatLogfmt, "text":
+		h = slog.NewTextHandler(l.out, handlerOpts)
+		format = FormatLogfmt
+	default:
+		return fmt.Errorf("logging: unknown format %q", format)
+	}
+
+	l.logger.Store(slog.New(h).With(slog.String("module", ModuleHTTP)))
+	l.format.Store(strings.ToLower(format))
+	return nil
+}
+
+// Format mengembalikan format output yang sedang aktif
+func (l *AccessLogger) Format() string {
+	return l.format.Load().(string)
+}
+
+// SetSampleRate mengganti rasio request sukses yang dicatat, dibatasi ke 0.0 - 1.0
+func (l *AccessLogger) SetSampleRate(rate float64) {
+	rate = min(max(rate, 0), 1)
+	l.sampleRate.Store(uint64(rate * 1e6))
+}
+
+// SampleRate mengembalikan rasio sampling yang sedang aktif
+func (l *AccessLogger) SampleRate() float64 {
+	return float64(l.sampleRate.Load()) / 1e6
+}
+
+// Middleware mengembalikan gin middleware yang mencatat access log
+func (l *AccessLogger) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if !l.sampled(status) {
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unknown"
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("request_id", c.GetString(RequestIDKey)),
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("query", l.redactQuery(c.Request.URL.RawQuery)),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+			slog.String("client_ip", c.ClientIP()),
+			slog.String("user_agent", c.Request.UserAgent()),
+			slog.String("user", c.GetString(UserKey)),
+			slog.String("trace_id", traceID(c.GetHeader("traceparent"))),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.String()))
+		}
+
+		l.logger.Load().LogAttrs(context.Background(), level, "http request", attrs...)
+	}
+}
+
+// sampled menentukan apakah request ini perlu dicatat
+func (l *AccessLogger) sampled(status int) bool {
+	if l.alwaysLogErrors && status >= 400 {
+		return true
+	}
+	rate := l.sampleRate.Load()
+	return rate >= 1e6 || rand.Uint64N(1e6) < rate
+}
+
+func (l *AccessLogger) isRedacted(key string) bool {
+	_, ok := l.redact[strings.ToLower(key)]
+	return ok
+}
+
+func (l *AccessLogger) replaceAttr(_ []string, a slog.Attr) slog.Attr {
+	if l.isRedacted(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+	return a
+}
+
+// redactQuery menyamarkan nilai query parameter yang termasuk daftar redaksi
+func (l *AccessLogger) redactQuery(raw string) string {
+	if raw == "" || len(l.redact) == 0 {
+		return raw
+	}
+	pairs := strings.Split(raw, "&")
+	for i, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+		if l.isRedacted(key) {
+			pairs[i] = key + "=" + redacted
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// traceID mengambil trace-id dari header W3C traceparent (version-traceid-spanid-flags)
+func traceID(traceparent string) string {
+	parts := strings.Split(traceparent, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 {
+		return ""
+	}
+	return parts[1]
+}
@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// GormLogger meneruskan log GORM ke logger slog modul db.
+// Query biasa dicatat di level debug, query lambat di warn dan error di level error.
+type GormLogger struct {
+	SlowThreshold time.Duration
+}
+
+// NewGormLogger membuat adapter logger GORM
+func NewGormLogger(slowThreshold time.Duration) *GormLogger {
+	return &GormLogger{SlowThreshold: slowThreshold}
+}
+
+// LogMode tidak dipakai karena level diatur lewat modul db
+func (g *GormLogger) LogMode(gormlogger.LogLevel) gormlogger.Interface {
+	return g
+}
+
+func (g *GormLogger) Info(ctx context.Context, msg string, args ...any) {
+	g.log(ctx, slog.LevelInfo, msg, args...)
+}
+
+func (g *GormLogger) Warn(ctx context.Context, msg string, args ...any) {
+	g.log(ctx, slog.LevelWarn, msg, args...)
+}
+
+func (g *GormLogger) Error(ctx context.Context, msg string, args ...any) {
+	g.log(ctx, slog.LevelError, msg, args...)
+}
+
+func (g *GormLogger) log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	logger := Logger(ModuleDB)
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	logger.Log(ctx, level, msg, slog.Any("args", args), slog.String("request_id", RequestIDFromContext(ctx)))
+}
+
+// Trace dipanggil GORM setelah setiap query selesai
+func (g *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	logger := Logger(ModuleDB)
+	elapsed := time.Since(begin)
+
+	level := slog.LevelDebug
+	msg := "query"
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		level, msg = slog.LevelError, "query failed"
+	case g.SlowThreshold > 0 && elapsed > g.SlowThreshold:
+		level, msg = slog.LevelWarn, "slow query"
+	}
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []slog.Attr{
+		slog.String("sql", sql),
+		slog.Int64("rows", rows),
+		slog.Float64("elapsed_ms", float64(elapsed.Microseconds())/1000),
+		slog.String("request_id", RequestIDFromContext(ctx)),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// ParamsFilter membuang nilai parameter dari SQL yang dicatat supaya password
+// dan data sensitif lain tidak pernah masuk ke log
+func (g *GormLogger) ParamsFilter(_ context.Context, sql string, _ ...any) (string, []any) {
+	return sql, nil
+}
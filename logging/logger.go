@@ -0,0 +1,204 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Nama modul logger yang tersedia
+const (
+	ModuleApp     = "app"
+	ModuleHandler = "handler"
+	ModuleMetric  = "metric"
+	ModuleDB      = "db"
+	ModuleHTTP    = "http"
+)
+
+// Modules adalah daftar semua modul logger yang dikenal
+var Modules = []string{ModuleApp, ModuleHandler, ModuleMetric, ModuleDB, ModuleHTTP}
+
+type module struct {
+	level     *slog.LevelVar
+	base      slog.Level
+	logger    *slog.Logger
+	revert    *time.Timer
+	expiresAt time.Time
+}
+
+// LevelStatus menggambarkan level sebuah modul saat ini
+type LevelStatus struct {
+	Module    string     `json:"module"`
+	Level     string     `json:"level"`
+	Base      string     `json:"base"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+var (
+	mu      sync.Mutex
+	modules           = map[string]*module{}
+	out     io.Writer = os.Stdout
+	format            = FormatJSON
+)
+
+func init() {
+	for _, name := range Modules {
+		modules[name] = newModule(name, slog.LevelInfo)
+	}
+}
+
+// Setup mengatur output, format dan level awal semua modul.
+// levels berisi override per modul, misalnya {"db": "debug"}.
+func Setup(w io.Writer, logFormat, defaultLevel string, levels map[string]string) error {
+	base, err := ParseLevel(defaultLevel)
+	if err != nil {
+		return err
+	}
+	logFormat = strings.ToLower(logFormat)
+	if logFormat != FormatJSON && logFormat != FormatLogfmt {
+		return fmt.Errorf("logging: unknown format %q", logFormat)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	out, format = w, logFormat
+	for _, name := range Modules {
+		level := base
+		if raw, ok := levels[name]; ok {
+			if level, err = ParseLevel(raw); err != nil {
+				return fmt.Errorf("logging: module %s: %w", name, err)
+			}
+		}
+		if m, ok := modules[name]; ok && m.revert != nil {
+			m.revert.Stop()
+		}
+		modules[name] = newModule(name, level)
+	}
+
+	slog.SetDefault(modules[ModuleApp].logger)
+	return nil
+}
+
+func newModule(name string, level slog.Level) *module {
+	lv := new(slog.LevelVar)
+	lv.Set(level)
+
+	opts := &slog.HandlerOptions{Level: lv}
+	var h slog.Handler
+	if format == FormatLogfmt {
+		h = slog.NewTextHandler(out, opts)
+	} else {
+		h = slog.NewJSONHandler(out, opts)
+	}
+
+	return &module{
+		level:  lv,
+		base:   level,
+		logger: slog.New(h).With(slog.String("module", name)),
+	}
+}
+
+// Logger mengembalikan logger milik modul name. Modul yang tidak dikenal memakai logger app.
+func Logger(name string) *slog.Logger {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if m, ok := modules[name]; ok {
+		return m.logger
+	}
+	return modules[ModuleApp].logger
+}
+
+// Leveler mengembalikan level dinamis milik modul, berguna untuk handler slog lain
+func Leveler(name string) slog.Leveler {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if m, ok := modules[name]; ok {
+		return m.level
+	}
+	return modules[ModuleApp].level
+}
+
+// SetLevel mengganti level modul. Bila ttl > 0 level kembali ke level dasar setelah ttl berlalu.
+func SetLevel(name string, level slog.Level, ttl time.Duration) (LevelStatus, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	m, ok := modules[name]
+	if !ok {
+		return LevelStatus{}, fmt.Errorf("logging: unknown module %q", name)
+	}
+
+	if m.revert != nil {
+		m.revert.Stop()
+		m.revert = nil
+		m.expiresAt = time.Time{}
+	}
+
+	m.level.Set(level)
+	if ttl > 0 {
+		m.expiresAt = time.Now().Add(ttl)
+		m.revert = time.AfterFunc(ttl, func() { revertLevel(name, m) })
+	} else {
+		// Tanpa TTL, level baru menjadi level dasar modul
+		m.base = level
+	}
+
+	return m.status(name), nil
+}
+
+func revertLevel(name string, m *module) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	// Abaikan bila modul sudah diganti oleh Setup
+	if modules[name] != m {
+		return
+	}
+	m.level.Set(m.base)
+	m.revert = nil
+	m.expiresAt = time.Time{}
+	m.logger.Info("log level reverted", slog.String("level", m.base.String()))
+}
+
+// Levels mengembalikan status level semua modul, terurut berdasarkan nama
+func Levels() []LevelStatus {
+	mu.Lock()
+	defer mu.Unlock()
+
+	statuses := make([]LevelStatus, 0, len(modules))
+	for name, m := range modules {
+		statuses = append(statuses, m.status(name))
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Module < statuses[j].Module })
+	return statuses
+}
+
+func (m *module) status(name string) LevelStatus {
+	s := LevelStatus{
+		Module: name,
+		Level:  strings.ToLower(m.level.Level().String()),
+		Base:   strings.ToLower(m.base.String()),
+	}
+	if !m.expiresAt.IsZero() {
+		expiresAt := m.expiresAt
+		s.ExpiresAt = &expiresAt
+	}
+	return s
+}
+
+// ParseLevel mengubah string seperti "debug" atau "warn" menjadi slog.Level
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return level, fmt.Errorf("logging: invalid level %q", s)
+	}
+	return level, nil
+}
@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HeaderRequestID adalah header yang dipakai untuk membawa request ID antar service
+const HeaderRequestID = "X-Request-ID"
+
+// RequestIDKey adalah key di gin.Context tempat request ID disimpan
+const RequestIDKey = "request_id"
+
+// UserKey adalah key di gin.Context tempat identitas user yang terautentikasi disimpan
+const UserKey = "user"
+
+type requestIDCtxKey struct{}
+
+// RequestID memakai X-Request-ID dari client bila valid, atau membuat yang baru,
+// lalu menyimpannya di context request dan header response
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(HeaderRequestID)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		c.Set(RequestIDKey, id)
+		c.Request = c.Request.WithContext(WithRequestID(c.Request.Context(), id))
+		c.Writer.Header().Set(HeaderRequestID, id)
+
+		c.Next()
+	}
+}
+
+// WithRequestID mengembalikan context turunan yang membawa request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDCtxKey{}, id)
+}
+
+// RequestIDFromContext mengambil request ID dari context, string kosong bila tidak ada
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDCtxKey{}).(string)
+	return id
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validRequestID menolak ID kosong, terlalu panjang, atau berisi karakter non-printable
+// supaya nilai dari client tidak bisa dipakai untuk log injection
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileMailer menyimpan setiap email sebagai file .eml di Dir, berguna untuk development
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+func (m *FileMailer) Send(_ context.Context, msg Message) error {
+	if msg.From == "" {
+		msg.From = m.From
+	}
+	body, err := encode(msg)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), sanitize(msg.To))
+	return os.WriteFile(filepath.Join(m.Dir, name), body, 0o600)
+}
+
+// WriterMailer menulis email ke io.Writer, misalnya os.Stdout
+type WriterMailer struct {
+	W    io.Writer
+	From string
+
+	mu sync.Mutex
+}
+
+func (m *WriterMailer) Send(_ context.Context, msg Message) error {
+	if msg.From == "" {
+		msg.From = m.From
+	}
+	body, err := encode(msg)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, err = fmt.Fprintf(m.W, "%s\n\n", body)
+	return err
+}
+
+func sanitize(s string) string {
+	out := []rune(s)
+	for i, r := range out {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '@' || r == '-' || r == '_') {
+			out[i] = '_'
+		}
+	}
+	return string(out)
+}
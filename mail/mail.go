@@ -0,0 +1,116 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// Message adalah email yang akan dikirim, Text dan HTML dikirim sebagai multipart/alternative
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer mengirim email. Implementasi tersedia untuk SMTP, file dan stdout.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var (
+	textTemplates = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/*.txt.tmpl"))
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/*.html.tmpl"))
+)
+
+// Template email yang tersedia
+const (
+	TemplateVerifyEmail   = "verify_email"
+	TemplatePasswordReset = "password_reset"
+)
+
+var subjects = map[string]string{
+	TemplateVerifyEmail:   "Verify your email address",
+	TemplatePasswordReset: "Reset your password",
+}
+
+// Render membuat Message dari template name (tanpa ekstensi) dengan data yang diberikan
+func Render(name, to string, data any) (Message, error) {
+	var text, html bytes.Buffer
+	if err := textTemplates.ExecuteTemplate(&text, name+".txt.tmpl", data); err != nil {
+		return Message{}, fmt.Errorf("mail: render %s text: %w", name, err)
+	}
+	if err := htmlTemplates.ExecuteTemplate(&html, name+".html.tmpl", data); err != nil {
+		return Message{}, fmt.Errorf("mail: render %s html: %w", name, err)
+	}
+	return Message{
+		To:      to,
+		Subject: subjects[name],
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// encode menulis Message sebagai email MIME lengkap (RFC 5322)
+func encode(msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	id := make([]byte, 12)
+	_, _ = rand.Read(id)
+	domain := "localhost"
+	if _, host, ok := strings.Cut(msg.From, "@"); ok {
+		domain = strings.Trim(host, "> ")
+	}
+
+	headers := []string{
+		"From: " + msg.From,
+		"To: " + msg.To,
+		"Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"Message-ID: <" + hex.EncodeToString(id) + "@" + domain + ">",
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary=" + mw.Boundary(),
+	}
+	var out bytes.Buffer
+	out.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n")
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		if part.body == "" {
+			continue
+		}
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"8bit"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write([]byte(strings.ReplaceAll(part.body, "\n", "\r\n"))); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	out.Write(buf.Bytes())
+	return out.Bytes(), nil
+}
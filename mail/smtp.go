@@ -0,0 +1,116 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPMailer mengirim email lewat server SMTP. Tanpa Username, email dikirim tanpa
+// autentikasi sehingga bisa dites dengan SMTP lokal seperti MailHog.
+type SMTPMailer struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	// Timeout membatasi satu pengiriman bila ctx tidak punya deadline, default 30 detik
+	Timeout time.Duration
+}
+
+// Send mengirim msg. Deadline ctx dipasang di koneksi dan pembatalan ctx langsung menutup
+// percakapan SMTP, sehingga server yang hang tidak meninggalkan goroutine dan koneksi.
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if msg.From == "" {
+		msg.From = m.From
+	}
+	body, err := encode(msg)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		timeout := m.Timeout
+		if timeout <= 0 {
+			timeout = 30 * time.Second
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	addr := net.JoinHostPort(m.Host, strconv.Itoa(m.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	err = m.send(conn, msg, body)
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	// Deadline koneksi bisa lewat sesaat sebelum ctx ikut selesai
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() && !time.Now().Before(deadline) {
+		return context.DeadlineExceeded
+	}
+	return err
+}
+
+// send menjalankan percakapan SMTP seperti smtp.SendMail di atas koneksi yang sudah dibuka
+func (m *SMTPMailer) send(conn net.Conn, msg Message, body []byte) error {
+	c, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: m.Host}); err != nil {
+			return err
+		}
+	}
+	if m.Username != "" {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err := c.Auth(smtp.PlainAuth("", m.Username, m.Password, m.Host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(m.From); err != nil {
+		return err
+	}
+	if err := c.Rcpt(msg.To); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
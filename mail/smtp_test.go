@@ -0,0 +1,174 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpServer adalah server SMTP minimal untuk test. Bila hang, server menerima koneksi
+// tanpa pernah mengirim greeting.
+type smtpServer struct {
+	ln       net.Listener
+	hang     bool
+	received chan string
+	closed   chan struct{}
+}
+
+func newSMTPServer(t *testing.T, hang bool) *smtpServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &smtpServer{ln: ln, hang: hang, received: make(chan string, 1), closed: make(chan struct{})}
+	t.Cleanup(func() { _ = ln.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *smtpServer) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpServer) serve() {
+	conn, err := s.ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	if s.hang {
+		// Read kembali saat client menutup koneksi
+		_, _ = conn.Read(make([]byte, 1))
+		close(s.closed)
+		return
+	}
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+	reply("220 localhost ESMTP test")
+	var data strings.Builder
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"):
+			reply("250-localhost")
+			reply("250 8BITMIME")
+		case strings.HasPrefix(cmd, "MAIL FROM"), strings.HasPrefix(cmd, "RCPT TO"):
+			reply("250 OK")
+			data.WriteString(strings.TrimSpace(line) + "\n")
+		case cmd == "DATA":
+			reply("354 end with .")
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			reply("250 queued")
+			s.received <- data.String()
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func TestSMTPMailerSend(t *testing.T) {
+	srv := newSMTPServer(t, false)
+	m := &SMTPMailer{Host: "127.0.0.1", Port: srv.port(), From: "noreply@example.com"}
+
+	msg, err := Render(TemplateVerifyEmail, "alice@example.com", map[string]any{"Username": "alice", "Link": "http://localhost/verify?token=abc", "ExpiresIn": "24h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	select {
+	case got := <-srv.received:
+		for _, want := range []string{
+			"MAIL FROM:<noreply@example.com>",
+			"RCPT TO:<alice@example.com>",
+			"To: alice@example.com",
+			"Subject: Verify your email address",
+			"multipart/alternative",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("message does not contain %q:\n%s", want, got)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not receive the message")
+	}
+}
+
+func TestSMTPMailerHungServer(t *testing.T) {
+	srv := newSMTPServer(t, true)
+	m := &SMTPMailer{Host: "127.0.0.1", Port: srv.port(), From: "noreply@example.com"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := m.Send(ctx, Message{To: "alice@example.com", Subject: "hi", Text: "hi"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Send error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("Send returned after %s, want about 100ms", elapsed)
+	}
+
+	// Koneksi harus ditutup, bukan ditinggal bersama goroutine yang menunggu greeting
+	select {
+	case <-srv.closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("connection to the hung server was not closed")
+	}
+}
+
+func TestSMTPMailerCancel(t *testing.T) {
+	srv := newSMTPServer(t, true)
+	m := &SMTPMailer{Host: "127.0.0.1", Port: srv.port(), From: "noreply@example.com", Timeout: time.Minute}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	err := m.Send(ctx, Message{To: "alice@example.com", Subject: "hi", Text: "hi"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Send error = %v, want context.Canceled", err)
+	}
+	select {
+	case <-srv.closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("connection was not closed after cancel")
+	}
+}
+
+func TestSMTPMailerRefused(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	_ = ln.Close()
+
+	m := &SMTPMailer{Host: "127.0.0.1", Port: port, From: "noreply@example.com"}
+	if err := m.Send(context.Background(), Message{To: "alice@example.com", Text: "hi"}); err == nil {
+		t.Fatal("Send to a closed port succeeded, port " + strconv.Itoa(port))
+	}
+}
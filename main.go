@@ -2,25 +2,51 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	"log"
+	"io"
 	"log/slog"
+	"maps"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
+	"wyw/alert"
+	"wyw/audit"
+	"wyw/capture"
+	"wyw/chaos"
+	"wyw/config"
 	"wyw/docs"
 
 	"wyw/entity"
 	"wyw/handler"
+	"wyw/idempotency"
+	"wyw/live"
+	"wyw/logging"
+	"wyw/mail"
 	"wyw/metric"
+	"wyw/middleware"
+	"wyw/otlp"
+	"wyw/outbox"
+	"wyw/probe"
+	"wyw/push"
+	"wyw/session"
+	"wyw/slo"
+	"wyw/sso"
+	"wyw/statsd"
+	"wyw/token"
+	"wyw/validation"
+	"wyw/webhook"
 
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -31,22 +57,25 @@ var (
 	InstanceDB *gorm.DB
 )
 
-func getInstance() *gorm.DB {
+// dbStatsName adalah label db_name metrik connection pool
+const dbStatsName = "wyw"
+
+func getInstance(cfg config.DBConfig) *gorm.DB {
 	Once.Do(func() {
 		//dsn := fmt.Sprintf("root:rootpassword@tcp(mysql:3306)/testdb?charset=utf8mb4&parseTime=True&loc=Local")
-		dsn := fmt.Sprintf("root:korie123@tcp(localhost:3306)/hehey?charset=utf8mb4&parseTime=True&loc=Local")
-		db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-			Logger: nil,
+		db, err := gorm.Open(mysql.Open(cfg.DSN), &gorm.Config{
+			Logger:         logging.NewGormLogger(cfg.SlowThreshold),
+			TranslateError: true,
 		})
 		if err != nil {
-			log.Fatalf("got error dial mysql %v", err)
+			fatal("got error dial mysql", err)
 		}
 
-		_ = db.AutoMigrate(&entity.User{})
+		_ = db.AutoMigrate(&entity.User{}, &entity.UserMFA{}, &entity.RecoveryCode{}, &entity.MFAChallengeAttempt{}, &entity.UserIdentity{})
 		// SETUP CONNECTION POOL
 		sqlDB, err := db.DB()
 		if err != nil {
-			log.Fatalf("failed get instance connection %v", err)
+			fatal("failed get instance connection", err)
 		}
 		sqlDB.SetMaxOpenConns(10)
 		sqlDB.SetMaxIdleConns(5)
@@ -58,18 +87,223 @@ func getInstance() *gorm.DB {
 	return InstanceDB
 }
 
-func CustomCORSMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS, PUT, DELETE")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-API-Key")
-		if c.Request.Method == "OPTIONS" {
-			fmt.Println("Handling OPTIONS method")
-			c.AbortWithStatus(http.StatusOK)
-			return
+// fatal mencatat error lewat logger app lalu menghentikan proses
+func fatal(msg string, err error) {
+	logging.Logger(logging.ModuleApp).Error(msg, slog.Any("error", err))
+	os.Exit(1)
+}
+
+// newMailer memilih implementasi Mailer sesuai MAIL_DRIVER
+func newMailer(cfg config.MailConfig) mail.Mailer {
+	switch cfg.Driver {
+	case "smtp":
+		return &mail.SMTPMailer{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.From,
+		}
+	case "file":
+		return &mail.FileMailer{Dir: cfg.Dir, From: cfg.From}
+	default:
+		return &mail.WriterMailer{W: os.Stdout, From: cfg.From}
+	}
+}
+
+// waitTimeout menunggu wg selesai paling lama timeout, false bila waktunya habis
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// auditOptions mengembalikan opsi audit log, dengan peringatan bila rantai hash tanpa kunci
+func auditOptions(cfg config.AuditConfig) audit.Options {
+	if cfg.Key == "" {
+		logging.Logger(logging.ModuleApp).Warn("AUDIT_KEY is not set, the audit hash chain is unkeyed and can be recomputed by anyone with database write access")
+	}
+	return audit.Options{Key: []byte(cfg.Key), AnchorPath: cfg.AnchorPath}
+}
+
+// tokenSecret mengembalikan secret dari konfigurasi, atau secret acak bila kosong
+func tokenSecret(secret string) []byte {
+	if secret != "" {
+		return []byte(secret)
+	}
+	logging.Logger(logging.ModuleApp).Warn("TOKEN_SECRET is not set, using a random secret; tokens will not survive a restart")
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		fatal("failed to generate token secret", err)
+	}
+	return b
+}
+
+// newOutboxSinks membuat sink outbox sesuai konfigurasi
+func newOutboxSinks(cfg config.OutboxConfig) ([]outbox.Sink, error) {
+	var sinks []outbox.Sink
+	for _, name := range cfg.Sinks {
+		switch name {
+		case "webhook":
+			if cfg.WebhookURL == "" {
+				return nil, errors.New("OUTBOX_WEBHOOK_URL is required for the webhook sink")
+			}
+			sinks = append(sinks, outbox.NewWebhookSink(cfg.WebhookURL, cfg.WebhookTimeout))
+		case "nats":
+			sink, err := outbox.NewNATSSink(cfg.NATSURL, cfg.NATSSubject)
+			if err != nil {
+				return nil, fmt.Errorf("connect nats: %w", err)
+			}
+			sinks = append(sinks, sink)
+		case "kafka":
+			sinks = append(sinks, outbox.NewKafkaSink(cfg.KafkaBrokers, cfg.KafkaTopic))
+		case "file":
+			sink, err := outbox.NewFileSink(cfg.FilePath)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+		default:
+			return nil, fmt.Errorf("unknown outbox sink %q", name)
 		}
-		c.Next()
 	}
+	return sinks, nil
+}
+
+// newProbes membuat probe sintetis sesuai konfigurasi
+func newProbes(cfg config.ProbeConfig, db *gorm.DB) ([]probe.Probe, error) {
+	checks := probe.DefaultChecks
+	if cfg.Checks != "" {
+		checks = nil
+		if err := json.Unmarshal([]byte(cfg.Checks), &checks); err != nil {
+			return nil, fmt.Errorf("PROBE_CHECKS: %w", err)
+		}
+	}
+
+	client := &http.Client{Timeout: cfg.Timeout}
+	var probes []probe.Probe
+	for _, check := range checks {
+		p, err := probe.NewHTTPProbe(check, cfg.BaseURL, client)
+		if err != nil {
+			return nil, err
+		}
+		probes = append(probes, p)
+	}
+	if cfg.DB {
+		probes = append(probes, probe.NewDBProbe(db))
+	}
+	if cfg.CanaryUsername != "" {
+		probes = append(probes, probe.NewLoginProbe(cfg.BaseURL, cfg.CanaryUsername, cfg.CanaryPassword, client))
+	}
+	return probes, nil
+}
+
+// newSLOs membaca definisi SLO dari konfigurasi
+func newSLOs(cfg config.SLOConfig) ([]slo.SLO, error) {
+	defs := slo.DefaultDefinitions
+	if cfg.Definitions != "" {
+		defs = nil
+		if err := json.Unmarshal([]byte(cfg.Definitions), &defs); err != nil {
+			return nil, fmt.Errorf("SLO_DEFINITIONS: %w", err)
+		}
+	}
+	return slo.Compile(defs, metric.HTTPDurationBuckets)
+}
+
+// metricsBackends memeriksa METRICS_BACKENDS dan mengembalikan backend yang aktif
+func metricsBackends(cfg config.MetricsConfig) (promEnabled, statsdEnabled bool, err error) {
+	for _, name := range cfg.Backends {
+		switch name {
+		case "prometheus":
+			promEnabled = true
+		case "statsd":
+			statsdEnabled = true
+		default:
+			return false, false, fmt.Errorf("METRICS_BACKENDS: unknown backend %q", name)
+		}
+	}
+	return promEnabled, statsdEnabled, nil
+}
+
+// newAlertEngine membaca rule dan inhibit rule dari konfigurasi lalu membuat alert engine
+func newAlertEngine(cfg config.AlertConfig, metrics *metric.AppMetricsExporter) (*alert.Engine, error) {
+	rules := alert.DefaultRules
+	if cfg.Rules != "" {
+		rules = nil
+		if err := json.Unmarshal([]byte(cfg.Rules), &rules); err != nil {
+			return nil, fmt.Errorf("ALERT_RULES: %w", err)
+		}
+	}
+	inhibit := alert.DefaultInhibitRules
+	if cfg.Inhibit != "" {
+		inhibit = nil
+		if err := json.Unmarshal([]byte(cfg.Inhibit), &inhibit); err != nil {
+			return nil, fmt.Errorf("ALERT_INHIBIT: %w", err)
+		}
+	}
+	return alert.NewEngine(rules, metrics, alert.Options{
+		Interval:     cfg.Interval,
+		InhibitRules: inhibit,
+		Notifier: alert.NotifierOptions{
+			WebhookURLs:    cfg.WebhookURLs,
+			Timeout:        cfg.WebhookTimeout,
+			GroupBy:        cfg.GroupBy,
+			GroupWait:      cfg.GroupWait,
+			GroupInterval:  cfg.GroupInterval,
+			RepeatInterval: cfg.RepeatInterval,
+			ExternalURL:    cfg.ExternalURL,
+		},
+	})
+}
+
+// withInstance mengisi label instance dengan hostname bila belum ada, supaya beberapa replika
+// yang push ke tujuan yang sama tidak saling menimpa
+func withInstance(labels map[string]string) map[string]string {
+	out := maps.Clone(labels)
+	if out == nil {
+		out = map[string]string{}
+	}
+	if _, ok := out["instance"]; !ok {
+		if host, err := os.Hostname(); err == nil {
+			out["instance"] = host
+		}
+	}
+	return out
+}
+
+// newRelyingParty membuat OIDC relying party, mapping "grup=role" dibaca berurutan
+func newRelyingParty(cfg config.OIDCConfig) *sso.RelyingParty {
+	var mapping []sso.RoleMapping
+	for _, item := range cfg.RoleMapping {
+		group, role, ok := strings.Cut(item, "=")
+		if !ok {
+			logging.Logger(logging.ModuleApp).Warn("ignoring invalid OIDC role mapping", slog.String("mapping", item))
+			continue
+		}
+		mapping = append(mapping, sso.RoleMapping{Group: strings.TrimSpace(group), Role: strings.TrimSpace(role)})
+	}
+	if cfg.StateSecret == "" {
+		logging.Logger(logging.ModuleApp).Warn("OIDC_STATE_SECRET is not set, using a random secret; logins in progress will not survive a restart")
+	}
+	return sso.NewRelyingParty(sso.Config{
+		IssuerURL:    cfg.IssuerURL,
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Scopes:       cfg.Scopes,
+		GroupsClaim:  cfg.GroupsClaim,
+		RoleMapping:  mapping,
+		DefaultRole:  cfg.DefaultRole,
+		StateSecret:  []byte(cfg.StateSecret),
+	})
 }
 
 // @title 	Tag Example Monitoring Service
@@ -77,22 +311,335 @@ func CustomCORSMiddleware() gin.HandlerFunc {
 // @description A Tag service API in Go using Gin framework
 // @host 	localhost:8080
 // @BasePath /api/v1
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Session token from /login, sent as "Bearer <token>"
 func main() {
-	db := getInstance()
+	cfg := config.Load()
+	if err := logging.Setup(os.Stdout, cfg.Log.Format, cfg.Log.Level, cfg.Log.Levels); err != nil {
+		fatal("invalid log configuration", err)
+	}
+	appLog := logging.Logger(logging.ModuleApp)
+
+	// SETUP VALIDATOR kustom (username, password) dan translasi pesan error
+	if err := validation.Register(validation.PasswordPolicy(cfg.Password)); err != nil {
+		fatal("failed to register validators", err)
+	}
+
+	// Subcommand seperti "audit verify" dijalankan lalu keluar tanpa menyalakan server
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(cfg, os.Args[1:]))
+	}
+
+	db := getInstance(cfg.DB)
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.New()
-	r.Use(gin.Recovery())
-	//SETUP CORS
-	r.Use(CustomCORSMiddleware())
+	// Route streaming didaftarkan lewat streaming.GET supaya dikecualikan dari TimeoutHandler
+	streaming := middleware.NewStreamingRoutes()
+
+	// SETUP REQUEST ID & ACCESS LOG, dipasang sebelum Recovery agar panic tetap tercatat
+	accessLogger := logging.NewAccessLogger(os.Stdout, logging.AccessLogOptions{
+		Format:          cfg.AccessLog.Format,
+		SampleRate:      cfg.AccessLog.SampleRate,
+		AlwaysLogErrors: cfg.AccessLog.AlwaysLogErrors,
+		Redact:          cfg.AccessLog.Redact,
+		Level:           logging.Leveler(logging.ModuleHTTP),
+	})
+	r.Use(logging.RequestID(), accessLogger.Middleware())
+	r.Use(middleware.Recovery())
 	docs.SwaggerInfo.BasePath = "/api/v1"
 
 	// Inisialisasi collector metrik, and implemtntation into midddleware
+	promEnabled, statsdEnabled, err := metricsBackends(cfg.Metrics)
+	if err != nil {
+		fatal("invalid metrics configuration", err)
+	}
 	metrics := metric.NewAppMetricsExporter()
+	if sqlDB, err := db.DB(); err == nil {
+		metrics.RegisterDBStats(sqlDB, dbStatsName)
+	}
 	r.Use(metrics.GinMiddleware())
 
+	// SETUP CAPTURE, request tersampel direkam ke JSONL untuk di-replay dengan "wyw replay"
+	var recorder *capture.Recorder
+	if cfg.Capture.Enabled {
+		var err error
+		if recorder, err = capture.NewRecorder(capture.Options{
+			Path:         cfg.Capture.Path,
+			SampleRate:   cfg.Capture.SampleRate,
+			MaxBodyBytes: cfg.Capture.MaxBodyBytes,
+			Redact:       cfg.Capture.Redact,
+			Skip:         streaming.Match,
+		}, metrics); err != nil {
+			fatal("failed to open capture file", err)
+		}
+		r.Use(recorder.Middleware())
+	}
+
+	// SETUP ERROR HANDLER, merender error dari c.Error sebagai application/problem+json
+	r.Use(middleware.ErrorHandler())
+	r.NoRoute(middleware.NoRoute)
+
+	//SETUP CORS
+	corsPolicy := middleware.CORSPolicy{
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
+		AllowedHeaders:   cfg.CORS.AllowedHeaders,
+		ExposedHeaders:   cfg.CORS.ExposedHeaders,
+		AllowCredentials: cfg.CORS.AllowCredentials,
+		MaxAge:           cfg.CORS.MaxAge,
+	}
+	adminCORSPolicy := corsPolicy
+	adminCORSPolicy.AllowedOrigins = cfg.CORS.AdminAllowedOrigins
+	corsMiddleware, err := middleware.NewCORS(middleware.CORSConfig{
+		Default:   corsPolicy,
+		Overrides: map[string]middleware.CORSPolicy{"/api/v1/admin": adminCORSPolicy},
+	}, r.Routes, metrics)
+	if err != nil {
+		fatal("invalid cors configuration", err)
+	}
+	r.Use(corsMiddleware)
+
+	//SETUP HARDENING: security headers & body size limit
+	securityHeaders := middleware.DefaultSecurityHeaders
+	securityHeaders.StrictTransportSecurity = ""
+	if cfg.Server.HSTSMaxAge > 0 {
+		securityHeaders.StrictTransportSecurity = fmt.Sprintf("max-age=%d; includeSubDomains", int(cfg.Server.HSTSMaxAge.Seconds()))
+	}
+	// Swagger UI butuh inline script & style
+	docsHeaders := securityHeaders
+	docsHeaders.ContentSecurityPolicy = "default-src 'self'; script-src 'self' 'unsafe-inline'; style-src 'self' 'unsafe-inline'; img-src 'self' data:"
+	docsHeaders.FrameOptions = "SAMEORIGIN"
+	r.Use(middleware.SecureHeaders(securityHeaders, map[string]middleware.SecurityHeaders{"/docs": docsHeaders}))
+	r.Use(middleware.BodyLimit(cfg.Server.MaxBodyBytes, nil, metrics))
+
+	// SETUP CHAOS, fault injection untuk menguji alert dan dashboard, nonaktif secara default
+	var injector *chaos.Injector
+	if cfg.Chaos.Enabled {
+		injector = chaos.NewInjector(metrics, chaos.Options{
+			MaxTTL: cfg.Chaos.MaxTTL,
+			Exempt: []string{"/api/v1/admin/chaos"},
+		})
+		if err := injector.RegisterCallbacks(db); err != nil {
+			fatal("failed to register chaos callbacks", err)
+		}
+		r.Use(injector.Middleware())
+		appLog.Warn("chaos fault injection is enabled")
+	}
+
+	// Context untuk goroutine background, dibatalkan saat shutdown
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	// background menghitung Run yang masih berjalan supaya shutdown bisa menunggunya
+	var background sync.WaitGroup
+	runBackground := func(run func(context.Context)) {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			run(backgroundCtx)
+		}()
+	}
+
+	// SETUP IDEMPOTENCY STORE untuk endpoint POST yang membuat data
+	var idempotencyStore idempotency.Store = idempotency.NewMemoryStore()
+	if cfg.Idempotency.Store == "gorm" {
+		if idempotencyStore, err = idempotency.NewGormStore(db); err != nil {
+			fatal("failed to migrate idempotency store", err)
+		}
+	}
+	idempotency.StartJanitor(backgroundCtx, idempotencyStore, time.Hour)
+	idempotent := middleware.Idempotency(idempotencyStore, cfg.Idempotency.TTL, metrics)
+
 	//INJECT HANDLER
-	userHandler := handler.NewUserHandler(db, metrics)
-	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	// SETUP OUTBOX, event bisnis dikirim ke sink oleh relay di background
+	if err := outbox.Migrate(db); err != nil {
+		fatal("failed to migrate outbox", err)
+	}
+	if err := webhook.Migrate(db); err != nil {
+		fatal("failed to migrate webhooks", err)
+	}
+	webhooks := webhook.NewDispatcher(db, metrics, webhook.Options{
+		Timeout:     cfg.Webhook.Timeout,
+		Concurrency: cfg.Webhook.Concurrency,
+		MinBackoff:  cfg.Webhook.MinBackoff,
+		MaxBackoff:  cfg.Webhook.MaxBackoff,
+		MaxAttempts: cfg.Webhook.MaxAttempts,
+	})
+	runBackground(webhooks.Run)
+	if injector != nil {
+		runBackground(injector.Run)
+	}
+	var prober *probe.Prober
+	if cfg.Probe.Enabled {
+		probes, err := newProbes(cfg.Probe, db)
+		if err != nil {
+			fatal("invalid probe configuration", err)
+		}
+		prober = probe.NewProber(probes, metrics, probe.Options{
+			Interval:  cfg.Probe.Interval,
+			Timeout:   cfg.Probe.Timeout,
+			Retention: cfg.Probe.Retention,
+		})
+		runBackground(prober.Run)
+	}
+	var outboxSinks []outbox.Sink
+	if cfg.Outbox.RelayEnabled {
+		if outboxSinks, err = newOutboxSinks(cfg.Outbox); err != nil {
+			fatal("invalid outbox configuration", err)
+		}
+		// Subscription webhook menerima event dari outbox yang sama dengan sink lain
+		outboxSinks = append(outboxSinks, webhooks)
+		relay := outbox.NewRelay(db, outboxSinks, metrics, outbox.RelayOptions{
+			PollInterval: cfg.Outbox.PollInterval,
+			BatchSize:    cfg.Outbox.BatchSize,
+			MaxAttempts:  cfg.Outbox.MaxAttempts,
+		})
+		runBackground(relay.Run)
+	}
+
+	auditor, err := audit.NewRecorder(db, auditOptions(cfg.Audit))
+	if err != nil {
+		fatal("failed to migrate audit log", err)
+	}
+	tokens, err := token.NewManager(tokenSecret(cfg.Account.TokenSecret), db)
+	if err != nil {
+		fatal("failed to setup token manager", err)
+	}
+	var sessionStore session.Store = session.NewMemoryStore()
+	if cfg.Auth.SessionStore == "gorm" {
+		gormSessions, err := session.NewGormStore(db)
+		if err != nil {
+			fatal("failed to migrate session store", err)
+		}
+		sessionStore = gormSessions
+		if cfg.Auth.SessionCacheTTL > 0 {
+			sessionStore = session.NewCachedStore(gormSessions, cfg.Auth.SessionCacheTTL)
+		}
+	}
+	sessions := session.NewManager(sessionStore, cfg.Auth.SessionTTL)
+	sessions.TrackActive(backgroundCtx, time.Minute, metrics.SetActiveSessions)
+	accountHandler := handler.NewAccountHandler(db, metrics, newMailer(cfg.Mail), tokens, sessions, auditor, handler.AccountOptions{
+		VerifyURL:      cfg.Account.BaseURL + "/api/v1/verify-email",
+		ResetURL:       cfg.Account.ResetURL,
+		VerifyTokenTTL: cfg.Account.VerifyTokenTTL,
+		ResetTokenTTL:  cfg.Account.ResetTokenTTL,
+	})
+	sessionHandler := handler.NewSessionHandler(metrics, sessions, auditor)
+	requireAuth := middleware.Auth(sessions)
+	userHandler := handler.NewUserHandler(db, metrics, accountHandler, sessions, auditor, cfg.Account.RequireVerifiedEmail, cfg.Auth.MFAChallengeTTL)
+	mfaHandler := handler.NewMFAHandler(db, metrics, tokens, sessions, auditor, cfg.Auth.MFAIssuer)
+	if promEnabled {
+		r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	}
+
+	// SETUP LIVE STREAM, request dan event bisnis diteruskan dari metrics ke client SSE/WebSocket
+	var liveHandler *handler.LiveHandlerImpl
+	if cfg.Live.Enabled {
+		hub := live.NewHub(metrics, live.Options{
+			History:       cfg.Live.History,
+			ClientBuffer:  cfg.Live.ClientBuffer,
+			StatsInterval: cfg.Live.StatsInterval,
+			StatsWindow:   cfg.Live.StatsWindow,
+		})
+		runBackground(hub.Run)
+		liveHandler = handler.NewLiveHandler(hub, cfg.Live.Heartbeat, cfg.CORS.AllowedOrigins)
+	}
+
+	// SETUP SLO, error budget dihitung in-process dari observasi HTTP yang sama dengan metrik
+	var sloHandler *handler.SLOHandlerImpl
+	if cfg.SLO.Enabled {
+		slos, err := newSLOs(cfg.SLO)
+		if err != nil {
+			fatal("invalid slo configuration", err)
+		}
+		tracker := slo.NewTracker(slos)
+		metrics.AddObserver(tracker)
+		sloHandler = handler.NewSLOHandler(tracker)
+	}
+
+	// SETUP ALERT, rule dievaluasi terhadap registry sendiri sehingga alert tetap jalan tanpa Prometheus
+	var alertHandler *handler.AlertHandlerImpl
+	if cfg.Alert.Enabled {
+		engine, err := newAlertEngine(cfg.Alert, metrics)
+		if err != nil {
+			fatal("invalid alert configuration", err)
+		}
+		runBackground(engine.Run)
+		alertHandler = handler.NewAlertHandler(metrics, engine, auditor)
+	}
+
+	// SETUP PUSH, untuk job singkat atau deployment di balik NAT yang tidak bisa di-scrape
+	if cfg.Push.PushgatewayURL != "" {
+		pusher := push.NewPushgateway(prometheus.GathererFunc(metrics.Gather), metrics, push.PushgatewayOptions{
+			URL:              cfg.Push.PushgatewayURL,
+			Job:              cfg.Push.Job,
+			Grouping:         withInstance(cfg.Push.Grouping),
+			Interval:         cfg.Push.Interval,
+			DeleteOnShutdown: cfg.Push.DeleteOnShutdown,
+		})
+		runBackground(pusher.Run)
+	}
+	if cfg.Push.RemoteWriteURL != "" {
+		externalLabels := withInstance(cfg.Push.ExternalLabels)
+		if _, ok := externalLabels["job"]; !ok {
+			externalLabels["job"] = cfg.Push.Job
+		}
+		writer, err := push.NewRemoteWriter(metrics, push.RemoteWriteOptions{
+			URL:                cfg.Push.RemoteWriteURL,
+			Interval:           cfg.Push.Interval,
+			Timeout:            cfg.Push.RemoteWriteTimeout,
+			Shards:             cfg.Push.Shards,
+			MaxSamplesPerSend:  cfg.Push.MaxSamplesPerSend,
+			WALDir:             cfg.Push.WALDir,
+			MaxPendingSegments: cfg.Push.MaxPending,
+			ExternalLabels:     externalLabels,
+		})
+		if err != nil {
+			fatal("invalid remote write configuration", err)
+		}
+		runBackground(writer.Run)
+	}
+
+	// SETUP OTLP, metrik yang sama dengan /metrics juga dikirim ke OpenTelemetry Collector
+	if cfg.OTLP.Enabled {
+		exporter, err := otlp.New(context.Background(), metrics, otlp.Options{
+			Protocol:    cfg.OTLP.Protocol,
+			Endpoint:    cfg.OTLP.Endpoint,
+			Headers:     cfg.OTLP.Headers,
+			Interval:    cfg.OTLP.Interval,
+			Timeout:     cfg.OTLP.Timeout,
+			Temporality: cfg.OTLP.Temporality,
+			ServiceName: cfg.OTLP.ServiceName,
+			Environment: cfg.OTLP.Environment,
+			Attributes:  cfg.OTLP.ResourceAttributes,
+		})
+		if err != nil {
+			fatal("invalid otlp configuration", err)
+		}
+		runBackground(exporter.Run)
+	}
+
+	// SETUP STATSD, observasi HTTP dan event bisnis dicerminkan ke agent StatsD/DogStatsD
+	if statsdEnabled {
+		emitter, err := statsd.New(metrics, statsd.Options{
+			Address:       cfg.Metrics.StatsDAddress,
+			Flavor:        cfg.Metrics.StatsDFlavor,
+			Prefix:        cfg.Metrics.StatsDPrefix,
+			Tags:          cfg.Metrics.StatsDTags,
+			SampleRate:    cfg.Metrics.StatsDSampleRate,
+			MaxTimings:    cfg.Metrics.StatsDMaxTimings,
+			FlushInterval: cfg.Metrics.StatsDFlushInterval,
+			QueueSize:     cfg.Metrics.StatsDQueueSize,
+			MaxPacketSize: cfg.Metrics.StatsDMaxPacketSize,
+		})
+		if err != nil {
+			fatal("invalid statsd configuration", err)
+		}
+		runBackground(emitter.Run)
+	}
 	r.GET("/docs/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 
 	v1 := r.Group("/api/v1")
@@ -103,35 +650,129 @@ func main() {
 		})
 
 		v1.POST("/login", userHandler.Login)
-		v1.POST("/register", userHandler.Register)
+		v1.POST("/login/mfa", mfaHandler.LoginMFA)
+		v1.POST("/register", idempotent, userHandler.Register)
 		v1.GET("/users", userHandler.GetUser)
+
+		v1.GET("/verify-email", accountHandler.VerifyEmail)
+		v1.POST("/password-reset/request", idempotent, accountHandler.RequestPasswordReset)
+		v1.POST("/password-reset/confirm", accountHandler.ConfirmPasswordReset)
+
+		if cfg.OIDC.Enabled {
+			oidcHandler := handler.NewOIDCHandler(db, metrics, newRelyingParty(cfg.OIDC), sessions, auditor, cfg.OIDC.AutoProvision)
+			v1.GET("/auth/oidc/login", oidcHandler.Login)
+			v1.GET("/auth/oidc/callback", oidcHandler.Callback)
+		}
+
+		if liveHandler != nil {
+			// Stream berisi request dan event bisnis semua user, hanya untuk admin
+			liveAuth := middleware.AdminAuth(cfg.AdminToken)
+			streaming.GET(v1, "/live/events", liveAuth, liveHandler.Events)
+			streaming.GET(v1, "/live/ws", liveAuth, liveHandler.WebSocket)
+		}
+
+		meMiddleware := []gin.HandlerFunc{requireAuth}
+		if injector != nil {
+			// Fault dengan scope user baru bisa dicocokkan setelah session diketahui
+			meMiddleware = append(meMiddleware, injector.UserMiddleware())
+		}
+		me := v1.Group("/me", meMiddleware...)
+		me.POST("/mfa/totp/enroll", mfaHandler.EnrollTOTP)
+		me.GET("/mfa/totp/qr.png", mfaHandler.TOTPQRCode)
+		me.POST("/mfa/totp/confirm", mfaHandler.ConfirmTOTP)
+		me.DELETE("/mfa/totp", mfaHandler.DisableTOTP)
+		me.GET("/sessions", sessionHandler.ListMine)
+		me.DELETE("/sessions/:id", sessionHandler.RevokeMine)
+	}
+
+	if cfg.AdminToken == "" {
+		appLog.Warn("ADMIN_TOKEN is not set, admin endpoints are disabled")
+	}
+	logHandler := handler.NewLogHandler(accessLogger)
+	auditHandler := handler.NewAuditHandler(auditor)
+	webhookHandler := handler.NewWebhookHandler(db, metrics, webhooks)
+	admin := v1.Group("/admin", middleware.AdminAuth(cfg.AdminToken))
+	{
+		admin.GET("/log-levels", logHandler.GetLevels)
+		admin.PUT("/log-levels/:module", logHandler.SetLevel)
+		admin.GET("/access-log", logHandler.GetAccessLog)
+		admin.PUT("/access-log", logHandler.SetAccessLog)
+		admin.DELETE("/users/:username/sessions", sessionHandler.RevokeAllForUser)
+		admin.GET("/audit", auditHandler.Query)
+		streaming.GET(admin, "/audit/export", auditHandler.Export)
+		admin.POST("/webhooks", idempotent, webhookHandler.CreateSubscription)
+		admin.GET("/webhooks", webhookHandler.ListSubscriptions)
+		admin.GET("/webhooks/:id", webhookHandler.GetSubscription)
+		admin.PUT("/webhooks/:id", webhookHandler.UpdateSubscription)
+		admin.DELETE("/webhooks/:id", webhookHandler.DeleteSubscription)
+		admin.GET("/webhooks/:id/deliveries", webhookHandler.ListDeliveries)
+		admin.GET("/webhook-deliveries/:id", webhookHandler.GetDelivery)
+		admin.POST("/webhook-deliveries/:id/redeliver", webhookHandler.Redeliver)
+		if prober != nil {
+			probeHandler := handler.NewProbeHandler(prober)
+			admin.GET("/probes", probeHandler.ListProbes)
+			admin.GET("/probes/:probe/history", probeHandler.ProbeHistory)
+		}
+		if sloHandler != nil {
+			admin.GET("/slo", sloHandler.ListSLOs)
+			admin.GET("/slo/rules", sloHandler.SLORules)
+		}
+		if alertHandler != nil {
+			admin.GET("/alerts", alertHandler.ListAlerts)
+			admin.GET("/alerts/rules", alertHandler.ListRules)
+			admin.POST("/alerts/silences", idempotent, alertHandler.CreateSilence)
+			admin.GET("/alerts/silences", alertHandler.ListSilences)
+			admin.DELETE("/alerts/silences/:id", alertHandler.DeleteSilence)
+		}
+		if injector != nil {
+			chaosHandler := handler.NewChaosHandler(metrics, injector, auditor)
+			admin.POST("/chaos/faults", idempotent, chaosHandler.CreateFault)
+			admin.GET("/chaos/faults", chaosHandler.ListFaults)
+			admin.DELETE("/chaos/faults", chaosHandler.ClearFaults)
+			admin.DELETE("/chaos/faults/:id", chaosHandler.DeleteFault)
+		}
 	}
 
 	/// BUAT EXSKPORTER BUAT SEND KE PROMETHEUS
 	promServer := &http.Server{
-		Addr:    ":8081",
-		Handler: metrics.MetricsHandler(),
+		Addr:              ":8081",
+		Handler:           metrics.MetricsHandler(),
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
 	}
 
-	go func() {
-		slog.Info("Listening And Serve Prometheus Exporter", slog.String("port", promServer.Addr))
-		if err := promServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			slog.Error("Server failed: %s", err)
-			panic(err)
-		}
-	}()
+	if promEnabled {
+		go func() {
+			appLog.Info("Listening And Serve Prometheus Exporter", slog.String("port", promServer.Addr))
+			if err := promServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				fatal("Server failed", err)
+			}
+		}()
+	}
 
 	/// RUN SERVER WEB SERVER
 	srv := &http.Server{
-		Addr:    ":8080",
-		Handler: r.Handler(),
+		Addr:              ":8080",
+		Handler:           middleware.TimeoutHandler(r.Handler(), cfg.Server.HandlerTimeout, streaming.Match, metrics),
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+	}
+
+	if liveHandler != nil {
+		// Shutdown tidak menunggu stream SSE/WebSocket yang tidak pernah selesai sendiri
+		srv.RegisterOnShutdown(liveHandler.Hub.Close)
 	}
 
 	go func() {
-		slog.Info("Listening And Server HTTP on ", slog.String("port", srv.Addr))
+		appLog.Info("Listening And Server HTTP on ", slog.String("port", srv.Addr))
 		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			slog.Error("Server failed: %s", err)
-			panic(err)
+			fatal("Server failed", err)
 		}
 	}()
 
@@ -139,18 +780,33 @@ func main() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("Shutdown Server ...")
+	appLog.Info("Shutdown Server ...")
+	stopBackground()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal("Server Shutdown:", err)
+	shutdownErr := srv.Shutdown(ctx)
+
+	// Relay masih memakai sink outbox sampai Run kembali, jadi sink ditutup sesudahnya
+	if !waitTimeout(&background, cfg.Server.ShutdownTimeout) {
+		appLog.Warn("background workers did not stop in time", slog.Duration("timeout", cfg.Server.ShutdownTimeout))
+	}
+	for _, sink := range outboxSinks {
+		if closer, ok := sink.(io.Closer); ok {
+			_ = closer.Close()
+		}
+	}
+	if recorder != nil {
+		_ = recorder.Close()
+	}
+	if shutdownErr != nil {
+		fatal("Server Shutdown", shutdownErr)
 	}
 
 	// catching ctx.Done(). timeout of 5 seconds.
 	select {
 	case <-ctx.Done():
-		log.Println("timeout of 5 seconds.")
+		appLog.Info("timeout of 5 seconds.")
 	}
-	log.Println("Server exiting")
+	appLog.Info("Server exiting")
 }
@@ -0,0 +1,63 @@
+package metric
+
+import (
+	"slices"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// AlertSample adalah status satu alert untuk metrik ALERTS
+type AlertSample struct {
+	// Labels sudah termasuk alertname
+	Labels map[string]string
+	// State adalah pending atau firing
+	State    string
+	ActiveAt time.Time
+}
+
+// alertsCollector mengekspor alert aktif dengan format yang sama seperti Prometheus:
+// ALERTS{alertname, alertstate, ...} bernilai 1 dan ALERTS_FOR_STATE berisi waktu aktif.
+// Label berbeda untuk setiap rule, jadi collector ini unchecked dan membuat Desc saat Collect.
+type alertsCollector struct {
+	source func() []AlertSample
+}
+
+func (c alertsCollector) Describe(chan<- *prometheus.Desc) {}
+
+func (c alertsCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, a := range c.source() {
+		names := make([]string, 0, len(a.Labels))
+		for name := range a.Labels {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		values := make([]string, 0, len(names))
+		for _, name := range names {
+			values = append(values, a.Labels[name])
+		}
+
+		// Label series dari registry bisa saja bentrok dengan alertstate atau tidak valid,
+		// lewati alert tersebut daripada panic di tengah scrape
+		alerts := prometheus.NewDesc("ALERTS", "Alerts evaluated by the embedded alert engine", append(names, "alertstate"), nil)
+		if m, err := prometheus.NewConstMetric(alerts, prometheus.GaugeValue, 1, append(values, a.State)...); err == nil {
+			ch <- m
+		}
+		forState := prometheus.NewDesc("ALERTS_FOR_STATE", "Unix time an alert became active", names, nil)
+		if m, err := prometheus.NewConstMetric(forState, prometheus.GaugeValue, float64(a.ActiveAt.Unix()), values...); err == nil {
+			ch <- m
+		}
+	}
+}
+
+// SetAlertSource mendaftarkan fungsi yang mengembalikan alert aktif sebagai metrik ALERTS.
+// Hanya boleh dipanggil sekali.
+func (e *AppMetricsExporter) SetAlertSource(source func() []AlertSample) {
+	e.registry.MustRegister(alertsCollector{source: source})
+}
+
+// Gather mengumpulkan nilai semua metrik di registry, dipakai evaluasi alert in-process
+func (e *AppMetricsExporter) Gather() ([]*dto.MetricFamily, error) {
+	return e.registry.Gather()
+}
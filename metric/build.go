@@ -0,0 +1,22 @@
+package metric
+
+import "runtime"
+
+// Version dan Commit diisi saat build, misalnya
+// go build -ldflags "-X wyw/metric.Version=1.2.0 -X wyw/metric.Commit=$(git rev-parse --short HEAD)"
+var (
+	Version = "1.0.0"
+	Commit  = "abc123"
+)
+
+// BuildInfo adalah informasi build yang diekspor sebagai app_build_info dan resource OTLP
+type BuildInfo struct {
+	Version   string
+	GoVersion string
+	Commit    string
+}
+
+// Build mengembalikan informasi build binary yang sedang berjalan
+func Build() BuildInfo {
+	return BuildInfo{Version: Version, GoVersion: runtime.Version(), Commit: Commit}
+}
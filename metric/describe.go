@@ -0,0 +1,100 @@
+package metric
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metricSet membuat metrik aplikasi sekaligus mencatat metadatanya, karena prometheus.Desc
+// tidak mengekspor nama, help dan label
+type metricSet struct {
+	collectors []prometheus.Collector
+	infos      []MetricInfo
+}
+
+func (s *metricSet) add(c prometheus.Collector, metricType, namespace, subsystem, name, help string, labels []string) {
+	s.collectors = append(s.collectors, c)
+	s.infos = append(s.infos, MetricInfo{
+		Name:   prometheus.BuildFQName(namespace, subsystem, name),
+		Type:   metricType,
+		Help:   help,
+		Labels: slices.Clone(labels),
+	})
+}
+
+func (s *metricSet) counter(opts prometheus.CounterOpts) prometheus.Counter {
+	c := prometheus.NewCounter(opts)
+	s.add(c, "counter", opts.Namespace, opts.Subsystem, opts.Name, opts.Help, nil)
+	return c
+}
+
+func (s *metricSet) counterVec(opts prometheus.CounterOpts, labels []string) *prometheus.CounterVec {
+	c := prometheus.NewCounterVec(opts, labels)
+	s.add(c, "counter", opts.Namespace, opts.Subsystem, opts.Name, opts.Help, labels)
+	return c
+}
+
+func (s *metricSet) gauge(opts prometheus.GaugeOpts) prometheus.Gauge {
+	g := prometheus.NewGauge(opts)
+	s.add(g, "gauge", opts.Namespace, opts.Subsystem, opts.Name, opts.Help, nil)
+	return g
+}
+
+func (s *metricSet) gaugeVec(opts prometheus.GaugeOpts, labels []string) *prometheus.GaugeVec {
+	g := prometheus.NewGaugeVec(opts, labels)
+	s.add(g, "gauge", opts.Namespace, opts.Subsystem, opts.Name, opts.Help, labels)
+	return g
+}
+
+func (s *metricSet) histogram(opts prometheus.HistogramOpts) prometheus.Histogram {
+	h := prometheus.NewHistogram(opts)
+	s.add(h, "histogram", opts.Namespace, opts.Subsystem, opts.Name, opts.Help, nil)
+	return h
+}
+
+func (s *metricSet) histogramVec(opts prometheus.HistogramOpts, labels []string) *prometheus.HistogramVec {
+	h := prometheus.NewHistogramVec(opts, labels)
+	s.add(h, "histogram", opts.Namespace, opts.Subsystem, opts.Name, opts.Help, labels)
+	return h
+}
+
+// Describe mengembalikan metadata semua metrik di registry, terurut berdasarkan nama.
+// Metrik aplikasi diambil dari metadata yang dicatat saat dibuat sehingga vector yang belum
+// pernah diisi tetap muncul, collector lain (Go runtime, process, database) dibaca dari
+// hasil Gather.
+func (e *AppMetricsExporter) Describe() ([]MetricInfo, error) {
+	infos := make(map[string]MetricInfo, len(e.metadata))
+	for _, info := range e.metadata {
+		infos[info.Name] = info
+	}
+
+	families, err := e.registry.Gather()
+	if err != nil {
+		return nil, err
+	}
+	for _, mf := range families {
+		if _, ok := infos[mf.GetName()]; ok {
+			continue
+		}
+		info := MetricInfo{
+			Name: mf.GetName(),
+			Type: strings.ToLower(mf.GetType().String()),
+			Help: mf.GetHelp(),
+		}
+		if len(mf.GetMetric()) > 0 {
+			for _, lp := range mf.GetMetric()[0].GetLabel() {
+				info.Labels = append(info.Labels, lp.GetName())
+			}
+		}
+		infos[info.Name] = info
+	}
+
+	result := make([]MetricInfo, 0, len(infos))
+	for _, info := range infos {
+		result = append(result, info)
+	}
+	slices.SortFunc(result, func(a, b MetricInfo) int { return strings.Compare(a.Name, b.Name) })
+	return result, nil
+}
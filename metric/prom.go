@@ -1,14 +1,20 @@
 package metric
 
 import (
+	"context"
+	"database/sql"
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"log/slog"
 	"net/http"
 	"runtime"
 	"strconv"
+	"sync"
 	"time"
+	"wyw/apperror"
+	"wyw/logging"
 )
 
 /*
@@ -16,6 +22,17 @@ go get github.com/prometheus/client_golang/prometheus
 go get github.com/prometheus/client_golang/prometheus/promhttp
 */
 
+// HTTPDurationBuckets adalah batas bucket histogram durasi request HTTP. Threshold latency
+// SLO harus sama dengan salah satu batas ini supaya bisa dihitung dari label le.
+var HTTPDurationBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.3, 0.5, 1, 2.5, 5, 10}
+
+// Observer menerima salinan setiap observasi HTTP dan event bisnis, dipakai untuk
+// meneruskan data yang sama ke tujuan lain tanpa mengubah kode handler
+type Observer interface {
+	ObserveHTTPRequest(status int, method, endpoint, code string, duration time.Duration)
+	RecordBusinessEvent(eventType, userID string)
+}
+
 type AppMetricsExporter struct {
 	// Registry untuk semua metrik
 	registry *prometheus.Registry
@@ -24,8 +41,64 @@ type AppMetricsExporter struct {
 	httpRequestsTotal   *prometheus.CounterVec
 	httpRequestDuration *prometheus.HistogramVec
 
+	// Security metrics
+	corsRejected     *prometheus.CounterVec
+	rejectedRequests *prometheus.CounterVec
+
+	// Validation metrics
+	validationFailures *prometheus.CounterVec
+
+	// Idempotency metrics
+	idempotencyRequests *prometheus.CounterVec
+
 	// Business metrics
 	businessEvents *prometheus.CounterVec
+	mailSent       *prometheus.CounterVec
+
+	// Session metrics
+	activeSessions prometheus.Gauge
+
+	// Outbox metrics
+	outboxBacklog    prometheus.Gauge
+	outboxOldestAge  prometheus.Gauge
+	outboxDeliveries *prometheus.CounterVec
+	outboxLag        prometheus.Histogram
+
+	// Webhook metrics
+	webhookDeliveries *prometheus.CounterVec
+	webhookDuration   *prometheus.HistogramVec
+
+	// Live stream metrics
+	liveClients        prometheus.Gauge
+	liveClientsDropped prometheus.Counter
+
+	// Traffic capture metrics
+	captureRecords *prometheus.CounterVec
+
+	// Chaos metrics
+	chaosFaultActive *prometheus.GaugeVec
+	chaosInjections  *prometheus.CounterVec
+
+	// Synthetic probe metrics
+	probeSuccess  *prometheus.GaugeVec
+	probeDuration *prometheus.GaugeVec
+	probeTotal    *prometheus.CounterVec
+
+	// Alerting metrics
+	alertNotifications *prometheus.CounterVec
+
+	// Push metrics
+	pushRequests       *prometheus.CounterVec
+	remoteWriteSamples *prometheus.CounterVec
+	remoteWritePending *prometheus.GaugeVec
+
+	// OTLP metrics
+	otlpExports    *prometheus.CounterVec
+	otlpDataPoints *prometheus.CounterVec
+
+	// StatsD metrics
+	statsdPackets *prometheus.CounterVec
+	statsdDropped prometheus.Counter
 
 	// System metrics
 	memoryUsage     prometheus.Gauge
@@ -37,6 +110,21 @@ type AppMetricsExporter struct {
 
 	// Waktu mulai aplikasi untuk perhitungan uptime
 	startTime time.Time
+
+	observersMu sync.RWMutex
+	observers   []Observer
+
+	// metadata adalah nama, tipe, help dan label metrik aplikasi, dicatat saat metrik dibuat
+	metadata []MetricInfo
+}
+
+// MetricInfo adalah metadata satu metrik di registry
+type MetricInfo struct {
+	Name string
+	// Type adalah counter, gauge, histogram, summary atau untyped
+	Type   string
+	Help   string
+	Labels []string
 }
 
 // NewAppMetricsExporter membuat instance baru eksporter dengan semua metrik terdaftar
@@ -50,32 +138,81 @@ func NewAppMetricsExporter() *AppMetricsExporter {
 	registry.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
 
 	// Buat eksporter dengan semua metrik
+	var set metricSet
 	exporter := &AppMetricsExporter{
 		registry: registry,
 
 		// HTTP metrics
-		httpRequestsTotal: prometheus.NewCounterVec(
+		httpRequestsTotal: set.counterVec(
 			prometheus.CounterOpts{
 				Namespace: "app",
 				Subsystem: "http",
 				Name:      "requests_total",
-				Help:      "Total count of HTTP requests by status, method, and endpoint",
+				Help:      "Total count of HTTP requests by status, method, endpoint, and error code",
 			},
-			[]string{"status", "method", "endpoint"},
+			[]string{"status", "method", "endpoint", "code"},
 		),
-		httpRequestDuration: prometheus.NewHistogramVec(
+		httpRequestDuration: set.histogramVec(
 			prometheus.HistogramOpts{
 				Namespace: "app",
 				Subsystem: "http",
 				Name:      "request_duration_seconds",
 				Help:      "Duration of HTTP requests in seconds",
-				Buckets:   []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 2.5, 5, 10},
+				Buckets:   HTTPDurationBuckets,
+				// Native histogram diekspor sebagai exponential histogram lewat OTLP, bucket
+				// klasik tetap ada untuk scrape format teks. MinResetDuration sengaja tidak
+				// diisi: reset native histogram ikut mengosongkan bucket klasik, _count dan
+				// _sum, jadi saat bucket penuh resolusinya diturunkan saja.
+				NativeHistogramBucketFactor:    1.1,
+				NativeHistogramMaxBucketNumber: 100,
 			},
-			[]string{"status", "method", "endpoint"},
+			[]string{"status", "method", "endpoint", "code"},
+		),
+
+		// Security metrics
+		corsRejected: set.counterVec(
+			prometheus.CounterOpts{
+				Namespace: "app",
+				Subsystem: "http",
+				Name:      "cors_rejected_total",
+				Help:      "Total count of rejected CORS requests by reason and route",
+			},
+			[]string{"reason", "route"},
+		),
+		rejectedRequests: set.counterVec(
+			prometheus.CounterOpts{
+				Namespace: "app",
+				Subsystem: "http",
+				Name:      "rejected_requests_total",
+				Help:      "Total count of requests rejected by the hardening layer (body size, timeout)",
+			},
+			[]string{"reason", "route"},
+		),
+
+		// Validation metrics
+		validationFailures: set.counterVec(
+			prometheus.CounterOpts{
+				Namespace: "app",
+				Subsystem: "http",
+				Name:      "validation_failures_total",
+				Help:      "Total count of request validation failures by route, field, and rule",
+			},
+			[]string{"route", "field", "rule"},
+		),
+
+		// Idempotency metrics
+		idempotencyRequests: set.counterVec(
+			prometheus.CounterOpts{
+				Namespace: "app",
+				Subsystem: "http",
+				Name:      "idempotency_requests_total",
+				Help:      "Total count of duplicate Idempotency-Key requests by route and result (replayed, mismatch, in_progress)",
+			},
+			[]string{"route", "result"},
 		),
 
 		// Business metrics
-		businessEvents: prometheus.NewCounterVec(
+		businessEvents: set.counterVec(
 			prometheus.CounterOpts{
 				Namespace: "app",
 				Subsystem: "business",
@@ -84,9 +221,162 @@ func NewAppMetricsExporter() *AppMetricsExporter {
 			},
 			[]string{"event_type", "user_id"},
 		),
+		mailSent: set.counterVec(
+			prometheus.CounterOpts{
+				Namespace: "app",
+				Subsystem: "mail",
+				Name:      "sent_total",
+				Help:      "Total count of emails sent by template and outcome",
+			},
+			[]string{"template", "outcome"},
+		),
+
+		// Session metrics
+		activeSessions: set.gauge(
+			prometheus.GaugeOpts{
+				Namespace: "app",
+				Name:      "active_sessions",
+				Help:      "Current number of active (not expired or revoked) login sessions",
+			},
+		),
+
+		// Outbox metrics
+		outboxBacklog: set.gauge(
+			prometheus.GaugeOpts{
+				Namespace: "app",
+				Subsystem: "outbox",
+				Name:      "backlog_messages",
+				Help:      "Number of outbox messages waiting to be delivered",
+			},
+		),
+		outboxOldestAge: set.gauge(
+			prometheus.GaugeOpts{
+				Namespace: "app",
+				Subsystem: "outbox",
+				Name:      "oldest_pending_age_seconds",
+				Help:      "Age of the oldest undelivered outbox message in seconds",
+			},
+		),
+		outboxDeliveries: set.counterVec(
+			prometheus.CounterOpts{
+				Namespace: "app",
+				Subsystem: "outbox",
+				Name:      "deliveries_total",
+				Help:      "Total count of outbox delivery attempts by sink and outcome",
+			},
+			[]string{"sink", "outcome"},
+		),
+		outboxLag: set.histogram(
+			prometheus.HistogramOpts{
+				Namespace: "app",
+				Subsystem: "outbox",
+				Name:      "delivery_lag_seconds",
+				Help:      "Time between an event being written to the outbox and its delivery",
+				Buckets:   []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 300, 900},
+			},
+		),
+
+		// Webhook metrics
+		webhookDeliveries: set.counterVec(
+			prometheus.CounterOpts{
+				Namespace: "app",
+				Subsystem: "webhook",
+				Name:      "deliveries_total",
+				Help:      "Total count of webhook delivery attempts by subscription and outcome (success, failure, dead)",
+			},
+			[]string{"subscription", "outcome"},
+		),
+		webhookDuration: set.histogramVec(
+			prometheus.HistogramOpts{
+				Namespace: "app",
+				Subsystem: "webhook",
+				Name:      "delivery_duration_seconds",
+				Help:      "Duration of webhook delivery attempts in seconds by subscription",
+				Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+			},
+			[]string{"subscription"},
+		),
+
+		// Live stream metrics
+		liveClients: set.gauge(
+			prometheus.GaugeOpts{
+				Namespace: "app",
+				Subsystem: "live",
+				Name:      "clients",
+				Help:      "Current number of connected live stream clients (SSE and WebSocket)",
+			},
+		),
+		liveClientsDropped: set.counter(
+			prometheus.CounterOpts{
+				Namespace: "app",
+				Subsystem: "live",
+				Name:      "clients_dropped_total",
+				Help:      "Total count of live stream clients disconnected because they could not keep up",
+			},
+		),
+
+		// Traffic capture metrics
+		captureRecords: set.counterVec(
+			prometheus.CounterOpts{
+				Namespace: "app",
+				Subsystem: "capture",
+				Name:      "records_total",
+				Help:      "Total count of sampled requests for traffic capture by outcome (written, dropped)",
+			},
+			[]string{"outcome"},
+		),
+
+		// Chaos metrics
+		chaosFaultActive: set.gaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: "app",
+				Subsystem: "chaos",
+				Name:      "fault_active",
+				Help:      "Injected faults that are currently active (1), for dashboard annotations",
+			},
+			[]string{"id", "type", "route"},
+		),
+		chaosInjections: set.counterVec(
+			prometheus.CounterOpts{
+				Namespace: "app",
+				Subsystem: "chaos",
+				Name:      "injections_total",
+				Help:      "Total count of requests or queries affected by an injected fault by type",
+			},
+			[]string{"type"},
+		),
+
+		// Synthetic probe metrics
+		probeSuccess: set.gaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: "app",
+				Subsystem: "probe",
+				Name:      "success",
+				Help:      "Whether the last run of a synthetic probe succeeded (1) or failed (0)",
+			},
+			[]string{"probe"},
+		),
+		probeDuration: set.gaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: "app",
+				Subsystem: "probe",
+				Name:      "duration_seconds",
+				Help:      "Duration of the last run of a synthetic probe in seconds",
+			},
+			[]string{"probe"},
+		),
+		probeTotal: set.counterVec(
+			prometheus.CounterOpts{
+				Namespace: "app",
+				Subsystem: "probe",
+				Name:      "runs_total",
+				Help:      "Total count of synthetic probe runs by outcome (success, failure)",
+			},
+			[]string{"probe", "outcome"},
+		),
 
 		// System metrics
-		memoryUsage: prometheus.NewGauge(
+		memoryUsage: set.gauge(
 			prometheus.GaugeOpts{
 				Namespace: "app",
 				Subsystem: "system",
@@ -94,7 +384,7 @@ func NewAppMetricsExporter() *AppMetricsExporter {
 				Help:      "Current memory usage in bytes",
 			},
 		),
-		goroutinesCount: prometheus.NewGauge(
+		goroutinesCount: set.gauge(
 			prometheus.GaugeOpts{
 				Namespace: "app",
 				Subsystem: "system",
@@ -102,7 +392,7 @@ func NewAppMetricsExporter() *AppMetricsExporter {
 				Help:      "Current number of goroutines",
 			},
 		),
-		uptime: prometheus.NewCounter(
+		uptime: set.counter(
 			prometheus.CounterOpts{
 				Namespace: "app",
 				Subsystem: "system",
@@ -111,8 +401,87 @@ func NewAppMetricsExporter() *AppMetricsExporter {
 			},
 		),
 
+		// Alerting metrics
+		alertNotifications: set.counterVec(
+			prometheus.CounterOpts{
+				Namespace: "app",
+				Subsystem: "alert",
+				Name:      "notifications_total",
+				Help:      "Total count of alert notifications sent to webhook receivers by outcome",
+			},
+			[]string{"receiver", "outcome"},
+		),
+
+		// Push metrics
+		pushRequests: set.counterVec(
+			prometheus.CounterOpts{
+				Namespace: "app",
+				Subsystem: "push",
+				Name:      "requests_total",
+				Help:      "Total count of pushes to the Pushgateway and remote-write endpoint by outcome",
+			},
+			[]string{"target", "outcome"},
+		),
+		remoteWriteSamples: set.counterVec(
+			prometheus.CounterOpts{
+				Namespace: "app",
+				Subsystem: "push",
+				Name:      "remote_write_samples_total",
+				Help:      "Total count of remote-write samples that were sent, failed a send attempt or were dropped",
+			},
+			[]string{"outcome"},
+		),
+		remoteWritePending: set.gaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: "app",
+				Subsystem: "push",
+				Name:      "remote_write_pending_segments",
+				Help:      "Number of remote-write batches waiting in the WAL by shard",
+			},
+			[]string{"shard"},
+		),
+
+		// OTLP metrics
+		otlpExports: set.counterVec(
+			prometheus.CounterOpts{
+				Namespace: "app",
+				Subsystem: "otlp",
+				Name:      "exports_total",
+				Help:      "Total count of OTLP metric exports by outcome",
+			},
+			[]string{"outcome"},
+		),
+		otlpDataPoints: set.counterVec(
+			prometheus.CounterOpts{
+				Namespace: "app",
+				Subsystem: "otlp",
+				Name:      "data_points_total",
+				Help:      "Total count of data points in OTLP metric exports by outcome",
+			},
+			[]string{"outcome"},
+		),
+
+		// StatsD metrics
+		statsdPackets: set.counterVec(
+			prometheus.CounterOpts{
+				Namespace: "app",
+				Subsystem: "statsd",
+				Name:      "packets_total",
+				Help:      "Total count of StatsD packets written by outcome",
+			},
+			[]string{"outcome"},
+		),
+		statsdDropped: set.counter(
+			prometheus.CounterOpts{
+				Namespace: "app",
+				Subsystem: "statsd",
+				Name:      "dropped_observations_total",
+				Help:      "Total count of observations dropped because the StatsD queue was full",
+			},
+		),
+
 		// Build info
-		buildInfo: prometheus.NewGaugeVec(
+		buildInfo: set.gaugeVec(
 			prometheus.GaugeOpts{
 				Namespace: "app",
 				Name:      "build_info",
@@ -125,18 +494,12 @@ func NewAppMetricsExporter() *AppMetricsExporter {
 	}
 
 	// Register semua metrik ke registry
-	registry.MustRegister(
-		exporter.httpRequestsTotal,
-		exporter.httpRequestDuration,
-		exporter.businessEvents,
-		exporter.memoryUsage,
-		exporter.goroutinesCount,
-		exporter.uptime,
-		exporter.buildInfo,
-	)
-
-	// Set build info (sebagai contoh)
-	exporter.buildInfo.WithLabelValues("1.0.0", runtime.Version(), "abc123").Set(1)
+	exporter.metadata = set.infos
+	registry.MustRegister(set.collectors...)
+
+	// Set build info
+	build := Build()
+	exporter.buildInfo.WithLabelValues(build.Version, build.GoVersion, build.Commit).Set(1)
 
 	// Mulai goroutine untuk memperbarui metrik sistem secara periodik
 	go exporter.collectSystemMetrics()
@@ -158,24 +521,235 @@ func (e *AppMetricsExporter) collectSystemMetrics() {
 		runtime.ReadMemStats(&memStats)
 		e.memoryUsage.Set(float64(memStats.Alloc))
 		e.goroutinesCount.Set(float64(runtime.NumGoroutine()))
+
+		logging.Logger(logging.ModuleMetric).Debug("system metrics collected",
+			slog.Uint64("memory_bytes", memStats.Alloc), slog.Int("goroutines", runtime.NumGoroutine()))
 	}
 }
 
+// RegisterDBStats menambahkan metrik connection pool database (go_sql_*) ke registry
+func (e *AppMetricsExporter) RegisterDBStats(db *sql.DB, name string) {
+	e.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
 // MetricsHandler mengembalikan HTTP handler untuk endpoint /metrics
 func (e *AppMetricsExporter) MetricsHandler() http.Handler {
 	return promhttp.HandlerFor(e.registry, promhttp.HandlerOpts{})
 }
 
-// ObserveHTTPRequest mencatat metrik untuk request HTTP
-func (e *AppMetricsExporter) ObserveHTTPRequest(status int, method, endpoint string, duration time.Duration) {
+// ObserveHTTPRequest mencatat metrik untuk request HTTP.
+// code adalah kode error domain (apperror.Code), "none" untuk request tanpa error.
+func (e *AppMetricsExporter) ObserveHTTPRequest(status int, method, endpoint, code string, duration time.Duration) {
 	statusStr := strconv.Itoa(status)
-	e.httpRequestsTotal.WithLabelValues(statusStr, method, endpoint).Inc()
-	e.httpRequestDuration.WithLabelValues(statusStr, method, endpoint).Observe(duration.Seconds())
+	if code == "" {
+		code = "none"
+	}
+	e.httpRequestsTotal.WithLabelValues(statusStr, method, endpoint, code).Inc()
+	e.httpRequestDuration.WithLabelValues(statusStr, method, endpoint, code).Observe(duration.Seconds())
+
+	for _, o := range e.currentObservers() {
+		o.ObserveHTTPRequest(status, method, endpoint, code, duration)
+	}
+}
+
+// AddObserver mendaftarkan observer yang menerima salinan observasi HTTP dan event bisnis.
+// Observer dipanggil di goroutine request, jadi implementasinya tidak boleh blocking.
+func (e *AppMetricsExporter) AddObserver(o Observer) {
+	e.observersMu.Lock()
+	defer e.observersMu.Unlock()
+	e.observers = append(e.observers, o)
+}
+
+func (e *AppMetricsExporter) currentObservers() []Observer {
+	e.observersMu.RLock()
+	defer e.observersMu.RUnlock()
+	return e.observers
+}
+
+// RecordCORSRejection mencatat request CORS yang ditolak beserta alasannya
+func (e *AppMetricsExporter) RecordCORSRejection(reason, route string) {
+	e.corsRejected.WithLabelValues(reason, route).Inc()
+}
+
+// RecordRejectedRequest mencatat request yang ditolak karena ukuran body atau timeout
+func (e *AppMetricsExporter) RecordRejectedRequest(reason, route string) {
+	e.rejectedRequests.WithLabelValues(reason, route).Inc()
+}
+
+// RecordValidationFailure mencatat field payload yang gagal validasi
+func (e *AppMetricsExporter) RecordValidationFailure(route, field, rule string) {
+	e.validationFailures.WithLabelValues(route, field, rule).Inc()
+}
+
+// RecordIdempotency mencatat request duplikat dengan Idempotency-Key
+func (e *AppMetricsExporter) RecordIdempotency(route, result string) {
+	e.idempotencyRequests.WithLabelValues(route, result).Inc()
 }
 
 // RecordBusinessEvent mencatat event bisnis
 func (e *AppMetricsExporter) RecordBusinessEvent(eventType, userID string) {
 	e.businessEvents.WithLabelValues(eventType, userID).Inc()
+
+	for _, o := range e.currentObservers() {
+		o.RecordBusinessEvent(eventType, userID)
+	}
+}
+
+// RecordMailSent mencatat hasil pengiriman email ("sent" atau "failed")
+func (e *AppMetricsExporter) RecordMailSent(template, outcome string) {
+	e.mailSent.WithLabelValues(template, outcome).Inc()
+}
+
+// SetActiveSessions memperbarui gauge jumlah session login yang aktif
+func (e *AppMetricsExporter) SetActiveSessions(n int64) {
+	e.activeSessions.Set(float64(n))
+}
+
+// SetOutboxBacklog memperbarui jumlah message outbox yang belum terkirim dan umur message tertua
+func (e *AppMetricsExporter) SetOutboxBacklog(pending int64, oldest time.Duration) {
+	e.outboxBacklog.Set(float64(pending))
+	e.outboxOldestAge.Set(oldest.Seconds())
+}
+
+// RecordOutboxDelivery mencatat hasil pengiriman outbox ke sebuah sink ("delivered" atau "failed")
+func (e *AppMetricsExporter) RecordOutboxDelivery(sink, outcome string) {
+	e.outboxDeliveries.WithLabelValues(sink, outcome).Inc()
+}
+
+// ObserveOutboxLag mencatat jeda antara event ditulis dan berhasil dikirim
+func (e *AppMetricsExporter) ObserveOutboxLag(lag time.Duration) {
+	e.outboxLag.Observe(lag.Seconds())
+}
+
+// ObserveWebhookDelivery mencatat hasil dan durasi satu percobaan pengiriman webhook
+func (e *AppMetricsExporter) ObserveWebhookDelivery(subscription, outcome string, duration time.Duration) {
+	e.webhookDeliveries.WithLabelValues(subscription, outcome).Inc()
+	e.webhookDuration.WithLabelValues(subscription).Observe(duration.Seconds())
+}
+
+// SetLiveClients memperbarui jumlah client live stream yang terhubung
+func (e *AppMetricsExporter) SetLiveClients(n int) {
+	e.liveClients.Set(float64(n))
+}
+
+// RecordLiveClientDropped mencatat client live stream yang diputus karena terlalu lambat
+func (e *AppMetricsExporter) RecordLiveClientDropped() {
+	e.liveClientsDropped.Inc()
+}
+
+// RecordCapture mencatat record capture yang ditulis atau dibuang karena antrean penuh
+func (e *AppMetricsExporter) RecordCapture(outcome string) {
+	e.captureRecords.WithLabelValues(outcome).Inc()
+}
+
+// SetChaosFault menandai fault aktif, atau menghapus series-nya saat fault berakhir
+func (e *AppMetricsExporter) SetChaosFault(id, faultType, route string, active bool) {
+	if !active {
+		e.chaosFaultActive.DeleteLabelValues(id, faultType, route)
+		return
+	}
+	e.chaosFaultActive.WithLabelValues(id, faultType, route).Set(1)
+}
+
+// RecordChaosInjection mencatat request atau query yang terkena fault
+func (e *AppMetricsExporter) RecordChaosInjection(faultType string) {
+	e.chaosInjections.WithLabelValues(faultType).Inc()
+}
+
+// ObserveProbe mencatat hasil satu kali probe sintetis
+func (e *AppMetricsExporter) ObserveProbe(probe string, success bool, duration time.Duration) {
+	outcome := "failure"
+	value := 0.0
+	if success {
+		outcome = "success"
+		value = 1
+	}
+	e.probeSuccess.WithLabelValues(probe).Set(value)
+	e.probeDuration.WithLabelValues(probe).Set(duration.Seconds())
+	e.probeTotal.WithLabelValues(probe, outcome).Inc()
+}
+
+// RecordAlertNotification mencatat hasil pengiriman notifikasi alert ke satu receiver
+func (e *AppMetricsExporter) RecordAlertNotification(receiver, outcome string) {
+	e.alertNotifications.WithLabelValues(receiver, outcome).Inc()
+}
+
+// RecordPush mencatat hasil satu push ke target (pushgateway atau remote_write)
+func (e *AppMetricsExporter) RecordPush(target, outcome string) {
+	e.pushRequests.WithLabelValues(target, outcome).Inc()
+}
+
+// RecordRemoteWriteSamples mencatat jumlah sample remote-write per outcome (sent, failed, dropped)
+func (e *AppMetricsExporter) RecordRemoteWriteSamples(outcome string, n int) {
+	e.remoteWriteSamples.WithLabelValues(outcome).Add(float64(n))
+}
+
+// SetRemoteWritePending mencatat jumlah batch yang menunggu di WAL satu shard
+func (e *AppMetricsExporter) SetRemoteWritePending(shard, segments int) {
+	e.remoteWritePending.WithLabelValues(strconv.Itoa(shard)).Set(float64(segments))
+}
+
+// RecordOTLPExport mencatat hasil satu export OTLP beserta jumlah data point-nya
+func (e *AppMetricsExporter) RecordOTLPExport(outcome string, points int) {
+	e.otlpExports.WithLabelValues(outcome).Inc()
+	e.otlpDataPoints.WithLabelValues(outcome).Add(float64(points))
+}
+
+// RecordStatsDPacket mencatat hasil penulisan satu paket StatsD (sent atau failed)
+func (e *AppMetricsExporter) RecordStatsDPacket(outcome string) {
+	e.statsdPackets.WithLabelValues(outcome).Inc()
+}
+
+// RecordStatsDDropped mencatat observasi yang dibuang karena antrean StatsD penuh
+func (e *AppMetricsExporter) RecordStatsDDropped() {
+	e.statsdDropped.Inc()
+}
+
+// requestTracker dibagi antara GinMiddleware dan TimeoutHandler yang bisa menjawab request
+// sebelum handler gin selesai, supaya request tersebut tercatat sekali dengan route aslinya
+type requestTracker struct {
+	mu        sync.Mutex
+	route     string
+	requestID string
+	observed  bool
+}
+
+type requestTrackerKey struct{}
+
+// TrackRequest memasang tracker di context request, dipanggil oleh lapisan di luar gin
+// sebelum request diteruskan ke router
+func TrackRequest(r *http.Request) *http.Request {
+	ctx := context.WithValue(r.Context(), requestTrackerKey{}, &requestTracker{route: "unknown"})
+	return r.WithContext(ctx)
+}
+
+// claim menandai request sudah dicatat, false berarti sudah dicatat pihak lain
+func (t *requestTracker) claim() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.observed {
+		return false
+	}
+	t.observed = true
+	return true
+}
+
+// ObserveTimeout mencatat request yang dijawab 503 karena timeout lewat ObserveHTTPRequest,
+// sehingga SLO, alert dan observer lain ikut melihatnya. Route dan request ID dari gin
+// dikembalikan untuk response, "unknown" bila router belum sempat berjalan.
+func (e *AppMetricsExporter) ObserveTimeout(r *http.Request, duration time.Duration) (route, requestID string) {
+	t, ok := r.Context().Value(requestTrackerKey{}).(*requestTracker)
+	if !ok {
+		e.ObserveHTTPRequest(http.StatusServiceUnavailable, r.Method, "unknown", string(apperror.CodeTimeout), duration)
+		return "unknown", ""
+	}
+	t.mu.Lock()
+	route, requestID = t.route, t.requestID
+	t.mu.Unlock()
+	if t.claim() {
+		e.ObserveHTTPRequest(http.StatusServiceUnavailable, r.Method, route, string(apperror.CodeTimeout), duration)
+	}
+	return route, requestID
 }
 
 // GinMiddleware menyediakan middleware Gin untuk merekam metrik HTTP
@@ -188,15 +762,27 @@ func (e *AppMetricsExporter) GinMiddleware() gin.HandlerFunc {
 		if endpoint == "" {
 			endpoint = "unknown"
 		}
+		tracker, _ := c.Request.Context().Value(requestTrackerKey{}).(*requestTracker)
+		if tracker != nil {
+			tracker.mu.Lock()
+			tracker.route, tracker.requestID = endpoint, c.GetString(logging.RequestIDKey)
+			tracker.mu.Unlock()
+		}
 
 		// Proses request
 		c.Next()
 
+		// Request yang sudah dijawab TimeoutHandler sudah dicatat sebagai 503
+		if tracker != nil && !tracker.claim() {
+			return
+		}
+
 		// Rekam metrik setelah request selesai
 		duration := time.Since(start)
 		status := c.Writer.Status()
 		method := c.Request.Method
+		code := c.GetString(apperror.ContextKey)
 
-		e.ObserveHTTPRequest(status, method, endpoint, duration)
+		e.ObserveHTTPRequest(status, method, endpoint, code, duration)
 	}
 }
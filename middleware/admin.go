@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"wyw/apperror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HeaderAPIKey adalah header yang membawa token admin
+const HeaderAPIKey = "X-API-Key"
+
+// AdminAuth membatasi akses endpoint admin hanya untuk request dengan X-API-Key yang cocok.
+// Bila token kosong, semua request ditolak sehingga endpoint admin nonaktif secara default.
+func AdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(HeaderAPIKey)
+		if token == "" || subtle.ConstantTimeCompare([]byte(key), []byte(token)) != 1 {
+			_ = c.Error(apperror.New(apperror.CodeUnauthorized, "invalid api key"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+	"wyw/apperror"
+	"wyw/logging"
+	"wyw/session"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionKey adalah key di gin.Context tempat *session.Session disimpan
+const SessionKey = "session"
+
+// Auth mewajibkan header "Authorization: Bearer <token>" dengan session yang masih aktif
+func Auth(sessions *session.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tok, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok {
+			_ = c.Error(apperror.New(apperror.CodeUnauthorized, "missing bearer token"))
+			c.Abort()
+			return
+		}
+
+		sess, err := sessions.Authenticate(c.Request.Context(), strings.TrimSpace(tok))
+		if err != nil {
+			if errors.Is(err, session.ErrNotFound) {
+				_ = c.Error(apperror.Wrap(apperror.CodeUnauthorized, err, "session is invalid or has expired"))
+			} else {
+				_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to load session"))
+			}
+			c.Abort()
+			return
+		}
+
+		c.Set(SessionKey, sess)
+		c.Set(logging.UserKey, sess.Username)
+		c.Next()
+	}
+}
+
+// CurrentSession mengembalikan session milik request yang sudah melewati Auth
+func CurrentSession(c *gin.Context) *session.Session {
+	sess, _ := c.MustGet(SessionKey).(*session.Session)
+	return sess
+}
@@ -0,0 +1,302 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"wyw/metric"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Alasan penolakan CORS yang dicatat ke metrik
+const (
+	CORSRejectOrigin = "origin"
+	CORSRejectMethod = "method"
+	CORSRejectHeader = "header"
+	CORSRejectRoute  = "route"
+)
+
+// CORSPolicy mendeskripsikan aturan CORS untuk sekelompok route.
+//
+// AllowedOrigins menerima tiga bentuk:
+//   - exact: "https://app.example.com"
+//   - wildcard subdomain: "https://*.example.com"
+//   - regex, diawali "re:": "re:https://[a-z]+\.example\.com". Pola selalu dicocokkan
+//     dengan seluruh origin, seperti matcher alert.
+//
+// Nilai "*" mengizinkan semua origin dan tidak boleh digabung dengan AllowCredentials.
+type CORSPolicy struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// CORSConfig berisi policy default dan override per prefix path (route group)
+type CORSConfig struct {
+	Default   CORSPolicy
+	Overrides map[string]CORSPolicy
+}
+
+type originMatcher func(origin string) bool
+
+type compiledPolicy struct {
+	CORSPolicy
+	anyOrigin bool
+	matchers  []originMatcher
+	methods   string
+	headers   string
+	exposed   string
+	maxAge    string
+}
+
+type cors struct {
+	policies *routeOverrides[*compiledPolicy]
+	metrics  *metric.AppMetricsExporter
+
+	routes     func() gin.RoutesInfo
+	routesOnce sync.Once
+	patterns   []routePattern
+}
+
+// NewCORS membuat middleware CORS berbasis policy. routes dipakai untuk menolak
+// preflight ke route yang tidak terdaftar, biasanya diisi engine.Routes.
+func NewCORS(cfg CORSConfig, routes func() gin.RoutesInfo, metrics *metric.AppMetricsExporter) (gin.HandlerFunc, error) {
+	def, err := compilePolicy(cfg.Default)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := make(map[string]*compiledPolicy, len(cfg.Overrides))
+	for prefix, policy := range cfg.Overrides {
+		compiled, err := compilePolicy(policy)
+		if err != nil {
+			return nil, fmt.Errorf("cors override %s: %w", prefix, err)
+		}
+		overrides[prefix] = compiled
+	}
+
+	m := &cors{
+		policies: newRouteOverrides(def, overrides),
+		metrics:  metrics,
+		routes:   routes,
+	}
+	return m.handle, nil
+}
+
+func compilePolicy(p CORSPolicy) (*compiledPolicy, error) {
+	cp := &compiledPolicy{CORSPolicy: p}
+	for _, origin := range p.AllowedOrigins {
+		switch {
+		case origin == "*":
+			cp.anyOrigin = true
+		case strings.HasPrefix(origin, "re:"):
+			re, err := regexp.Compile("^(?:" + strings.TrimPrefix(origin, "re:") + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("cors: invalid origin pattern %q: %w", origin, err)
+			}
+			cp.matchers = append(cp.matchers, re.MatchString)
+		case strings.Contains(origin, "*."):
+			scheme, host, _ := strings.Cut(origin, "*.")
+			suffix := "." + strings.ToLower(host)
+			cp.matchers = append(cp.matchers, func(o string) bool {
+				rest, ok := strings.CutPrefix(strings.ToLower(o), scheme)
+				return ok && strings.HasSuffix(rest, suffix) && len(rest) > len(suffix)
+			})
+		default:
+			exact := strings.ToLower(origin)
+			cp.matchers = append(cp.matchers, func(o string) bool { return strings.ToLower(o) == exact })
+		}
+	}
+	if cp.anyOrigin && p.AllowCredentials {
+		return nil, errors.New(`cors: wildcard origin "*" cannot be combined with credentials`)
+	}
+
+	cp.AllowedMethods = slices.Clone(p.AllowedMethods)
+	if len(cp.AllowedMethods) == 0 {
+		cp.AllowedMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete}
+	}
+	for i, method := range cp.AllowedMethods {
+		cp.AllowedMethods[i] = strings.ToUpper(method)
+	}
+	cp.methods = strings.Join(cp.AllowedMethods, ", ")
+	cp.headers = strings.Join(p.AllowedHeaders, ", ")
+	cp.exposed = strings.Join(p.ExposedHeaders, ", ")
+	if p.MaxAge > 0 {
+		cp.maxAge = strconv.Itoa(int(p.MaxAge.Seconds()))
+	}
+	return cp, nil
+}
+
+func (m *cors) handle(c *gin.Context) {
+	origin := c.GetHeader("Origin")
+	if origin == "" {
+		c.Next()
+		return
+	}
+
+	policy := m.policies.lookup(c.Request.URL.Path)
+	preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+	h := c.Writer.Header()
+	h.Add("Vary", "Origin")
+
+	if !policy.allowOrigin(origin) {
+		if preflight {
+			m.reject(c, CORSRejectOrigin, http.StatusForbidden)
+			return
+		}
+		// Request biasa tetap diproses, browser yang akan memblokir response
+		c.Next()
+		return
+	}
+
+	if preflight {
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+
+		method := strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))
+		if !slices.Contains(policy.AllowedMethods, method) {
+			m.reject(c, CORSRejectMethod, http.StatusForbidden)
+			return
+		}
+		if !policy.allowHeaders(c.GetHeader("Access-Control-Request-Headers")) {
+			m.reject(c, CORSRejectHeader, http.StatusForbidden)
+			return
+		}
+		if !m.routeExists(method, c.Request.URL.Path) {
+			m.reject(c, CORSRejectRoute, http.StatusNotFound)
+			return
+		}
+
+		policy.writeOrigin(h, origin)
+		h.Set("Access-Control-Allow-Methods", policy.methods)
+		if policy.headers != "" {
+			h.Set("Access-Control-Allow-Headers", policy.headers)
+		}
+		if policy.maxAge != "" {
+			h.Set("Access-Control-Max-Age", policy.maxAge)
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+		return
+	}
+
+	policy.writeOrigin(h, origin)
+	if policy.exposed != "" {
+		h.Set("Access-Control-Expose-Headers", policy.exposed)
+	}
+	c.Next()
+}
+
+func (m *cors) reject(c *gin.Context, reason string, status int) {
+	route := m.routeFor(c.Request.URL.Path)
+	m.metrics.RecordCORSRejection(reason, route)
+	c.AbortWithStatus(status)
+}
+
+func (p *compiledPolicy) allowOrigin(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+	for _, match := range p.matchers {
+		if match(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *compiledPolicy) allowHeaders(requested string) bool {
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.TrimSpace(header)
+		if header == "" {
+			continue
+		}
+		if !slices.ContainsFunc(p.AllowedHeaders, func(allowed string) bool { return strings.EqualFold(allowed, header) }) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *compiledPolicy) writeOrigin(h http.Header, origin string) {
+	if p.anyOrigin {
+		h.Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+	h.Set("Access-Control-Allow-Origin", origin)
+	if p.AllowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// routePattern adalah route gin yang sudah dipecah per segmen untuk pencocokan path
+type routePattern struct {
+	method   string
+	path     string
+	segments []string
+}
+
+func (m *cors) loadRoutes() {
+	m.routesOnce.Do(func() {
+		if m.routes == nil {
+			return
+		}
+		for _, route := range m.routes() {
+			m.patterns = append(m.patterns, routePattern{
+				method:   route.Method,
+				path:     route.Path,
+				segments: strings.Split(strings.Trim(route.Path, "/"), "/"),
+			})
+		}
+	})
+}
+
+func (m *cors) routeExists(method, path string) bool {
+	if m.routes == nil {
+		return true
+	}
+	m.loadRoutes()
+	for _, p := range m.patterns {
+		if p.method == method && p.match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// routeFor mengembalikan pola route yang cocok dengan path untuk label metrik
+func (m *cors) routeFor(path string) string {
+	m.loadRoutes()
+	for _, p := range m.patterns {
+		if p.match(path) {
+			return p.path
+		}
+	}
+	return "unknown"
+}
+
+func (p routePattern) match(path string) bool {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, seg := range p.segments {
+		if strings.HasPrefix(seg, "*") {
+			return true
+		}
+		if i >= len(parts) {
+			return false
+		}
+		if !strings.HasPrefix(seg, ":") && seg != parts[i] {
+			return false
+		}
+	}
+	return len(parts) == len(p.segments)
+}
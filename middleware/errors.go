@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"wyw/apperror"
+	"wyw/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorHandler merender error terakhir yang didaftarkan handler lewat c.Error
+// sebagai application/problem+json
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 {
+			return
+		}
+		WriteProblem(c, c.Errors.Last().Err)
+	}
+}
+
+// WriteProblem menulis err sebagai problem detail bila response belum ditulis
+func WriteProblem(c *gin.Context, err error) {
+	appErr := apperror.From(err)
+	c.Set(apperror.ContextKey, string(appErr.Code))
+
+	if appErr.Code == apperror.CodeInternal {
+		logging.Logger(logging.ModuleHTTP).ErrorContext(c.Request.Context(), "internal error",
+			slog.String("route", c.FullPath()), slog.Any("error", err))
+	}
+
+	if c.Writer.Written() {
+		return
+	}
+	problem := appErr.Problem(c.Request.URL.Path, c.GetString(logging.RequestIDKey))
+	c.Header("Content-Type", apperror.ContentType)
+	c.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// Recovery mengubah panic menjadi problem detail internal_error dan mencatat stack trace-nya
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// http.ErrAbortHandler sengaja memutus koneksi tanpa response, teruskan ke net/http
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+			logging.Logger(logging.ModuleHTTP).ErrorContext(c.Request.Context(), "panic recovered",
+				slog.Any("panic", recovered), slog.String("stack", string(debug.Stack())))
+			WriteProblem(c, fmt.Errorf("panic: %v", recovered))
+		}()
+		c.Next()
+	}
+}
+
+// NoRoute menjawab route yang tidak terdaftar dengan problem detail not_found
+func NoRoute(c *gin.Context) {
+	_ = c.Error(apperror.New(apperror.CodeNotFound, "no route for "+c.Request.Method+" "+c.Request.URL.Path))
+}
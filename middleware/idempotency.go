@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+	"wyw/apperror"
+	"wyw/idempotency"
+	"wyw/logging"
+	"wyw/metric"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HeaderIdempotencyKey adalah header yang dikirim client untuk request yang aman di-retry
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// HeaderIdempotentReplayed menandai response yang berasal dari replay
+const HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+// Hasil pemrosesan Idempotency-Key yang dicatat ke metrik
+const (
+	IdempotencyReplayed   = "replayed"
+	IdempotencyMismatch   = "mismatch"
+	IdempotencyInProgress = "in_progress"
+)
+
+// Idempotency menyimpan response pertama untuk setiap Idempotency-Key lalu me-replay-nya
+// untuk request duplikat. Key yang dipakai ulang dengan payload berbeda ditolak dengan 422.
+// Dipasang per route supaya error handler tetap bisa dirender sebelum response disimpan.
+func Idempotency(store idempotency.Store, ttl time.Duration, metrics *metric.AppMetricsExporter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(HeaderIdempotencyKey)
+		if key == "" || c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+		if len(key) > 255 {
+			_ = c.Error(apperror.New(apperror.CodeValidation, "Idempotency-Key must be at most 255 characters"))
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			_ = c.Error(apperror.Wrap(apperror.CodeInvalidJSON, err, "failed to read request body"))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		route := routeLabel(c)
+		storeKey := hashHex(c.Request.Method, route, c.GetString(logging.UserKey), key)
+		fingerprint := hashHex(c.Request.Method, c.Request.URL.RequestURI(), string(body))
+
+		rec := &idempotency.Record{
+			Key:         storeKey,
+			Fingerprint: fingerprint,
+			CreatedAt:   time.Now(),
+			ExpiresAt:   time.Now().Add(ttl),
+		}
+		reserved, err := store.Reserve(ctx, rec)
+		if err != nil {
+			_ = c.Error(apperror.Wrap(apperror.CodeInternal, err, "failed to reserve idempotency key"))
+			c.Abort()
+			return
+		}
+
+		if !reserved {
+			existing, err := store.Get(ctx, storeKey)
+			if err != nil || existing == nil {
+				// Record hilang di antara Reserve dan Get (kedaluwarsa/dihapus), minta client retry
+				_ = c.Error(apperror.New(apperror.CodeIdempotencyBusy, "retry the request"))
+				c.Abort()
+				return
+			}
+			replay(c, existing, fingerprint, route, metrics)
+			return
+		}
+
+		// Reservasi dilepas bila response tidak tersimpan, termasuk saat handler panic karena
+		// Recovery berada di luar middleware ini, supaya retry tidak tertahan sampai TTL
+		stored := false
+		defer func() {
+			if stored {
+				return
+			}
+			if err := store.Delete(context.WithoutCancel(ctx), storeKey); err != nil {
+				logging.Logger(logging.ModuleHTTP).WarnContext(ctx, "failed to release idempotency key", slog.Any("error", err))
+			}
+		}()
+
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// Error handler global berjalan setelah middleware ini, jadi render problem di sini
+		// supaya body error ikut tersimpan
+		if len(c.Errors) > 0 && !c.Writer.Written() {
+			WriteProblem(c, c.Errors.Last().Err)
+		}
+
+		status := c.Writer.Status()
+		if status >= http.StatusInternalServerError {
+			// Kegagalan server tidak disimpan supaya client bisa retry dengan key yang sama
+			return
+		}
+
+		rec.Completed = true
+		rec.Status = status
+		rec.ContentType = c.Writer.Header().Get("Content-Type")
+		rec.Body = recorder.body.Bytes()
+		if err := store.Complete(ctx, rec); err != nil {
+			logging.Logger(logging.ModuleHTTP).WarnContext(ctx, "failed to store idempotent response", slog.Any("error", err))
+			return
+		}
+		stored = true
+	}
+}
+
+func replay(c *gin.Context, rec *idempotency.Record, fingerprint, route string, metrics *metric.AppMetricsExporter) {
+	switch {
+	case rec.Fingerprint != fingerprint:
+		metrics.RecordIdempotency(route, IdempotencyMismatch)
+		_ = c.Error(apperror.New(apperror.CodeIdempotencyReused, "Idempotency-Key was already used with a different request payload"))
+		c.Abort()
+	case !rec.Completed:
+		metrics.RecordIdempotency(route, IdempotencyInProgress)
+		_ = c.Error(apperror.New(apperror.CodeIdempotencyBusy, "a request with this Idempotency-Key is still being processed"))
+		c.Abort()
+	default:
+		metrics.RecordIdempotency(route, IdempotencyReplayed)
+		c.Header(HeaderIdempotentReplayed, "true")
+		c.Data(rec.Status, rec.ContentType, rec.Body)
+		c.Abort()
+	}
+}
+
+func hashHex(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// bodyRecorder menyalin body response supaya bisa disimpan untuk replay
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
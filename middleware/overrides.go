@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"slices"
+	"strings"
+)
+
+// routeOverrides memilih nilai per prefix path dengan fallback ke nilai default.
+// Prefix terpanjang yang cocok menang, sehingga "/api/v1/admin" mengalahkan "/api/v1".
+type routeOverrides[T any] struct {
+	def      T
+	prefixes []string
+	values   map[string]T
+}
+
+func newRouteOverrides[T any](def T, overrides map[string]T) *routeOverrides[T] {
+	o := &routeOverrides[T]{def: def, values: make(map[string]T, len(overrides))}
+	for prefix, value := range overrides {
+		o.values[prefix] = value
+		o.prefixes = append(o.prefixes, prefix)
+	}
+	slices.SortFunc(o.prefixes, func(a, b string) int { return len(b) - len(a) })
+	return o
+}
+
+func (o *routeOverrides[T]) lookup(path string) T {
+	for _, prefix := range o.prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return o.values[prefix]
+		}
+	}
+	return o.def
+}
@@ -0,0 +1,248 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+	"wyw/apperror"
+	"wyw/logging"
+	"wyw/metric"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Alasan request ditolak oleh lapisan hardening
+const (
+	RejectBodyTooLarge = "body_too_large"
+	RejectTimeout      = "timeout"
+)
+
+// SecurityHeaders berisi header keamanan yang dikirim di setiap response.
+// Field kosong berarti header tersebut tidak dikirim.
+type SecurityHeaders struct {
+	StrictTransportSecurity string
+	ContentSecurityPolicy   string
+	ContentTypeOptions      string
+	ReferrerPolicy          string
+	FrameOptions            string
+}
+
+// DefaultSecurityHeaders cocok untuk endpoint JSON yang tidak pernah dirender sebagai halaman
+var DefaultSecurityHeaders = SecurityHeaders{
+	StrictTransportSecurity: "max-age=31536000; includeSubDomains",
+	ContentSecurityPolicy:   "default-src 'none'; frame-ancestors 'none'",
+	ContentTypeOptions:      "nosniff",
+	ReferrerPolicy:          "no-referrer",
+	FrameOptions:            "DENY",
+}
+
+// SecureHeaders memasang header keamanan, dengan override per prefix path
+func SecureHeaders(def SecurityHeaders, overrides map[string]SecurityHeaders) gin.HandlerFunc {
+	policies := newRouteOverrides(def, overrides)
+	return func(c *gin.Context) {
+		p := policies.lookup(c.Request.URL.Path)
+		h := c.Writer.Header()
+		setIfNotEmpty(h, "Strict-Transport-Security", p.StrictTransportSecurity)
+		setIfNotEmpty(h, "Content-Security-Policy", p.ContentSecurityPolicy)
+		setIfNotEmpty(h, "X-Content-Type-Options", p.ContentTypeOptions)
+		setIfNotEmpty(h, "Referrer-Policy", p.ReferrerPolicy)
+		setIfNotEmpty(h, "X-Frame-Options", p.FrameOptions)
+		c.Next()
+	}
+}
+
+func setIfNotEmpty(h http.Header, key, value string) {
+	if value != "" {
+		h.Set(key, value)
+	}
+}
+
+// BodyLimit menolak request dengan body lebih besar dari batas dengan status 413.
+// overrides berisi batas khusus per prefix path, nilai <= 0 berarti tanpa batas.
+func BodyLimit(def int64, overrides map[string]int64, metrics *metric.AppMetricsExporter) gin.HandlerFunc {
+	limits := newRouteOverrides(def, overrides)
+	return func(c *gin.Context) {
+		limit := limits.lookup(c.Request.URL.Path)
+		if limit <= 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			rejectTooLarge(c, metrics)
+			return
+		}
+
+		if c.Request.ContentLength < 0 {
+			// Chunked: baca sampai limit+1 byte supaya bisa menjawab 413 sebelum handler jalan
+			body, err := io.ReadAll(io.LimitReader(c.Request.Body, limit+1))
+			if err != nil {
+				c.AbortWithStatus(http.StatusBadRequest)
+				return
+			}
+			if int64(len(body)) > limit {
+				rejectTooLarge(c, metrics)
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		} else {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		}
+
+		c.Next()
+	}
+}
+
+func rejectTooLarge(c *gin.Context, metrics *metric.AppMetricsExporter) {
+	metrics.RecordRejectedRequest(RejectBodyTooLarge, routeLabel(c))
+	c.Header("Connection", "close")
+	_ = c.Error(apperror.New(apperror.CodeBodyTooLarge, "request body exceeds the configured limit"))
+	c.Abort()
+}
+
+func routeLabel(c *gin.Context) string {
+	if route := c.FullPath(); route != "" {
+		return route
+	}
+	return "unknown"
+}
+
+// StreamingRoutes adalah route yang menulis response bertahap (SSE, WebSocket, export
+// NDJSON) sehingga tidak boleh dibungkus TimeoutHandler yang mem-buffer seluruh response.
+// Route streaming didaftarkan lewat GET supaya daftar ini selalu sama dengan tabel route.
+type StreamingRoutes struct {
+	paths map[string]struct{}
+}
+
+// NewStreamingRoutes membuat daftar route streaming yang masih kosong
+func NewStreamingRoutes() *StreamingRoutes {
+	return &StreamingRoutes{paths: map[string]struct{}{}}
+}
+
+// GET mendaftarkan route streaming di group. Path tidak boleh berisi parameter karena
+// Match membandingkan path request apa adanya.
+func (s *StreamingRoutes) GET(g *gin.RouterGroup, relativePath string, handlers ...gin.HandlerFunc) {
+	if strings.ContainsAny(relativePath, ":*") {
+		panic("middleware: streaming route " + relativePath + " must not contain parameters")
+	}
+	g.GET(relativePath, handlers...)
+	s.paths[path.Join(g.BasePath(), relativePath)] = struct{}{}
+}
+
+// Match melaporkan apakah request menuju route streaming, dipakai sebagai skip TimeoutHandler
+func (s *StreamingRoutes) Match(r *http.Request) bool {
+	_, ok := s.paths[r.URL.Path]
+	return ok
+}
+
+// TimeoutHandler menghentikan request yang melewati batas waktu dengan 503 problem+json.
+// Request tersebut dicatat lewat metrics dengan route gin aslinya, bukan status yang
+// akhirnya ditulis handler. skip dipakai untuk route streaming.
+//
+// Seperti http.TimeoutHandler, response handler di-buffer dan baru dikirim bila handler
+// selesai tepat waktu.
+func TimeoutHandler(next http.Handler, timeout time.Duration, skip func(*http.Request) bool, metrics *metric.AppMetricsExporter) http.Handler {
+	if timeout <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if skip != nil && skip(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		r = metric.TrackRequest(r.WithContext(ctx))
+
+		tw := &timeoutWriter{header: make(http.Header)}
+		done := make(chan struct{})
+		panicked := make(chan any, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicked <- p
+				}
+			}()
+			next.ServeHTTP(tw, r)
+			close(done)
+		}()
+
+		select {
+		case p := <-panicked:
+			panic(p)
+		case <-done:
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			maps.Copy(w.Header(), tw.header)
+			if tw.status == 0 {
+				tw.status = http.StatusOK
+			}
+			w.WriteHeader(tw.status)
+			_, _ = w.Write(tw.buf.Bytes())
+		case <-ctx.Done():
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			tw.timedOut = true
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				// Client sudah memutus koneksi, status akhir handler dicatat GinMiddleware
+				return
+			}
+			route, requestID := metrics.ObserveTimeout(r, time.Since(start))
+			metrics.RecordRejectedRequest(RejectTimeout, route)
+
+			problem := apperror.New(apperror.CodeTimeout, "request exceeded the server time limit").
+				Problem(r.URL.Path, requestID)
+			w.Header().Set("Content-Type", apperror.ContentType)
+			if requestID != "" {
+				w.Header().Set(logging.HeaderRequestID, requestID)
+			}
+			w.WriteHeader(problem.Status)
+			_ = json.NewEncoder(w).Encode(problem)
+		}
+	})
+}
+
+// timeoutWriter mem-buffer response handler. Setelah timeout, tulisan berikutnya ditolak
+// dengan http.ErrHandlerTimeout supaya handler bisa berhenti.
+type timeoutWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	buf      bytes.Buffer
+	status   int
+	timedOut bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.status != 0 {
+		return
+	}
+	tw.status = status
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.status == 0 {
+		tw.status = http.StatusOK
+	}
+	return tw.buf.Write(b)
+}
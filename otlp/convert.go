@@ -0,0 +1,340 @@
+package otlp
+
+import (
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// state adalah nilai kumulatif terakhir satu series yang sudah berhasil diekspor, dipakai
+// untuk menghitung delta pada export berikutnya
+type state struct {
+	t     time.Time
+	value float64
+	count uint64
+	sum   float64
+	// buckets berisi jumlah per bucket (bukan kumulatif) untuk histogram klasik
+	buckets []uint64
+	// scale, zero, pos dan neg untuk histogram eksponensial, indeks bucket mengikuti OTLP
+	scale    int32
+	zero     uint64
+	pos, neg map[int32]uint64
+}
+
+// converter mengubah hasil Gather registry Prometheus menjadi metricdata OpenTelemetry
+type converter struct {
+	temporality metricdata.Temporality
+	// start dipakai sebagai StartTime series yang tidak punya created timestamp
+	start time.Time
+	prev  map[string]state
+}
+
+func newConverter(temporality metricdata.Temporality, start time.Time) *converter {
+	return &converter{temporality: temporality, start: start, prev: make(map[string]state)}
+}
+
+// convert mengembalikan metrik, jumlah data point, dan state baru yang baru boleh dipakai
+// (lewat commit) setelah export berhasil, supaya delta tidak hilang saat export gagal
+func (c *converter) convert(families []*dto.MetricFamily, now time.Time) ([]metricdata.Metrics, int, map[string]state) {
+	var out []metricdata.Metrics
+	next := make(map[string]state, len(c.prev))
+	points := 0
+	names := make(map[string]bool, len(families))
+	for _, mf := range families {
+		names[mf.GetName()] = true
+	}
+	for _, mf := range families {
+		if len(mf.GetMetric()) == 0 {
+			continue
+		}
+		m := metricdata.Metrics{Name: mf.GetName(), Description: mf.GetHelp(), Unit: unit(mf.GetName())}
+		switch mf.GetType() {
+		case dto.MetricType_COUNTER:
+			// Exporter Prometheus di Collector menambahkan _total kembali untuk sum monotonic,
+			// kecuali bila namanya bentrok dengan family lain (go_memstats_alloc_bytes)
+			if name := strings.TrimSuffix(m.Name, "_total"); !names[name] {
+				m.Name = name
+			}
+			m.Data, points = c.sum(mf, now, next), points+len(mf.GetMetric())
+		case dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+			m.Data, points = gauge(mf, now), points+len(mf.GetMetric())
+		case dto.MetricType_HISTOGRAM:
+			if mf.GetMetric()[0].GetHistogram().Schema != nil {
+				m.Data = c.exponentialHistogram(mf, now, next)
+			} else {
+				m.Data = c.histogram(mf, now, next)
+			}
+			points += len(mf.GetMetric())
+		case dto.MetricType_SUMMARY:
+			m.Data, points = c.summary(mf, now), points+len(mf.GetMetric())
+		default:
+			continue
+		}
+		out = append(out, m)
+	}
+	return out, points, next
+}
+
+// commit menyimpan state dari export yang berhasil. Series yang tidak muncul lagi dibuang.
+func (c *converter) commit(next map[string]state) {
+	c.prev = next
+}
+
+func (c *converter) delta() bool {
+	return c.temporality == metricdata.DeltaTemporality
+}
+
+// startTime memakai created timestamp dari client_golang bila ada
+func (c *converter) startTime(created *timestamppb.Timestamp) time.Time {
+	if created != nil {
+		return created.AsTime()
+	}
+	return c.start
+}
+
+func (c *converter) sum(mf *dto.MetricFamily, now time.Time, next map[string]state) metricdata.Sum[float64] {
+	sum := metricdata.Sum[float64]{Temporality: c.temporality, IsMonotonic: true}
+	for _, m := range mf.GetMetric() {
+		key := seriesKey(mf.GetName(), m)
+		start := c.startTime(m.GetCounter().GetCreatedTimestamp())
+		v := m.GetCounter().GetValue()
+		next[key] = state{t: now, value: v}
+		if prev, ok := c.prev[key]; ok && c.delta() {
+			start = prev.t
+			if v >= prev.value {
+				v -= prev.value
+			}
+		}
+		sum.DataPoints = append(sum.DataPoints, metricdata.DataPoint[float64]{
+			Attributes: attributes(m), StartTime: start, Time: now, Value: v,
+		})
+	}
+	return sum
+}
+
+func gauge(mf *dto.MetricFamily, now time.Time) metricdata.Gauge[float64] {
+	var g metricdata.Gauge[float64]
+	for _, m := range mf.GetMetric() {
+		v := m.GetGauge().GetValue()
+		if mf.GetType() == dto.MetricType_UNTYPED {
+			v = m.GetUntyped().GetValue()
+		}
+		g.DataPoints = append(g.DataPoints, metricdata.DataPoint[float64]{Attributes: attributes(m), Time: now, Value: v})
+	}
+	return g
+}
+
+// histogram mengubah histogram klasik menjadi histogram OTLP dengan batas bucket eksplisit
+func (c *converter) histogram(mf *dto.MetricFamily, now time.Time, next map[string]state) metricdata.Histogram[float64] {
+	hist := metricdata.Histogram[float64]{Temporality: c.temporality}
+	for _, m := range mf.GetMetric() {
+		h := m.GetHistogram()
+		key := seriesKey(mf.GetName(), m)
+		start := c.startTime(h.GetCreatedTimestamp())
+
+		var bounds []float64
+		var counts []uint64
+		var cumulative uint64
+		for _, b := range h.GetBucket() {
+			if math.IsInf(b.GetUpperBound(), 1) {
+				continue
+			}
+			bounds = append(bounds, b.GetUpperBound())
+			counts = append(counts, b.GetCumulativeCount()-cumulative)
+			cumulative = b.GetCumulativeCount()
+		}
+		counts = append(counts, h.GetSampleCount()-cumulative)
+		count, sum := h.GetSampleCount(), h.GetSampleSum()
+		next[key] = state{t: now, count: count, sum: sum, buckets: counts}
+
+		if prev, ok := c.prev[key]; ok && c.delta() {
+			start = prev.t
+			if count >= prev.count && len(prev.buckets) == len(counts) {
+				delta := make([]uint64, len(counts))
+				for i := range counts {
+					delta[i] = counts[i] - prev.buckets[i]
+				}
+				counts, count, sum = delta, count-prev.count, sum-prev.sum
+			}
+		}
+		hist.DataPoints = append(hist.DataPoints, metricdata.HistogramDataPoint[float64]{
+			Attributes: attributes(m), StartTime: start, Time: now,
+			Count: count, Sum: sum, Bounds: bounds, BucketCounts: counts,
+		})
+	}
+	return hist
+}
+
+// exponentialHistogram mengubah native histogram Prometheus menjadi histogram eksponensial
+// OTLP. Keduanya memakai basis 2^(2^-scale); bucket Prometheus i mencakup (base^(i-1), base^i]
+// sedangkan bucket OTLP k mencakup (base^k, base^(k+1)], jadi k = i-1.
+func (c *converter) exponentialHistogram(mf *dto.MetricFamily, now time.Time, next map[string]state) metricdata.ExponentialHistogram[float64] {
+	hist := metricdata.ExponentialHistogram[float64]{Temporality: c.temporality}
+	for _, m := range mf.GetMetric() {
+		h := m.GetHistogram()
+		key := seriesKey(mf.GetName(), m)
+		start := c.startTime(h.GetCreatedTimestamp())
+
+		cur := state{
+			t:     now,
+			count: h.GetSampleCount(),
+			sum:   h.GetSampleSum(),
+			scale: h.GetSchema(),
+			zero:  h.GetZeroCount(),
+			pos:   nativeBuckets(h.GetPositiveSpan(), h.GetPositiveDelta()),
+			neg:   nativeBuckets(h.GetNegativeSpan(), h.GetNegativeDelta()),
+		}
+		next[key] = cur
+		dp := cur
+
+		if prev, ok := c.prev[key]; ok && c.delta() {
+			start = prev.t
+			// Resolusi native histogram bisa turun saat bucket terlalu banyak, samakan
+			// dulu scale state sebelumnya sebelum dikurangkan
+			if prev.scale > cur.scale {
+				prev.pos = downscale(prev.pos, prev.scale-cur.scale)
+				prev.neg = downscale(prev.neg, prev.scale-cur.scale)
+			}
+			if cur.count >= prev.count && prev.scale >= cur.scale {
+				dp = state{
+					count: cur.count - prev.count,
+					sum:   cur.sum - prev.sum,
+					scale: cur.scale,
+					zero:  cur.zero - min(cur.zero, prev.zero),
+					pos:   subtract(cur.pos, prev.pos),
+					neg:   subtract(cur.neg, prev.neg),
+				}
+			}
+		}
+		hist.DataPoints = append(hist.DataPoints, metricdata.ExponentialHistogramDataPoint[float64]{
+			Attributes:     attributes(m),
+			StartTime:      start,
+			Time:           now,
+			Count:          dp.count,
+			Sum:            dp.sum,
+			Scale:          dp.scale,
+			ZeroCount:      dp.zero,
+			ZeroThreshold:  h.GetZeroThreshold(),
+			PositiveBucket: dense(dp.pos),
+			NegativeBucket: dense(dp.neg),
+		})
+	}
+	return hist
+}
+
+// summary selalu kumulatif karena OTLP tidak mendefinisikan temporality untuk summary
+func (c *converter) summary(mf *dto.MetricFamily, now time.Time) metricdata.Summary {
+	var summary metricdata.Summary
+	for _, m := range mf.GetMetric() {
+		s := m.GetSummary()
+		dp := metricdata.SummaryDataPoint{
+			Attributes: attributes(m),
+			StartTime:  c.startTime(s.GetCreatedTimestamp()),
+			Time:       now,
+			Count:      s.GetSampleCount(),
+			Sum:        s.GetSampleSum(),
+		}
+		for _, q := range s.GetQuantile() {
+			dp.QuantileValues = append(dp.QuantileValues, metricdata.QuantileValue{Quantile: q.GetQuantile(), Value: q.GetValue()})
+		}
+		summary.DataPoints = append(summary.DataPoints, dp)
+	}
+	return summary
+}
+
+// nativeBuckets membaca span dan delta native histogram menjadi jumlah per indeks bucket OTLP
+func nativeBuckets(spans []*dto.BucketSpan, deltas []int64) map[int32]uint64 {
+	out := make(map[int32]uint64)
+	var idx int32
+	var count int64
+	i := 0
+	for _, s := range spans {
+		idx += s.GetOffset()
+		for range s.GetLength() {
+			if i >= len(deltas) {
+				return out
+			}
+			count += deltas[i]
+			i++
+			if count > 0 {
+				out[idx-1] = uint64(count)
+			}
+			idx++
+		}
+	}
+	return out
+}
+
+// downscale menggabungkan bucket ke scale yang lebih kecil sebanyak by tingkat
+func downscale(buckets map[int32]uint64, by int32) map[int32]uint64 {
+	out := make(map[int32]uint64, len(buckets))
+	for idx, n := range buckets {
+		out[idx>>by] += n
+	}
+	return out
+}
+
+func subtract(cur, prev map[int32]uint64) map[int32]uint64 {
+	out := make(map[int32]uint64, len(cur))
+	for idx, n := range cur {
+		if d := n - min(n, prev[idx]); d > 0 {
+			out[idx] = d
+		}
+	}
+	return out
+}
+
+// dense mengubah bucket jarang menjadi offset dan deret jumlah yang rapat seperti di OTLP
+func dense(buckets map[int32]uint64) metricdata.ExponentialBucket {
+	if len(buckets) == 0 {
+		return metricdata.ExponentialBucket{}
+	}
+	keys := slices.Sorted(maps.Keys(buckets))
+	lo, hi := keys[0], keys[len(keys)-1]
+	counts := make([]uint64, hi-lo+1)
+	for _, idx := range keys {
+		counts[idx-lo] = buckets[idx]
+	}
+	return metricdata.ExponentialBucket{Offset: lo, Counts: counts}
+}
+
+func attributes(m *dto.Metric) attribute.Set {
+	kvs := make([]attribute.KeyValue, 0, len(m.GetLabel()))
+	for _, lp := range m.GetLabel() {
+		kvs = append(kvs, attribute.String(lp.GetName(), lp.GetValue()))
+	}
+	return attribute.NewSet(kvs...)
+}
+
+// seriesKey menyusun identitas series dari nama dan label. Label dari client_golang sudah
+// terurut sehingga tidak perlu diurutkan ulang.
+func seriesKey(name string, m *dto.Metric) string {
+	var b strings.Builder
+	b.WriteString(name)
+	for _, lp := range m.GetLabel() {
+		b.WriteString("\xff")
+		b.WriteString(lp.GetName())
+		b.WriteString("\xfe")
+		b.WriteString(lp.GetValue())
+	}
+	return b.String()
+}
+
+// unit menurunkan unit UCUM dari akhiran nama metrik Prometheus
+func unit(name string) string {
+	name = strings.TrimSuffix(name, "_total")
+	switch {
+	case strings.HasSuffix(name, "_seconds"):
+		return "s"
+	case strings.HasSuffix(name, "_bytes"):
+		return "By"
+	}
+	return ""
+}
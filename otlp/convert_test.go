@@ -0,0 +1,201 @@
+package otlp
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	start = time.Unix(1700000000, 0)
+	t1    = start.Add(time.Minute)
+	t2    = start.Add(2 * time.Minute)
+)
+
+func counterFamily(v float64) *dto.MetricFamily {
+	return &dto.MetricFamily{
+		Name: proto.String("jobs_total"),
+		Type: dto.MetricType_COUNTER.Enum(),
+		Metric: []*dto.Metric{{
+			Label:   []*dto.LabelPair{{Name: proto.String("queue"), Value: proto.String("mail")}},
+			Counter: &dto.Counter{Value: proto.Float64(v)},
+		}},
+	}
+}
+
+// classicFamily membuat histogram dengan bucket le=1, le=5 dan +Inf dari jumlah kumulatif
+func classicFamily(le1, le5, count uint64, sum float64) *dto.MetricFamily {
+	return &dto.MetricFamily{
+		Name: proto.String("request_duration_seconds"),
+		Type: dto.MetricType_HISTOGRAM.Enum(),
+		Metric: []*dto.Metric{{Histogram: &dto.Histogram{
+			SampleCount: proto.Uint64(count),
+			SampleSum:   proto.Float64(sum),
+			Bucket: []*dto.Bucket{
+				{UpperBound: proto.Float64(1), CumulativeCount: proto.Uint64(le1)},
+				{UpperBound: proto.Float64(5), CumulativeCount: proto.Uint64(le5)},
+			},
+		}}},
+	}
+}
+
+// nativeFamily membuat native histogram dengan satu span bucket positif
+func nativeFamily(schema int32, offset int32, deltas []int64, zero uint64, sum float64) *dto.MetricFamily {
+	count := zero
+	var n int64
+	for _, d := range deltas {
+		n += d
+		count += uint64(n)
+	}
+	return &dto.MetricFamily{
+		Name: proto.String("request_size_bytes"),
+		Type: dto.MetricType_HISTOGRAM.Enum(),
+		Metric: []*dto.Metric{{Histogram: &dto.Histogram{
+			SampleCount:   proto.Uint64(count),
+			SampleSum:     proto.Float64(sum),
+			Schema:        proto.Int32(schema),
+			ZeroThreshold: proto.Float64(1e-128),
+			ZeroCount:     proto.Uint64(zero),
+			PositiveSpan:  []*dto.BucketSpan{{Offset: proto.Int32(offset), Length: proto.Uint32(uint32(len(deltas)))}},
+			PositiveDelta: deltas,
+		}}},
+	}
+}
+
+// export menjalankan convert untuk satu family lalu commit seperti export yang berhasil
+func export(t *testing.T, c *converter, mf *dto.MetricFamily, now time.Time) metricdata.Aggregation {
+	t.Helper()
+	data, next := convertOne(t, c, mf, now)
+	c.commit(next)
+	return data
+}
+
+func convertOne(t *testing.T, c *converter, mf *dto.MetricFamily, now time.Time) (metricdata.Aggregation, map[string]state) {
+	t.Helper()
+	metrics, points, next := c.convert([]*dto.MetricFamily{mf}, now)
+	if len(metrics) != 1 || points != 1 {
+		t.Fatalf("converted %d metrics with %d points, want 1", len(metrics), points)
+	}
+	return metrics[0].Data, next
+}
+
+func TestDeltaSum(t *testing.T) {
+	c := newConverter(metricdata.DeltaTemporality, start)
+	for _, step := range []struct {
+		name  string
+		value float64
+		now   time.Time
+		want  float64
+		start time.Time
+	}{
+		{name: "first export", value: 10, now: t1, want: 10, start: start},
+		{name: "increase", value: 15, now: t2, want: 5, start: t1},
+		// Counter yang turun berarti proses di-restart, nilainya adalah kenaikan sejak reset
+		{name: "reset", value: 3, now: t2.Add(time.Minute), want: 3, start: t2},
+	} {
+		sum, ok := export(t, c, counterFamily(step.value), step.now).(metricdata.Sum[float64])
+		if !ok || sum.Temporality != metricdata.DeltaTemporality || !sum.IsMonotonic {
+			t.Fatalf("%s: data = %#v, want a monotonic delta sum", step.name, sum)
+		}
+		dp := sum.DataPoints[0]
+		if dp.Value != step.want || !dp.StartTime.Equal(step.start) || !dp.Time.Equal(step.now) {
+			t.Fatalf("%s: point = %v from %s to %s, want %v from %s", step.name, dp.Value, dp.StartTime, dp.Time, step.want, step.start)
+		}
+	}
+}
+
+func TestDeltaKeptUntilCommit(t *testing.T) {
+	c := newConverter(metricdata.DeltaTemporality, start)
+	export(t, c, counterFamily(10), t1)
+
+	// Export yang gagal tidak di-commit, jadi export berikutnya masih menghitung dari t1
+	convertOne(t, c, counterFamily(12), t2)
+	sum := export(t, c, counterFamily(15), t2.Add(time.Minute)).(metricdata.Sum[float64])
+	if dp := sum.DataPoints[0]; dp.Value != 5 || !dp.StartTime.Equal(t1) {
+		t.Fatalf("point after a failed export = %v from %s, want 5 from %s", dp.Value, dp.StartTime, t1)
+	}
+}
+
+func TestCumulativeSum(t *testing.T) {
+	c := newConverter(metricdata.CumulativeTemporality, start)
+	export(t, c, counterFamily(10), t1)
+	sum := export(t, c, counterFamily(15), t2).(metricdata.Sum[float64])
+	if dp := sum.DataPoints[0]; dp.Value != 15 || !dp.StartTime.Equal(start) {
+		t.Fatalf("cumulative point = %v from %s, want 15 from %s", dp.Value, dp.StartTime, start)
+	}
+	if sum.DataPoints[0].Attributes.Len() != 1 {
+		t.Fatalf("attributes = %v, want the queue label", sum.DataPoints[0].Attributes)
+	}
+}
+
+func TestDeltaClassicHistogram(t *testing.T) {
+	c := newConverter(metricdata.DeltaTemporality, start)
+	first := export(t, c, classicFamily(2, 5, 6, 10), t1).(metricdata.Histogram[float64])
+	if dp := first.DataPoints[0]; !slices.Equal(dp.BucketCounts, []uint64{2, 3, 1}) || !slices.Equal(dp.Bounds, []float64{1, 5}) || dp.Count != 6 {
+		t.Fatalf("first point counts=%v bounds=%v count=%d, want per-bucket counts without +Inf", dp.BucketCounts, dp.Bounds, dp.Count)
+	}
+
+	second := export(t, c, classicFamily(3, 8, 10, 25), t2).(metricdata.Histogram[float64])
+	dp := second.DataPoints[0]
+	if !slices.Equal(dp.BucketCounts, []uint64{1, 2, 1}) || dp.Count != 4 || dp.Sum != 15 || !dp.StartTime.Equal(t1) {
+		t.Fatalf("delta point counts=%v count=%d sum=%v start=%s, want [1 2 1], 4, 15 from %s", dp.BucketCounts, dp.Count, dp.Sum, dp.StartTime, t1)
+	}
+
+	// Setelah reset histogram dikirim apa adanya
+	reset := export(t, c, classicFamily(1, 1, 2, 1.5), t2.Add(time.Minute)).(metricdata.Histogram[float64])
+	if dp := reset.DataPoints[0]; !slices.Equal(dp.BucketCounts, []uint64{1, 0, 1}) || dp.Count != 2 || dp.Sum != 1.5 {
+		t.Fatalf("point after a reset counts=%v count=%d sum=%v, want the values since the reset", dp.BucketCounts, dp.Count, dp.Sum)
+	}
+}
+
+func TestNativeHistogramBucketShift(t *testing.T) {
+	c := newConverter(metricdata.CumulativeTemporality, start)
+	// Schema 0: bucket Prometheus 1 adalah (1, 2] dan bucket 2 adalah (2, 4], di OTLP
+	// keduanya menjadi bucket 0 dan 1
+	hist := export(t, c, nativeFamily(0, 1, []int64{2, 1}, 1, 9), t1).(metricdata.ExponentialHistogram[float64])
+	dp := hist.DataPoints[0]
+	if dp.Scale != 0 || dp.PositiveBucket.Offset != 0 || !slices.Equal(dp.PositiveBucket.Counts, []uint64{2, 3}) {
+		t.Fatalf("positive buckets scale=%d offset=%d counts=%v, want scale 0 offset 0 [2 3]", dp.Scale, dp.PositiveBucket.Offset, dp.PositiveBucket.Counts)
+	}
+	if dp.Count != 6 || dp.ZeroCount != 1 || dp.ZeroThreshold != 1e-128 {
+		t.Fatalf("count=%d zero=%d threshold=%v", dp.Count, dp.ZeroCount, dp.ZeroThreshold)
+	}
+}
+
+func TestDeltaNativeHistogram(t *testing.T) {
+	c := newConverter(metricdata.DeltaTemporality, start)
+	// Schema 1, bucket Prometheus 1..2 menjadi bucket OTLP 0 dan 1 dengan jumlah 2 dan 3
+	export(t, c, nativeFamily(1, 1, []int64{2, 1}, 1, 9), t1)
+
+	// Subtract pada scale yang sama: bucket OTLP 0..2 menjadi 2, 4 dan 1
+	hist := export(t, c, nativeFamily(1, 1, []int64{2, 2, -3}, 2, 14), t2).(metricdata.ExponentialHistogram[float64])
+	dp := hist.DataPoints[0]
+	if dp.Scale != 1 || dp.PositiveBucket.Offset != 1 || !slices.Equal(dp.PositiveBucket.Counts, []uint64{1, 1}) {
+		t.Fatalf("delta buckets scale=%d offset=%d counts=%v, want scale 1 offset 1 [1 1]", dp.Scale, dp.PositiveBucket.Offset, dp.PositiveBucket.Counts)
+	}
+	if dp.Count != 3 || dp.ZeroCount != 1 || dp.Sum != 5 || !dp.StartTime.Equal(t1) {
+		t.Fatalf("delta count=%d zero=%d sum=%v start=%s", dp.Count, dp.ZeroCount, dp.Sum, dp.StartTime)
+	}
+
+	// Resolusi turun ke schema 0: bucket OTLP 0..2 di scale 1 digabung menjadi bucket 0
+	// (2+4) dan 1 (1) sebelum dikurangkan dari bucket baru 0 (7) dan 1 (3)
+	hist = export(t, c, nativeFamily(0, 1, []int64{7, -4}, 2, 30), t2.Add(time.Minute)).(metricdata.ExponentialHistogram[float64])
+	dp = hist.DataPoints[0]
+	if dp.Scale != 0 || dp.PositiveBucket.Offset != 0 || !slices.Equal(dp.PositiveBucket.Counts, []uint64{1, 2}) {
+		t.Fatalf("downscaled delta scale=%d offset=%d counts=%v, want scale 0 offset 0 [1 2]", dp.Scale, dp.PositiveBucket.Offset, dp.PositiveBucket.Counts)
+	}
+	if dp.Count != 3 || dp.ZeroCount != 0 || dp.Sum != 16 {
+		t.Fatalf("downscaled delta count=%d zero=%d sum=%v, want 3, 0, 16", dp.Count, dp.ZeroCount, dp.Sum)
+	}
+
+	// Sample count yang turun berarti reset, histogram dikirim apa adanya
+	hist = export(t, c, nativeFamily(0, 1, []int64{1}, 0, 1), t2.Add(2*time.Minute)).(metricdata.ExponentialHistogram[float64])
+	dp = hist.DataPoints[0]
+	if dp.Count != 1 || !slices.Equal(dp.PositiveBucket.Counts, []uint64{1}) || dp.PositiveBucket.Offset != 0 {
+		t.Fatalf("point after a reset count=%d offset=%d counts=%v, want the values since the reset", dp.Count, dp.PositiveBucket.Offset, dp.PositiveBucket.Counts)
+	}
+}
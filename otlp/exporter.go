@@ -0,0 +1,205 @@
+package otlp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+	"wyw/logging"
+	"wyw/metric"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// Protokol transport OTLP
+const (
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http"
+)
+
+// Temporality data point counter dan histogram
+const (
+	TemporalityCumulative = "cumulative"
+	TemporalityDelta      = "delta"
+)
+
+// Options mengatur Exporter
+type Options struct {
+	Protocol string
+	// Endpoint adalah URL collector, misalnya http://localhost:4317 untuk gRPC atau
+	// http://localhost:4318/v1/metrics untuk HTTP. Skema http berarti tanpa TLS.
+	Endpoint string
+	Headers  map[string]string
+	Interval time.Duration
+	Timeout  time.Duration
+	// Temporality cumulative cocok untuk backend Prometheus-like, delta untuk backend seperti
+	// Datadog atau Dynatrace yang mengharapkan selisih per interval
+	Temporality string
+	ServiceName string
+	Environment string
+	// Attributes ditambahkan ke resource, OTEL_RESOURCE_ATTRIBUTES tetap dihormati
+	Attributes map[string]string
+}
+
+// Exporter menjembatani registry AppMetricsExporter ke OTLP. Metrik HTTP dan bisnis cukup
+// diinstrumentasi sekali lewat Prometheus lalu dikirim juga ke OpenTelemetry Collector.
+type Exporter struct {
+	metrics  *metric.AppMetricsExporter
+	exporter sdkmetric.Exporter
+	resource *resource.Resource
+	opts     Options
+
+	// mu menjaga converter karena Export bisa dipanggil dari Run dan saat shutdown
+	mu   sync.Mutex
+	conv *converter
+}
+
+// New membuat Exporter. Koneksi gRPC dibuka secara lazy sehingga collector yang belum siap
+// tidak menggagalkan startup.
+func New(ctx context.Context, metrics *metric.AppMetricsExporter, opts Options) (*Exporter, error) {
+	if opts.Interval <= 0 {
+		opts.Interval = 30 * time.Second
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.ServiceName == "" {
+		opts.ServiceName = "wyw"
+	}
+	temporality := metricdata.CumulativeTemporality
+	switch opts.Temporality {
+	case "", TemporalityCumulative:
+	case TemporalityDelta:
+		temporality = metricdata.DeltaTemporality
+	default:
+		return nil, fmt.Errorf("otlp temporality must be %s or %s", TemporalityCumulative, TemporalityDelta)
+	}
+
+	var exporter sdkmetric.Exporter
+	var err error
+	switch opts.Protocol {
+	case "", ProtocolGRPC:
+		grpcOpts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithTimeout(opts.Timeout), otlpmetricgrpc.WithHeaders(opts.Headers)}
+		if opts.Endpoint != "" {
+			grpcOpts = append(grpcOpts, otlpmetricgrpc.WithEndpointURL(opts.Endpoint))
+		}
+		exporter, err = otlpmetricgrpc.New(ctx, grpcOpts...)
+	case ProtocolHTTP:
+		httpOpts := []otlpmetrichttp.Option{otlpmetrichttp.WithTimeout(opts.Timeout), otlpmetrichttp.WithHeaders(opts.Headers)}
+		if opts.Endpoint != "" {
+			httpOpts = append(httpOpts, otlpmetrichttp.WithEndpointURL(opts.Endpoint))
+		}
+		exporter, err = otlpmetrichttp.New(ctx, httpOpts...)
+	default:
+		return nil, fmt.Errorf("otlp protocol must be %s or %s", ProtocolGRPC, ProtocolHTTP)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := newResource(ctx, opts)
+	if errors.Is(err, resource.ErrPartialResource) {
+		// Sebagian detector gagal (misalnya host), atribut lain tetap dipakai
+		logging.Logger(logging.ModuleMetric).Warn("incomplete OTLP resource", slog.Any("error", err))
+	} else if err != nil {
+		return nil, err
+	}
+	return &Exporter{
+		metrics:  metrics,
+		exporter: exporter,
+		resource: res,
+		opts:     opts,
+		conv:     newConverter(temporality, time.Now()),
+	}, nil
+}
+
+// newResource menyusun resource dari build info, konfigurasi, host dan environment OTEL_*
+func newResource(ctx context.Context, opts Options) (*resource.Resource, error) {
+	build := metric.Build()
+	attrs := []attribute.KeyValue{
+		semconv.ServiceName(opts.ServiceName),
+		semconv.ServiceVersion(build.Version),
+		semconv.VCSRefHeadRevision(build.Commit),
+	}
+	if opts.Environment != "" {
+		attrs = append(attrs, semconv.DeploymentEnvironmentName(opts.Environment))
+	}
+	for _, k := range slices.Sorted(maps.Keys(opts.Attributes)) {
+		attrs = append(attrs, attribute.String(k, opts.Attributes[k]))
+	}
+	return resource.New(ctx,
+		resource.WithSchemaURL(semconv.SchemaURL),
+		resource.WithHost(),
+		resource.WithProcessRuntimeName(),
+		resource.WithProcessRuntimeVersion(),
+		resource.WithAttributes(attrs...),
+		resource.WithFromEnv(),
+	)
+}
+
+// Run mengekspor setiap interval sampai ctx selesai, lalu mengekspor sekali lagi dan
+// menutup koneksi
+func (e *Exporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			e.shutdown()
+			return
+		case <-ticker.C:
+		}
+		if err := e.Export(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			logging.Logger(logging.ModuleMetric).Warn("failed to export metrics over OTLP", slog.Any("error", err))
+		}
+	}
+}
+
+func (e *Exporter) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), e.opts.Timeout)
+	defer cancel()
+	if err := e.Export(ctx, time.Now()); err != nil {
+		logging.Logger(logging.ModuleMetric).Warn("failed to export final metrics over OTLP", slog.Any("error", err))
+	}
+	if err := e.exporter.Shutdown(ctx); err != nil {
+		logging.Logger(logging.ModuleMetric).Warn("failed to shut down OTLP exporter", slog.Any("error", err))
+	}
+}
+
+// Export mengirim nilai registry pada waktu now. Untuk temporality delta, state baru hanya
+// disimpan bila export berhasil sehingga export berikutnya mencakup interval yang gagal.
+func (e *Exporter) Export(ctx context.Context, now time.Time) error {
+	families, err := e.metrics.Gather()
+	if err != nil && len(families) == 0 {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	metrics, points, next := e.conv.convert(families, now)
+	rm := &metricdata.ResourceMetrics{
+		Resource: e.resource,
+		ScopeMetrics: []metricdata.ScopeMetrics{{
+			Scope:   instrumentation.Scope{Name: "wyw/metric", Version: metric.Build().Version},
+			Metrics: metrics,
+		}},
+	}
+	if err := e.exporter.Export(ctx, rm); err != nil {
+		e.metrics.RecordOTLPExport("failure", points)
+		return err
+	}
+	e.conv.commit(next)
+	e.metrics.RecordOTLPExport("success", points)
+	return nil
+}
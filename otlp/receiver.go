@@ -0,0 +1,156 @@
+package otlp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricpb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// Receiver adalah pengganti OpenTelemetry Collector untuk mencoba export secara lokal.
+// Setiap request OTLP metrics lewat gRPC atau HTTP dicetak ringkasannya ke w.
+type Receiver struct {
+	colmetricpb.UnimplementedMetricsServiceServer
+
+	mu      sync.Mutex
+	w       io.Writer
+	verbose bool
+}
+
+// NewReceiver membuat Receiver. verbose mencetak setiap data point.
+func NewReceiver(w io.Writer, verbose bool) *Receiver {
+	return &Receiver{w: w, verbose: verbose}
+}
+
+// Register mendaftarkan MetricsService ke server gRPC
+func (r *Receiver) Register(s *grpc.Server) {
+	colmetricpb.RegisterMetricsServiceServer(s, r)
+}
+
+// Export mengimplementasikan MetricsService gRPC
+func (r *Receiver) Export(_ context.Context, req *colmetricpb.ExportMetricsServiceRequest) (*colmetricpb.ExportMetricsServiceResponse, error) {
+	r.print("grpc", req)
+	return &colmetricpb.ExportMetricsServiceResponse{}, nil
+}
+
+// ServeHTTP menerima POST /v1/metrics dengan body protobuf
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if ct := req.Header.Get("Content-Type"); ct != "application/x-protobuf" {
+		http.Error(w, "only application/x-protobuf is supported", http.StatusUnsupportedMediaType)
+		return
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var msg colmetricpb.ExportMetricsServiceRequest
+	if err := proto.Unmarshal(body, &msg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.print("http", &msg)
+
+	resp, _ := proto.Marshal(&colmetricpb.ExportMetricsServiceResponse{})
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	_, _ = w.Write(resp)
+}
+
+func (r *Receiver) print(transport string, req *colmetricpb.ExportMetricsServiceRequest) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rm := range req.GetResourceMetrics() {
+		var metrics, points int
+		for _, sm := range rm.GetScopeMetrics() {
+			metrics += len(sm.GetMetrics())
+			for _, m := range sm.GetMetrics() {
+				points += dataPoints(m)
+			}
+		}
+		fmt.Fprintf(r.w, "%s otlp/%s: %d metrics, %d data points from {%s}\n",
+			time.Now().Format(time.TimeOnly), transport, metrics, points, formatAttributes(rm.GetResource().GetAttributes()))
+		if !r.verbose {
+			continue
+		}
+		for _, sm := range rm.GetScopeMetrics() {
+			for _, m := range sm.GetMetrics() {
+				fmt.Fprintf(r.w, "  %s %s unit=%q points=%d\n", m.GetName(), kind(m), m.GetUnit(), dataPoints(m))
+			}
+		}
+	}
+}
+
+// kind menjelaskan tipe dan temporality metrik, misalnya "sum/delta/monotonic"
+func kind(m *metricpb.Metric) string {
+	switch d := m.GetData().(type) {
+	case *metricpb.Metric_Gauge:
+		return "gauge"
+	case *metricpb.Metric_Sum:
+		k := "sum/" + temporality(d.Sum.GetAggregationTemporality())
+		if d.Sum.GetIsMonotonic() {
+			k += "/monotonic"
+		}
+		return k
+	case *metricpb.Metric_Histogram:
+		return "histogram/" + temporality(d.Histogram.GetAggregationTemporality())
+	case *metricpb.Metric_ExponentialHistogram:
+		h := d.ExponentialHistogram
+		k := "exponential_histogram/" + temporality(h.GetAggregationTemporality())
+		if dps := h.GetDataPoints(); len(dps) > 0 {
+			k += fmt.Sprintf("/scale=%d", dps[0].GetScale())
+		}
+		return k
+	case *metricpb.Metric_Summary:
+		return "summary"
+	}
+	return "unknown"
+}
+
+func temporality(t metricpb.AggregationTemporality) string {
+	switch t {
+	case metricpb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA:
+		return TemporalityDelta
+	case metricpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE:
+		return TemporalityCumulative
+	}
+	return "unspecified"
+}
+
+func dataPoints(m *metricpb.Metric) int {
+	switch d := m.GetData().(type) {
+	case *metricpb.Metric_Gauge:
+		return len(d.Gauge.GetDataPoints())
+	case *metricpb.Metric_Sum:
+		return len(d.Sum.GetDataPoints())
+	case *metricpb.Metric_Histogram:
+		return len(d.Histogram.GetDataPoints())
+	case *metricpb.Metric_ExponentialHistogram:
+		return len(d.ExponentialHistogram.GetDataPoints())
+	case *metricpb.Metric_Summary:
+		return len(d.Summary.GetDataPoints())
+	}
+	return 0
+}
+
+func formatAttributes(attrs []*commonpb.KeyValue) string {
+	parts := make([]string, 0, len(attrs))
+	for _, kv := range attrs {
+		if strings.HasPrefix(kv.GetKey(), "service.") || strings.HasPrefix(kv.GetKey(), "deployment.") {
+			parts = append(parts, kv.GetKey()+"="+kv.GetValue().GetStringValue())
+		}
+	}
+	return strings.Join(parts, ", ")
+}
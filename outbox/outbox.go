@@ -0,0 +1,53 @@
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Message adalah satu event bisnis yang menunggu dikirim ke sink. Baris ditulis di transaksi
+// yang sama dengan perubahan data, sehingga event hanya ada bila perubahan datanya commit.
+type Message struct {
+	ID uint64 `json:"id" gorm:"primaryKey;autoIncrement"`
+	// Aggregate adalah entitas pemilik event, contoh "user:user123". Event dengan aggregate
+	// yang sama dikirim berurutan sesuai ID.
+	Aggregate string          `json:"aggregate" gorm:"index;size:191"`
+	EventType string          `json:"event_type" gorm:"size:64"`
+	Payload   json.RawMessage `json:"payload" gorm:"type:json"`
+	CreatedAt time.Time       `json:"created_at"`
+
+	Attempts      int        `json:"-"`
+	NextAttemptAt time.Time  `json:"-"`
+	LastError     string     `json:"-" gorm:"size:512"`
+	DeliveredAt   *time.Time `json:"-" gorm:"index:idx_outbox_pending"`
+	// FailedAt diisi bila pengiriman melewati MaxAttempts, message tidak dicoba lagi
+	FailedAt *time.Time `json:"-" gorm:"index:idx_outbox_pending"`
+}
+
+func (Message) TableName() string {
+	return "outbox"
+}
+
+// Migrate memastikan tabel outbox ada
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(&Message{})
+}
+
+// Enqueue menulis event ke outbox memakai tx. Panggil di dalam transaksi yang sama
+// dengan perubahan data supaya keduanya commit atau rollback bersama.
+func Enqueue(tx *gorm.DB, aggregate, eventType string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	return tx.Create(&Message{
+		Aggregate:     aggregate,
+		EventType:     eventType,
+		Payload:       body,
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	}).Error
+}
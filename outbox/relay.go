@@ -0,0 +1,199 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+	"wyw/logging"
+	"wyw/metric"
+	"wyw/retry"
+	"wyw/textutil"
+
+	"gorm.io/gorm"
+)
+
+// Sink adalah tujuan pengiriman event outbox. Deliver harus idempotent di sisi penerima
+// karena relay menjamin at-least-once, bukan exactly-once.
+type Sink interface {
+	Name() string
+	Deliver(ctx context.Context, msg Message) error
+}
+
+// RelayOptions mengatur polling dan retry relay
+type RelayOptions struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// MinBackoff dan MaxBackoff membatasi jeda retry eksponensial
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// MaxAttempts menandai message gagal permanen setelah sekian percobaan supaya satu
+	// event rusak tidak menahan aggregate-nya selamanya
+	MaxAttempts int
+	// Lease adalah lama message di-klaim satu relay sebelum relay lain boleh mengambilnya,
+	// misalnya karena instance yang mengklaim mati di tengah pengiriman
+	Lease time.Duration
+}
+
+// Relay membaca outbox secara periodik dan mengirim event ke semua sink. Beberapa instance
+// boleh menjalankan relay bersamaan: message di-klaim dulu sebelum dikirim dan aggregate
+// yang message terdepannya sedang di-klaim atau menunggu retry dilewati seluruhnya, sehingga
+// urutan per aggregate tetap terjaga.
+type Relay struct {
+	db      *gorm.DB
+	sinks   []Sink
+	metrics *metric.AppMetricsExporter
+	opts    RelayOptions
+}
+
+// NewRelay membuat Relay dengan nilai default untuk opsi yang kosong
+func NewRelay(db *gorm.DB, sinks []Sink, metrics *metric.AppMetricsExporter, opts RelayOptions) *Relay {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = time.Second
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = 5 * time.Minute
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 20
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = time.Minute
+	}
+	return &Relay{db: db, sinks: sinks, metrics: metrics, opts: opts}
+}
+
+// Run menjalankan relay sampai ctx selesai
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.RunOnce(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			logging.Logger(logging.ModuleDB).Error("outbox relay failed", slog.Any("error", err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Relay) pending(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&Message{}).Where("delivered_at IS NULL AND failed_at IS NULL")
+}
+
+// RunOnce mengirim satu batch message yang sudah jatuh tempo lalu memperbarui metrik backlog
+func (r *Relay) RunOnce(ctx context.Context) error {
+	now := time.Now()
+	// Message yang belum jatuh tempo (menunggu retry atau sedang di-klaim relay lain) menahan
+	// semua message berikutnya dari aggregate yang sama
+	waiting := r.pending(ctx).Select("aggregate").Where("next_attempt_at > ?", now)
+	var batch []Message
+	err := r.pending(ctx).
+		Where("next_attempt_at <= ? AND aggregate NOT IN (?)", now, waiting).
+		Order("id ASC").Limit(r.opts.BatchSize).Find(&batch).Error
+	if err != nil {
+		return err
+	}
+
+	// blocked berisi aggregate yang message sebelumnya belum terkirim, message berikutnya
+	// dari aggregate itu harus menunggu supaya urutannya tidak tertukar
+	blocked := map[string]bool{}
+	for _, msg := range batch {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if blocked[msg.Aggregate] {
+			continue
+		}
+		// Gagal klaim berarti relay lain sudah mengambil message ini lebih dulu
+		if !r.claim(ctx, &msg) {
+			blocked[msg.Aggregate] = true
+			continue
+		}
+		if err := r.deliver(ctx, msg); err != nil {
+			blocked[msg.Aggregate] = true
+		}
+	}
+
+	return r.updateBacklog(ctx)
+}
+
+// claim menggeser next_attempt_at sejauh Lease secara kondisional, sama seperti klaim
+// delivery webhook, supaya hanya satu relay yang mengirim message ini
+func (r *Relay) claim(ctx context.Context, msg *Message) bool {
+	lease := time.Now().Add(r.opts.Lease)
+	res := r.db.WithContext(ctx).Model(&Message{}).
+		Where("id = ? AND next_attempt_at = ? AND delivered_at IS NULL AND failed_at IS NULL", msg.ID, msg.NextAttemptAt).
+		Update("next_attempt_at", lease)
+	if res.Error != nil || res.RowsAffected != 1 {
+		return false
+	}
+	msg.NextAttemptAt = lease
+	return true
+}
+
+func (r *Relay) deliver(ctx context.Context, msg Message) error {
+	var deliverErr error
+	for _, sink := range r.sinks {
+		if err := sink.Deliver(ctx, msg); err != nil {
+			r.metrics.RecordOutboxDelivery(sink.Name(), "failed")
+			deliverErr = errors.Join(deliverErr, err)
+			continue
+		}
+		r.metrics.RecordOutboxDelivery(sink.Name(), "delivered")
+	}
+
+	now := time.Now()
+	if deliverErr == nil {
+		r.metrics.ObserveOutboxLag(now.Sub(msg.CreatedAt))
+		return r.db.WithContext(ctx).Model(&msg).Update("delivered_at", now).Error
+	}
+
+	// Semua sink dicoba ulang, sink yang sudah berhasil bisa menerima duplikat (at-least-once)
+	attempts := msg.Attempts + 1
+	updates := map[string]any{
+		"attempts":        attempts,
+		"next_attempt_at": now.Add(retry.Backoff(attempts, r.opts.MinBackoff, r.opts.MaxBackoff)),
+		"last_error":      textutil.Truncate(deliverErr.Error(), 512),
+	}
+	if attempts >= r.opts.MaxAttempts {
+		updates["failed_at"] = now
+		logging.Logger(logging.ModuleDB).Error("outbox message dropped after max attempts",
+			slog.Uint64("id", msg.ID), slog.String("aggregate", msg.Aggregate), slog.String("event_type", msg.EventType),
+			slog.Int("attempts", attempts), slog.Any("error", deliverErr))
+	} else {
+		logging.Logger(logging.ModuleDB).Warn("outbox delivery failed, will retry",
+			slog.Uint64("id", msg.ID), slog.String("aggregate", msg.Aggregate), slog.Int("attempts", attempts),
+			slog.Any("error", deliverErr))
+	}
+	if err := r.db.WithContext(ctx).Model(&msg).Updates(updates).Error; err != nil {
+		return err
+	}
+	return deliverErr
+}
+
+func (r *Relay) updateBacklog(ctx context.Context) error {
+	var pending int64
+	if err := r.pending(ctx).Count(&pending).Error; err != nil {
+		return err
+	}
+	var age time.Duration
+	if pending > 0 {
+		var oldest Message
+		if err := r.pending(ctx).Select("id", "created_at").Order("id ASC").Limit(1).Find(&oldest).Error; err != nil {
+			return err
+		}
+		age = time.Since(oldest.CreatedAt)
+	}
+	r.metrics.SetOutboxBacklog(pending, age)
+	return nil
+}
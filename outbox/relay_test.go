@@ -0,0 +1,294 @@
+package outbox
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"wyw/metric"
+	"wyw/testutil"
+
+	"gorm.io/gorm"
+)
+
+// delivered adalah message yang diterima webhook sink beserta header ID-nya
+type delivered struct {
+	Message
+	ID string
+}
+
+// receiver adalah penerima webhook untuk test
+type receiver struct {
+	*testutil.Receiver[delivered]
+}
+
+func newReceiver(t *testing.T) receiver {
+	t.Helper()
+	return receiver{testutil.NewReceiver(t, func(r *http.Request) (delivered, error) {
+		msg, err := testutil.DecodeJSON[Message](r)
+		return delivered{Message: msg, ID: r.Header.Get(HeaderMessageID)}, err
+	})}
+}
+
+// setFail menjawab 500 untuk message yang memenuhi fail
+func (rc receiver) setFail(fail func(Message) bool) {
+	if fail == nil {
+		rc.SetStatus(nil)
+		return
+	}
+	rc.SetStatus(func(d delivered) int {
+		if fail(d.Message) {
+			return http.StatusInternalServerError
+		}
+		return http.StatusNoContent
+	})
+}
+
+// events mengembalikan "aggregate/event_type" dari message yang diterima, berurutan
+func (rc receiver) events() []string {
+	var out []string
+	for _, d := range rc.Received() {
+		out = append(out, d.Aggregate+"/"+d.EventType)
+	}
+	return out
+}
+
+func (rc receiver) ids() []string {
+	var out []string
+	for _, d := range rc.Received() {
+		out = append(out, d.ID)
+	}
+	return out
+}
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := testutil.NewDB(t)
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func enqueue(t *testing.T, db *gorm.DB, aggregate, eventType string) {
+	t.Helper()
+	if err := Enqueue(db, aggregate, eventType, map[string]string{"username": aggregate}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// makeDue membuat semua message yang menunggu retry langsung jatuh tempo
+func makeDue(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	err := db.Model(&Message{}).Where("delivered_at IS NULL AND failed_at IS NULL").
+		Update("next_attempt_at", time.Now().Add(-time.Second)).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRelayDeliversInOrder(t *testing.T) {
+	db := newTestDB(t)
+	rc := newReceiver(t)
+	relay := NewRelay(db, []Sink{NewWebhookSink(rc.URL, time.Second)}, metric.NewAppMetricsExporter(), RelayOptions{})
+
+	enqueue(t, db, "user:alice", "register")
+	enqueue(t, db, "user:bob", "register")
+	enqueue(t, db, "user:alice", "login")
+	if err := relay.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"user:alice/register", "user:bob/register", "user:alice/login"}
+	if got := rc.events(); !equal(got, want) {
+		t.Fatalf("received %v, want %v", got, want)
+	}
+	if !equal(rc.ids(), []string{"1", "2", "3"}) {
+		t.Fatalf("%s headers = %v, want message IDs", HeaderMessageID, rc.ids())
+	}
+	var pending int64
+	if err := db.Model(&Message{}).Where("delivered_at IS NULL").Count(&pending).Error; err != nil {
+		t.Fatal(err)
+	}
+	if pending != 0 {
+		t.Fatalf("%d messages still pending after delivery", pending)
+	}
+}
+
+func TestRelayRetryWaitsForNextAttempt(t *testing.T) {
+	db := newTestDB(t)
+	rc := newReceiver(t)
+	relay := NewRelay(db, []Sink{NewWebhookSink(rc.URL, time.Second)}, metric.NewAppMetricsExporter(), RelayOptions{MinBackoff: time.Hour, MaxBackoff: 2 * time.Hour})
+
+	enqueue(t, db, "user:alice", "register")
+	enqueue(t, db, "user:bob", "register")
+	enqueue(t, db, "user:alice", "login")
+	rc.setFail(func(msg Message) bool { return msg.ID == 1 })
+
+	if err := relay.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// Event alice berikutnya tidak boleh mendahului event yang gagal
+	if got, want := rc.events(), []string{"user:bob/register"}; !equal(got, want) {
+		t.Fatalf("received %v, want %v", got, want)
+	}
+	var failed Message
+	if err := db.First(&failed, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	if failed.Attempts != 1 || failed.LastError == "" || !failed.NextAttemptAt.After(time.Now().Add(50*time.Minute)) {
+		t.Fatalf("failed message attempts=%d last_error=%q next_attempt_at=%s, want a scheduled retry",
+			failed.Attempts, failed.LastError, failed.NextAttemptAt)
+	}
+
+	// Sebelum jatuh tempo tidak ada yang dicoba lagi walaupun receiver sudah pulih
+	rc.setFail(nil)
+	if err := relay.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := rc.events(); len(got) != 1 {
+		t.Fatalf("received %v before the retry was due", got)
+	}
+
+	makeDue(t, db)
+	if err := relay.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"user:bob/register", "user:alice/register", "user:alice/login"}
+	if got := rc.events(); !equal(got, want) {
+		t.Fatalf("received %v, want %v", got, want)
+	}
+}
+
+func TestRelayMaxAttempts(t *testing.T) {
+	if got := NewRelay(nil, nil, nil, RelayOptions{}).opts.MaxAttempts; got <= 0 {
+		t.Fatalf("default MaxAttempts = %d, want a finite limit", got)
+	}
+
+	db := newTestDB(t)
+	rc := newReceiver(t)
+	relay := NewRelay(db, []Sink{NewWebhookSink(rc.URL, time.Second)}, metric.NewAppMetricsExporter(), RelayOptions{MaxAttempts: 2})
+
+	enqueue(t, db, "user:alice", "register")
+	enqueue(t, db, "user:alice", "login")
+	rc.setFail(func(msg Message) bool { return msg.EventType == "register" })
+
+	for range 2 {
+		if err := relay.RunOnce(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		makeDue(t, db)
+	}
+	var dropped Message
+	if err := db.First(&dropped, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	if dropped.FailedAt == nil || dropped.Attempts != 2 {
+		t.Fatalf("message after max attempts: failed_at=%v attempts=%d", dropped.FailedAt, dropped.Attempts)
+	}
+
+	// Message yang gagal permanen tidak lagi menahan aggregate-nya
+	if err := relay.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rc.events(), []string{"user:alice/login"}; !equal(got, want) {
+		t.Fatalf("received %v, want %v", got, want)
+	}
+}
+
+func TestRelayClaim(t *testing.T) {
+	db := newTestDB(t)
+	rc := newReceiver(t)
+	sinks := []Sink{NewWebhookSink(rc.URL, time.Second)}
+	first := NewRelay(db, sinks, metric.NewAppMetricsExporter(), RelayOptions{})
+	second := NewRelay(db, sinks, metric.NewAppMetricsExporter(), RelayOptions{})
+
+	enqueue(t, db, "user:alice", "register")
+	enqueue(t, db, "user:alice", "login")
+	enqueue(t, db, "user:bob", "register")
+
+	var stale, head Message
+	if err := db.First(&head, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	stale = head
+	if !first.claim(context.Background(), &head) {
+		t.Fatal("first claim failed")
+	}
+	// Relay lain yang membaca baris sebelum klaim tidak bisa mengklaimnya lagi
+	if second.claim(context.Background(), &stale) {
+		t.Fatal("message was claimed twice")
+	}
+
+	// Selama message terdepan di-klaim, relay lain melewati seluruh aggregate-nya
+	if err := second.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rc.events(), []string{"user:bob/register"}; !equal(got, want) {
+		t.Fatalf("received %v while alice's first event was claimed, want %v", got, want)
+	}
+}
+
+func TestRelayConcurrentRelays(t *testing.T) {
+	db := newTestDB(t)
+	rc := newReceiver(t)
+	sinks := []Sink{NewWebhookSink(rc.URL, time.Second)}
+
+	var want []string
+	for i := range 20 {
+		aggregate := []string{"user:alice", "user:bob", "user:carol"}[i%3]
+		eventType := "event" + string(rune('a'+i))
+		enqueue(t, db, aggregate, eventType)
+		want = append(want, aggregate+"/"+eventType)
+	}
+
+	var wg sync.WaitGroup
+	for range 3 {
+		relay := NewRelay(db, sinks, metric.NewAppMetricsExporter(), RelayOptions{BatchSize: 5})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 10 {
+				if err := relay.RunOnce(context.Background()); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	got := rc.events()
+	if len(got) != len(want) {
+		t.Fatalf("received %d messages, want each of %d exactly once: %v", len(got), len(want), got)
+	}
+	// Urutan antar aggregate bebas, urutan di dalam satu aggregate harus sama
+	perAggregate := func(events []string) map[string][]string {
+		out := map[string][]string{}
+		for _, e := range events {
+			aggregate, _, _ := strings.Cut(e, "/")
+			out[aggregate] = append(out[aggregate], e)
+		}
+		return out
+	}
+	gotBy, wantBy := perAggregate(got), perAggregate(want)
+	for aggregate, events := range wantBy {
+		if !equal(gotBy[aggregate], events) {
+			t.Fatalf("%s received %v, want %v", aggregate, gotBy[aggregate], events)
+		}
+	}
+}
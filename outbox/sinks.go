@@ -0,0 +1,166 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+)
+
+// HeaderMessageID membawa ID outbox supaya penerima bisa membuang duplikat
+const HeaderMessageID = "X-Outbox-Message-ID"
+
+// WebhookSink mengirim event sebagai HTTP POST JSON. Status selain 2xx dianggap gagal.
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhookSink membuat WebhookSink dengan timeout per request
+func NewWebhookSink(url string, timeout time.Duration) *WebhookSink {
+	return &WebhookSink{URL: url, Client: &http.Client{Timeout: timeout}}
+}
+
+func (s *WebhookSink) Name() string { return "webhook" }
+
+func (s *WebhookSink) Deliver(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderMessageID, strconv.FormatUint(msg.ID, 10))
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("outbox: webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+// NATSSink mem-publish event ke subject "<prefix>.<event_type>". Header Nats-Msg-Id
+// dipakai JetStream untuk deduplikasi bila stream-nya dikonfigurasi.
+type NATSSink struct {
+	conn   *nats.Conn
+	prefix string
+}
+
+// NewNATSSink membuka koneksi ke server NATS
+func NewNATSSink(url, subjectPrefix string) (*NATSSink, error) {
+	conn, err := nats.Connect(url, nats.Name("example-monitoring-outbox"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+	return &NATSSink{conn: conn, prefix: subjectPrefix}, nil
+}
+
+func (s *NATSSink) Name() string { return "nats" }
+
+func (s *NATSSink) Deliver(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	m := nats.NewMsg(s.prefix + "." + msg.EventType)
+	m.Data = body
+	m.Header.Set(nats.MsgIdHdr, strconv.FormatUint(msg.ID, 10))
+	if err := s.conn.PublishMsg(m); err != nil {
+		return err
+	}
+	// Flush memastikan server sudah menerima message sebelum outbox menandainya terkirim
+	return s.conn.FlushWithContext(ctx)
+}
+
+func (s *NATSSink) Close() error {
+	return s.conn.Drain()
+}
+
+// KafkaSink menulis event ke topic Kafka dengan aggregate sebagai key, sehingga event
+// satu aggregate selalu masuk partition yang sama dan urutannya terjaga
+type KafkaSink struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaSink membuat writer Kafka yang menunggu ack dari semua replica
+func NewKafkaSink(brokers []string, topic string) *KafkaSink {
+	return &KafkaSink{writer: &kafka.Writer{
+		Addr:                   kafka.TCP(brokers...),
+		Topic:                  topic,
+		Balancer:               &kafka.Hash{},
+		RequiredAcks:           kafka.RequireAll,
+		AllowAutoTopicCreation: true,
+	}}
+}
+
+func (s *KafkaSink) Name() string { return "kafka" }
+
+func (s *KafkaSink) Deliver(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return s.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(msg.Aggregate),
+		Value: body,
+		Headers: []kafka.Header{
+			{Key: HeaderMessageID, Value: []byte(strconv.FormatUint(msg.ID, 10))},
+			{Key: "event_type", Value: []byte(msg.EventType)},
+		},
+	})
+}
+
+func (s *KafkaSink) Close() error {
+	return s.writer.Close()
+}
+
+// FileSink menambahkan event sebagai NDJSON ke sebuah file, berguna untuk demo dan debugging
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink membuka file tujuan dalam mode append
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{file: f}, nil
+}
+
+func (s *FileSink) Name() string { return "file" }
+
+func (s *FileSink) Deliver(_ context.Context, msg Message) error {
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
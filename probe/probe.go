@@ -0,0 +1,226 @@
+package probe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// UserAgent dipakai semua probe HTTP supaya trafiknya mudah dibedakan di access log
+const UserAgent = "wyw-prober/1.0"
+
+// Probe adalah satu pemeriksaan sintetis. Run mengembalikan nil bila pemeriksaan berhasil.
+type Probe interface {
+	Name() string
+	Run(ctx context.Context) error
+}
+
+// HTTPCheck mendefinisikan probe HTTP, dibaca dari konfigurasi sebagai JSON
+type HTTPCheck struct {
+	Name   string `json:"name"`
+	Method string `json:"method"`
+	// URL boleh relatif terhadap base URL prober, misalnya /api/v1/users
+	URL     string            `json:"url"`
+	Body    string            `json:"body,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// ExpectStatus 0 berarti status 2xx apa pun
+	ExpectStatus int `json:"expect_status,omitempty"`
+	// ExpectBody harus muncul di body response
+	ExpectBody string `json:"expect_body,omitempty"`
+	// ExpectBodyRegex harus cocok dengan body response
+	ExpectBodyRegex string `json:"expect_body_regex,omitempty"`
+}
+
+// HTTPProbe menjalankan HTTPCheck
+type HTTPProbe struct {
+	check  HTTPCheck
+	url    string
+	re     *regexp.Regexp
+	client *http.Client
+}
+
+// NewHTTPProbe membuat probe HTTP, URL relatif digabung dengan baseURL
+func NewHTTPProbe(check HTTPCheck, baseURL string, client *http.Client) (*HTTPProbe, error) {
+	if check.Name == "" || check.URL == "" {
+		return nil, errors.New("http check needs a name and url")
+	}
+	if check.Method == "" {
+		check.Method = http.MethodGet
+	}
+	p := &HTTPProbe{check: check, url: check.URL, client: client}
+	if strings.HasPrefix(check.URL, "/") {
+		p.url = strings.TrimRight(baseURL, "/") + check.URL
+	}
+	if check.ExpectBodyRegex != "" {
+		re, err := regexp.Compile(check.ExpectBodyRegex)
+		if err != nil {
+			return nil, fmt.Errorf("check %s: %w", check.Name, err)
+		}
+		p.re = re
+	}
+	return p, nil
+}
+
+func (p *HTTPProbe) Name() string {
+	return p.check.Name
+}
+
+func (p *HTTPProbe) Run(ctx context.Context) error {
+	var body io.Reader
+	if p.check.Body != "" {
+		body = strings.NewReader(p.check.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, p.check.Method, p.url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	if p.check.Body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range p.check.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("read body: %w", err)
+	}
+
+	switch {
+	case p.check.ExpectStatus != 0 && resp.StatusCode != p.check.ExpectStatus:
+		return fmt.Errorf("status %d, want %d", resp.StatusCode, p.check.ExpectStatus)
+	case p.check.ExpectStatus == 0 && (resp.StatusCode < 200 || resp.StatusCode > 299):
+		return fmt.Errorf("status %d, want 2xx", resp.StatusCode)
+	case p.check.ExpectBody != "" && !bytes.Contains(respBody, []byte(p.check.ExpectBody)):
+		return fmt.Errorf("body does not contain %q", p.check.ExpectBody)
+	case p.re != nil && !p.re.Match(respBody):
+		return fmt.Errorf("body does not match %q", p.check.ExpectBodyRegex)
+	}
+	return nil
+}
+
+// DBProbe memeriksa round-trip ke database lewat pool koneksi aplikasi
+type DBProbe struct {
+	db *gorm.DB
+}
+
+// NewDBProbe membuat probe bernama "db"
+func NewDBProbe(db *gorm.DB) *DBProbe {
+	return &DBProbe{db: db}
+}
+
+func (p *DBProbe) Name() string {
+	return "db"
+}
+
+func (p *DBProbe) Run(ctx context.Context) error {
+	var one int
+	if err := p.db.WithContext(ctx).Raw("SELECT 1").Scan(&one).Error; err != nil {
+		return err
+	}
+	if one != 1 {
+		return fmt.Errorf("SELECT 1 returned %d", one)
+	}
+	return nil
+}
+
+// LoginProbe masuk dengan akun canary seperti user sungguhan, memanggil endpoint yang
+// memerlukan login, lalu mencabut session-nya supaya session tidak menumpuk
+type LoginProbe struct {
+	baseURL  string
+	username string
+	password string
+	client   *http.Client
+}
+
+// NewLoginProbe membuat probe bernama "login_canary"
+func NewLoginProbe(baseURL, username, password string, client *http.Client) *LoginProbe {
+	return &LoginProbe{baseURL: strings.TrimRight(baseURL, "/"), username: username, password: password, client: client}
+}
+
+func (p *LoginProbe) Name() string {
+	return "login_canary"
+}
+
+func (p *LoginProbe) Run(ctx context.Context) error {
+	payload, _ := json.Marshal(map[string]string{"username": p.username, "password": p.password})
+	var login struct {
+		Token       string `json:"token"`
+		MFARequired bool   `json:"mfa_required"`
+	}
+	if err := p.call(ctx, http.MethodPost, "/api/v1/login", "", payload, http.StatusOK, &login); err != nil {
+		return fmt.Errorf("login: %w", err)
+	}
+	if login.MFARequired {
+		return errors.New("login: canary account must not have two-factor authentication enabled")
+	}
+	if login.Token == "" {
+		return errors.New("login: response has no token")
+	}
+
+	var sessions struct {
+		Data []struct {
+			ID      string `json:"id"`
+			Current bool   `json:"current"`
+		} `json:"data"`
+	}
+	if err := p.call(ctx, http.MethodGet, "/api/v1/me/sessions", login.Token, nil, http.StatusOK, &sessions); err != nil {
+		return fmt.Errorf("list sessions: %w", err)
+	}
+	for _, s := range sessions.Data {
+		if s.Current {
+			if err := p.call(ctx, http.MethodDelete, "/api/v1/me/sessions/"+s.ID, login.Token, nil, 0, nil); err != nil {
+				return fmt.Errorf("revoke session: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// call mengirim request dan mendecode response JSON ke out. expect 0 berarti 2xx apa pun.
+func (p *LoginProbe) call(ctx context.Context, method, path, token string, body []byte, expect int, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if (expect != 0 && resp.StatusCode != expect) || (expect == 0 && resp.StatusCode/100 != 2) {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// DefaultChecks dipakai bila PROBE_CHECKS kosong
+var DefaultChecks = []HTTPCheck{
+	{Name: "root", Method: http.MethodGet, URL: "/api/v1/", ExpectStatus: http.StatusOK},
+	{Name: "users", Method: http.MethodGet, URL: "/api/v1/users", ExpectStatus: http.StatusOK, ExpectBody: `"data"`},
+}
@@ -0,0 +1,198 @@
+package probe
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"wyw/logging"
+	"wyw/metric"
+)
+
+// maxResults membatasi riwayat per probe walaupun interval sangat kecil
+const maxResults = 100_000
+
+// Windows adalah rentang waktu availability yang ditampilkan di status
+var Windows = []time.Duration{5 * time.Minute, time.Hour, 24 * time.Hour}
+
+// Result adalah hasil satu kali probe
+type Result struct {
+	Time       time.Time `json:"time"`
+	Success    bool      `json:"success"`
+	DurationMs float64   `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Availability adalah rasio probe yang berhasil dalam satu rentang waktu
+type Availability struct {
+	Window  string  `json:"window" example:"1h0m0s"`
+	Samples int     `json:"samples" example:"120"`
+	Ratio   float64 `json:"ratio" example:"0.9917"`
+}
+
+// Status adalah ringkasan satu probe untuk endpoint admin
+type Status struct {
+	Probe string  `json:"probe" example:"login_canary"`
+	Last  *Result `json:"last,omitempty"`
+	// ConsecutiveFailures menghitung kegagalan berturut-turut sampai hasil terakhir
+	ConsecutiveFailures int            `json:"consecutive_failures"`
+	Availability        []Availability `json:"availability"`
+}
+
+// Options mengatur jadwal dan riwayat prober
+type Options struct {
+	Interval time.Duration
+	Timeout  time.Duration
+	// Retention adalah lama riwayat hasil yang disimpan di memori
+	Retention time.Duration
+}
+
+// Prober menjalankan setiap probe secara berkala, mengekspor hasilnya sebagai metrik dan
+// menyimpan riwayatnya untuk menghitung availability dari sisi user
+type Prober struct {
+	probes  []Probe
+	metrics *metric.AppMetricsExporter
+	opts    Options
+
+	mu      sync.RWMutex
+	results map[string][]Result
+}
+
+// NewProber membuat Prober untuk probes
+func NewProber(probes []Probe, metrics *metric.AppMetricsExporter, opts Options) *Prober {
+	if opts.Interval <= 0 {
+		opts.Interval = 30 * time.Second
+	}
+	if opts.Timeout <= 0 || opts.Timeout > opts.Interval {
+		opts.Timeout = min(10*time.Second, opts.Interval)
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = 24 * time.Hour
+	}
+	results := make(map[string][]Result, len(probes))
+	for _, p := range probes {
+		results[p.Name()] = nil
+	}
+	return &Prober{probes: probes, metrics: metrics, opts: opts, results: results}
+}
+
+// Run menjalankan semua probe sampai ctx selesai. Awal tiap probe digeser acak supaya
+// tidak semuanya berjalan di detik yang sama.
+func (p *Prober) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, probe := range p.probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(rand.N(p.opts.Interval)):
+			}
+			ticker := time.NewTicker(p.opts.Interval)
+			defer ticker.Stop()
+			for {
+				p.runOnce(ctx, probe)
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (p *Prober) runOnce(ctx context.Context, probe Probe) {
+	ctx, cancel := context.WithTimeout(ctx, p.opts.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := probe.Run(ctx)
+	duration := time.Since(start)
+
+	res := Result{Time: start.UTC(), Success: err == nil, DurationMs: float64(duration.Microseconds()) / 1000}
+	if err != nil {
+		res.Error = err.Error()
+		logging.Logger(logging.ModuleApp).Warn("probe failed", slog.String("probe", probe.Name()), slog.Any("error", err))
+	}
+	p.metrics.ObserveProbe(probe.Name(), res.Success, duration)
+	p.record(probe.Name(), res)
+}
+
+func (p *Prober) record(name string, res Result) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	results := append(p.results[name], res)
+	cutoff := res.Time.Add(-p.opts.Retention)
+	i, _ := slices.BinarySearchFunc(results, cutoff, func(r Result, t time.Time) int { return r.Time.Compare(t) })
+	i = max(i, len(results)-maxResults)
+	if i > 0 {
+		results = slices.Clone(results[i:])
+	}
+	p.results[name] = results
+}
+
+// Statuses mengembalikan ringkasan semua probe, diurutkan berdasarkan nama
+func (p *Prober) Statuses() []Status {
+	now := time.Now()
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	statuses := make([]Status, 0, len(p.results))
+	for name, results := range p.results {
+		st := Status{Probe: name, Availability: []Availability{}}
+		if len(results) > 0 {
+			last := results[len(results)-1]
+			st.Last = &last
+		}
+		for i := len(results) - 1; i >= 0 && !results[i].Success; i-- {
+			st.ConsecutiveFailures++
+		}
+		for _, window := range Windows {
+			if window > p.opts.Retention {
+				continue
+			}
+			a := Availability{Window: window.String()}
+			success := 0
+			for _, r := range results {
+				if now.Sub(r.Time) <= window {
+					a.Samples++
+					if r.Success {
+						success++
+					}
+				}
+			}
+			if a.Samples > 0 {
+				a.Ratio = float64(success) / float64(a.Samples)
+			}
+			st.Availability = append(st.Availability, a)
+		}
+		statuses = append(statuses, st)
+	}
+	slices.SortFunc(statuses, func(a, b Status) int { return strings.Compare(a.Probe, b.Probe) })
+	return statuses
+}
+
+// History mengembalikan hasil probe sejak since, yang terbaru lebih dulu, paling banyak limit.
+// false bila probe tidak dikenal.
+func (p *Prober) History(name string, since time.Time, limit int) ([]Result, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	results, ok := p.results[name]
+	if !ok {
+		return nil, false
+	}
+	out := []Result{}
+	for i := len(results) - 1; i >= 0 && len(out) < limit; i-- {
+		if results[i].Time.Before(since) {
+			break
+		}
+		out = append(out, results[i])
+	}
+	return out, true
+}
@@ -0,0 +1,161 @@
+package push
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Label, Sample dan TimeSeries adalah bagian WriteRequest protokol remote write 1.0
+// (prometheus.WriteRequest di prompb). Di-encode manual dengan protowire supaya tidak perlu
+// menarik modul Prometheus server hanya untuk tiga message.
+type Label struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type Sample struct {
+	Value     float64 `json:"value"`
+	Timestamp int64   `json:"timestamp"`
+}
+
+type TimeSeries struct {
+	// Labels harus terurut berdasarkan nama dan berisi __name__
+	Labels  []Label  `json:"labels"`
+	Samples []Sample `json:"samples"`
+}
+
+// Name mengembalikan nilai label __name__
+func (ts TimeSeries) Name() string {
+	for _, l := range ts.Labels {
+		if l.Name == "__name__" {
+			return l.Value
+		}
+	}
+	return ""
+}
+
+// Nomor field di remote.proto dan types.proto
+const (
+	fieldWriteRequestTimeseries = 1
+	fieldTimeSeriesLabels       = 1
+	fieldTimeSeriesSamples      = 2
+	fieldLabelName              = 1
+	fieldLabelValue             = 2
+	fieldSampleValue            = 1
+	fieldSampleTimestamp        = 2
+)
+
+// EncodeWriteRequest meng-encode series sebagai WriteRequest protobuf tanpa kompresi
+func EncodeWriteRequest(series []TimeSeries) []byte {
+	var b, ts, msg []byte
+	for _, s := range series {
+		ts = ts[:0]
+		for _, l := range s.Labels {
+			msg = msg[:0]
+			msg = protowire.AppendTag(msg, fieldLabelName, protowire.BytesType)
+			msg = protowire.AppendString(msg, l.Name)
+			msg = protowire.AppendTag(msg, fieldLabelValue, protowire.BytesType)
+			msg = protowire.AppendString(msg, l.Value)
+			ts = protowire.AppendTag(ts, fieldTimeSeriesLabels, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, msg)
+		}
+		for _, smp := range s.Samples {
+			msg = msg[:0]
+			msg = protowire.AppendTag(msg, fieldSampleValue, protowire.Fixed64Type)
+			msg = protowire.AppendFixed64(msg, math.Float64bits(smp.Value))
+			msg = protowire.AppendTag(msg, fieldSampleTimestamp, protowire.VarintType)
+			msg = protowire.AppendVarint(msg, uint64(smp.Timestamp))
+			ts = protowire.AppendTag(ts, fieldTimeSeriesSamples, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, msg)
+		}
+		b = protowire.AppendTag(b, fieldWriteRequestTimeseries, protowire.BytesType)
+		b = protowire.AppendBytes(b, ts)
+	}
+	return b
+}
+
+// DecodeWriteRequest membaca WriteRequest protobuf tanpa kompresi. Field yang tidak dikenal
+// (metadata, exemplar, histogram native) dilewati.
+func DecodeWriteRequest(b []byte) ([]TimeSeries, error) {
+	var series []TimeSeries
+	err := decodeFields(b, func(num protowire.Number, typ protowire.Type, v []byte, _ uint64) error {
+		if num != fieldWriteRequestTimeseries || typ != protowire.BytesType {
+			return nil
+		}
+		var ts TimeSeries
+		err := decodeFields(v, func(num protowire.Number, typ protowire.Type, v []byte, _ uint64) error {
+			switch {
+			case num == fieldTimeSeriesLabels && typ == protowire.BytesType:
+				var l Label
+				err := decodeFields(v, func(num protowire.Number, typ protowire.Type, v []byte, _ uint64) error {
+					switch {
+					case num == fieldLabelName && typ == protowire.BytesType:
+						l.Name = string(v)
+					case num == fieldLabelValue && typ == protowire.BytesType:
+						l.Value = string(v)
+					}
+					return nil
+				})
+				ts.Labels = append(ts.Labels, l)
+				return err
+			case num == fieldTimeSeriesSamples && typ == protowire.BytesType:
+				var s Sample
+				err := decodeFields(v, func(num protowire.Number, typ protowire.Type, _ []byte, n uint64) error {
+					switch {
+					case num == fieldSampleValue && typ == protowire.Fixed64Type:
+						s.Value = math.Float64frombits(n)
+					case num == fieldSampleTimestamp && typ == protowire.VarintType:
+						s.Timestamp = int64(n)
+					}
+					return nil
+				})
+				ts.Samples = append(ts.Samples, s)
+				return err
+			}
+			return nil
+		})
+		series = append(series, ts)
+		return err
+	})
+	return series, err
+}
+
+// decodeFields memanggil fn untuk setiap field di b. Field bytes diberikan sebagai v,
+// field varint dan fixed sebagai n.
+func decodeFields(b []byte, fn func(num protowire.Number, typ protowire.Type, v []byte, n uint64) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return fmt.Errorf("invalid protobuf tag: %w", protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		var v []byte
+		var x uint64
+		switch typ {
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(b)
+		case protowire.VarintType:
+			x, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			x, n = protowire.ConsumeFixed64(b)
+		case protowire.Fixed32Type:
+			var x32 uint32
+			x32, n = protowire.ConsumeFixed32(b)
+			x = uint64(x32)
+		default:
+			return errors.New("unsupported protobuf wire type")
+		}
+		if n < 0 {
+			return fmt.Errorf("invalid protobuf field %d: %w", num, protowire.ParseError(n))
+		}
+		b = b[n:]
+		if err := fn(num, typ, v, x); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package push
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+	"wyw/logging"
+	"wyw/metric"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// PushgatewayOptions mengatur Pushgateway
+type PushgatewayOptions struct {
+	URL string
+	Job string
+	// Grouping adalah grouping key selain job, misalnya instance. Push mengganti seluruh
+	// metrik dengan grouping key yang sama.
+	Grouping map[string]string
+	Interval time.Duration
+	Timeout  time.Duration
+	// DeleteOnShutdown menghapus grup dari Pushgateway saat berhenti supaya metrik proses
+	// yang sudah mati tidak terus di-scrape sebagai nilai terakhir
+	DeleteOnShutdown bool
+}
+
+// Pushgateway mengirim isi registry ke Prometheus Pushgateway, untuk job singkat atau
+// deployment di balik NAT yang tidak bisa di-scrape
+type Pushgateway struct {
+	pusher  *push.Pusher
+	metrics *metric.AppMetricsExporter
+	opts    PushgatewayOptions
+}
+
+// NewPushgateway membuat Pushgateway untuk gatherer. metrics boleh nil untuk job singkat
+// yang tidak memakai AppMetricsExporter, misalnya loadgen.
+func NewPushgateway(gatherer prometheus.Gatherer, metrics *metric.AppMetricsExporter, opts PushgatewayOptions) *Pushgateway {
+	if opts.Interval <= 0 {
+		opts.Interval = 15 * time.Second
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	pusher := push.New(opts.URL, opts.Job).Gatherer(gatherer).Client(&http.Client{Timeout: opts.Timeout})
+	for _, name := range slices.Sorted(maps.Keys(opts.Grouping)) {
+		pusher.Grouping(name, opts.Grouping[name])
+	}
+	return &Pushgateway{pusher: pusher, metrics: metrics, opts: opts}
+}
+
+// Run mengirim registry setiap interval sampai ctx selesai. Saat berhenti grup dihapus bila
+// DeleteOnShutdown, selain itu nilai terakhir dikirim sekali lagi.
+func (p *Pushgateway) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.opts.Interval)
+	defer ticker.Stop()
+	for {
+		if err := p.Push(ctx); err != nil && ctx.Err() == nil {
+			logging.Logger(logging.ModuleMetric).Warn("failed to push metrics to pushgateway", slog.Any("error", err))
+		}
+		select {
+		case <-ctx.Done():
+			p.shutdown()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Pushgateway) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), p.opts.Timeout)
+	defer cancel()
+	if !p.opts.DeleteOnShutdown {
+		if err := p.Push(ctx); err != nil {
+			logging.Logger(logging.ModuleMetric).Warn("failed to push final metrics to pushgateway", slog.Any("error", err))
+		}
+		return
+	}
+	if err := p.Delete(); err != nil {
+		logging.Logger(logging.ModuleMetric).Warn("failed to delete metrics from pushgateway", slog.Any("error", err))
+		return
+	}
+	logging.Logger(logging.ModuleMetric).Info("deleted metrics from pushgateway", slog.String("job", p.opts.Job))
+}
+
+// Push mengganti metrik grup di Pushgateway dengan isi registry saat ini
+func (p *Pushgateway) Push(ctx context.Context) error {
+	err := p.pusher.PushContext(ctx)
+	p.record(err)
+	return err
+}
+
+// Delete menghapus semua metrik grup dari Pushgateway
+func (p *Pushgateway) Delete() error {
+	err := p.pusher.Delete()
+	p.record(err)
+	return err
+}
+
+func (p *Pushgateway) record(err error) {
+	if p.metrics == nil {
+		return
+	}
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+	}
+	p.metrics.RecordPush("pushgateway", outcome)
+}
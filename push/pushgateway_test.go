@@ -0,0 +1,61 @@
+package push
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+	"wyw/testutil"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// newPushgatewayStub mencatat method dan path setiap request ke Pushgateway
+func newPushgatewayStub(t *testing.T) *testutil.Receiver[string] {
+	t.Helper()
+	return testutil.NewReceiver(t, func(r *http.Request) (string, error) {
+		return r.Method + " " + r.URL.Path, nil
+	})
+}
+
+func TestPushgatewayShutdown(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		deleteOnShutdown bool
+		last             string
+	}{
+		{name: "delete", deleteOnShutdown: true, last: "DELETE /metrics/job/wyw/instance/node-1"},
+		{name: "final push", deleteOnShutdown: false, last: "PUT /metrics/job/wyw/instance/node-1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ps := newPushgatewayStub(t)
+			registry := prometheus.NewRegistry()
+			registry.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{Name: "jobs_total", Help: "Jobs"}))
+			p := NewPushgateway(registry, nil, PushgatewayOptions{
+				URL:              ps.URL,
+				Job:              "wyw",
+				Grouping:         map[string]string{"instance": "node-1"},
+				Interval:         time.Hour,
+				DeleteOnShutdown: tc.deleteOnShutdown,
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				p.Run(ctx)
+			}()
+			testutil.Eventually(t, "the first push", func() bool { return len(ps.Received()) > 0 })
+			cancel()
+			<-done
+
+			got := ps.Received()
+			if got[0] != "PUT /metrics/job/wyw/instance/node-1" {
+				t.Fatalf("first request = %s, want a PUT of the group", got[0])
+			}
+			if len(got) != 2 || got[1] != tc.last {
+				t.Fatalf("requests = %v, want %s on shutdown", got, tc.last)
+			}
+		})
+	}
+}
@@ -0,0 +1,351 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"maps"
+	"math"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"wyw/logging"
+	"wyw/metric"
+	"wyw/retry"
+
+	"github.com/klauspost/compress/snappy"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// RemoteWriteOptions mengatur RemoteWriter
+type RemoteWriteOptions struct {
+	URL string
+	// Interval adalah jeda antar pengambilan nilai registry, setara scrape_interval
+	Interval time.Duration
+	Timeout  time.Duration
+	// Shards adalah jumlah antrean paralel. Series selalu masuk shard yang sama supaya
+	// sample-nya tiba berurutan.
+	Shards            int
+	MaxSamplesPerSend int
+	// WALDir menyimpan batch yang belum terkirim, satu subdirektori per shard
+	WALDir string
+	// MaxPendingSegments membatasi batch per shard, batch tertua dibuang bila terlampaui
+	MaxPendingSegments int
+	MinBackoff         time.Duration
+	MaxBackoff         time.Duration
+	// ExternalLabels ditambahkan ke setiap series yang belum memiliki label tersebut
+	ExternalLabels map[string]string
+}
+
+// RemoteWriter mengirim isi registry ke endpoint Prometheus remote write 1.0 (Prometheus
+// dengan --web.enable-remote-write-receiver, Mimir, Thanos Receive, VictoriaMetrics, dsb.)
+type RemoteWriter struct {
+	metrics *metric.AppMetricsExporter
+	opts    RemoteWriteOptions
+	client  *http.Client
+	shards  []*shard
+}
+
+// shard adalah satu antrean WAL dengan satu goroutine pengirim
+type shard struct {
+	id     int
+	wal    *wal
+	notify chan struct{}
+}
+
+// errPermanent menandai penolakan yang tidak akan berhasil bila diulang (4xx selain 429)
+var errPermanent = errors.New("remote write rejected")
+
+// NewRemoteWriter membuka WAL setiap shard. Batch sisa proses sebelumnya ikut dikirim saat Run.
+func NewRemoteWriter(metrics *metric.AppMetricsExporter, opts RemoteWriteOptions) (*RemoteWriter, error) {
+	if opts.URL == "" {
+		return nil, errors.New("remote write URL is required")
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = 15 * time.Second
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 30 * time.Second
+	}
+	if opts.Shards <= 0 {
+		opts.Shards = 1
+	}
+	if opts.MaxSamplesPerSend <= 0 {
+		opts.MaxSamplesPerSend = 2000
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = 500 * time.Millisecond
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = max(30*time.Second, opts.MinBackoff)
+	}
+
+	w := &RemoteWriter{metrics: metrics, opts: opts, client: &http.Client{Timeout: opts.Timeout}}
+	for i := range opts.Shards {
+		q, err := openWAL(filepath.Join(opts.WALDir, "shard-"+strconv.Itoa(i)))
+		if err != nil {
+			return nil, fmt.Errorf("remote write WAL: %w", err)
+		}
+		w.shards = append(w.shards, &shard{id: i, wal: q, notify: make(chan struct{}, 1)})
+		metrics.SetRemoteWritePending(i, q.len())
+	}
+	return w, nil
+}
+
+// Run mengambil nilai registry setiap interval dan mengirimnya sampai ctx selesai.
+// Batch yang belum terkirim saat berhenti tetap di WAL.
+func (w *RemoteWriter) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, s := range w.shards {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.runShard(ctx, s)
+		}()
+	}
+
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+	for {
+		if err := w.Collect(time.Now()); err != nil {
+			logging.Logger(logging.ModuleMetric).Warn("failed to queue remote write samples", slog.Any("error", err))
+		}
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Collect mengambil nilai registry pada waktu now, membaginya ke shard dan menulisnya ke WAL
+func (w *RemoteWriter) Collect(now time.Time) error {
+	families, err := w.metrics.Gather()
+	if err != nil && len(families) == 0 {
+		return err
+	}
+	byShard := make([][]TimeSeries, len(w.shards))
+	for _, ts := range toTimeSeries(families, now, w.opts.ExternalLabels) {
+		h := fnv.New64a()
+		for _, l := range ts.Labels {
+			_, _ = h.Write([]byte(l.Name))
+			_, _ = h.Write([]byte{0xff})
+			_, _ = h.Write([]byte(l.Value))
+			_, _ = h.Write([]byte{0xfe})
+		}
+		i := h.Sum64() % uint64(len(w.shards))
+		byShard[i] = append(byShard[i], ts)
+	}
+
+	for i, series := range byShard {
+		s := w.shards[i]
+		for batch := range slices.Chunk(series, w.opts.MaxSamplesPerSend) {
+			payload := snappy.Encode(nil, EncodeWriteRequest(batch))
+			dropped, err := s.wal.append(payload, len(batch), w.opts.MaxPendingSegments)
+			if err != nil {
+				return err
+			}
+			for _, d := range dropped {
+				w.metrics.RecordRemoteWriteSamples("dropped", d.samples)
+			}
+			if len(dropped) > 0 {
+				logging.Logger(logging.ModuleMetric).Warn("remote write queue is full, dropped oldest batches",
+					slog.Int("shard", s.id), slog.Int("batches", len(dropped)))
+			}
+		}
+		w.metrics.SetRemoteWritePending(s.id, s.wal.len())
+		select {
+		case s.notify <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// runShard mengirim batch WAL satu per satu dari yang tertua. Kegagalan sementara diulang
+// dengan backoff eksponensial tanpa melompati batch supaya urutan sample terjaga.
+func (w *RemoteWriter) runShard(ctx context.Context, s *shard) {
+	attempts := 0
+	// retry menunggu backoff sebelum batch yang sama dicoba lagi, false bila ctx selesai
+	retry := func(msg string, retryAfter time.Duration, err error) bool {
+		attempts++
+		delay := max(retry.Backoff(attempts, w.opts.MinBackoff, w.opts.MaxBackoff), min(retryAfter, w.opts.MaxBackoff))
+		logging.Logger(logging.ModuleMetric).Warn(msg,
+			slog.Int("shard", s.id), slog.Int("attempts", attempts), slog.Duration("backoff", delay), slog.Any("error", err))
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(delay):
+			return true
+		}
+	}
+	// remove membuang batch dari WAL. Batch yang gagal dihapus akan terbaca lagi, jadi
+	// tunggu dulu supaya loop tidak berputar tanpa jeda saat disk bermasalah.
+	remove := func(seg segment) bool {
+		if err := s.wal.remove(seg); err != nil {
+			return retry("failed to remove remote write batch, will retry", 0, err)
+		}
+		attempts = 0
+		return true
+	}
+
+	for {
+		seg, payload, ok, err := s.wal.oldest()
+		if err != nil {
+			logging.Logger(logging.ModuleMetric).Error("failed to read remote write WAL, dropping the batch",
+				slog.Int("shard", s.id), slog.Int("samples", seg.samples), slog.Any("error", err))
+			if !remove(seg) {
+				return
+			}
+			if attempts == 0 {
+				w.metrics.RecordRemoteWriteSamples("dropped", seg.samples)
+			}
+			w.metrics.SetRemoteWritePending(s.id, s.wal.len())
+			continue
+		}
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.notify:
+			}
+			continue
+		}
+
+		retryAfter, err := w.send(ctx, payload)
+		switch {
+		case err == nil:
+			w.metrics.RecordPush("remote_write", "success")
+			w.metrics.RecordRemoteWriteSamples("sent", seg.samples)
+			if !remove(seg) {
+				return
+			}
+		case errors.Is(err, errPermanent):
+			w.metrics.RecordPush("remote_write", "failure")
+			w.metrics.RecordRemoteWriteSamples("dropped", seg.samples)
+			logging.Logger(logging.ModuleMetric).Error("remote write batch rejected, dropping it",
+				slog.Int("shard", s.id), slog.Int("samples", seg.samples), slog.Any("error", err))
+			if !remove(seg) {
+				return
+			}
+		default:
+			if ctx.Err() != nil {
+				return
+			}
+			w.metrics.RecordPush("remote_write", "failure")
+			w.metrics.RecordRemoteWriteSamples("failed", seg.samples)
+			if !retry("remote write failed, will retry", retryAfter, err) {
+				return
+			}
+		}
+		w.metrics.SetRemoteWritePending(s.id, s.wal.len())
+	}
+}
+
+// send mengirim satu batch. Retry-After dari response 429 dan 5xx dikembalikan supaya dihormati.
+func (w *RemoteWriter) send(ctx context.Context, payload []byte) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.opts.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", errPermanent, err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	req.Header.Set("User-Agent", "wyw-remote-write")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return 0, nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("server answered %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, fmt.Errorf("%w: %w", errPermanent, err)
+	}
+	var retryAfter time.Duration
+	if secs, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil {
+		retryAfter = time.Duration(secs) * time.Second
+	}
+	return retryAfter, err
+}
+
+// toTimeSeries mengubah hasil Gather menjadi series remote write dengan satu sample per
+// series. Histogram dan summary dipecah seperti format eksposisi Prometheus.
+func toTimeSeries(families []*dto.MetricFamily, now time.Time, external map[string]string) []TimeSeries {
+	var out []TimeSeries
+	add := func(name string, m *dto.Metric, extra map[string]string, v float64) {
+		labels := make(map[string]string, len(m.GetLabel())+len(extra)+len(external)+1)
+		maps.Copy(labels, external)
+		for _, lp := range m.GetLabel() {
+			labels[lp.GetName()] = lp.GetValue()
+		}
+		maps.Copy(labels, extra)
+		labels["__name__"] = name
+
+		ts := TimeSeries{Labels: make([]Label, 0, len(labels))}
+		for _, n := range slices.Sorted(maps.Keys(labels)) {
+			ts.Labels = append(ts.Labels, Label{Name: n, Value: labels[n]})
+		}
+		t := now.UnixMilli()
+		if m.TimestampMs != nil {
+			t = m.GetTimestampMs()
+		}
+		ts.Samples = []Sample{{Value: v, Timestamp: t}}
+		out = append(out, ts)
+	}
+
+	for _, mf := range families {
+		name := mf.GetName()
+		for _, m := range mf.GetMetric() {
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				add(name, m, nil, m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				add(name, m, nil, m.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				add(name, m, nil, m.GetUntyped().GetValue())
+			case dto.MetricType_HISTOGRAM:
+				h := m.GetHistogram()
+				for _, b := range h.GetBucket() {
+					if math.IsInf(b.GetUpperBound(), 1) {
+						continue
+					}
+					add(name+"_bucket", m, map[string]string{"le": formatFloat(b.GetUpperBound())}, float64(b.GetCumulativeCount()))
+				}
+				add(name+"_bucket", m, map[string]string{"le": "+Inf"}, float64(h.GetSampleCount()))
+				add(name+"_sum", m, nil, h.GetSampleSum())
+				add(name+"_count", m, nil, float64(h.GetSampleCount()))
+			case dto.MetricType_SUMMARY:
+				s := m.GetSummary()
+				for _, q := range s.GetQuantile() {
+					add(name, m, map[string]string{"quantile": formatFloat(q.GetQuantile())}, q.GetValue())
+				}
+				add(name+"_sum", m, nil, s.GetSampleSum())
+				add(name+"_count", m, nil, float64(s.GetSampleCount()))
+			}
+		}
+	}
+	return out
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
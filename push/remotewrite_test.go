@@ -0,0 +1,278 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"wyw/logging"
+	"wyw/metric"
+	"wyw/testutil"
+
+	"github.com/klauspost/compress/snappy"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// newRemoteWriteReceiver adalah receiver remote write untuk test, mencatat setiap
+// WriteRequest yang diterima sebagai satu batch
+func newRemoteWriteReceiver(t *testing.T) *testutil.Receiver[[]TimeSeries] {
+	t.Helper()
+	return testutil.NewReceiver(t, func(r *http.Request) ([]TimeSeries, error) {
+		if r.Header.Get("Content-Encoding") != "snappy" || r.Header.Get("X-Prometheus-Remote-Write-Version") != "0.1.0" {
+			return nil, errors.New("not a remote write 1.0 request")
+		}
+		compressed, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		raw, err := snappy.Decode(nil, compressed)
+		if err != nil {
+			return nil, err
+		}
+		return DecodeWriteRequest(raw)
+	})
+}
+
+// runWriter menjalankan w sampai test selesai dan menunggu Run kembali
+func runWriter(t *testing.T, w *RemoteWriter) context.CancelFunc {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Run(ctx)
+	}()
+	stop := func() {
+		cancel()
+		<-done
+	}
+	t.Cleanup(stop)
+	return stop
+}
+
+// walSeries membaca semua series yang menunggu di WAL satu shard
+func walSeries(t *testing.T, q *wal) []TimeSeries {
+	t.Helper()
+	var out []TimeSeries
+	for _, seg := range q.segments {
+		compressed, err := os.ReadFile(filepath.Join(q.dir, seg.name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, err := snappy.Decode(nil, compressed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		series, err := DecodeWriteRequest(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, series...)
+	}
+	return out
+}
+
+func seriesKey(ts TimeSeries) string {
+	var b strings.Builder
+	for _, l := range ts.Labels {
+		b.WriteString(l.Name + "=" + l.Value + ",")
+	}
+	return b.String()
+}
+
+func findSeries(series []TimeSeries, name string) (TimeSeries, bool) {
+	for _, ts := range series {
+		if ts.Name() == name {
+			return ts, true
+		}
+	}
+	return TimeSeries{}, false
+}
+
+func TestRemoteWriteReplaysWAL(t *testing.T) {
+	dir := t.TempDir()
+	metrics := metric.NewAppMetricsExporter()
+	metrics.SetActiveSessions(7)
+
+	// Proses pertama hanya sempat menulis ke WAL sebelum berhenti
+	stale := time.UnixMilli(1700000000000)
+	first, err := NewRemoteWriter(metrics, RemoteWriteOptions{URL: "http://127.0.0.1:0", WALDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Collect(stale); err != nil {
+		t.Fatal(err)
+	}
+	if pending := first.shards[0].wal.len(); pending != 1 {
+		t.Fatalf("%d batches in the WAL after Collect, want 1", pending)
+	}
+
+	rr := newRemoteWriteReceiver(t)
+	metrics.SetActiveSessions(9)
+	second, err := NewRemoteWriter(metrics, RemoteWriteOptions{URL: rr.URL, WALDir: dir, Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending := second.shards[0].wal.len(); pending != 1 {
+		t.Fatalf("reopened WAL holds %d batches, want the batch left by the previous process", pending)
+	}
+	runWriter(t, second)
+	testutil.Eventually(t, "the replayed and the fresh batch", func() bool { return len(rr.Received()) >= 2 })
+	testutil.Eventually(t, "an empty WAL", func() bool { return second.shards[0].wal.len() == 0 })
+
+	// Batch lama dikirim lebih dulu dengan timestamp dan nilai aslinya
+	batches := rr.Received()
+	replayed, ok := findSeries(batches[0], "app_active_sessions")
+	if !ok {
+		t.Fatal("replayed batch has no app_active_sessions series")
+	}
+	if s := replayed.Samples[0]; s.Value != 7 || s.Timestamp != stale.UnixMilli() {
+		t.Fatalf("replayed sample = %+v, want value 7 at %d", s, stale.UnixMilli())
+	}
+	fresh, ok := findSeries(batches[1], "app_active_sessions")
+	if !ok || fresh.Samples[0].Value != 9 || fresh.Samples[0].Timestamp <= stale.UnixMilli() {
+		t.Fatalf("fresh batch sample = %+v, want the current value", fresh.Samples)
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, "shard-0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("WAL directory still holds %d files after delivery", len(entries))
+	}
+}
+
+func TestRemoteWriteSharding(t *testing.T) {
+	metrics := metric.NewAppMetricsExporter()
+	metrics.RecordPush("pushgateway", "success")
+	metrics.RecordPush("remote_write", "failure")
+	w, err := NewRemoteWriter(metrics, RemoteWriteOptions{
+		URL:            "http://127.0.0.1:0",
+		WALDir:         t.TempDir(),
+		Shards:         4,
+		ExternalLabels: map[string]string{"cluster": "test"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Series yang sama harus selalu masuk shard yang sama di setiap Collect
+	owner := map[string]int{}
+	for round, now := range []time.Time{time.UnixMilli(1000), time.UnixMilli(2000)} {
+		if err := w.Collect(now); err != nil {
+			t.Fatal(err)
+		}
+		used := 0
+		for _, s := range w.shards {
+			series := walSeries(t, s.wal)
+			if len(series) > 0 {
+				used++
+			}
+			for _, ts := range series {
+				if ts.Samples[0].Timestamp != now.UnixMilli() {
+					continue
+				}
+				key := seriesKey(ts)
+				if !strings.Contains(key, "cluster=test,") {
+					t.Fatalf("series %s lacks the external label", key)
+				}
+				if prev, ok := owner[key]; ok && prev != s.id {
+					t.Fatalf("series %s moved from shard %d to shard %d", key, prev, s.id)
+				}
+				if round == 0 {
+					owner[key] = s.id
+				}
+			}
+		}
+		if used < 2 {
+			t.Fatalf("series spread over %d of %d shards", used, len(w.shards))
+		}
+	}
+	if want := len(toTimeSeries(mustGather(t, metrics), time.Now(), nil)); len(owner) != want {
+		t.Fatalf("sharded %d distinct series, want %d", len(owner), want)
+	}
+}
+
+func mustGather(t *testing.T, metrics *metric.AppMetricsExporter) []*dto.MetricFamily {
+	t.Helper()
+	families, err := metrics.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return families
+}
+
+// lockedBuffer menampung log dari goroutine shard
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) count(msg string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return strings.Count(b.buf.String(), msg)
+}
+
+func TestRemoteWriteBacksOffWhenWALFails(t *testing.T) {
+	logs := &lockedBuffer{}
+	if err := logging.Setup(logs, logging.FormatJSON, "info", nil); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = logging.Setup(os.Stdout, logging.FormatJSON, "info", nil) })
+
+	// Segment yang berupa direktori tidak bisa dibaca, dan selama berisi file juga tidak
+	// bisa dihapus
+	dir := t.TempDir()
+	broken := filepath.Join(dir, "shard-0", segment{seq: 0, samples: 3}.name())
+	if err := os.MkdirAll(broken, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(broken, "blocker"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rr := newRemoteWriteReceiver(t)
+	w, err := NewRemoteWriter(metric.NewAppMetricsExporter(), RemoteWriteOptions{
+		URL:        rr.URL,
+		WALDir:     dir,
+		Interval:   time.Hour,
+		MinBackoff: 50 * time.Millisecond,
+		MaxBackoff: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop := runWriter(t, w)
+	time.Sleep(300 * time.Millisecond)
+	stop()
+
+	const msg = "failed to remove remote write batch"
+	if n := logs.count(msg); n == 0 || n > 10 {
+		t.Fatalf("shard retried the broken batch %d times in 300ms with a 50ms backoff", n)
+	}
+	if len(rr.Received()) != 0 {
+		t.Fatal("batches behind the broken one were sent out of order")
+	}
+
+	// Setelah disk pulih batch rusak dibuang dan antrean berjalan lagi
+	if err := os.Remove(filepath.Join(broken, "blocker")); err != nil {
+		t.Fatal(err)
+	}
+	runWriter(t, w)
+	testutil.Eventually(t, "delivery after the WAL recovered", func() bool { return len(rr.Received()) > 0 })
+	testutil.Eventually(t, "an empty WAL", func() bool { return w.shards[0].wal.len() == 0 })
+}
@@ -0,0 +1,136 @@
+package push
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// segment adalah satu batch WriteRequest terkompresi di WAL. Nama file berisi urutan dan
+// jumlah sample, misalnya 00000000000000000042-500.snappy, supaya jumlah sample yang
+// di-drop bisa dihitung tanpa membaca isinya.
+type segment struct {
+	seq     uint64
+	samples int
+}
+
+func (s segment) name() string {
+	return fmt.Sprintf("%020d-%d.snappy", s.seq, s.samples)
+}
+
+// wal adalah antrean batch per shard di disk. Batch ditulis sebelum dikirim dan baru dihapus
+// setelah receiver menerimanya, sehingga batch yang belum terkirim selamat dari restart.
+type wal struct {
+	dir string
+
+	mu       sync.Mutex
+	segments []segment
+	next     uint64
+}
+
+// openWAL membuka direktori WAL dan memuat batch sisa proses sebelumnya
+func openWAL(dir string) (*wal, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	w := &wal{dir: dir}
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasSuffix(name, ".tmp") {
+			// Sisa penulisan yang terputus, isinya tidak lengkap
+			_ = os.Remove(filepath.Join(dir, name))
+			continue
+		}
+		seq, samples, ok := strings.Cut(strings.TrimSuffix(name, ".snappy"), "-")
+		if !ok || !strings.HasSuffix(name, ".snappy") {
+			continue
+		}
+		s, err1 := strconv.ParseUint(seq, 10, 64)
+		n, err2 := strconv.Atoi(samples)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		w.segments = append(w.segments, segment{seq: s, samples: n})
+		w.next = max(w.next, s+1)
+	}
+	slices.SortFunc(w.segments, func(a, b segment) int { return cmp.Compare(a.seq, b.seq) })
+	return w, nil
+}
+
+// append menulis batch baru. Bila antrean melebihi limit, batch tertua dibuang dan
+// dikembalikan supaya sample-nya dihitung sebagai dropped.
+func (w *wal) append(payload []byte, samples, limit int) ([]segment, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	s := segment{seq: w.next, samples: samples}
+	path := filepath.Join(w.dir, s.name())
+	if err := os.WriteFile(path+".tmp", payload, 0o644); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return nil, err
+	}
+	w.next++
+	w.segments = append(w.segments, s)
+
+	var dropped []segment
+	for limit > 0 && len(w.segments) > limit {
+		dropped = append(dropped, w.segments[0])
+		_ = os.Remove(filepath.Join(w.dir, w.segments[0].name()))
+		w.segments = w.segments[1:]
+	}
+	return dropped, nil
+}
+
+// oldest mengembalikan batch tertua beserta isinya
+func (w *wal) oldest() (segment, []byte, bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for len(w.segments) > 0 {
+		s := w.segments[0]
+		payload, err := os.ReadFile(filepath.Join(w.dir, s.name()))
+		if errors.Is(err, fs.ErrNotExist) {
+			// Dihapus dari luar proses, lanjut ke batch berikutnya
+			w.segments = w.segments[1:]
+			continue
+		}
+		return s, payload, true, err
+	}
+	return segment{}, nil, false, nil
+}
+
+// remove menghapus batch yang sudah terkirim atau ditolak permanen. Batch yang sudah
+// dibuang karena limit diabaikan. Bila file gagal dihapus batch tetap di antrean, sama
+// seperti yang akan terlihat setelah restart.
+func (w *wal) remove(s segment) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	i := slices.IndexFunc(w.segments, func(x segment) bool { return x.seq == s.seq })
+	if i < 0 {
+		return nil
+	}
+	if err := os.Remove(filepath.Join(w.dir, s.name())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	w.segments = slices.Delete(w.segments, i, i+1)
+	return nil
+}
+
+// len mengembalikan jumlah batch yang menunggu
+func (w *wal) len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.segments)
+}
@@ -0,0 +1,20 @@
+// Package retry berisi perhitungan jeda retry yang dipakai relay outbox, dispatcher
+// webhook dan remote write.
+package retry
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff mengembalikan jeda eksponensial untuk percobaan ke-attempts (mulai dari 1):
+// minDelay digandakan setiap percobaan sampai maxDelay, lalu ditambah jitter 0-20% supaya
+// instance yang gagal bersamaan tidak mencoba ulang bersamaan.
+func Backoff(attempts int, minDelay, maxDelay time.Duration) time.Duration {
+	d := minDelay
+	for i := 1; i < attempts && d < maxDelay; i++ {
+		d *= 2
+	}
+	d = min(d, maxDelay)
+	return d + time.Duration(rand.Int64N(int64(d)/5+1))
+}
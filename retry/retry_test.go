@@ -0,0 +1,27 @@
+package retry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	for _, tc := range []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 0, want: time.Second},
+		{attempts: 1, want: time.Second},
+		{attempts: 2, want: 2 * time.Second},
+		{attempts: 4, want: 8 * time.Second},
+		{attempts: 7, want: 30 * time.Second},
+		{attempts: 100, want: 30 * time.Second},
+	} {
+		for range 20 {
+			got := Backoff(tc.attempts, time.Second, 30*time.Second)
+			if got < tc.want || got > tc.want+tc.want/5 {
+				t.Fatalf("Backoff(%d) = %s, want %s plus at most 20%% jitter", tc.attempts, got, tc.want)
+			}
+		}
+	}
+}
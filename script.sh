@@ -1,14 +1,14 @@
-#CEK DI GRAFANA BAGIAN CONNECTION TAMBHAKAN PROMETEUS
-# add ini http://prometheus:9090 (sesuikan nama container)
-# dan save
+# data source prometheus dan dashboard app (HTTP, bisnis, DB pool, runtime) sudah di-provision
+# lewat folder grafana/, generate ulang kalau ada metrik baru lalu restart grafana
+go run . dashboards generate
 
-# ke menu dashboard
-# pilh matic misal mysql_global_status_binlog_cache_disk_use  dan run query
-
-
-10826 # dashbvord code golang
+# dashboard mysql exporter masih import manual
 14057 # dashboard untuk mysql
 
+# generate trafik supaya dashboard ada isinya (constant, ramp, spike, soak)
+go run . loadgen -scenario ramp -rate 50 -duration 5m
+go run . loadgen -scenario spike -rate 20 -duration 3m -json loadgen-report.json
+
 
 # go documentation swagegr
 go get -u github.com/swaggo/files
@@ -0,0 +1,107 @@
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CachedStore menyimpan hasil GetByTokenHash di memori selama ttl supaya request yang
+// sudah login tidak selalu membutuhkan query ke database. Pencabutan lewat instance ini
+// langsung membuang cache, sedangkan pencabutan dari instance lain baru terlihat setelah ttl.
+type CachedStore struct {
+	Store
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	session  Session
+	cachedAt time.Time
+}
+
+// NewCachedStore membungkus store dengan cache in-memory
+func NewCachedStore(store Store, ttl time.Duration) *CachedStore {
+	return &CachedStore{Store: store, ttl: ttl, entries: map[string]cacheEntry{}}
+}
+
+func (s *CachedStore) GetByTokenHash(ctx context.Context, hash string) (*Session, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	entry, ok := s.entries[hash]
+	if ok && now.Sub(entry.cachedAt) < s.ttl && entry.session.Active(now) {
+		s.mu.Unlock()
+		copied := entry.session
+		return &copied, nil
+	}
+	delete(s.entries, hash)
+	s.mu.Unlock()
+
+	sess, err := s.Store.GetByTokenHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.entries[hash] = cacheEntry{session: *sess, cachedAt: now}
+	s.mu.Unlock()
+	return sess, nil
+}
+
+func (s *CachedStore) Touch(ctx context.Context, id string, at time.Time) error {
+	if err := s.Store.Touch(ctx, id, at); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	for hash, entry := range s.entries {
+		if entry.session.ID == id {
+			entry.session.LastSeenAt = at
+			s.entries[hash] = entry
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *CachedStore) Revoke(ctx context.Context, username, id string) error {
+	if err := s.Store.Revoke(ctx, username, id); err != nil {
+		return err
+	}
+	s.evict(func(sess Session) bool { return sess.ID == id })
+	return nil
+}
+
+func (s *CachedStore) RevokeAll(ctx context.Context, username string) (int64, error) {
+	n, err := s.Store.RevokeAll(ctx, username)
+	if err != nil {
+		return 0, err
+	}
+	s.evict(func(sess Session) bool { return sess.Username == username })
+	return n, nil
+}
+
+func (s *CachedStore) CountActive(ctx context.Context) (int64, error) {
+	// Dipanggil periodik, jadi dipakai juga untuk membuang entry cache yang sudah basi
+	now := time.Now()
+	s.evict(func(sess Session) bool { return !sess.Active(now) })
+	s.mu.Lock()
+	for hash, entry := range s.entries {
+		if now.Sub(entry.cachedAt) >= s.ttl {
+			delete(s.entries, hash)
+		}
+	}
+	s.mu.Unlock()
+	return s.Store.CountActive(ctx)
+}
+
+func (s *CachedStore) evict(match func(Session) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for hash, entry := range s.entries {
+		if match(entry.session) {
+			delete(s.entries, hash)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GormStore menyimpan session di tabel sessions sehingga bisa dipakai bersama antar instance
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore membuat GormStore dan memastikan tabelnya ada
+func NewGormStore(db *gorm.DB) (*GormStore, error) {
+	if err := db.AutoMigrate(&Session{}); err != nil {
+		return nil, err
+	}
+	return &GormStore{db: db}, nil
+}
+
+func (s *GormStore) active(ctx context.Context) *gorm.DB {
+	return s.db.WithContext(ctx).Model(&Session{}).Where("revoked_at IS NULL AND expires_at > ?", time.Now())
+}
+
+func (s *GormStore) Create(ctx context.Context, sess *Session) error {
+	return s.db.WithContext(ctx).Create(sess).Error
+}
+
+func (s *GormStore) GetByTokenHash(ctx context.Context, hash string) (*Session, error) {
+	var sess Session
+	err := s.active(ctx).Where("token_hash = ?", hash).First(&sess).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sess, nil
+}
+
+func (s *GormStore) ListByUsername(ctx context.Context, username string) ([]Session, error) {
+	out := []Session{}
+	err := s.active(ctx).Where("username = ?", username).Order("created_at DESC").Find(&out).Error
+	return out, err
+}
+
+func (s *GormStore) Touch(ctx context.Context, id string, at time.Time) error {
+	return s.db.WithContext(ctx).Model(&Session{}).Where("id = ?", id).Update("last_seen_at", at).Error
+}
+
+func (s *GormStore) Revoke(ctx context.Context, username, id string) error {
+	res := s.active(ctx).Where("id = ? AND username = ?", id, username).Update("revoked_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *GormStore) RevokeAll(ctx context.Context, username string) (int64, error) {
+	res := s.active(ctx).Where("username = ?", username).Update("revoked_at", time.Now())
+	return res.RowsAffected, res.Error
+}
+
+func (s *GormStore) CountActive(ctx context.Context) (int64, error) {
+	var n int64
+	err := s.active(ctx).Count(&n).Error
+	return n, err
+}
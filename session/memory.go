@@ -0,0 +1,116 @@
+package session
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore menyimpan session di memori proses
+type MemoryStore struct {
+	mu       sync.RWMutex
+	sessions map[string]*Session
+}
+
+// NewMemoryStore membuat MemoryStore kosong
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: map[string]*Session{}}
+}
+
+func (s *MemoryStore) Create(_ context.Context, sess *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copied := *sess
+	s.sessions[sess.TokenHash] = &copied
+	return nil
+}
+
+func (s *MemoryStore) GetByTokenHash(_ context.Context, hash string) (*Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sess, ok := s.sessions[hash]
+	if !ok || !sess.Active(time.Now()) {
+		return nil, ErrNotFound
+	}
+	copied := *sess
+	return &copied, nil
+}
+
+func (s *MemoryStore) ListByUsername(_ context.Context, username string) ([]Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	out := []Session{}
+	for _, sess := range s.sessions {
+		if sess.Username == username && sess.Active(now) {
+			out = append(out, *sess)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (s *MemoryStore) Touch(_ context.Context, id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, sess := range s.sessions {
+		if sess.ID == id {
+			sess.LastSeenAt = at
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (s *MemoryStore) Revoke(_ context.Context, username, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for hash, sess := range s.sessions {
+		if sess.ID == id && sess.Username == username && sess.Active(now) {
+			delete(s.sessions, hash)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (s *MemoryStore) RevokeAll(_ context.Context, username string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	now := time.Now()
+	for hash, sess := range s.sessions {
+		if sess.Username == username {
+			if sess.Active(now) {
+				n++
+			}
+			delete(s.sessions, hash)
+		}
+	}
+	return n, nil
+}
+
+func (s *MemoryStore) CountActive(_ context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Sekalian membuang session kedaluwarsa supaya map tidak tumbuh tanpa batas
+	var n int64
+	now := time.Now()
+	for hash, sess := range s.sessions {
+		if sess.Active(now) {
+			n++
+		} else {
+			delete(s.sessions, hash)
+		}
+	}
+	return n, nil
+}
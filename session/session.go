@@ -0,0 +1,237 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"wyw/textutil"
+)
+
+// touchInterval membatasi penulisan LastSeenAt supaya tidak ada write ke store di setiap request
+const touchInterval = time.Minute
+
+// ErrNotFound dikembalikan bila session tidak ada, sudah dicabut atau kedaluwarsa
+var ErrNotFound = errors.New("session: not found")
+
+// Session adalah sesi login milik user. Token hanya disimpan dalam bentuk hash.
+type Session struct {
+	ID         string    `json:"id" gorm:"primaryKey;size:32"`
+	TokenHash  string    `json:"-" gorm:"uniqueIndex;size:64"`
+	Username   string    `json:"username" gorm:"index;size:191"`
+	IP         string    `json:"ip" gorm:"size:64"`
+	UserAgent  string    `json:"user_agent" gorm:"size:512"`
+	Device     string    `json:"device" gorm:"size:64"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	// ExpiresAt dan RevokedAt diindeks bersama karena dipakai oleh CountActive
+	ExpiresAt time.Time  `json:"expires_at" gorm:"index:idx_sessions_active"`
+	RevokedAt *time.Time `json:"-" gorm:"index:idx_sessions_active"`
+}
+
+// Active melaporkan apakah session belum dicabut dan belum kedaluwarsa pada waktu now
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// Store menyimpan session
+type Store interface {
+	Create(ctx context.Context, s *Session) error
+	// GetByTokenHash mengembalikan session aktif dengan hash token tersebut
+	GetByTokenHash(ctx context.Context, hash string) (*Session, error)
+	// ListByUsername mengembalikan session aktif milik username, terbaru lebih dulu
+	ListByUsername(ctx context.Context, username string) ([]Session, error)
+	// Touch memperbarui LastSeenAt sebuah session
+	Touch(ctx context.Context, id string, at time.Time) error
+	// Revoke mencabut satu session milik username, ErrNotFound bila tidak ada atau sudah tidak aktif
+	Revoke(ctx context.Context, username, id string) error
+	// RevokeAll mencabut semua session aktif milik username dan mengembalikan jumlahnya
+	RevokeAll(ctx context.Context, username string) (int64, error)
+	// CountActive menghitung seluruh session aktif
+	CountActive(ctx context.Context) (int64, error)
+}
+
+// Manager membuat session baru dan mencari session dari token bearer
+type Manager struct {
+	store Store
+	ttl   time.Duration
+
+	// active adalah perkiraan jumlah session aktif. Nilainya disesuaikan saat session dibuat
+	// atau dicabut dan dihitung ulang dari store secara periodik untuk session yang kedaluwarsa.
+	active   atomic.Int64
+	mu       sync.Mutex
+	onActive func(int64)
+}
+
+// NewManager membuat Manager dengan masa berlaku session ttl
+func NewManager(store Store, ttl time.Duration) *Manager {
+	return &Manager{store: store, ttl: ttl}
+}
+
+// TrackActive memanggil report setiap jumlah session aktif berubah dan menghitung ulang
+// dari store setiap interval sampai ctx selesai. Dengan begitu gauge tidak perlu
+// memindai tabel setiap kali Prometheus melakukan scrape.
+func (m *Manager) TrackActive(ctx context.Context, interval time.Duration, report func(int64)) {
+	m.mu.Lock()
+	m.onActive = report
+	m.mu.Unlock()
+
+	recount := func() {
+		n, err := m.store.CountActive(ctx)
+		if err != nil {
+			return
+		}
+		m.active.Store(n)
+		report(n)
+	}
+	recount()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				recount()
+			}
+		}
+	}()
+}
+
+func (m *Manager) adjustActive(delta int64) {
+	n := m.active.Add(delta)
+	if n < 0 {
+		m.active.Store(0)
+		n = 0
+	}
+	m.mu.Lock()
+	report := m.onActive
+	m.mu.Unlock()
+	if report != nil {
+		report(n)
+	}
+}
+
+// Create membuat session untuk username dan mengembalikan token bearer mentahnya
+func (m *Manager) Create(ctx context.Context, username, ip, userAgent string) (string, *Session, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", nil, err
+	}
+
+	tok := base64.RawURLEncoding.EncodeToString(raw)
+	now := time.Now()
+	s := &Session{
+		ID:         hex.EncodeToString(id),
+		TokenHash:  HashToken(tok),
+		Username:   username,
+		IP:         ip,
+		UserAgent:  textutil.Truncate(userAgent, 512),
+		Device:     DeviceFromUserAgent(userAgent),
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(m.ttl),
+	}
+	if err := m.store.Create(ctx, s); err != nil {
+		return "", nil, err
+	}
+	m.adjustActive(1)
+	return tok, s, nil
+}
+
+// Authenticate mencari session aktif untuk token bearer
+func (m *Manager) Authenticate(ctx context.Context, tok string) (*Session, error) {
+	if tok == "" {
+		return nil, ErrNotFound
+	}
+	s, err := m.store.GetByTokenHash(ctx, HashToken(tok))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !s.Active(now) {
+		return nil, ErrNotFound
+	}
+	if now.Sub(s.LastSeenAt) >= touchInterval {
+		if err := m.store.Touch(ctx, s.ID, now); err != nil {
+			return nil, err
+		}
+		s.LastSeenAt = now
+	}
+	return s, nil
+}
+
+// List mengembalikan session aktif milik username
+func (m *Manager) List(ctx context.Context, username string) ([]Session, error) {
+	return m.store.ListByUsername(ctx, username)
+}
+
+// Revoke mencabut satu session milik username
+func (m *Manager) Revoke(ctx context.Context, username, id string) error {
+	if err := m.store.Revoke(ctx, username, id); err != nil {
+		return err
+	}
+	m.adjustActive(-1)
+	return nil
+}
+
+// RevokeAll mencabut semua session milik username, misalnya saat akun disusupi
+func (m *Manager) RevokeAll(ctx context.Context, username string) (int64, error) {
+	n, err := m.store.RevokeAll(ctx, username)
+	if err != nil {
+		return 0, err
+	}
+	m.adjustActive(-n)
+	return n, nil
+}
+
+// DeviceFromUserAgent membuat label perangkat singkat seperti "Firefox on Linux" dari header User-Agent
+func DeviceFromUserAgent(ua string) string {
+	if ua == "" {
+		return "unknown"
+	}
+	lower := strings.ToLower(ua)
+
+	browser := "Other"
+	for _, b := range []struct{ token, name string }{
+		{"edg/", "Edge"}, {"opr/", "Opera"}, {"firefox/", "Firefox"}, {"chrome/", "Chrome"},
+		{"safari/", "Safari"}, {"curl/", "curl"}, {"postman", "Postman"}, {"go-http-client", "Go"},
+	} {
+		if strings.Contains(lower, b.token) {
+			browser = b.name
+			break
+		}
+	}
+
+	os := ""
+	for _, o := range []struct{ token, name string }{
+		{"android", "Android"}, {"iphone", "iOS"}, {"ipad", "iPadOS"}, {"windows", "Windows"},
+		{"mac os x", "macOS"}, {"linux", "Linux"},
+	} {
+		if strings.Contains(lower, o.token) {
+			os = o.name
+			break
+		}
+	}
+	if os == "" {
+		return browser
+	}
+	return browser + " on " + os
+}
+
+// HashToken mengembalikan hash SHA-256 hex dari token bearer
+func HashToken(tok string) string {
+	sum := sha256.Sum256([]byte(tok))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,134 @@
+package slo
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Nama series yang dihasilkan metric.AppMetricsExporter.ObserveHTTPRequest
+const (
+	requestsSeries = "app_http_requests_total"
+	bucketSeries   = "app_http_request_duration_seconds_bucket"
+	countSeries    = "app_http_request_duration_seconds_count"
+)
+
+// RuleFile adalah format file rule Prometheus
+type RuleFile struct {
+	Groups []RuleGroup `yaml:"groups"`
+}
+
+// RuleGroup adalah satu grup rule yang dievaluasi bersama
+type RuleGroup struct {
+	Name  string `yaml:"name"`
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule adalah recording rule (Record terisi) atau alerting rule (Alert terisi)
+type Rule struct {
+	Record      string            `yaml:"record,omitempty"`
+	Alert       string            `yaml:"alert,omitempty"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// RecordName adalah nama recording rule rasio error SLO untuk satu window
+func RecordName(window time.Duration) string {
+	return "slo:sli_error:ratio_rate" + FormatDuration(window)
+}
+
+// Rules membuat recording rule rasio error per window dan alert burn rate multi-window
+// untuk setiap SLO, satu grup per SLO
+func Rules(slos []SLO) RuleFile {
+	var file RuleFile
+	for _, s := range slos {
+		labels := map[string]string{"slo": s.Name}
+		group := RuleGroup{Name: "slo-" + s.Name}
+
+		for _, w := range append(s.RateWindows(), s.Window) {
+			group.Rules = append(group.Rules, Rule{Record: RecordName(w), Expr: errorRatioExpr(s, w), Labels: labels})
+		}
+		group.Rules = append(group.Rules, Rule{
+			Record: "slo:error_budget:remaining",
+			Expr:   fmt.Sprintf("1 - %s{slo=%q} / %s", RecordName(s.Window), s.Name, formatFloat(s.ErrorBudget())),
+			Labels: labels,
+		})
+
+		var severities []string
+		bySeverity := make(map[string][]string)
+		for _, w := range s.AlertWindows() {
+			if _, ok := bySeverity[w.Severity]; !ok {
+				severities = append(severities, w.Severity)
+			}
+			threshold := fmt.Sprintf("(%s * %s)", formatFloat(w.BurnRate(s.Window)), formatFloat(s.ErrorBudget()))
+			bySeverity[w.Severity] = append(bySeverity[w.Severity], fmt.Sprintf("(%s{slo=%q} > %s and %s{slo=%q} > %s)",
+				RecordName(w.Long), s.Name, threshold, RecordName(w.Short), s.Name, threshold))
+		}
+		for _, severity := range severities {
+			group.Rules = append(group.Rules, Rule{
+				Alert:  "SLOErrorBudgetBurn",
+				Expr:   strings.Join(bySeverity[severity], "\nor\n"),
+				Labels: map[string]string{"slo": s.Name, "severity": severity},
+				Annotations: map[string]string{
+					"summary":     fmt.Sprintf("SLO %s is burning its error budget too fast", s.Name),
+					"description": describe(s),
+				},
+			})
+		}
+		file.Groups = append(file.Groups, group)
+	}
+	return file
+}
+
+// RulesYAML menulis Rules sebagai YAML yang bisa dipakai di rule_files Prometheus
+func RulesYAML(slos []SLO) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(Rules(slos)); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// errorRatioExpr adalah PromQL rasio request buruk SLO dalam satu window
+func errorRatioExpr(s SLO, window time.Duration) string {
+	selector := fmt.Sprintf("endpoint=%q", s.Endpoint)
+	if s.Method != "" {
+		selector += fmt.Sprintf(",method=%q", s.Method)
+	}
+	w := FormatDuration(window)
+
+	if s.Latency == 0 {
+		return fmt.Sprintf("sum(rate(%s{%s,status=~\"5..\"}[%s]))\n/\nsum(rate(%s{%s}[%s]))",
+			requestsSeries, selector, w, requestsSeries, selector, w)
+	}
+	le := formatFloat(s.Latency.Seconds())
+	return fmt.Sprintf("1 - (\n  sum(rate(%s{%s,status!~\"5..\",le=%q}[%s]))\n  /\n  sum(rate(%s{%s}[%s]))\n)",
+		bucketSeries, selector, le, w, countSeries, selector, w)
+}
+
+func describe(s SLO) string {
+	target := formatFloat(round(s.Objective*100)) + "% of "
+	if s.Method != "" {
+		target += s.Method + " "
+	}
+	target += s.Endpoint + " requests succeed"
+	if s.Latency > 0 {
+		target += " under " + s.Latency.String()
+	}
+	return target + " over " + FormatDuration(s.Window)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,187 @@
+package slo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Definition adalah deklarasi satu SLO, dibaca dari konfigurasi sebagai JSON
+type Definition struct {
+	Name string `json:"name"`
+	// Endpoint adalah route gin, sama dengan label endpoint di app_http_requests_total
+	Endpoint string `json:"endpoint"`
+	// Method kosong berarti semua method
+	Method string `json:"method,omitempty"`
+	// Objective adalah target rasio request yang baik, misalnya 0.999
+	Objective float64 `json:"objective"`
+	// Latency kosong berarti hanya availability yang dihitung, selain itu request yang lebih
+	// lambat dari nilai ini dianggap buruk walaupun berhasil
+	Latency string `json:"latency,omitempty"`
+	// Window adalah periode compliance, menerima satuan d dan w, misalnya 30d
+	Window string `json:"window"`
+}
+
+// DefaultDefinitions dipakai bila konfigurasi tidak mendeklarasikan SLO
+var DefaultDefinitions = []Definition{
+	{Name: "login", Endpoint: "/api/v1/login", Method: "POST", Objective: 0.999, Latency: "300ms", Window: "30d"},
+}
+
+// SLO adalah Definition yang sudah divalidasi
+type SLO struct {
+	Name      string
+	Endpoint  string
+	Method    string
+	Objective float64
+	Latency   time.Duration
+	Window    time.Duration
+}
+
+// AlertWindow adalah pasangan window panjang dan pendek untuk alert multi-window,
+// multi-burn-rate. Alert menyala bila kedua window membakar budget di atas burn rate.
+type AlertWindow struct {
+	Severity string
+	Long     time.Duration
+	Short    time.Duration
+	// BudgetConsumed adalah porsi error budget yang habis dalam window panjang saat alert menyala
+	BudgetConsumed float64
+}
+
+// AlertWindows mengikuti rekomendasi SRE workbook: untuk window 30 hari burn rate-nya
+// 14.4 dan 6 (page) serta 3 dan 1 (ticket)
+var AlertWindows = []AlertWindow{
+	{Severity: "page", Long: time.Hour, Short: 5 * time.Minute, BudgetConsumed: 0.02},
+	{Severity: "page", Long: 6 * time.Hour, Short: 30 * time.Minute, BudgetConsumed: 0.05},
+	{Severity: "ticket", Long: 24 * time.Hour, Short: 2 * time.Hour, BudgetConsumed: 0.10},
+	{Severity: "ticket", Long: 72 * time.Hour, Short: 6 * time.Hour, BudgetConsumed: 0.10},
+}
+
+// BurnRate mengembalikan burn rate yang menghabiskan BudgetConsumed dalam window panjang
+func (w AlertWindow) BurnRate(window time.Duration) float64 {
+	return round(w.BudgetConsumed * window.Hours() / w.Long.Hours())
+}
+
+// ErrorBudget adalah rasio request buruk yang masih diperbolehkan
+func (s SLO) ErrorBudget() float64 {
+	return round(1 - s.Objective)
+}
+
+// AlertWindows mengembalikan pasangan window yang masuk akal untuk periode compliance SLO.
+// Pasangan dengan burn rate di bawah 1 dilewati karena alert-nya menyala sebelum budget terancam.
+func (s SLO) AlertWindows() []AlertWindow {
+	var windows []AlertWindow
+	for _, w := range AlertWindows {
+		if w.Long <= s.Window && w.BurnRate(s.Window) >= 1 {
+			windows = append(windows, w)
+		}
+	}
+	return windows
+}
+
+// RateWindows mengembalikan semua window rasio error yang dibutuhkan alert, terurut naik
+func (s SLO) RateWindows() []time.Duration {
+	var windows []time.Duration
+	for _, w := range s.AlertWindows() {
+		windows = append(windows, w.Short, w.Long)
+	}
+	slices.Sort(windows)
+	return slices.Compact(windows)
+}
+
+// Good melaporkan apakah satu request memenuhi SLO. Status 5xx selalu buruk, error 4xx
+// adalah kesalahan client sehingga tetap dihitung baik.
+func (s SLO) Good(status int, duration time.Duration) bool {
+	if status >= 500 {
+		return false
+	}
+	return s.Latency == 0 || duration <= s.Latency
+}
+
+// Matches melaporkan apakah request dengan method dan endpoint ini termasuk SLO
+func (s SLO) Matches(method, endpoint string) bool {
+	return endpoint == s.Endpoint && (s.Method == "" || s.Method == method)
+}
+
+// Compile memvalidasi definisi. buckets adalah batas histogram latency HTTP, threshold
+// latency harus tepat di salah satunya supaya rule PromQL dan status in-process sama.
+func Compile(defs []Definition, buckets []float64) ([]SLO, error) {
+	slos := make([]SLO, 0, len(defs))
+	seen := make(map[string]bool, len(defs))
+	for _, d := range defs {
+		if d.Name == "" || d.Endpoint == "" {
+			return nil, errors.New("slo needs a name and endpoint")
+		}
+		if seen[d.Name] {
+			return nil, fmt.Errorf("slo %s: duplicate name", d.Name)
+		}
+		seen[d.Name] = true
+		if d.Objective <= 0 || d.Objective >= 1 {
+			return nil, fmt.Errorf("slo %s: objective must be between 0 and 1", d.Name)
+		}
+		s := SLO{Name: d.Name, Endpoint: d.Endpoint, Method: strings.ToUpper(d.Method), Objective: d.Objective}
+
+		window := d.Window
+		if window == "" {
+			window = "30d"
+		}
+		var err error
+		if s.Window, err = ParseDuration(window); err != nil {
+			return nil, fmt.Errorf("slo %s: window: %w", d.Name, err)
+		}
+		if s.Window < time.Hour {
+			return nil, fmt.Errorf("slo %s: window must be at least 1h", d.Name)
+		}
+
+		if d.Latency != "" {
+			if s.Latency, err = time.ParseDuration(d.Latency); err != nil {
+				return nil, fmt.Errorf("slo %s: latency: %w", d.Name, err)
+			}
+			if !slices.Contains(buckets, s.Latency.Seconds()) {
+				return nil, fmt.Errorf("slo %s: latency %s is not a histogram bucket boundary %v", d.Name, s.Latency, buckets)
+			}
+		}
+		slos = append(slos, s)
+	}
+	return slos, nil
+}
+
+// ParseDuration seperti time.ParseDuration tetapi juga menerima satuan d dan w
+func ParseDuration(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.Atoi(n)
+			if err != nil || v <= 0 {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			return time.Duration(v) * unit, nil
+		}
+	}
+	return time.ParseDuration(s)
+}
+
+// FormatDuration menulis durasi dengan satuan terbesar yang utuh, format yang sama
+// dengan durasi Prometheus (5m, 1h, 3d)
+func FormatDuration(d time.Duration) string {
+	switch {
+	case d%(7*24*time.Hour) == 0:
+		return strconv.Itoa(int(d/(7*24*time.Hour))) + "w"
+	case d%(24*time.Hour) == 0:
+		return strconv.Itoa(int(d/(24*time.Hour))) + "d"
+	case d%time.Hour == 0:
+		return strconv.Itoa(int(d/time.Hour)) + "h"
+	case d%time.Minute == 0:
+		return strconv.Itoa(int(d/time.Minute)) + "m"
+	default:
+		return strconv.Itoa(int(d/time.Second)) + "s"
+	}
+}
+
+// round membuang noise floating point supaya angka di rule tetap terbaca, misalnya 0.001
+// bukan 0.0010000000000000009
+func round(v float64) float64 {
+	return math.Round(v*1e9) / 1e9
+}
@@ -0,0 +1,185 @@
+package slo
+
+import (
+	"sync"
+	"time"
+)
+
+// resolution adalah lebar satu bucket counter in-process
+const resolution = time.Minute
+
+// BurnRate adalah rasio error dan burn rate SLO dalam satu window
+type BurnRate struct {
+	Window     string  `json:"window" example:"1h"`
+	Total      uint64  `json:"total" example:"5230"`
+	ErrorRatio float64 `json:"error_ratio" example:"0.0004"`
+	// BurnRate 1 berarti budget habis tepat di akhir periode compliance
+	BurnRate float64 `json:"burn_rate" example:"0.4"`
+}
+
+// Alert adalah status satu pasangan window alert multi-burn-rate
+type Alert struct {
+	Severity  string  `json:"severity" example:"page"`
+	Long      string  `json:"long_window" example:"1h"`
+	Short     string  `json:"short_window" example:"5m"`
+	Threshold float64 `json:"threshold" example:"14.4"`
+	Firing    bool    `json:"firing"`
+}
+
+// Status adalah error budget satu SLO yang dihitung dari counter in-process
+type Status struct {
+	SLO       string  `json:"slo" example:"login"`
+	Endpoint  string  `json:"endpoint" example:"/api/v1/login"`
+	Method    string  `json:"method,omitempty" example:"POST"`
+	Objective float64 `json:"objective" example:"0.999"`
+	LatencyMs float64 `json:"latency_ms,omitempty" example:"300"`
+	Window    string  `json:"window" example:"30d"`
+	// Coverage adalah porsi periode compliance yang teramati sejak proses mulai, counter
+	// in-process hilang saat restart sehingga nilai di bawah 1 berarti data belum lengkap
+	Coverage float64 `json:"coverage" example:"0.25"`
+	Total    uint64  `json:"total" example:"120000"`
+	Good     uint64  `json:"good" example:"119950"`
+	// SLI adalah rasio request baik, 1 bila belum ada request
+	SLI float64 `json:"sli" example:"0.99958"`
+	// BudgetRemaining adalah sisa error budget, negatif bila SLO sudah dilanggar
+	BudgetRemaining float64    `json:"budget_remaining" example:"0.583"`
+	BurnRates       []BurnRate `json:"burn_rates"`
+	Alerts          []Alert    `json:"alerts"`
+}
+
+// counts adalah jumlah request dalam satu bucket waktu
+type counts struct {
+	minute int64
+	total  uint64
+	good   uint64
+}
+
+// series menyimpan counter per menit satu SLO dalam ring sepanjang periode compliance
+type series struct {
+	slo     SLO
+	mu      sync.Mutex
+	buckets []counts
+}
+
+// Tracker menghitung error budget SLO dari observasi HTTP. Tracker memenuhi
+// metric.Observer sehingga memakai data yang sama dengan metrik Prometheus.
+type Tracker struct {
+	series  []*series
+	started time.Time
+}
+
+// NewTracker membuat Tracker untuk slos
+func NewTracker(slos []SLO) *Tracker {
+	t := &Tracker{started: time.Now()}
+	for _, s := range slos {
+		t.series = append(t.series, &series{slo: s, buckets: make([]counts, s.Window/resolution)})
+	}
+	return t
+}
+
+// SLOs mengembalikan SLO yang dilacak
+func (t *Tracker) SLOs() []SLO {
+	slos := make([]SLO, 0, len(t.series))
+	for _, s := range t.series {
+		slos = append(slos, s.slo)
+	}
+	return slos
+}
+
+func (t *Tracker) ObserveHTTPRequest(status int, method, endpoint, _ string, duration time.Duration) {
+	minute := time.Now().Unix() / int64(resolution/time.Second)
+	for _, s := range t.series {
+		if !s.slo.Matches(method, endpoint) {
+			continue
+		}
+		good := s.slo.Good(status, duration)
+
+		s.mu.Lock()
+		b := &s.buckets[minute%int64(len(s.buckets))]
+		if b.minute != minute {
+			*b = counts{minute: minute}
+		}
+		b.total++
+		if good {
+			b.good++
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (t *Tracker) RecordBusinessEvent(string, string) {}
+
+// Statuses menghitung error budget, burn rate dan alert setiap SLO
+func (t *Tracker) Statuses() []Status {
+	now := time.Now()
+	minute := now.Unix() / int64(resolution/time.Second)
+	statuses := make([]Status, 0, len(t.series))
+	for _, s := range t.series {
+		statuses = append(statuses, s.status(minute, now.Sub(t.started)))
+	}
+	return statuses
+}
+
+func (s *series) status(minute int64, observed time.Duration) Status {
+	st := Status{
+		SLO:       s.slo.Name,
+		Endpoint:  s.slo.Endpoint,
+		Method:    s.slo.Method,
+		Objective: s.slo.Objective,
+		LatencyMs: float64(s.slo.Latency) / float64(time.Millisecond),
+		Window:    FormatDuration(s.slo.Window),
+		Coverage:  round(min(1, observed.Seconds()/s.slo.Window.Seconds())),
+	}
+
+	ratios := make(map[time.Duration]float64)
+	s.mu.Lock()
+	st.Total, st.Good = s.sum(minute, s.slo.Window)
+	for _, w := range s.slo.RateWindows() {
+		total, good := s.sum(minute, w)
+		ratio := errorRatio(total, good)
+		ratios[w] = ratio
+		st.BurnRates = append(st.BurnRates, BurnRate{
+			Window:     FormatDuration(w),
+			Total:      total,
+			ErrorRatio: round(ratio),
+			BurnRate:   round(ratio / s.slo.ErrorBudget()),
+		})
+	}
+	s.mu.Unlock()
+
+	st.SLI = round(1 - errorRatio(st.Total, st.Good))
+	st.BudgetRemaining = round(1 - errorRatio(st.Total, st.Good)/s.slo.ErrorBudget())
+	for _, w := range s.slo.AlertWindows() {
+		threshold := w.BurnRate(s.slo.Window)
+		limit := threshold * s.slo.ErrorBudget()
+		st.Alerts = append(st.Alerts, Alert{
+			Severity:  w.Severity,
+			Long:      FormatDuration(w.Long),
+			Short:     FormatDuration(w.Short),
+			Threshold: threshold,
+			Firing:    ratios[w.Long] > limit && ratios[w.Short] > limit,
+		})
+	}
+	return st
+}
+
+// sum menjumlahkan bucket dalam window yang berakhir di minute, bucket yang sudah
+// ditimpa menit lain atau belum pernah terisi dilewati
+func (s *series) sum(minute int64, window time.Duration) (total, good uint64) {
+	n := min(int64(window/resolution), int64(len(s.buckets)))
+	for m := minute - n + 1; m <= minute; m++ {
+		b := s.buckets[m%int64(len(s.buckets))]
+		if b.minute == m {
+			total += b.total
+			good += b.good
+		}
+	}
+	return total, good
+}
+
+func errorRatio(total, good uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(total-good) / float64(total)
+}
@@ -0,0 +1,287 @@
+package sso
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// StateCookie adalah nama cookie yang mengikat state login ke browser yang memulainya
+const StateCookie = "wyw_oidc_state"
+
+var (
+	ErrUnknownState  = errors.New("sso: unknown or expired state")
+	ErrNonceMismatch = errors.New("sso: id token nonce mismatch")
+	ErrNoIDToken     = errors.New("sso: token response has no id_token")
+)
+
+// RoleMapping memetakan grup dari IdP ke role aplikasi, dicek berurutan
+type RoleMapping struct {
+	Group string
+	Role  string
+}
+
+// Config mengatur OpenID Connect relying party
+type Config struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// GroupsClaim adalah nama claim ID token yang berisi daftar grup
+	GroupsClaim string
+	RoleMapping []RoleMapping
+	DefaultRole string
+	// StateTTL adalah batas waktu user menyelesaikan login di IdP
+	StateTTL time.Duration
+	// StateSecret menandatangani cookie state. Bila kosong dibuat acak sehingga login yang
+	// sedang berjalan gagal setelah restart atau bila callback jatuh ke instance lain.
+	StateSecret []byte
+}
+
+// Identity adalah hasil login yang sudah diverifikasi dari ID token
+type Identity struct {
+	Issuer            string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+	Groups            []string
+	Role              string
+}
+
+// pendingLogin disimpan di cookie state yang ditandatangani, bukan di memori server
+type pendingLogin struct {
+	State     string `json:"s"`
+	Nonce     string `json:"n"`
+	Verifier  string `json:"v"`
+	ExpiresAt int64  `json:"e"`
+}
+
+// RelyingParty menjalankan authorization code flow dengan PKCE. Discovery dilakukan saat
+// pertama kali dipakai supaya aplikasi tetap bisa start ketika IdP belum tersedia.
+// JWKS di-cache oleh go-oidc dan diambil ulang otomatis ketika IdP merotasi key (kid baru).
+type RelyingParty struct {
+	cfg Config
+
+	initMu   sync.Mutex
+	oauth    *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// NewRelyingParty membuat RelyingParty tanpa menghubungi IdP
+func NewRelyingParty(cfg Config) *RelyingParty {
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{oidc.ScopeOpenID, "profile", "email"}
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+	if cfg.StateTTL <= 0 {
+		cfg.StateTTL = 10 * time.Minute
+	}
+	if len(cfg.StateSecret) == 0 {
+		cfg.StateSecret = make([]byte, 32)
+		_, _ = rand.Read(cfg.StateSecret)
+	}
+	return &RelyingParty{cfg: cfg}
+}
+
+func (rp *RelyingParty) discover(ctx context.Context) (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	rp.initMu.Lock()
+	defer rp.initMu.Unlock()
+
+	if rp.oauth != nil {
+		return rp.oauth, rp.verifier, nil
+	}
+	provider, err := oidc.NewProvider(ctx, rp.cfg.IssuerURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sso: discovery: %w", err)
+	}
+	rp.oauth = &oauth2.Config{
+		ClientID:     rp.cfg.ClientID,
+		ClientSecret: rp.cfg.ClientSecret,
+		RedirectURL:  rp.cfg.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       rp.cfg.Scopes,
+	}
+	rp.verifier = provider.Verifier(&oidc.Config{ClientID: rp.cfg.ClientID})
+	return rp.oauth, rp.verifier, nil
+}
+
+// AuthCodeURL membuat URL authorization IdP dengan state, nonce dan PKCE challenge baru.
+// Cookie yang dikembalikan harus dipasang di response supaya callback hanya bisa
+// diselesaikan oleh browser yang memulai login.
+func (rp *RelyingParty) AuthCodeURL(ctx context.Context) (string, *http.Cookie, error) {
+	oauth, _, err := rp.discover(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	pending := pendingLogin{
+		State:     randomString(),
+		Nonce:     randomString(),
+		Verifier:  oauth2.GenerateVerifier(),
+		ExpiresAt: time.Now().Add(rp.cfg.StateTTL).Unix(),
+	}
+	payload, err := json.Marshal(pending)
+	if err != nil {
+		return "", nil, err
+	}
+	value := base64.RawURLEncoding.EncodeToString(payload) + "." + rp.sign(payload)
+
+	authURL := oauth.AuthCodeURL(pending.State, oidc.Nonce(pending.Nonce), oauth2.S256ChallengeOption(pending.Verifier))
+	return authURL, rp.cookie(value, int(rp.cfg.StateTTL.Seconds())), nil
+}
+
+// ClearCookie mengembalikan cookie yang menghapus cookie state di browser
+func (rp *RelyingParty) ClearCookie() *http.Cookie {
+	return rp.cookie("", -1)
+}
+
+// cookie membuat cookie state yang hanya dikirim ke path callback. SameSite=Lax tetap
+// mengirim cookie pada redirect GET dari IdP.
+func (rp *RelyingParty) cookie(value string, maxAge int) *http.Cookie {
+	path, secure := "/", false
+	if u, err := url.Parse(rp.cfg.RedirectURL); err == nil {
+		if u.Path != "" {
+			path = u.Path
+		}
+		secure = u.Scheme == "https"
+	}
+	return &http.Cookie{
+		Name:     StateCookie,
+		Value:    value,
+		Path:     path,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+func (rp *RelyingParty) sign(payload []byte) string {
+	mac := hmac.New(sha256.New, rp.cfg.StateSecret)
+	mac.Write(payload)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// openCookie memeriksa tanda tangan, masa berlaku dan kecocokan state dari cookie
+func (rp *RelyingParty) openCookie(value, state string) (pendingLogin, error) {
+	encoded, sig, ok := strings.Cut(value, ".")
+	if !ok {
+		return pendingLogin{}, ErrUnknownState
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || !hmac.Equal([]byte(sig), []byte(rp.sign(payload))) {
+		return pendingLogin{}, ErrUnknownState
+	}
+	var pending pendingLogin
+	if err := json.Unmarshal(payload, &pending); err != nil {
+		return pendingLogin{}, ErrUnknownState
+	}
+	if time.Now().Unix() > pending.ExpiresAt || subtle.ConstantTimeCompare([]byte(pending.State), []byte(state)) != 1 {
+		return pendingLogin{}, ErrUnknownState
+	}
+	return pending, nil
+}
+
+// Exchange menukar authorization code lalu memverifikasi ID token (signature, issuer,
+// audience, expiry dan nonce). cookie adalah nilai cookie state dari AuthCodeURL, state
+// harus sama dengan yang tersimpan di dalamnya.
+func (rp *RelyingParty) Exchange(ctx context.Context, code, state, cookie string) (Identity, error) {
+	pending, err := rp.openCookie(cookie, state)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	oauth, verifier, err := rp.discover(ctx)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	tok, err := oauth.Exchange(ctx, code, oauth2.VerifierOption(pending.Verifier))
+	if err != nil {
+		return Identity{}, fmt.Errorf("sso: code exchange: %w", err)
+	}
+	rawIDToken, ok := tok.Extra("id_token").(string)
+	if !ok {
+		return Identity{}, ErrNoIDToken
+	}
+	idToken, err := verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return Identity{}, fmt.Errorf("sso: verify id token: %w", err)
+	}
+	if idToken.Nonce != pending.Nonce {
+		return Identity{}, ErrNonceMismatch
+	}
+
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return Identity{}, fmt.Errorf("sso: decode claims: %w", err)
+	}
+
+	identity := Identity{
+		Issuer:            idToken.Issuer,
+		Subject:           idToken.Subject,
+		Email:             stringClaim(claims, "email"),
+		EmailVerified:     claims["email_verified"] == true,
+		PreferredUsername: stringClaim(claims, "preferred_username"),
+		Groups:            stringsClaim(claims, rp.cfg.GroupsClaim),
+	}
+	identity.Role = rp.mapRole(identity.Groups)
+	return identity, nil
+}
+
+// mapRole mengembalikan role dari mapping pertama yang grupnya dimiliki user
+func (rp *RelyingParty) mapRole(groups []string) string {
+	for _, m := range rp.cfg.RoleMapping {
+		for _, g := range groups {
+			if g == m.Group {
+				return m.Role
+			}
+		}
+	}
+	return rp.cfg.DefaultRole
+}
+
+func stringClaim(claims map[string]any, key string) string {
+	s, _ := claims[key].(string)
+	return s
+}
+
+func stringsClaim(claims map[string]any, key string) []string {
+	switch v := claims[key].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func randomString() string {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,427 @@
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+// mockProvider adalah OpenID provider minimal di dalam proses: discovery, JWKS dan token
+// endpoint dengan PKCE. Authorization endpoint disimulasikan lewat authorize.
+type mockProvider struct {
+	t   *testing.T
+	srv *httptest.Server
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PrivateKey
+	published []string
+	signKID   string
+	codes     map[string]authRequest
+	// claims mengubah claim ID token berikutnya sebelum ditandatangani
+	claims func(map[string]any)
+	// forge menandatangani token dengan key yang tidak dipublikasikan, header kid tetap signKID
+	forge *rsa.PrivateKey
+
+	discoveryHits int
+	jwksHits      int
+}
+
+type authRequest struct {
+	nonce     string
+	challenge string
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	t.Helper()
+	p := &mockProvider{t: t, keys: map[string]*rsa.PrivateKey{}, codes: map[string]authRequest{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("GET /jwks", p.jwks)
+	mux.HandleFunc("POST /token", p.token)
+	p.srv = httptest.NewServer(mux)
+	t.Cleanup(p.srv.Close)
+	p.rotate("key-1", false)
+	return p
+}
+
+// rotate membuat key baru untuk menandatangani token. Bila keep false, key lama tidak lagi
+// dipublikasikan di JWKS.
+func (p *mockProvider) rotate(kid string, keep bool) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys[kid] = key
+	if !keep {
+		p.published = nil
+	}
+	p.published = append(p.published, kid)
+	p.signKID = kid
+}
+
+func (p *mockProvider) discovery(w http.ResponseWriter, _ *http.Request) {
+	p.mu.Lock()
+	p.discoveryHits++
+	p.mu.Unlock()
+	writeJSON(w, map[string]any{
+		"issuer":                                p.srv.URL,
+		"authorization_endpoint":                p.srv.URL + "/authorize",
+		"token_endpoint":                        p.srv.URL + "/token",
+		"jwks_uri":                              p.srv.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (p *mockProvider) jwks(w http.ResponseWriter, _ *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.jwksHits++
+	var set jose.JSONWebKeySet
+	for _, kid := range p.published {
+		set.Keys = append(set.Keys, jose.JSONWebKey{Key: &p.keys[kid].PublicKey, KeyID: kid, Algorithm: string(jose.RS256), Use: "sig"})
+	}
+	writeJSON(w, set)
+}
+
+func (p *mockProvider) token(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	req, ok := p.codes[r.FormValue("code")]
+	delete(p.codes, r.FormValue("code"))
+	p.mu.Unlock()
+	if !ok {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+	sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != req.challenge {
+		http.Error(w, `{"error":"invalid_grant","error_description":"pkce"}`, http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, map[string]any{
+		"access_token": "access",
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     p.idToken(req.nonce),
+	})
+}
+
+func (p *mockProvider) idToken(nonce string) string {
+	claims := map[string]any{
+		"iss":                p.srv.URL,
+		"sub":                "user-1",
+		"aud":                "wyw",
+		"exp":                time.Now().Add(5 * time.Minute).Unix(),
+		"iat":                time.Now().Unix(),
+		"nonce":              nonce,
+		"email":              "alice@example.com",
+		"email_verified":     true,
+		"preferred_username": "alice",
+		"groups":             []string{"dev", "sre"},
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.claims != nil {
+		p.claims(claims)
+	}
+	key := p.keys[p.signKID]
+	if p.forge != nil {
+		key = p.forge
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{
+		Algorithm: jose.RS256,
+		Key:       jose.JSONWebKey{Key: key, KeyID: p.signKID},
+	}, (&jose.SignerOptions{}).WithType("JWT"))
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	payload, _ := json.Marshal(claims)
+	obj, err := signer.Sign(payload)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	raw, err := obj.CompactSerialize()
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	return raw
+}
+
+// authorize mensimulasikan user yang login di IdP lalu diarahkan kembali dengan code
+func (p *mockProvider) authorize(authURL string) (code, state string) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" {
+		p.t.Fatalf("code_challenge_method = %q, want S256", q.Get("code_challenge_method"))
+	}
+	code = rand.Text()
+	p.mu.Lock()
+	p.codes[code] = authRequest{nonce: q.Get("nonce"), challenge: q.Get("code_challenge")}
+	p.mu.Unlock()
+	return code, q.Get("state")
+}
+
+// hits mengembalikan jumlah request discovery dan JWKS
+func (p *mockProvider) hits() (discovery, jwks int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.discoveryHits, p.jwksHits
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func newTestRelyingParty(p *mockProvider) *RelyingParty {
+	return NewRelyingParty(Config{
+		IssuerURL:   p.srv.URL,
+		ClientID:    "wyw",
+		RedirectURL: "https://wyw.example.com/api/v1/auth/oidc/callback",
+		RoleMapping: []RoleMapping{{Group: "sre", Role: "admin"}, {Group: "dev", Role: "user"}},
+		DefaultRole: "viewer",
+		StateSecret: []byte("0123456789abcdef0123456789abcdef"),
+	})
+}
+
+// login menjalankan satu authorization code flow penuh
+func login(t *testing.T, rp *RelyingParty, p *mockProvider) (Identity, error) {
+	t.Helper()
+	authURL, cookie, err := rp.AuthCodeURL(context.Background())
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code, state := p.authorize(authURL)
+	return rp.Exchange(context.Background(), code, state, cookie.Value)
+}
+
+func TestDiscoveryAndLogin(t *testing.T) {
+	p := newMockProvider(t)
+	rp := newTestRelyingParty(p)
+
+	authURL, cookie, err := rp.AuthCodeURL(context.Background())
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	if !strings.HasPrefix(authURL, p.srv.URL+"/authorize?") {
+		t.Fatalf("auth URL %q does not use the discovered authorization endpoint", authURL)
+	}
+	if cookie.Name != StateCookie || !cookie.HttpOnly || !cookie.Secure || cookie.SameSite != http.SameSiteLaxMode {
+		t.Fatalf("state cookie %+v must be HttpOnly, Secure and SameSite=Lax", cookie)
+	}
+	if cookie.Path != "/api/v1/auth/oidc/callback" || cookie.MaxAge != 600 {
+		t.Fatalf("state cookie path %q max-age %d, want the callback path and 600", cookie.Path, cookie.MaxAge)
+	}
+
+	code, state := p.authorize(authURL)
+	identity, err := rp.Exchange(context.Background(), code, state, cookie.Value)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	want := Identity{
+		Issuer:            p.srv.URL,
+		Subject:           "user-1",
+		Email:             "alice@example.com",
+		EmailVerified:     true,
+		PreferredUsername: "alice",
+		Groups:            []string{"dev", "sre"},
+		Role:              "admin",
+	}
+	if identity.Issuer != want.Issuer || identity.Subject != want.Subject || identity.Email != want.Email ||
+		!identity.EmailVerified || identity.PreferredUsername != want.PreferredUsername ||
+		strings.Join(identity.Groups, ",") != "dev,sre" || identity.Role != want.Role {
+		t.Fatalf("identity = %+v, want %+v", identity, want)
+	}
+
+	// Discovery hanya dilakukan sekali
+	if _, err := login(t, rp, p); err != nil {
+		t.Fatalf("second login: %v", err)
+	}
+	if discovery, _ := p.hits(); discovery != 1 {
+		t.Fatalf("discovery fetched %d times, want 1", discovery)
+	}
+}
+
+func TestDiscoveryUnavailable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	rp := NewRelyingParty(Config{IssuerURL: srv.URL, ClientID: "wyw"})
+	if _, _, err := rp.AuthCodeURL(context.Background()); err == nil {
+		t.Fatal("AuthCodeURL succeeded without a reachable provider")
+	}
+}
+
+func TestJWKSRotation(t *testing.T) {
+	p := newMockProvider(t)
+	rp := newTestRelyingParty(p)
+
+	if _, err := login(t, rp, p); err != nil {
+		t.Fatalf("login with key-1: %v", err)
+	}
+	_, before := p.hits()
+
+	// Key baru dengan kid yang belum dikenal membuat JWKS diambil ulang
+	p.rotate("key-2", true)
+	if _, err := login(t, rp, p); err != nil {
+		t.Fatalf("login after rotation to key-2: %v", err)
+	}
+	if _, after := p.hits(); after <= before {
+		t.Fatal("JWKS was not fetched again after the signing key rotated")
+	}
+
+	// Setelah rotasi berikutnya JWKS diambil ulang, key yang sudah dicabut tidak lagi diterima
+	p.rotate("key-3", false)
+	if _, err := login(t, rp, p); err != nil {
+		t.Fatalf("login after rotation to key-3: %v", err)
+	}
+	p.mu.Lock()
+	p.signKID = "key-1"
+	p.mu.Unlock()
+	if _, err := login(t, rp, p); err == nil {
+		t.Fatal("login with a key removed from the JWKS succeeded")
+	}
+}
+
+func TestTokenValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims func(map[string]any)
+	}{
+		{"wrong audience", func(c map[string]any) { c["aud"] = "someone-else" }},
+		{"wrong issuer", func(c map[string]any) { c["iss"] = "https://evil.example.com" }},
+		{"expired", func(c map[string]any) { c["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{"nonce mismatch", func(c map[string]any) { c["nonce"] = "replayed" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newMockProvider(t)
+			p.claims = tt.claims
+			if _, err := login(t, newTestRelyingParty(p), p); err == nil {
+				t.Fatal("login succeeded with an invalid ID token")
+			}
+		})
+	}
+
+	t.Run("unpublished key", func(t *testing.T) {
+		p := newMockProvider(t)
+		rp := newTestRelyingParty(p)
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// kid sama dengan key yang dipublikasikan tetapi private key berbeda
+		p.mu.Lock()
+		p.forge = key
+		p.mu.Unlock()
+		if _, err := login(t, rp, p); err == nil {
+			t.Fatal("login succeeded with a forged signature")
+		}
+	})
+
+	t.Run("email not verified", func(t *testing.T) {
+		p := newMockProvider(t)
+		p.claims = func(c map[string]any) { c["email_verified"] = false }
+		identity, err := login(t, newTestRelyingParty(p), p)
+		if err != nil {
+			t.Fatalf("login: %v", err)
+		}
+		if identity.EmailVerified {
+			t.Fatal("EmailVerified is true for email_verified=false")
+		}
+	})
+}
+
+func TestStateCookie(t *testing.T) {
+	p := newMockProvider(t)
+	rp := newTestRelyingParty(p)
+
+	start := func() (code, state, cookie string) {
+		authURL, c, err := rp.AuthCodeURL(context.Background())
+		if err != nil {
+			t.Fatalf("AuthCodeURL: %v", err)
+		}
+		code, state = p.authorize(authURL)
+		return code, state, c.Value
+	}
+
+	t.Run("missing cookie", func(t *testing.T) {
+		code, state, _ := start()
+		if _, err := rp.Exchange(context.Background(), code, state, ""); !errors.Is(err, ErrUnknownState) {
+			t.Fatalf("Exchange error = %v, want ErrUnknownState", err)
+		}
+	})
+
+	t.Run("cookie from another login", func(t *testing.T) {
+		code, state, _ := start()
+		_, _, other := start()
+		if _, err := rp.Exchange(context.Background(), code, state, other); !errors.Is(err, ErrUnknownState) {
+			t.Fatalf("Exchange error = %v, want ErrUnknownState", err)
+		}
+	})
+
+	t.Run("tampered cookie", func(t *testing.T) {
+		code, state, cookie := start()
+		encoded, sig, _ := strings.Cut(cookie, ".")
+		payload, _ := base64.RawURLEncoding.DecodeString(encoded)
+		var pending pendingLogin
+		_ = json.Unmarshal(payload, &pending)
+		pending.ExpiresAt = time.Now().Add(time.Hour).Unix()
+		payload, _ = json.Marshal(pending)
+		forged := base64.RawURLEncoding.EncodeToString(payload) + "." + sig
+		if _, err := rp.Exchange(context.Background(), code, state, forged); !errors.Is(err, ErrUnknownState) {
+			t.Fatalf("Exchange error = %v, want ErrUnknownState", err)
+		}
+	})
+
+	t.Run("signed by another secret", func(t *testing.T) {
+		other := NewRelyingParty(Config{IssuerURL: p.srv.URL, ClientID: "wyw", StateSecret: []byte("another-secret-another-secret-00")})
+		authURL, c, err := other.AuthCodeURL(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		code, state := p.authorize(authURL)
+		if _, err := rp.Exchange(context.Background(), code, state, c.Value); !errors.Is(err, ErrUnknownState) {
+			t.Fatalf("Exchange error = %v, want ErrUnknownState", err)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		short := NewRelyingParty(Config{IssuerURL: p.srv.URL, ClientID: "wyw", StateTTL: time.Nanosecond})
+		authURL, c, err := short.AuthCodeURL(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		code, state := p.authorize(authURL)
+		time.Sleep(1100 * time.Millisecond)
+		if _, err := short.Exchange(context.Background(), code, state, c.Value); !errors.Is(err, ErrUnknownState) {
+			t.Fatalf("Exchange error = %v, want ErrUnknownState", err)
+		}
+	})
+
+	t.Run("shared secret across instances", func(t *testing.T) {
+		code, state, cookie := start()
+		replica := newTestRelyingParty(p)
+		if _, err := replica.Exchange(context.Background(), code, state, cookie); err != nil {
+			t.Fatalf("Exchange on another instance with the same secret: %v", err)
+		}
+	})
+}
@@ -0,0 +1,317 @@
+package statsd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+	"wyw/logging"
+	"wyw/metric"
+)
+
+// Flavor protokol yang dikirim
+const (
+	FlavorStatsD    = "statsd"
+	FlavorDogStatsD = "dogstatsd"
+)
+
+// Options mengatur Emitter
+type Options struct {
+	// Address adalah udp://host:port, unix:///path/ke/socket (datagram) atau host:port
+	Address string
+	Flavor  string
+	// Prefix ditambahkan di depan setiap nama metrik, misalnya "wyw."
+	Prefix string
+	// Tags ditambahkan ke setiap metrik, misalnya env dan service. Hanya didukung DogStatsD
+	// karena StatsD klasik tidak punya tempat untuk tag yang tidak dikenal di nama metrik.
+	Tags map[string]string
+	// SampleRate berlaku untuk timing durasi request. Counter diagregasi di client sehingga
+	// selalu dikirim utuh.
+	SampleRate float64
+	// MaxTimings membatasi sampel timing yang disimpan per series dalam satu interval.
+	// Sampel yang lebih dari batas dipilih acak dan sample rate yang dikirim disesuaikan.
+	MaxTimings    int
+	FlushInterval time.Duration
+	// QueueSize membatasi observasi yang menunggu diagregasi, sisanya dibuang dan dihitung
+	QueueSize int
+	// MaxPacketSize adalah batas ukuran datagram, default 1432 untuk UDP dan 8192 untuk UDS
+	MaxPacketSize int
+}
+
+// observation adalah salinan satu panggilan metric.Observer
+type observation struct {
+	business  bool
+	status    int
+	method    string
+	endpoint  string
+	code      string
+	duration  time.Duration
+	eventType string
+}
+
+// timingSample adalah reservoir sampel timing satu series dalam satu interval
+type timingSample struct {
+	values []float64
+	// seen adalah jumlah sampel yang lolos SampleRate, termasuk yang tidak disimpan
+	seen int
+}
+
+// Emitter meneruskan observasi HTTP dan event bisnis ke StatsD atau DogStatsD. Emitter
+// memenuhi metric.Observer sehingga handler tetap hanya memanggil AppMetricsExporter.
+type Emitter struct {
+	metrics *metric.AppMetricsExporter
+	opts    Options
+	network string
+	addr    string
+	format  formatter
+
+	queue   chan observation
+	dropped atomic.Int64
+
+	// Dipakai hanya oleh goroutine Run
+	conn    net.Conn
+	failing bool
+	counts  map[key]int64
+	timings map[key]*timingSample
+	packet  []byte
+}
+
+// New membuat Emitter dan mendaftarkannya sebagai observer metrics. Koneksi dibuka saat
+// flush pertama sehingga agent yang belum siap tidak menggagalkan startup.
+func New(metrics *metric.AppMetricsExporter, opts Options) (*Emitter, error) {
+	if opts.Flavor == "" {
+		opts.Flavor = FlavorDogStatsD
+	}
+	if opts.Flavor != FlavorStatsD && opts.Flavor != FlavorDogStatsD {
+		return nil, fmt.Errorf("statsd flavor must be %s or %s", FlavorStatsD, FlavorDogStatsD)
+	}
+	if opts.Flavor == FlavorStatsD && len(opts.Tags) > 0 {
+		return nil, fmt.Errorf("statsd tags are only supported by the %s flavor", FlavorDogStatsD)
+	}
+	if opts.SampleRate == 0 {
+		opts.SampleRate = 1
+	}
+	if opts.SampleRate < 0 || opts.SampleRate > 1 {
+		return nil, fmt.Errorf("statsd sample rate must be between 0 and 1")
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 4096
+	}
+	if opts.MaxTimings <= 0 {
+		opts.MaxTimings = 1000
+	}
+	network, addr, err := parseAddress(opts.Address)
+	if err != nil {
+		return nil, err
+	}
+	if opts.MaxPacketSize <= 0 {
+		opts.MaxPacketSize = 1432
+		if network == "unixgram" {
+			opts.MaxPacketSize = 8192
+		}
+	}
+	e := &Emitter{
+		metrics: metrics,
+		opts:    opts,
+		network: network,
+		addr:    addr,
+		format:  newFormatter(opts.Flavor, opts.Prefix, opts.Tags),
+		queue:   make(chan observation, opts.QueueSize),
+		counts:  map[key]int64{},
+		timings: map[key]*timingSample{},
+	}
+	metrics.AddObserver(e)
+	return e, nil
+}
+
+// parseAddress mengembalikan network dan alamat untuk net.Dial
+func parseAddress(address string) (string, string, error) {
+	scheme, rest, ok := strings.Cut(address, "://")
+	if !ok {
+		scheme, rest = "udp", address
+	}
+	switch scheme {
+	case "udp":
+		if _, _, err := net.SplitHostPort(rest); err != nil {
+			return "", "", fmt.Errorf("statsd address %q: %w", address, err)
+		}
+		return "udp", rest, nil
+	case "unix", "unixgram":
+		if rest == "" {
+			return "", "", fmt.Errorf("statsd address %q has no socket path", address)
+		}
+		return "unixgram", rest, nil
+	}
+	return "", "", fmt.Errorf("statsd address %q must use udp:// or unix://", address)
+}
+
+// ObserveHTTPRequest memenuhi metric.Observer
+func (e *Emitter) ObserveHTTPRequest(status int, method, endpoint, code string, duration time.Duration) {
+	e.enqueue(observation{status: status, method: method, endpoint: endpoint, code: code, duration: duration})
+}
+
+// RecordBusinessEvent memenuhi metric.Observer. user_id tidak dijadikan tag karena
+// kardinalitasnya tidak terbatas untuk backend StatsD.
+func (e *Emitter) RecordBusinessEvent(eventType, _ string) {
+	e.enqueue(observation{business: true, eventType: eventType})
+}
+
+// enqueue tidak pernah blocking, observasi dibuang bila antrean penuh
+func (e *Emitter) enqueue(o observation) {
+	select {
+	case e.queue <- o:
+	default:
+		e.dropped.Add(1)
+		e.metrics.RecordStatsDDropped()
+	}
+}
+
+// Run mengagregasi observasi dan mengirimnya setiap FlushInterval sampai ctx selesai, lalu
+// mengirim sisa antrean sekali lagi
+func (e *Emitter) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case o := <-e.queue:
+			e.aggregate(o)
+		case <-ticker.C:
+			e.flush()
+		case <-ctx.Done():
+			e.drain()
+			e.flush()
+			if e.conn != nil {
+				_ = e.conn.Close()
+			}
+			return
+		}
+	}
+}
+
+func (e *Emitter) drain() {
+	for {
+		select {
+		case o := <-e.queue:
+			e.aggregate(o)
+		default:
+			return
+		}
+	}
+}
+
+func (e *Emitter) aggregate(o observation) {
+	if o.business {
+		e.counts[e.format.key("business.events", tag{"event_type", o.eventType})]++
+		return
+	}
+	tags := []tag{
+		{"method", o.method},
+		{"endpoint", o.endpoint},
+		{"status", strconv.Itoa(o.status)},
+		{"code", o.code},
+	}
+	e.counts[e.format.key("http.requests", tags...)]++
+	if e.opts.SampleRate < 1 && rand.Float64() >= e.opts.SampleRate {
+		return
+	}
+	k := e.format.key("http.request.duration", tags...)
+	ts := e.timings[k]
+	if ts == nil {
+		ts = &timingSample{}
+		e.timings[k] = ts
+	}
+	ts.add(float64(o.duration.Microseconds())/1000, e.opts.MaxTimings)
+}
+
+// add menyimpan v dengan reservoir sampling: setelah max sampel tersimpan, setiap sampel
+// punya peluang yang sama untuk tetap ada di akhir interval
+func (ts *timingSample) add(v float64, max int) {
+	ts.seen++
+	if len(ts.values) < max {
+		ts.values = append(ts.values, v)
+		return
+	}
+	if i := rand.IntN(ts.seen); i < max {
+		ts.values[i] = v
+	}
+}
+
+// flush menulis hasil agregasi satu interval sebagai datagram yang digabung sampai
+// MaxPacketSize. Jumlah observasi yang dibuang ikut dikirim supaya tetap terlihat saat
+// Prometheus tidak dipakai.
+func (e *Emitter) flush() {
+	if dropped := e.dropped.Swap(0); dropped > 0 {
+		e.counts[e.format.key("statsd.dropped")] += dropped
+	}
+	for k, v := range e.counts {
+		e.write(e.format.counter(k, v))
+	}
+	for k, ts := range e.timings {
+		// Sample rate mencakup SampleRate dan sampel yang tidak muat di reservoir, supaya
+		// agent tetap menghitung jumlah request yang benar
+		rate := e.opts.SampleRate * float64(len(ts.values)) / float64(ts.seen)
+		for _, line := range e.format.timings(k, ts.values, rate, e.opts.MaxPacketSize) {
+			e.write(line)
+		}
+	}
+	e.send()
+	clear(e.counts)
+	clear(e.timings)
+}
+
+// write menambahkan baris ke paket, paket dikirim dulu bila baris tidak muat
+func (e *Emitter) write(line string) {
+	if len(e.packet) > 0 && len(e.packet)+1+len(line) > e.opts.MaxPacketSize {
+		e.send()
+	}
+	if len(e.packet) > 0 {
+		e.packet = append(e.packet, '\n')
+	}
+	e.packet = append(e.packet, line...)
+}
+
+// send menulis paket. Paket yang gagal dibuang karena StatsD memang lossy, koneksi ditutup
+// supaya flush berikutnya dial ulang (misalnya socket agent dibuat ulang).
+func (e *Emitter) send() {
+	if len(e.packet) == 0 {
+		return
+	}
+	defer func() { e.packet = e.packet[:0] }()
+	if e.conn == nil {
+		conn, err := net.Dial(e.network, e.addr)
+		if err != nil {
+			e.fail(err)
+			return
+		}
+		e.conn = conn
+	}
+	if _, err := e.conn.Write(e.packet); err != nil {
+		_ = e.conn.Close()
+		e.conn = nil
+		e.fail(err)
+		return
+	}
+	if e.failing {
+		logging.Logger(logging.ModuleMetric).Info("statsd packets are being delivered again", slog.String("address", e.opts.Address))
+		e.failing = false
+	}
+	e.metrics.RecordStatsDPacket("sent")
+}
+
+// fail mencatat paket gagal, log hanya ditulis sekali sampai pengiriman pulih supaya agent
+// yang mati tidak membanjiri log setiap flush
+func (e *Emitter) fail(err error) {
+	e.metrics.RecordStatsDPacket("failed")
+	if !e.failing {
+		logging.Logger(logging.ModuleMetric).Warn("failed to send statsd packet", slog.String("address", e.opts.Address), slog.Any("error", err))
+		e.failing = true
+	}
+}
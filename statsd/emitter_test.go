@@ -0,0 +1,81 @@
+package statsd
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+	"wyw/metric"
+)
+
+func TestNewRejectsTagsForClassicStatsD(t *testing.T) {
+	opts := Options{Address: "127.0.0.1:8125", Flavor: FlavorStatsD, Tags: map[string]string{"env": "prod"}}
+	if _, err := New(metric.NewAppMetricsExporter(), opts); err == nil {
+		t.Fatal("classic statsd accepted global tags it cannot send")
+	}
+	opts.Flavor = FlavorDogStatsD
+	if _, err := New(metric.NewAppMetricsExporter(), opts); err != nil {
+		t.Fatalf("dogstatsd rejected global tags: %v", err)
+	}
+}
+
+func TestTimingsAreCappedPerInterval(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	e, err := New(metric.NewAppMetricsExporter(), Options{
+		Address:    conn.LocalAddr().String(),
+		Flavor:     FlavorStatsD,
+		SampleRate: 0.5,
+		MaxTimings: 10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2000 {
+		e.aggregate(observation{status: 200, method: "GET", endpoint: "/api/v1/users", code: "ok", duration: 5 * time.Millisecond})
+	}
+	k := e.format.key("http.request.duration", tag{"method", "GET"}, tag{"endpoint", "/api/v1/users"}, tag{"status", "200"}, tag{"code", "ok"})
+	ts := e.timings[k]
+	if ts == nil || len(ts.values) != 10 || ts.seen < 800 || ts.seen > 1200 {
+		t.Fatalf("timing reservoir = %+v, want 10 values out of about 1000 sampled", ts)
+	}
+	seen := ts.seen
+	e.flush()
+	if len(e.timings) != 0 {
+		t.Fatal("timings were kept after the flush")
+	}
+
+	var lines []string
+	buf := make([]byte, 65536)
+	for len(lines) < 11 {
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("read after %d lines: %v", len(lines), err)
+		}
+		lines = append(lines, strings.Split(string(buf[:n]), "\n")...)
+	}
+
+	var timings int
+	wantRate := "|@" + formatMillis(0.5*10/float64(seen))
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "http.requests.GET.api_v1_users.200.ok:"):
+			if line != "http.requests.GET.api_v1_users.200.ok:2000|c" {
+				t.Fatalf("counter line = %s, want every request counted", line)
+			}
+		case strings.HasPrefix(line, "http.request.duration."):
+			if !strings.HasSuffix(line, "ms"+wantRate) {
+				t.Fatalf("timing line = %s, want the rate adjusted to %s", line, wantRate)
+			}
+			timings++
+		}
+	}
+	if timings != 10 {
+		t.Fatalf("sent %d timing lines, want 10", timings)
+	}
+}
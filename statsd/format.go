@@ -0,0 +1,145 @@
+package statsd
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// tag adalah pasangan nama dan nilai, urutannya dipertahankan untuk flavor statsd
+type tag struct {
+	name, value string
+}
+
+// key mengidentifikasi satu series teragregasi: nama metrik beserta tag yang sudah diformat
+type key struct {
+	name string
+	tags string
+}
+
+// formatter menulis nama dan tag sesuai flavor. StatsD klasik tidak mengenal tag sehingga
+// nilai tag disisipkan ke nama sebagai segmen Graphite, DogStatsD memakai |#name:value.
+type formatter struct {
+	flavor string
+	prefix string
+	// global adalah tag dari Options.Tags yang sudah diformat untuk DogStatsD
+	global string
+}
+
+func newFormatter(flavor, prefix string, tags map[string]string) formatter {
+	f := formatter{flavor: flavor, prefix: prefix}
+	if flavor == FlavorDogStatsD {
+		parts := make([]string, 0, len(tags))
+		for _, name := range slices.Sorted(maps.Keys(tags)) {
+			parts = append(parts, dogTag(name, tags[name]))
+		}
+		f.global = strings.Join(parts, ",")
+	}
+	return f
+}
+
+func (f formatter) key(name string, tags ...tag) key {
+	if f.flavor == FlavorStatsD {
+		var b strings.Builder
+		b.WriteString(name)
+		for _, t := range tags {
+			b.WriteByte('.')
+			b.WriteString(segment(t.value))
+		}
+		return key{name: b.String()}
+	}
+	parts := make([]string, 0, len(tags)+1)
+	for _, t := range tags {
+		parts = append(parts, dogTag(t.name, t.value))
+	}
+	if f.global != "" {
+		parts = append(parts, f.global)
+	}
+	return key{name: name, tags: strings.Join(parts, ",")}
+}
+
+// counter menulis baris counter, misalnya wyw.http.requests:12|c|#method:GET
+func (f formatter) counter(k key, value int64) string {
+	return f.line(k, strconv.FormatInt(value, 10), "c", 1)
+}
+
+// timings menulis durasi dalam milidetik. DogStatsD menerima beberapa nilai dalam satu
+// baris (protokol v1.1) sehingga nilai dikemas sampai batas max, StatsD klasik satu nilai
+// per baris.
+func (f formatter) timings(k key, values []float64, rate float64, max int) []string {
+	var lines []string
+	if f.flavor == FlavorStatsD {
+		for _, v := range values {
+			lines = append(lines, f.line(k, formatMillis(v), "ms", rate))
+		}
+		return lines
+	}
+	suffix := len(f.line(k, "", "ms", rate))
+	var packed strings.Builder
+	for _, v := range values {
+		value := formatMillis(v)
+		if packed.Len() > 0 && packed.Len()+1+len(value)+suffix > max {
+			lines = append(lines, f.line(k, packed.String(), "ms", rate))
+			packed.Reset()
+		}
+		if packed.Len() > 0 {
+			packed.WriteByte(':')
+		}
+		packed.WriteString(value)
+	}
+	if packed.Len() > 0 {
+		lines = append(lines, f.line(k, packed.String(), "ms", rate))
+	}
+	return lines
+}
+
+func (f formatter) line(k key, value, typ string, rate float64) string {
+	var b strings.Builder
+	b.WriteString(f.prefix)
+	b.WriteString(k.name)
+	b.WriteByte(':')
+	b.WriteString(value)
+	b.WriteByte('|')
+	b.WriteString(typ)
+	if rate < 1 {
+		b.WriteString("|@")
+		b.WriteString(strconv.FormatFloat(rate, 'f', -1, 64))
+	}
+	if k.tags != "" {
+		b.WriteString("|#")
+		b.WriteString(k.tags)
+	}
+	return b.String()
+}
+
+func formatMillis(ms float64) string {
+	return strconv.FormatFloat(ms, 'f', -1, 64)
+}
+
+// dogTag membuang karakter yang punya arti dalam protokol DogStatsD
+func dogTag(name, value string) string {
+	clean := strings.NewReplacer("|", "_", ",", "_", "#", "_", "\n", "_")
+	return clean.Replace(name) + ":" + clean.Replace(value)
+}
+
+// segment mengubah nilai tag menjadi satu segmen nama Graphite, misalnya
+// /api/v1/users/:id menjadi api_v1_users_id
+func segment(value string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range value {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' {
+			b.WriteRune(r)
+			underscore = false
+		} else if !underscore && b.Len() > 0 {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	s := strings.TrimSuffix(b.String(), "_")
+	if s == "" {
+		return "root"
+	}
+	return s
+}
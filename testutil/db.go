@@ -0,0 +1,30 @@
+// Package testutil berisi fixture bersama untuk test: database SQLite sementara dan
+// receiver HTTP yang mencatat request.
+package testutil
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// NewDB membuka database SQLite di direktori sementara test. Satu koneksi saja supaya
+// transaksi dari beberapa goroutine antre seperti lock baris di MySQL.
+func NewDB(t testing.TB) *gorm.DB {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_pragma=busy_timeout(5000)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	return db
+}
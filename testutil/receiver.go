@@ -0,0 +1,82 @@
+package testutil
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Receiver adalah server HTTP untuk test yang men-decode setiap request lalu mencatat
+// hasilnya berurutan. Request yang gagal di-decode dijawab 400, dan request yang dijawab
+// selain 2xx oleh fungsi status tidak dicatat.
+type Receiver[T any] struct {
+	URL string
+
+	mu       sync.Mutex
+	received []T
+	status   func(T) int
+}
+
+// NewReceiver menjalankan Receiver sampai test selesai
+func NewReceiver[T any](t testing.TB, decode func(*http.Request) (T, error)) *Receiver[T] {
+	t.Helper()
+	rc := &Receiver[T]{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v, err := decode(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		code := http.StatusOK
+		if rc.status != nil {
+			code = rc.status(v)
+		}
+		if code/100 != 2 {
+			http.Error(w, http.StatusText(code), code)
+			return
+		}
+		rc.received = append(rc.received, v)
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(srv.Close)
+	rc.URL = srv.URL
+	return rc
+}
+
+// SetStatus mengatur status response per request, nil berarti selalu 200
+func (rc *Receiver[T]) SetStatus(status func(T) int) {
+	rc.mu.Lock()
+	rc.status = status
+	rc.mu.Unlock()
+}
+
+// Received mengembalikan salinan request yang sudah dicatat
+func (rc *Receiver[T]) Received() []T {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return append([]T(nil), rc.received...)
+}
+
+// DecodeJSON men-decode body request JSON sebagai T
+func DecodeJSON[T any](r *http.Request) (T, error) {
+	var v T
+	err := json.NewDecoder(r.Body).Decode(&v)
+	return v, err
+}
+
+// Eventually menunggu sampai cond terpenuhi atau menggagalkan test setelah beberapa detik
+func Eventually(t testing.TB, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
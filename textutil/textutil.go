@@ -0,0 +1,20 @@
+package textutil
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Truncate memotong s menjadi paling banyak n byte tanpa memotong di tengah karakter
+// multi-byte. Byte UTF-8 yang tidak valid diganti U+FFFD lebih dulu, karena kolom utf8mb4
+// MySQL dalam strict mode menolak string yang bukan UTF-8 valid.
+func Truncate(s string, n int) string {
+	s = strings.ToValidUTF8(s, "\uFFFD")
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,34 @@
+package textutil
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   string
+		n    int
+		want string
+	}{
+		{name: "short", in: "abc", n: 5, want: "abc"},
+		{name: "exact", in: "abcde", n: 5, want: "abcde"},
+		{name: "ascii", in: "abcdef", n: 4, want: "abcd"},
+		{name: "inside a rune", in: "ab€cd", n: 4, want: "ab"},
+		{name: "after a rune", in: "ab€cd", n: 5, want: "ab€"},
+		{name: "inside an emoji", in: "😀😀", n: 7, want: "😀"},
+		{name: "invalid bytes", in: "a\xffb", n: 10, want: "a\uFFFDb"},
+		{name: "zero", in: "abc", n: 0, want: ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Truncate(tc.in, tc.n)
+			if got != tc.want {
+				t.Fatalf("Truncate(%q, %d) = %q, want %q", tc.in, tc.n, got, tc.want)
+			}
+			if !utf8.ValidString(got) || len(got) > tc.n {
+				t.Fatalf("Truncate(%q, %d) = %q is not valid UTF-8 within %d bytes", tc.in, tc.n, got, tc.n)
+			}
+		})
+	}
+}
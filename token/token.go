@@ -0,0 +1,128 @@
+package token
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Tujuan token, token hanya valid untuk tujuan saat ia dibuat
+const (
+	PurposeVerifyEmail   = "verify_email"
+	PurposePasswordReset = "password_reset"
+	PurposeMFAChallenge  = "mfa_challenge"
+)
+
+var (
+	ErrInvalid  = errors.New("token: invalid token")
+	ErrExpired  = errors.New("token: token expired")
+	ErrConsumed = errors.New("token: token already used")
+)
+
+// Claims adalah isi token yang ditandatangani
+type Claims struct {
+	ID        string    `json:"jti"`
+	Purpose   string    `json:"pur"`
+	Subject   string    `json:"sub"`
+	ExpiresAt time.Time `json:"exp"`
+}
+
+// ConsumedToken mencatat ID token yang sudah dipakai supaya token hanya bisa dipakai sekali
+type ConsumedToken struct {
+	ID         string    `gorm:"primaryKey;size:32"`
+	Purpose    string    `gorm:"size:32"`
+	Subject    string    `gorm:"size:191"`
+	ExpiresAt  time.Time `gorm:"index"`
+	ConsumedAt time.Time
+}
+
+// Manager membuat dan memverifikasi token HMAC-SHA256 sekali pakai
+type Manager struct {
+	secret []byte
+}
+
+// NewManager membuat Manager dan memastikan tabel consumed_tokens ada
+func NewManager(secret []byte, db *gorm.DB) (*Manager, error) {
+	if len(secret) < 32 {
+		return nil, errors.New("token: secret must be at least 32 bytes")
+	}
+	if err := db.AutoMigrate(&ConsumedToken{}); err != nil {
+		return nil, err
+	}
+	return &Manager{secret: secret}, nil
+}
+
+// Issue membuat token baru untuk subject dengan masa berlaku ttl
+func (m *Manager) Issue(purpose, subject string, ttl time.Duration) (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	claims := Claims{
+		ID:        hex.EncodeToString(id),
+		Purpose:   purpose,
+		Subject:   subject,
+		ExpiresAt: time.Now().Add(ttl).UTC().Truncate(time.Second),
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + m.sign(encoded), nil
+}
+
+// Verify memeriksa tanda tangan, tujuan dan masa berlaku token tanpa menandainya terpakai
+func (m *Manager) Verify(raw, purpose string) (Claims, error) {
+	var claims Claims
+	encoded, sig, ok := strings.Cut(raw, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(m.sign(encoded))) {
+		return claims, ErrInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || json.Unmarshal(payload, &claims) != nil {
+		return claims, ErrInvalid
+	}
+	if claims.Purpose != purpose {
+		return claims, ErrInvalid
+	}
+	if time.Now().After(claims.ExpiresAt) {
+		return claims, ErrExpired
+	}
+	return claims, nil
+}
+
+// Redeem memverifikasi token lalu menandainya terpakai dalam transaksi tx.
+// Pemanggilan kedua untuk token yang sama mengembalikan ErrConsumed.
+func (m *Manager) Redeem(ctx context.Context, tx *gorm.DB, raw, purpose string) (Claims, error) {
+	claims, err := m.Verify(raw, purpose)
+	if err != nil {
+		return claims, err
+	}
+	err = tx.WithContext(ctx).Create(&ConsumedToken{
+		ID:         claims.ID,
+		Purpose:    claims.Purpose,
+		Subject:    claims.Subject,
+		ExpiresAt:  claims.ExpiresAt,
+		ConsumedAt: time.Now(),
+	}).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return claims, ErrConsumed
+	}
+	return claims, err
+}
+
+func (m *Manager) sign(encoded string) string {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
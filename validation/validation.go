@@ -0,0 +1,214 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+	"wyw/apperror"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	idTranslations "github.com/go-playground/validator/v10/translations/id"
+)
+
+// PasswordPolicy mengatur aturan kekuatan password untuk validator "password"
+type PasswordPolicy struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// DefaultPasswordPolicy dipakai bila konfigurasi tidak mengatur policy sendiri
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:    8,
+	MaxLength:    72,
+	RequireUpper: true,
+	RequireLower: true,
+	RequireDigit: true,
+}
+
+// usernamePattern hanya mengizinkan ASCII supaya nama yang mirip secara visual
+// (unicode confusable) tidak bisa didaftarkan
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+var universal *ut.UniversalTranslator
+
+// Register mendaftarkan validator kustom dan translasi pesan ke validator milik Gin
+func Register(policy PasswordPolicy) error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("validation: gin validator engine is not go-playground/validator")
+	}
+
+	// Pakai nama field dari tag json supaya error sesuai dengan payload client
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	if err := v.RegisterValidation("username", validateUsername); err != nil {
+		return err
+	}
+	if err := v.RegisterValidation("password", policy.validate); err != nil {
+		return err
+	}
+
+	enLocale, idLocale := en.New(), id.New()
+	universal = ut.New(enLocale, enLocale, idLocale)
+
+	enTrans, _ := universal.GetTranslator("en")
+	if err := enTranslations.RegisterDefaultTranslations(v, enTrans); err != nil {
+		return err
+	}
+	idTrans, _ := universal.GetTranslator("id")
+	if err := idTranslations.RegisterDefaultTranslations(v, idTrans); err != nil {
+		return err
+	}
+
+	custom := map[ut.Translator]map[string]string{
+		enTrans: {
+			"username": "{0} may only contain letters, digits, '.', '_' and '-' and must start with a letter or digit",
+			"password": "{0} " + policy.describe("en"),
+		},
+		idTrans: {
+			"username": "{0} hanya boleh berisi huruf, angka, '.', '_' dan '-' serta diawali huruf atau angka",
+			"password": "{0} " + policy.describe("id"),
+		},
+	}
+	for trans, messages := range custom {
+		for tag, message := range messages {
+			if err := registerTranslation(v, trans, tag, message); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func registerTranslation(v *validator.Validate, trans ut.Translator, tag, message string) error {
+	return v.RegisterTranslation(tag, trans,
+		func(t ut.Translator) error { return t.Add(tag, message, true) },
+		func(t ut.Translator, fe validator.FieldError) string {
+			msg, _ := t.T(tag, fe.Field())
+			return msg
+		},
+	)
+}
+
+func validateUsername(fl validator.FieldLevel) bool {
+	return usernamePattern.MatchString(fl.Field().String())
+}
+
+func (p PasswordPolicy) validate(fl validator.FieldLevel) bool {
+	password := fl.Field().String()
+	if len(password) < p.MinLength || (p.MaxLength > 0 && len(password) > p.MaxLength) {
+		return false
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+	return (!p.RequireUpper || upper) && (!p.RequireLower || lower) &&
+		(!p.RequireDigit || digit) && (!p.RequireSymbol || symbol)
+}
+
+// describe menjelaskan policy dalam bahasa yang diminta untuk pesan error
+func (p PasswordPolicy) describe(lang string) string {
+	type rule struct {
+		enabled bool
+		en, id  string
+	}
+	rules := []rule{
+		{p.RequireUpper, "an uppercase letter", "huruf besar"},
+		{p.RequireLower, "a lowercase letter", "huruf kecil"},
+		{p.RequireDigit, "a digit", "angka"},
+		{p.RequireSymbol, "a symbol", "simbol"},
+	}
+	var parts []string
+	for _, r := range rules {
+		if r.enabled {
+			if lang == "id" {
+				parts = append(parts, r.id)
+			} else {
+				parts = append(parts, r.en)
+			}
+		}
+	}
+
+	if lang == "id" {
+		msg := fmt.Sprintf("harus terdiri dari %d sampai %d karakter", p.MinLength, p.MaxLength)
+		if len(parts) > 0 {
+			msg += " dan mengandung " + strings.Join(parts, ", ")
+		}
+		return msg
+	}
+	msg := fmt.Sprintf("must be %d to %d characters long", p.MinLength, p.MaxLength)
+	if len(parts) > 0 {
+		msg += " and contain " + strings.Join(parts, ", ")
+	}
+	return msg
+}
+
+// FieldErrors mengubah error dari validator menjadi daftar apperror.FieldError
+// yang diterjemahkan sesuai header Accept-Language. ok bernilai false bila err
+// bukan error validasi (misalnya JSON rusak).
+func FieldErrors(err error, acceptLanguage string) (fields []apperror.FieldError, ok bool) {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return nil, false
+	}
+
+	trans := translator(acceptLanguage)
+	for _, fe := range verrs {
+		message := fe.Error()
+		if trans != nil {
+			message = fe.Translate(trans)
+		}
+		fields = append(fields, apperror.FieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Message: message,
+		})
+	}
+	return fields, true
+}
+
+// translator memilih translator dari header Accept-Language, fallback ke bahasa Inggris
+func translator(acceptLanguage string) ut.Translator {
+	if universal == nil {
+		return nil
+	}
+	var locales []string
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		base, _, _ := strings.Cut(tag, "-")
+		if base != "" {
+			locales = append(locales, strings.ToLower(base))
+		}
+	}
+	trans, _ := universal.FindTranslator(locales...)
+	return trans
+}
@@ -0,0 +1,270 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"wyw/logging"
+	"wyw/metric"
+	"wyw/outbox"
+	"wyw/retry"
+	"wyw/textutil"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrNotFound dikembalikan bila subscription atau delivery tidak ada
+var ErrNotFound = errors.New("webhook: not found")
+
+// Options mengatur pengiriman dan retry webhook
+type Options struct {
+	PollInterval time.Duration
+	Timeout      time.Duration
+	// Concurrency adalah jumlah delivery yang dikirim bersamaan
+	Concurrency int
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+	// MaxAttempts adalah batas percobaan sebelum delivery masuk status dead
+	MaxAttempts int
+}
+
+// Dispatcher mengirim delivery yang jatuh tempo ke URL subscription dengan signature
+// HMAC-SHA256, mencoba ulang dengan backoff eksponensial, dan menyimpan riwayat percobaan
+type Dispatcher struct {
+	db      *gorm.DB
+	client  *http.Client
+	metrics *metric.AppMetricsExporter
+	opts    Options
+}
+
+// NewDispatcher membuat Dispatcher dengan nilai default untuk opsi yang kosong
+func NewDispatcher(db *gorm.DB, metrics *metric.AppMetricsExporter, opts Options) *Dispatcher {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = 10 * time.Second
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = time.Hour
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 8
+	}
+	return &Dispatcher{
+		db:      db,
+		client:  &http.Client{Timeout: opts.Timeout},
+		metrics: metrics,
+		opts:    opts,
+	}
+}
+
+// Name memenuhi outbox.Sink
+func (d *Dispatcher) Name() string { return "webhook_subscriptions" }
+
+// Deliver memenuhi outbox.Sink: event dari outbox dipecah menjadi satu delivery untuk setiap
+// subscription aktif yang cocok. Pengiriman HTTP-nya dilakukan oleh Run, sehingga endpoint
+// yang lambat atau mati tidak menahan relay outbox.
+func (d *Dispatcher) Deliver(ctx context.Context, msg outbox.Message) error {
+	var subs []Subscription
+	if err := d.db.WithContext(ctx).Where("active = ?", true).Find(&subs).Error; err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	var deliveries []Delivery
+	for _, sub := range subs {
+		if !sub.Matches(msg.EventType) {
+			continue
+		}
+		deliveries = append(deliveries, Delivery{
+			SubscriptionID: sub.ID,
+			EventID:        msg.ID,
+			EventType:      msg.EventType,
+			Payload:        string(body),
+			Status:         StatusPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	// Outbox bersifat at-least-once, event yang sama tidak boleh membuat delivery ganda
+	return d.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error
+}
+
+// Run mengirim delivery yang jatuh tempo sampai ctx selesai
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.RunOnce(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			logging.Logger(logging.ModuleHTTP).Error("webhook dispatch failed", slog.Any("error", err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce mengirim satu batch delivery yang jatuh tempo
+func (d *Dispatcher) RunOnce(ctx context.Context) error {
+	var due []Delivery
+	err := d.db.WithContext(ctx).
+		Where("status IN ? AND next_attempt_at <= ?", []string{StatusPending, StatusRetrying}, time.Now()).
+		Order("next_attempt_at ASC").Limit(d.opts.Concurrency * 10).Find(&due).Error
+	if err != nil {
+		return err
+	}
+
+	sem := make(chan struct{}, d.opts.Concurrency)
+	var wg sync.WaitGroup
+	for _, delivery := range due {
+		if !d.claim(ctx, &delivery) {
+			continue
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(delivery Delivery) {
+			defer func() { <-sem; wg.Done() }()
+			if err := d.attempt(ctx, delivery); err != nil {
+				logging.Logger(logging.ModuleHTTP).Error("failed to store webhook attempt",
+					slog.Uint64("delivery_id", delivery.ID), slog.Any("error", err))
+			}
+		}(delivery)
+	}
+	wg.Wait()
+	return nil
+}
+
+// claim menggeser next_attempt_at secara kondisional supaya instance lain yang membaca
+// delivery yang sama tidak mengirimnya bersamaan
+func (d *Dispatcher) claim(ctx context.Context, delivery *Delivery) bool {
+	lease := time.Now().Add(2 * d.opts.Timeout)
+	res := d.db.WithContext(ctx).Model(&Delivery{}).
+		Where("id = ? AND next_attempt_at = ?", delivery.ID, delivery.NextAttemptAt).
+		Update("next_attempt_at", lease)
+	if res.Error != nil || res.RowsAffected != 1 {
+		return false
+	}
+	delivery.NextAttemptAt = lease
+	return true
+}
+
+func (d *Dispatcher) attempt(ctx context.Context, delivery Delivery) error {
+	var sub Subscription
+	if err := d.db.WithContext(ctx).First(&sub, delivery.SubscriptionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return d.db.WithContext(ctx).Model(&delivery).Updates(map[string]any{
+				"status": StatusDead, "last_error": "subscription deleted",
+			}).Error
+		}
+		return err
+	}
+
+	start := time.Now()
+	status, sendErr := d.send(ctx, sub, delivery)
+	elapsed := time.Since(start)
+
+	attempt := Attempt{DeliveryID: delivery.ID, StatusCode: status, DurationMs: elapsed.Milliseconds(), CreatedAt: start}
+	updates := map[string]any{"attempts": delivery.Attempts + 1, "last_status": status}
+	subscription := strconv.FormatUint(uint64(sub.ID), 10)
+
+	switch {
+	case sendErr == nil:
+		updates["status"] = StatusSucceeded
+		updates["delivered_at"] = time.Now()
+		updates["last_error"] = ""
+		d.metrics.ObserveWebhookDelivery(subscription, "success", elapsed)
+	case delivery.Attempts+1 >= d.opts.MaxAttempts:
+		attempt.Error = textutil.Truncate(sendErr.Error(), 512)
+		updates["status"] = StatusDead
+		updates["last_error"] = attempt.Error
+		d.metrics.ObserveWebhookDelivery(subscription, "dead", elapsed)
+		logging.Logger(logging.ModuleHTTP).Warn("webhook delivery moved to dead letter",
+			slog.Uint64("delivery_id", delivery.ID), slog.String("subscription", subscription), slog.Any("error", sendErr))
+	default:
+		attempt.Error = textutil.Truncate(sendErr.Error(), 512)
+		updates["status"] = StatusRetrying
+		updates["last_error"] = attempt.Error
+		updates["next_attempt_at"] = time.Now().Add(retry.Backoff(delivery.Attempts+1, d.opts.MinBackoff, d.opts.MaxBackoff))
+		d.metrics.ObserveWebhookDelivery(subscription, "failure", elapsed)
+	}
+
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&attempt).Error; err != nil {
+			return err
+		}
+		return tx.Model(&delivery).Updates(updates).Error
+	})
+}
+
+func (d *Dispatcher) send(ctx context.Context, sub Subscription, delivery Delivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "example-monitoring-webhooks/1.0")
+	req.Header.Set(HeaderID, strconv.FormatUint(delivery.ID, 10))
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, now, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook: endpoint responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// Redeliver menjadwalkan ulang sebuah delivery, termasuk yang sudah dead atau berhasil,
+// untuk dikirim secepatnya dengan hitungan percobaan baru
+func (d *Dispatcher) Redeliver(ctx context.Context, id uint64) (*Delivery, error) {
+	res := d.db.WithContext(ctx).Model(&Delivery{}).Where("id = ?", id).Updates(map[string]any{
+		"status":          StatusPending,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+	})
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrNotFound
+	}
+	var delivery Delivery
+	if err := d.db.WithContext(ctx).First(&delivery, id).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
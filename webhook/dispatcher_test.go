@@ -0,0 +1,207 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+	"wyw/metric"
+	"wyw/outbox"
+	"wyw/testutil"
+
+	"gorm.io/gorm"
+)
+
+const testSecret = "whsec_test"
+
+// request adalah delivery yang diterima receiver setelah signature-nya lolos Verify
+type request struct {
+	Header http.Header
+	Body   []byte
+}
+
+// newSignedReceiver menolak dengan 400 setiap request yang signature atau timestamp-nya
+// tidak lolos Verify, seperti yang dilakukan penerima sungguhan
+func newSignedReceiver(t *testing.T) *testutil.Receiver[request] {
+	t.Helper()
+	return testutil.NewReceiver(t, func(r *http.Request) (request, error) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return request{}, err
+		}
+		err = Verify(testSecret, r.Header.Get(HeaderTimestamp), r.Header.Get(HeaderSignature), body, 5*time.Minute, time.Now())
+		return request{Header: r.Header.Clone(), Body: body}, err
+	})
+}
+
+func newTestDispatcher(t *testing.T, url string, opts Options) (*Dispatcher, *gorm.DB) {
+	t.Helper()
+	db := testutil.NewDB(t)
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	sub := Subscription{URL: url, Events: "register,login", Secret: testSecret, Active: true}
+	if err := db.Create(&sub).Error; err != nil {
+		t.Fatal(err)
+	}
+	return NewDispatcher(db, metric.NewAppMetricsExporter(), opts), db
+}
+
+func deliver(t *testing.T, d *Dispatcher, id uint64, eventType string) {
+	t.Helper()
+	msg := outbox.Message{ID: id, Aggregate: "user:alice", EventType: eventType, Payload: json.RawMessage(`{"username":"alice"}`)}
+	if err := d.Deliver(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func runOnce(t *testing.T, d *Dispatcher) {
+	t.Helper()
+	if err := d.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func loadDelivery(t *testing.T, db *gorm.DB, id uint64) Delivery {
+	t.Helper()
+	var delivery Delivery
+	if err := db.First(&delivery, id).Error; err != nil {
+		t.Fatal(err)
+	}
+	return delivery
+}
+
+// makeDue membuat semua delivery jatuh tempo tanpa menunggu backoff
+func makeDue(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	if err := db.Model(&Delivery{}).Where("1 = 1").Update("next_attempt_at", time.Now().Add(-time.Second)).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDispatcherSignsDeliveries(t *testing.T) {
+	rc := newSignedReceiver(t)
+	d, db := newTestDispatcher(t, rc.URL, Options{})
+	deliver(t, d, 7, "register")
+	deliver(t, d, 8, "password_reset")
+	runOnce(t, d)
+
+	got := rc.Received()
+	if len(got) != 1 {
+		t.Fatalf("receiver accepted %d deliveries, want only the subscribed event", len(got))
+	}
+	req := got[0]
+	if req.Header.Get(HeaderEvent) != "register" || req.Header.Get(HeaderID) != "1" {
+		t.Fatalf("headers %s=%q %s=%q", HeaderEvent, req.Header.Get(HeaderEvent), HeaderID, req.Header.Get(HeaderID))
+	}
+	var msg outbox.Message
+	if err := json.Unmarshal(req.Body, &msg); err != nil || msg.ID != 7 {
+		t.Fatalf("body = %s, want the outbox message", req.Body)
+	}
+	if delivery := loadDelivery(t, db, 1); delivery.Status != StatusSucceeded || delivery.Attempts != 1 || delivery.DeliveredAt == nil {
+		t.Fatalf("delivery = %+v, want succeeded after one attempt", delivery)
+	}
+
+	ts, sig := req.Header.Get(HeaderTimestamp), req.Header.Get(HeaderSignature)
+	for _, tc := range []struct {
+		name   string
+		secret string
+		body   []byte
+	}{
+		{name: "wrong secret", secret: "whsec_other", body: req.Body},
+		{name: "tampered body", secret: testSecret, body: append([]byte(" "), req.Body...)},
+	} {
+		if err := Verify(tc.secret, ts, sig, tc.body, 5*time.Minute, time.Now()); !errors.Is(err, ErrInvalidSignature) {
+			t.Fatalf("%s: Verify = %v, want ErrInvalidSignature", tc.name, err)
+		}
+	}
+}
+
+func TestVerifyRejectsStaleTimestamp(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	signedAt := time.Unix(1700000000, 0)
+	ts, sig := "1700000000", Sign(testSecret, signedAt, body)
+
+	if err := Verify(testSecret, ts, sig, body, 5*time.Minute, signedAt.Add(4*time.Minute)); err != nil {
+		t.Fatalf("Verify within tolerance = %v", err)
+	}
+	for _, now := range []time.Time{signedAt.Add(6 * time.Minute), signedAt.Add(-6 * time.Minute)} {
+		if err := Verify(testSecret, ts, sig, body, 5*time.Minute, now); !errors.Is(err, ErrStaleTimestamp) {
+			t.Fatalf("Verify %s after signing = %v, want ErrStaleTimestamp", now.Sub(signedAt), err)
+		}
+	}
+	// Timestamp yang diganti supaya lolos toleransi membuat signature tidak cocok
+	if err := Verify(testSecret, "1700000300", sig, body, 5*time.Minute, signedAt.Add(6*time.Minute)); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("Verify with a replaced timestamp = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestDispatcherRetryDeadAndRedeliver(t *testing.T) {
+	rc := newSignedReceiver(t)
+	rc.SetStatus(func(request) int { return http.StatusServiceUnavailable })
+	d, db := newTestDispatcher(t, rc.URL, Options{MaxAttempts: 2, MinBackoff: time.Hour, MaxBackoff: 2 * time.Hour})
+	deliver(t, d, 1, "register")
+	if delivery := loadDelivery(t, db, 1); delivery.Status != StatusPending {
+		t.Fatalf("new delivery status = %s, want %s", delivery.Status, StatusPending)
+	}
+
+	runOnce(t, d)
+	delivery := loadDelivery(t, db, 1)
+	if delivery.Status != StatusRetrying || delivery.Attempts != 1 || delivery.LastStatus != http.StatusServiceUnavailable {
+		t.Fatalf("delivery after a failure = %+v, want retrying", delivery)
+	}
+	if time.Until(delivery.NextAttemptAt) < 50*time.Minute {
+		t.Fatalf("next attempt at %s, want the one hour backoff", delivery.NextAttemptAt)
+	}
+	// Delivery yang belum jatuh tempo tidak dikirim
+	runOnce(t, d)
+	if delivery := loadDelivery(t, db, 1); delivery.Attempts != 1 {
+		t.Fatalf("delivery was retried before its backoff: %d attempts", delivery.Attempts)
+	}
+
+	makeDue(t, db)
+	runOnce(t, d)
+	if delivery := loadDelivery(t, db, 1); delivery.Status != StatusDead || delivery.Attempts != 2 || delivery.LastError == "" {
+		t.Fatalf("delivery after the last attempt = %+v, want dead", delivery)
+	}
+	makeDue(t, db)
+	runOnce(t, d)
+	if delivery := loadDelivery(t, db, 1); delivery.Attempts != 2 {
+		t.Fatal("dead delivery was retried automatically")
+	}
+
+	rc.SetStatus(nil)
+	redelivered, err := d.Redeliver(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if redelivered.Status != StatusPending || redelivered.Attempts != 0 {
+		t.Fatalf("redelivered = %+v, want pending with a fresh attempt count", redelivered)
+	}
+	runOnce(t, d)
+	if delivery := loadDelivery(t, db, 1); delivery.Status != StatusSucceeded || delivery.LastError != "" {
+		t.Fatalf("delivery after redelivery = %+v, want succeeded", delivery)
+	}
+	if len(rc.Received()) != 1 {
+		t.Fatalf("receiver accepted %d deliveries, want 1", len(rc.Received()))
+	}
+
+	var attempts []Attempt
+	if err := db.Order("id ASC").Find(&attempts, "delivery_id = ?", 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	codes := make([]int, len(attempts))
+	for i, a := range attempts {
+		codes[i] = a.StatusCode
+	}
+	if len(codes) != 3 || codes[0] != 503 || codes[1] != 503 || codes[2] != 200 || attempts[0].Error == "" {
+		t.Fatalf("attempt history = %v, want 503, 503, 200", codes)
+	}
+
+	if _, err := d.Redeliver(context.Background(), 42); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Redeliver of an unknown delivery = %v, want ErrNotFound", err)
+	}
+}
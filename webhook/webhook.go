@@ -0,0 +1,135 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Header yang dikirim pada setiap delivery
+const (
+	HeaderID        = "X-Webhook-ID"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// AllEvents dipakai di filter subscription untuk menerima semua event
+const AllEvents = "*"
+
+// Status delivery
+const (
+	StatusPending   = "pending"
+	StatusRetrying  = "retrying"
+	StatusSucceeded = "succeeded"
+	// StatusDead berarti delivery melewati batas percobaan dan hanya dikirim ulang secara manual
+	StatusDead = "dead"
+)
+
+var (
+	ErrInvalidSignature = errors.New("webhook: invalid signature")
+	ErrStaleTimestamp   = errors.New("webhook: timestamp outside tolerance")
+)
+
+// Subscription adalah endpoint milik tim lain yang menerima event lifecycle user
+type Subscription struct {
+	ID  uint   `json:"id" gorm:"primaryKey"`
+	URL string `json:"url" gorm:"size:2048"`
+	// Events berisi daftar event yang dipisah koma, atau "*" untuk semua event
+	Events    string    `json:"events" gorm:"size:512"`
+	Secret    string    `json:"-" gorm:"size:128"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Subscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// EventList mengembalikan filter event sebagai slice
+func (s Subscription) EventList() []string {
+	return strings.Split(s.Events, ",")
+}
+
+// Matches melaporkan apakah subscription menerima eventType
+func (s Subscription) Matches(eventType string) bool {
+	events := s.EventList()
+	return slices.Contains(events, AllEvents) || slices.Contains(events, eventType)
+}
+
+// Delivery adalah pengiriman satu event ke satu subscription beserta status retry-nya
+type Delivery struct {
+	ID             uint64 `json:"id" gorm:"primaryKey"`
+	SubscriptionID uint   `json:"subscription_id" gorm:"uniqueIndex:idx_webhook_delivery_event;index"`
+	// EventID adalah ID message outbox, unik per subscription supaya redelivery dari
+	// outbox tidak membuat delivery ganda
+	EventID       uint64     `json:"event_id" gorm:"uniqueIndex:idx_webhook_delivery_event"`
+	EventType     string     `json:"event_type" gorm:"size:64"`
+	Payload       string     `json:"payload" gorm:"type:text"`
+	Status        string     `json:"status" gorm:"index:idx_webhook_delivery_due;size:16"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"index:idx_webhook_delivery_due"`
+	LastStatus    int        `json:"last_status_code,omitempty"`
+	LastError     string     `json:"last_error,omitempty" gorm:"size:512"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+}
+
+func (Delivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// Attempt adalah riwayat satu percobaan pengiriman
+type Attempt struct {
+	ID         uint64    `json:"id" gorm:"primaryKey"`
+	DeliveryID uint64    `json:"delivery_id" gorm:"index"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty" gorm:"size:512"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (Attempt) TableName() string {
+	return "webhook_attempts"
+}
+
+// Migrate memastikan tabel webhook ada
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(&Subscription{}, &Delivery{}, &Attempt{})
+}
+
+// Sign menghitung signature "v1=<hex>" dari HMAC-SHA256 atas "<timestamp>.<body>".
+// Timestamp ikut ditandatangani sehingga penerima bisa menolak request lama yang diputar ulang.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify memeriksa header signature dan timestamp di sisi penerima. Request yang
+// timestamp-nya berselisih lebih dari tolerance dari now ditolak.
+func Verify(secret, timestampHeader, signatureHeader string, body []byte, tolerance time.Duration, now time.Time) error {
+	unix, err := strconv.ParseInt(timestampHeader, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	ts := time.Unix(unix, 0)
+	if now.Sub(ts).Abs() > tolerance {
+		return ErrStaleTimestamp
+	}
+	expected := Sign(secret, ts, body)
+	if !hmac.Equal([]byte(expected), []byte(signatureHeader)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}